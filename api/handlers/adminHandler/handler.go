@@ -0,0 +1,370 @@
+package adminHandler
+
+import (
+	"Medscribe/api/middleware"
+	"Medscribe/audioStore"
+	auditLog "Medscribe/auditLogStore"
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	"Medscribe/user"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// Audit log export formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+var exportContentTypes = map[string]string{
+	ExportFormatCSV:    "text/csv",
+	ExportFormatNDJSON: "application/x-ndjson",
+}
+
+var csvHeader = []string{"sequence", "timestamp", "actor", "action", "reportIDs", "ipAddress", "userAgent", "correlationID", "prevHash", "hash"}
+
+// AuditLogResponse is a page of audit log entries. NextAfter is passed as "after" to fetch the next page.
+type AuditLogResponse struct {
+	Entries   []auditLog.Entry `json:"entries"`
+	NextAfter int64            `json:"nextAfter,omitempty"`
+}
+
+// VerifyAuditLogResponse reports whether the audit log's hash chain is intact.
+type VerifyAuditLogResponse struct {
+	Valid    bool   `json:"valid"`
+	Verified int64  `json:"verified"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// SetSupervisorRequest assigns the supervisor who co-signs a user's notes. An empty SupervisorID removes it.
+type SetSupervisorRequest struct {
+	UserID       string `json:"userID"`
+	SupervisorID string `json:"supervisorID"`
+}
+
+// LegalHoldRequest places a legal hold on a report when Hold is set and releases it otherwise.
+type LegalHoldRequest struct {
+	ReportID string `json:"reportID"`
+	Hold     bool   `json:"hold"`
+	Reason   string `json:"reason"`
+}
+
+// AdminHandler serves the administrator API. Every route must sit behind the auth and admin middleware.
+type AdminHandler interface {
+	QueryAuditLog(w http.ResponseWriter, r *http.Request)
+	ExportAuditLog(w http.ResponseWriter, r *http.Request)
+	VerifyAuditLog(w http.ResponseWriter, r *http.Request)
+	SetSupervisor(w http.ResponseWriter, r *http.Request)
+	SetLegalHold(w http.ResponseWriter, r *http.Request)
+}
+
+type adminHandler struct {
+	auditLog auditLog.AuditLogStore
+	users    user.UserStore
+	reports  reports.Reports
+	audio    audioStore.AudioStore
+}
+
+func NewAdminHandler(auditLogStore auditLog.AuditLogStore, userStore user.UserStore, reportsStore reports.Reports, audio audioStore.AudioStore) AdminHandler {
+	return &adminHandler{auditLog: auditLogStore, users: userStore, reports: reportsStore, audio: audio}
+}
+
+// QueryAuditLog returns audit log entries filtered by the actor, action, reportID, from and to query
+// parameters, in pages of at most limit entries after the after sequence number.
+func (h *adminHandler) QueryAuditLog(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.audit(w, r, auditLog.ActionQueryAuditLog, filter) {
+		return
+	}
+
+	entries, err := h.auditLog.Query(r.Context(), filter)
+	if err != nil {
+		logger.Error("Error querying audit log", zap.Error(err))
+		http.Error(w, "error querying audit log", http.StatusInternalServerError)
+		return
+	}
+	response := AuditLogResponse{Entries: entries}
+	if len(entries) > 0 {
+		response.NextAfter = entries[len(entries)-1].Sequence
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Error encoding audit log", zap.Error(err))
+		http.Error(w, "error encoding audit log", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ExportAuditLog streams every entry matching the query parameters of QueryAuditLog as a CSV or
+// NDJSON download, chosen by the format query parameter.
+func (h *adminHandler) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportFormatCSV
+	}
+
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported export format: %s", format), http.StatusBadRequest)
+		return
+	}
+	if !h.audit(w, r, auditLog.ActionExportAuditLog, filter) {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Cache-Control", "no-store")
+
+	exported, err := h.export(r.Context(), w, filter, format)
+	if err != nil {
+		// The download has already started, so the error can only be logged.
+		logger.Error("Error exporting audit log", zap.Int("Exported", exported), zap.Error(err))
+		return
+	}
+
+	logger.Info("Audit log exported", zap.String("Format", format), zap.Int("Exported", exported))
+}
+
+// VerifyAuditLog checks the audit log's hash chain from the first entry to the last.
+func (h *adminHandler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	if !h.audit(w, r, auditLog.ActionVerifyAuditLog, auditLog.Filter{}) {
+		return
+	}
+
+	verified, err := h.auditLog.Verify(r.Context())
+	response := VerifyAuditLogResponse{Valid: err == nil, Verified: verified}
+	var chainErr *auditLog.ChainError
+	if errors.As(err, &chainErr) {
+		logger.Error("Audit log chain is broken", zap.Int64("Sequence", chainErr.Sequence), zap.String("Reason", chainErr.Reason))
+		response.BrokenAt = chainErr.Sequence
+		response.Reason = chainErr.Reason
+	} else if err != nil {
+		logger.Error("Error verifying audit log", zap.Error(err))
+		http.Error(w, "error verifying audit log", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Error encoding audit log verification", zap.Error(err))
+		http.Error(w, "error encoding audit log verification", http.StatusInternalServerError)
+		return
+	}
+}
+
+// SetSupervisor assigns the supervisor who co-signs a user's notes, or removes it. Notes already awaiting
+// co-signature stay with the supervisor they were signed for.
+func (h *adminHandler) SetSupervisor(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	var req SetSupervisorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	req.UserID, req.SupervisorID = strings.TrimSpace(req.UserID), strings.TrimSpace(req.SupervisorID)
+	if req.UserID == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+	if !h.record(w, r, auditLog.ActionSetSupervisor) {
+		return
+	}
+
+	if err := h.users.SetSupervisor(r.Context(), req.UserID, req.SupervisorID); err != nil {
+		logger.Error("Error setting supervisor", zap.String("UserID", req.UserID), zap.String("SupervisorID", req.SupervisorID), zap.Error(err))
+		if errors.Is(err, user.ErrSupervisorNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error setting supervisor", http.StatusBadRequest)
+		return
+	}
+
+	logger.Info("Supervisor updated", zap.String("UserID", req.UserID), zap.String("SupervisorID", req.SupervisorID))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetLegalHold places or releases a legal hold on a report and its recordings. While held, retention
+// never purges them and the report cannot be deleted. Only administrators manage holds, so the provider
+// who owns a report cannot release its hold to delete it.
+func (h *adminHandler) SetLegalHold(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	var req LegalHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Hold && strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "a reason is required to place a legal hold", http.StatusBadRequest)
+		return
+	}
+	reportObjectID, err := primitive.ObjectIDFromHex(req.ReportID)
+	if err != nil {
+		http.Error(w, "invalid report ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.reports.Get(r.Context(), req.ReportID); err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+	if !h.record(w, r, auditLog.ActionLegalHold, req.ReportID) {
+		return
+	}
+
+	var hold *reports.LegalHold
+	if req.Hold {
+		hold = &reports.LegalHold{Reason: strings.TrimSpace(req.Reason), PlacedAt: primitive.NewDateTimeFromTime(time.Now())}
+	}
+	// Recordings are held first, so a failure never leaves a held report with purgeable audio.
+	if err := h.audio.SetLegalHold(r.Context(), reportObjectID, req.Hold); err != nil {
+		logger.Error("Error setting legal hold on report audio", zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "error setting legal hold", http.StatusInternalServerError)
+		return
+	}
+	if err := h.reports.SetLegalHold(r.Context(), req.ReportID, hold); err != nil {
+		logger.Error("Error setting legal hold", zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "error setting legal hold", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Legal hold updated", zap.String("ReportID", req.ReportID), zap.Bool("Hold", req.Hold))
+	w.WriteHeader(http.StatusOK)
+}
+
+// audit records the administrator's access to the audit log itself, with the report filtered on if any.
+func (h *adminHandler) audit(w http.ResponseWriter, r *http.Request, action string, filter auditLog.Filter) bool {
+	var reportIDs []string
+	if filter.ReportID != "" {
+		reportIDs = append(reportIDs, filter.ReportID)
+	}
+	return h.record(w, r, action, reportIDs...)
+}
+
+// record appends the administrator's action to the audit log. It writes the error response itself and returns
+// false when the action could not be recorded.
+func (h *adminHandler) record(w http.ResponseWriter, r *http.Request, action string, reportIDs ...string) bool {
+	if _, err := h.auditLog.Append(r.Context(), middleware.NewAuditEntry(r.Context(), action, reportIDs...)); err != nil {
+		contextLogger.FromCtx(r.Context()).Error("Error recording admin action", zap.String("Action", action), zap.Error(err))
+		http.Error(w, "error recording audit log access", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// parseFilter reads an audit log filter from query parameters. from and to are RFC 3339 timestamps.
+func parseFilter(query url.Values) (auditLog.Filter, error) {
+	filter := auditLog.Filter{
+		Actor:    strings.TrimSpace(query.Get("actor")),
+		Action:   strings.TrimSpace(query.Get("action")),
+		ReportID: strings.TrimSpace(query.Get("reportID")),
+	}
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return auditLog.Filter{}, fmt.Errorf("invalid from: %s", value)
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return auditLog.Filter{}, fmt.Errorf("invalid to: %s", value)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return auditLog.Filter{}, errors.New("from must be before to")
+	}
+	if value := query.Get("after"); value != "" {
+		if filter.AfterSequence, err = strconv.ParseInt(value, 10, 64); err != nil || filter.AfterSequence < 0 {
+			return auditLog.Filter{}, fmt.Errorf("invalid after: %s", value)
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.ParseInt(value, 10, 64); err != nil || filter.Limit <= 0 {
+			return auditLog.Filter{}, fmt.Errorf("invalid limit: %s", value)
+		}
+	}
+	return filter, nil
+}
+
+// export writes the matching entries to w in the format and returns how many were written.
+func (h *adminHandler) export(ctx context.Context, w io.Writer, filter auditLog.Filter, format string) (int, error) {
+	exported := 0
+	if format == ExportFormatNDJSON {
+		encoder := json.NewEncoder(w)
+		err := h.auditLog.Export(ctx, filter, func(entry auditLog.Entry) error {
+			exported++
+			return encoder.Encode(entry)
+		})
+		return exported, err
+	}
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return 0, err
+	}
+	err := h.auditLog.Export(ctx, filter, func(entry auditLog.Entry) error {
+		exported++
+		return csvWriter.Write(csvRecord(entry))
+	})
+	csvWriter.Flush()
+	if err != nil {
+		return exported, err
+	}
+	return exported, csvWriter.Error()
+}
+
+func csvRecord(entry auditLog.Entry) []string {
+	return []string{
+		strconv.FormatInt(entry.Sequence, 10),
+		entry.Timestamp.Time().UTC().Format(time.RFC3339Nano),
+		entry.Actor,
+		entry.Action,
+		strings.Join(entry.ReportIDs, " "),
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.CorrelationID,
+		entry.PrevHash,
+		entry.Hash,
+	}
+}
@@ -0,0 +1,278 @@
+package adminHandler
+
+import (
+	"Medscribe/api/middleware"
+	"Medscribe/audioStore"
+	auditLog "Medscribe/auditLogStore"
+	"Medscribe/reports"
+	"Medscribe/user"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testAdminID = "admin123"
+
+func newAdminRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testAdminID))
+}
+
+// newTestAdminHandler builds an admin handler whose report and recording stores are mocks without expectations.
+func newTestAdminHandler(store *auditLog.MockAuditLogStore, users *user.MockUserStore) AdminHandler {
+	return NewAdminHandler(store, users, new(reports.MockReportsStore), new(audioStore.MockAudioStore))
+}
+
+func adminEntry(action, reportID string) interface{} {
+	return mock.MatchedBy(func(entry auditLog.Entry) bool {
+		return entry.Actor == testAdminID && entry.Action == action &&
+			(reportID == "" && len(entry.ReportIDs) == 0 || len(entry.ReportIDs) == 1 && entry.ReportIDs[0] == reportID)
+	})
+}
+
+func TestQueryAuditLog(t *testing.T) {
+	t.Run("should return the filtered page and record the query", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		handler := newTestAdminHandler(store, new(user.MockUserStore))
+
+		from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		filter := auditLog.Filter{ReportID: "report-1", From: from, AfterSequence: 10, Limit: 2}
+		entries := []auditLog.Entry{{Sequence: 11, Actor: "provider-a"}, {Sequence: 14, Actor: "provider-a"}}
+		store.On("Append", mock.Anything, adminEntry(auditLog.ActionQueryAuditLog, "report-1")).Return(auditLog.Entry{}, nil).Once()
+		store.On("Query", mock.Anything, filter).Return(entries, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.QueryAuditLog(rr, newAdminRequest("/admin/auditLog?reportID=report-1&from=2026-03-01T00:00:00Z&after=10&limit=2"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response AuditLogResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Len(t, response.Entries, 2)
+		assert.Equal(t, int64(14), response.NextAfter)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("should refuse the query when it cannot be recorded", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		handler := newTestAdminHandler(store, new(user.MockUserStore))
+		store.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, errors.New("connection reset")).Once()
+
+		rr := httptest.NewRecorder()
+		handler.QueryAuditLog(rr, newAdminRequest("/admin/auditLog"))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		store.AssertNotCalled(t, "Query", mock.Anything, mock.Anything)
+	})
+
+	for name, target := range map[string]string{
+		"invalid from":      "/admin/auditLog?from=yesterday",
+		"from after to":     "/admin/auditLog?from=2026-03-02T00:00:00Z&to=2026-03-01T00:00:00Z",
+		"invalid limit":     "/admin/auditLog?limit=0",
+		"negative sequence": "/admin/auditLog?after=-1",
+	} {
+		t.Run("should reject "+name, func(t *testing.T) {
+			store := new(auditLog.MockAuditLogStore)
+			rr := httptest.NewRecorder()
+			newTestAdminHandler(store, new(user.MockUserStore)).QueryAuditLog(rr, newAdminRequest(target))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			store.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestExportAuditLog(t *testing.T) {
+	timestamp := primitive.NewDateTimeFromTime(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC))
+	entries := []auditLog.Entry{
+		{Sequence: 1, Actor: "provider-a", Action: auditLog.ActionViewReport, ReportIDs: []string{"report-1"}, IPAddress: "203.0.113.7", Timestamp: timestamp, Hash: "aa"},
+		{Sequence: 2, Actor: "provider-a", Action: auditLog.ActionDeleteReport, ReportIDs: []string{"report-1", "report-2"}, Timestamp: timestamp, PrevHash: "aa", Hash: "bb"},
+	}
+
+	t.Run("should export entries as CSV by default", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		store.On("Append", mock.Anything, adminEntry(auditLog.ActionExportAuditLog, "")).Return(auditLog.Entry{}, nil).Once()
+		store.On("Export", mock.Anything, auditLog.Filter{Actor: "provider-a"}, mock.Anything).Return(entries, nil).Once()
+
+		rr := httptest.NewRecorder()
+		newTestAdminHandler(store, new(user.MockUserStore)).ExportAuditLog(rr, newAdminRequest("/admin/auditLog/export?actor=provider-a"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), ".csv")
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, csvHeader, records[0])
+		assert.Equal(t, []string{"1", "2026-03-01T09:30:00Z", "provider-a", auditLog.ActionViewReport, "report-1", "203.0.113.7", "", "", "", "aa"}, records[1])
+		assert.Equal(t, "report-1 report-2", records[2][4])
+		store.AssertExpectations(t)
+	})
+
+	t.Run("should export entries as NDJSON", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		store.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil).Once()
+		store.On("Export", mock.Anything, auditLog.Filter{}, mock.Anything).Return(entries, nil).Once()
+
+		rr := httptest.NewRecorder()
+		newTestAdminHandler(store, new(user.MockUserStore)).ExportAuditLog(rr, newAdminRequest("/admin/auditLog/export?format=ndjson"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var exported auditLog.Entry
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &exported))
+		assert.Equal(t, "bb", exported.Hash)
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		rr := httptest.NewRecorder()
+		newTestAdminHandler(store, new(user.MockUserStore)).ExportAuditLog(rr, newAdminRequest("/admin/auditLog/export?format=xlsx"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		store.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified int64
+		err      error
+		wantCode int
+		want     VerifyAuditLogResponse
+	}{
+		{name: "intact chain", verified: 42, wantCode: http.StatusOK, want: VerifyAuditLogResponse{Valid: true, Verified: 42}},
+		{
+			name:     "broken chain",
+			verified: 6,
+			err:      &auditLog.ChainError{Sequence: 7, Reason: "hash does not match the entry's contents"},
+			wantCode: http.StatusOK,
+			want:     VerifyAuditLogResponse{Verified: 6, BrokenAt: 7, Reason: "hash does not match the entry's contents"},
+		},
+		{name: "read failure", err: errors.New("connection reset"), wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(auditLog.MockAuditLogStore)
+			store.On("Append", mock.Anything, adminEntry(auditLog.ActionVerifyAuditLog, "")).Return(auditLog.Entry{}, nil).Once()
+			store.On("Verify", mock.Anything).Return(tt.verified, tt.err).Once()
+
+			rr := httptest.NewRecorder()
+			newTestAdminHandler(store, new(user.MockUserStore)).VerifyAuditLog(rr, newAdminRequest("/admin/auditLog/verify"))
+
+			require.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var response VerifyAuditLogResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, tt.want, response)
+		})
+	}
+}
+
+func TestSetSupervisor(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPatch, "/admin/supervisor", strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testAdminID))
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		storeErr     error
+		expectedCode int
+	}{
+		{"assigns the supervisor", `{"userID":"provider-a","supervisorID":"provider-b"}`, nil, http.StatusOK},
+		{"supervisor does not exist", `{"userID":"provider-a","supervisorID":"missing"}`, user.ErrSupervisorNotFound, http.StatusNotFound},
+		{"invalid assignment", `{"userID":"provider-a","supervisorID":"provider-a"}`, errors.New("a user cannot supervise themselves"), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, users := new(auditLog.MockAuditLogStore), new(user.MockUserStore)
+			var req SetSupervisorRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			store.On("Append", mock.Anything, adminEntry(auditLog.ActionSetSupervisor, "")).Return(auditLog.Entry{}, nil).Once()
+			users.On("SetSupervisor", mock.Anything, req.UserID, req.SupervisorID).Return(tt.storeErr).Once()
+
+			rr := httptest.NewRecorder()
+			newTestAdminHandler(store, users).SetSupervisor(rr, newRequest(tt.body))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			store.AssertExpectations(t)
+			users.AssertExpectations(t)
+		})
+	}
+
+	t.Run("should require the user", func(t *testing.T) {
+		store, users := new(auditLog.MockAuditLogStore), new(user.MockUserStore)
+		rr := httptest.NewRecorder()
+		newTestAdminHandler(store, users).SetSupervisor(rr, newRequest(`{"supervisorID":"provider-b"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		users.AssertNotCalled(t, "SetSupervisor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetLegalHold(t *testing.T) {
+	reportID := primitive.NewObjectID()
+	newRequest := func(userID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPatch, "/admin/legalHold", strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, userID))
+	}
+	newHandler := func() (AdminHandler, *auditLog.MockAuditLogStore, *reports.MockReportsStore, *audioStore.MockAudioStore) {
+		store, reportsStore, audio := new(auditLog.MockAuditLogStore), new(reports.MockReportsStore), new(audioStore.MockAudioStore)
+		reportsStore.On("Get", mock.Anything, reportID.Hex()).Return(reports.Report{ID: reportID, ProviderID: "provider-a"}, nil)
+		return NewAdminHandler(store, new(user.MockUserStore), reportsStore, audio), store, reportsStore, audio
+	}
+
+	t.Run("should place a hold on the report and its recordings", func(t *testing.T) {
+		handler, store, reportsStore, audio := newHandler()
+		store.On("Append", mock.Anything, adminEntry(auditLog.ActionLegalHold, reportID.Hex())).Return(auditLog.Entry{}, nil).Once()
+		audio.On("SetLegalHold", mock.Anything, reportID, true).Return(nil).Once()
+		reportsStore.On("SetLegalHold", mock.Anything, reportID.Hex(), mock.MatchedBy(func(hold *reports.LegalHold) bool {
+			return hold != nil && hold.Reason == "litigation"
+		})).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.SetLegalHold(rr, newRequest(testAdminID, `{"reportID":"`+reportID.Hex()+`","hold":true,"reason":" litigation "}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		store.AssertExpectations(t)
+		audio.AssertExpectations(t)
+		reportsStore.AssertExpectations(t)
+	})
+
+	t.Run("should require a reason to place a hold", func(t *testing.T) {
+		handler, _, reportsStore, _ := newHandler()
+		rr := httptest.NewRecorder()
+		handler.SetLegalHold(rr, newRequest(testAdminID, `{"reportID":"`+reportID.Hex()+`","hold":true}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		reportsStore.AssertNotCalled(t, "SetLegalHold", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should forbid the owner of the report from releasing its hold", func(t *testing.T) {
+		handler, _, reportsStore, audio := newHandler()
+		route := middleware.NewAdminMiddleware([]string{testAdminID})(http.HandlerFunc(handler.SetLegalHold))
+
+		rr := httptest.NewRecorder()
+		route.ServeHTTP(rr, newRequest("provider-a", `{"reportID":"`+reportID.Hex()+`","hold":false}`))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		audio.AssertNotCalled(t, "SetLegalHold", mock.Anything, mock.Anything, mock.Anything)
+		reportsStore.AssertNotCalled(t, "SetLegalHold", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -2,25 +2,52 @@ package reportsHandler
 
 import (
 	"Medscribe/api/middleware"
+	"Medscribe/audioStore"
+	auditLog "Medscribe/auditLogStore"
+	emailsender "Medscribe/emailService"
 	inferenceService "Medscribe/inference/service"
 	contextLogger "Medscribe/logger"
 	"Medscribe/reports"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	transcriptRevisions "Medscribe/transcriptRevisionStore"
+	transcriber "Medscribe/transcription"
+	uploads "Medscribe/uploadStore"
 	"Medscribe/user"
 	"Medscribe/utils"
+	"bytes"
 	"context"
 
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 )
 
+const (
+	// maxFormMemory is how much of a multipart form is held in memory before the rest spills to disk.
+	maxFormMemory = 8 << 20
+	// maxFormOverhead allows for the metadata and multipart framing around the audio file of a form.
+	maxFormOverhead = 1 << 20
+	// maxTranscriptRequestSize caps a report request that carries its transcript, ample for a long
+	// diarized visit.
+	maxTranscriptRequestSize = 10 << 20
+)
+
 type ReportsHandler interface {
 	GenerateReport(w http.ResponseWriter, r *http.Request)
+	GenerateReportFromUpload(w http.ResponseWriter, r *http.Request)
+	GenerateReportFromTranscript(w http.ResponseWriter, r *http.Request)
+	AppendRecording(w http.ResponseWriter, r *http.Request)
 	RegenerateReport(w http.ResponseWriter, r *http.Request)
 	LearnStyle(w http.ResponseWriter, r *http.Request)
 	ChangeReportName(w http.ResponseWriter, r *http.Request)
@@ -28,8 +55,20 @@ type ReportsHandler interface {
 	GetReport(w http.ResponseWriter, r *http.Request)
 	DeleteReport(w http.ResponseWriter, r *http.Request)
 	GetTranscript(w http.ResponseWriter, r *http.Request)
+	ExportTranscript(w http.ResponseWriter, r *http.Request)
 	MarkRead(w http.ResponseWriter, r *http.Request)
 	MarkUnread(w http.ResponseWriter, r *http.Request)
+	RelabelSpeaker(w http.ResponseWriter, r *http.Request)
+	UpdateTranscript(w http.ResponseWriter, r *http.Request)
+	GetTranscriptRevisions(w http.ResponseWriter, r *http.Request)
+	GetSectionRevisions(w http.ResponseWriter, r *http.Request)
+	DiffSectionRevisions(w http.ResponseWriter, r *http.Request)
+	RestoreSectionRevision(w http.ResponseWriter, r *http.Request)
+	GetAudio(w http.ResponseWriter, r *http.Request)
+	GenerateAfterVisitSummary(w http.ResponseWriter, r *http.Request)
+	SignReport(w http.ResponseWriter, r *http.Request)
+	AddAddendum(w http.ResponseWriter, r *http.Request)
+	ExportNote(w http.ResponseWriter, r *http.Request)
 }
 type GetReportRequest struct {
 	ReportID string `json:"reportID"`
@@ -45,22 +84,153 @@ type LearnStyleRequest struct {
 	Current        string `json:"current"`
 }
 
+// SignReportRequest signs a report at Version, the version the signer reviewed. Attestation defaults to
+// reports.DefaultAttestation.
+type SignReportRequest struct {
+	ReportID    string `json:"reportID"`
+	Version     int64  `json:"version"`
+	Attestation string `json:"attestation"`
+}
+
+// SignReportResponse is the recorded signature and the report's status after signing: signed, or
+// awaiting co-signature when the signer is supervised.
+type SignReportResponse struct {
+	Status    string            `json:"status"`
+	Signature reports.Signature `json:"signature"`
+}
+
+// AddendumRequest appends a correction to a signed report.
+type AddendumRequest struct {
+	ReportID string `json:"reportID"`
+	Content  string `json:"content"`
+}
+
 type ChangeNameRequest struct {
 	ReportID string `json:"reportID"`
+	Version  int64  `json:"version"`
 	NewName  string `json:"newName"`
 }
 
 type UpdateContentData struct {
 	ReportID       string `json:"reportID"`
+	Version        int64  `json:"version"`
 	ContentSection string `json:"contentSection"`
 	Content        string `json:"content"`
 }
 
+// ReportVersionResponse is the version of a report after a write; the next write has to be based on it.
+type ReportVersionResponse struct {
+	Version int64 `json:"version"`
+}
+
+// VersionConflictResponse rejects a write based on a stale version of a report. Version is the current one,
+// which the client reloads before writing again.
+type VersionConflictResponse struct {
+	Error   string `json:"error"`
+	Version int64  `json:"version"`
+}
+
+// SectionRevisionsRequest lists the revision history of a report section.
+type SectionRevisionsRequest struct {
+	ReportID string `json:"reportID"`
+	Section  string `json:"section"`
+}
+
+// SectionDiffRequest compares two revisions of a report section.
+type SectionDiffRequest struct {
+	ReportID string `json:"reportID"`
+	Section  string `json:"section"`
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+}
+
+// SectionDiffResponse is the word-level diff from one revision of a section to another.
+type SectionDiffResponse struct {
+	From   int                          `json:"from"`
+	To     int                          `json:"to"`
+	Chunks []sectionRevisions.DiffChunk `json:"chunks"`
+}
+
+// RestoreSectionRequest restores an earlier revision of a report section as its newest revision.
+type RestoreSectionRequest struct {
+	ReportID string `json:"reportID"`
+	Version  int64  `json:"version"`
+	Section  string `json:"section"`
+	Revision int    `json:"revision"`
+}
+
+// RestoreSectionResponse is the revision recorded for the restored content and the report's new version.
+type RestoreSectionResponse struct {
+	sectionRevisions.SectionRevision
+	Version int64 `json:"version"`
+}
+
+// RelabelSpeakerRequest reassigns the turns transcribed with the raw diarization label FromSpeaker,
+// e.g. "Speaker2", to the role or participant ToSpeaker.
+type RelabelSpeakerRequest struct {
+	ReportID    string `json:"reportID"`
+	Version     int64  `json:"version"`
+	FromSpeaker string `json:"fromSpeaker"`
+	ToSpeaker   string `json:"toSpeaker"`
+}
+
+type UpdateTranscriptRequest struct {
+	ReportID           string                       `json:"reportID"`
+	Version            int64                        `json:"version"`
+	Transcript         string                       `json:"transcript"`
+	DiarizedTranscript []transcriber.TranscriptTurn `json:"diarizedTranscript"`
+	Regenerate         bool                         `json:"regenerate"`
+	Sections           []string                     `json:"sections"`
+	VisitContext       string                       `json:"visitContext"`
+	// NoteLanguage and PatientLanguage change the report's languages for the regeneration.
+	NoteLanguage    string `json:"noteLanguage"`
+	PatientLanguage string `json:"patientLanguage"`
+}
+
+// AppendRecordingRequest is the metadata of a recording appended to an existing report. The audio is
+// sent as the "audio" form file, or taken from a finalized upload when UploadID is set.
+type AppendRecordingRequest struct {
+	ReportID     string  `json:"reportID"`
+	Duration     float64 `json:"duration"`
+	UploadID     string  `json:"uploadID"`
+	VisitContext string  `json:"visitContext"`
+}
+
+// GenerateFromTranscriptRequest generates a report from a transcript supplied in TranscriptFormat, one of
+// transcriber.ExportFormats, or detected from its content when TranscriptFormat is empty.
+type GenerateFromTranscriptRequest struct {
+	PatientName      string                    `json:"patientName"`
+	Timestamp        time.Time                 `json:"timestamp"`
+	Duration         float64                   `json:"duration"`
+	LastVisitID      string                    `json:"lastVisitID"`
+	VisitContext     string                    `json:"visitContext"`
+	Mode             string                    `json:"mode"`
+	Participants     []transcriber.Participant `json:"participants"`
+	NoteLanguage     string                    `json:"noteLanguage"`
+	PatientLanguage  string                    `json:"patientLanguage"`
+	Transcript       string                    `json:"transcript"`
+	TranscriptFormat string                    `json:"transcriptFormat"`
+}
+
+// AfterVisitSummaryRequest asks for the patient-facing summary of a report. ReadingLevel is a US
+// school grade and defaults to 6th grade.
+type AfterVisitSummaryRequest struct {
+	ReportID     string `json:"reportID"`
+	ReadingLevel int    `json:"readingLevel"`
+}
+
 type reportsHandler struct {
-	reportsService   reports.Reports
-	inferenceService inferenceService.InferenceService
-	userStore        user.UserStore
-	logger           *zap.Logger
+	reportsService          reports.Reports
+	inferenceService        inferenceService.InferenceService
+	userStore               user.UserStore
+	transcriptRevisionStore transcriptRevisions.TranscriptRevisionStore
+	sectionRevisionStore    sectionRevisions.SectionRevisionStore
+	audioStore              audioStore.AudioStore
+	uploadStore             uploads.UploadStore
+	maxAudioSize            int64
+	auditLog                auditLog.AuditLogStore
+	emailSender             emailsender.EmailSender
+	logger                  *zap.Logger
 }
 
 type ReadStatusRequest struct {
@@ -68,12 +238,19 @@ type ReadStatusRequest struct {
 	Opened   bool   `json:"opened"`
 }
 
-func NewReportsHandler(reportsService reports.Reports, inferenceService inferenceService.InferenceService, userStore user.UserStore, logger *zap.Logger) ReportsHandler {
+func NewReportsHandler(reportsService reports.Reports, inferenceService inferenceService.InferenceService, userStore user.UserStore, transcriptRevisionStore transcriptRevisions.TranscriptRevisionStore, sectionRevisionStore sectionRevisions.SectionRevisionStore, audioStore audioStore.AudioStore, uploadStore uploads.UploadStore, maxAudioSize int64, auditLogStore auditLog.AuditLogStore, emailSender emailsender.EmailSender, logger *zap.Logger) ReportsHandler {
 	return &reportsHandler{
-		reportsService:   reportsService,
-		inferenceService: inferenceService,
-		userStore:        userStore,
-		logger:           logger,
+		reportsService:          reportsService,
+		inferenceService:        inferenceService,
+		userStore:               userStore,
+		transcriptRevisionStore: transcriptRevisionStore,
+		sectionRevisionStore:    sectionRevisionStore,
+		audioStore:              audioStore,
+		uploadStore:             uploadStore,
+		maxAudioSize:            maxAudioSize,
+		auditLog:                auditLogStore,
+		emailSender:             emailSender,
+		logger:                  logger,
 	}
 }
 
@@ -95,9 +272,7 @@ func (h *reportsHandler) GenerateReport(w http.ResponseWriter, r *http.Request)
 	logger.Info("Starting report generation", zap.String("UserID", userID))
 
 	// Parse multipart form
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		logger.Error("Failed to parse form", zap.Error(err))
-		http.Error(w, "failed to parse form", http.StatusBadRequest)
+	if !h.parseAudioForm(w, r) {
 		return
 	}
 	defer r.Body.Close()
@@ -111,24 +286,19 @@ func (h *reportsHandler) GenerateReport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	req.ProviderID = userID
-
-	// Get the audio file from the form
-	file, _, err := r.FormFile("audio")
-	if err != nil {
-		logger.Error("Failed to get audio file", zap.Error(err))
-		http.Error(w, "failed to get audio file", http.StatusBadRequest)
+	if err := normalizeReportRequest(&req); err != nil {
+		logger.Error("Invalid report request", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	// Read the audio bytes
-	audioBytes, err := io.ReadAll(file)
-	if err != nil {
-		logger.Error("Failed to read audio", zap.Error(err))
-		http.Error(w, "failed to read audio", http.StatusInternalServerError)
+	// Read the audio file from the form
+	audioBytes, contentType, ok := h.readFormAudio(w, r)
+	if !ok {
 		return
 	}
 	req.AudioBytes = audioBytes
+	req.AudioContentType = contentType
 
 	// Set up SSE headers for streaming
 	w.Header().Set("Content-Type", "application/x-ndjson")
@@ -139,14 +309,365 @@ func (h *reportsHandler) GenerateReport(w http.ResponseWriter, r *http.Request)
 	// Start the report generation pipeline in a goroutine
 	// Log pipeline start
 	logger.Info("Report generation pipeline started", zap.String("UserID", userID))
-	if err := h.inferenceService.GenerateReportPipeline(r.Context(), &req,&utils.SafeResponseWriter{ResponseWriter: w}); err != nil {
-		logger.Error("Error during report generation pipeline", zap.Error(err))
+	pipelineErr := h.inferenceService.GenerateReportPipeline(r.Context(), &req, &utils.SafeResponseWriter{ResponseWriter: w})
+	h.auditCreated(r.Context(), req.ID)
+	if pipelineErr != nil {
+		logger.Error("Error during report generation pipeline", zap.Error(pipelineErr))
 		return
 	}
 
 	// Log successful report generation
 	logger.Info("Report generation completed successfully", zap.String("UserID", userID))
 }
+
+// GenerateReportFromUpload starts report generation from a finalized resumable upload instead of a multipart form.
+func (h *reportsHandler) GenerateReportFromUpload(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Error("User is not authorized", zap.String("UserID", userID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req inferenceService.ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid ReportRequest Format", zap.Error(err))
+		http.Error(w, "invalid ReportRequest Format", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	req.ProviderID = userID
+	if err := normalizeReportRequest(&req); err != nil {
+		logger.Error("Invalid report request", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	audioBytes, upload, ok := h.readUpload(w, r, userID, req.UploadID)
+	if !ok {
+		return
+	}
+	req.AudioBytes = audioBytes
+	req.AudioContentType = upload.ContentType
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	logger.Info("Report generation pipeline started", zap.String("UserID", userID), zap.String("UploadID", req.UploadID))
+	pipelineErr := h.inferenceService.GenerateReportPipeline(r.Context(), &req, &utils.SafeResponseWriter{ResponseWriter: w})
+	h.auditCreated(r.Context(), req.ID)
+	if pipelineErr != nil {
+		logger.Error("Error during report generation pipeline", zap.Error(pipelineErr))
+		return
+	}
+
+	// The recording is retained with the report, so the upload is no longer needed.
+	if err := h.uploadStore.Delete(r.Context(), req.UploadID); err != nil {
+		logger.Warn("Error deleting consumed upload", zap.String("UploadID", req.UploadID), zap.Error(err))
+	}
+	logger.Info("Report generation completed successfully", zap.String("UserID", userID), zap.String("UploadID", req.UploadID))
+}
+
+// GenerateReportFromTranscript generates a report from a transcript supplied as text, WebVTT, SRT
+// or diarized JSON instead of audio, for visits transcribed elsewhere or re-runs of old transcripts.
+func (h *reportsHandler) GenerateReportFromTranscript(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Error("User is not authorized", zap.String("UserID", userID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxTranscriptRequestSize)
+	defer body.Close()
+	var transcriptReq GenerateFromTranscriptRequest
+	if err := json.NewDecoder(body).Decode(&transcriptReq); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "transcript exceeds the maximum request size", http.StatusRequestEntityTooLarge)
+			return
+		}
+		logger.Error("Invalid GenerateFromTranscriptRequest Format", zap.Error(err))
+		http.Error(w, "invalid GenerateFromTranscriptRequest Format", http.StatusBadRequest)
+		return
+	}
+	req := inferenceService.ReportRequest{
+		ProviderID:      userID,
+		PatientName:     transcriptReq.PatientName,
+		Timestamp:       transcriptReq.Timestamp,
+		Duration:        transcriptReq.Duration,
+		LastVisitID:     transcriptReq.LastVisitID,
+		VisitContext:    transcriptReq.VisitContext,
+		Mode:            transcriptReq.Mode,
+		Participants:    transcriptReq.Participants,
+		NoteLanguage:    transcriptReq.NoteLanguage,
+		PatientLanguage: transcriptReq.PatientLanguage,
+	}
+	if err := normalizeReportRequest(&req); err != nil {
+		logger.Error("Invalid report request", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	imported, err := transcriber.ParseTranscript(transcriptReq.Transcript, transcriptReq.TranscriptFormat)
+	if err != nil {
+		logger.Warn("Invalid transcript provided", zap.String("TranscriptFormat", transcriptReq.TranscriptFormat), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ImportedTranscript = &imported
+	if req.Duration < 1 {
+		req.Duration = max(imported.Duration, 1)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	logger.Info("Report generation from transcript started", zap.String("UserID", userID), zap.Bool("Diarized", imported.Diarized))
+	pipelineErr := h.inferenceService.GenerateReportPipeline(r.Context(), &req, &utils.SafeResponseWriter{ResponseWriter: w})
+	h.auditCreated(r.Context(), req.ID)
+	if pipelineErr != nil {
+		logger.Error("Error during report generation pipeline", zap.Error(pipelineErr))
+		return
+	}
+
+	logger.Info("Report generation completed successfully", zap.String("UserID", userID))
+}
+
+// AppendRecording transcribes another recording of the same visit, merges it into the report's
+// transcript and regenerates the report, streaming progress like GenerateReport.
+func (h *reportsHandler) AppendRecording(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Error("User is not authorized", zap.String("UserID", userID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.parseAudioForm(w, r) {
+		return
+	}
+	defer r.Body.Close()
+
+	var req AppendRecordingRequest
+	if err := json.NewDecoder(strings.NewReader(r.FormValue("metadata"))).Decode(&req); err != nil {
+		logger.Error("Invalid metadata", zap.Error(err))
+		http.Error(w, "invalid metadata", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.verifyReportBelongsToProvider(r.Context(), userID, req.ReportID); err != nil {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionAppendRecording, req.ReportID) {
+		return
+	}
+	previousTranscripts, err := h.reportsService.GetTranscription(r.Context(), req.ReportID)
+	if err != nil {
+		logger.Error("Error fetching report transcript", zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusInternalServerError)
+		return
+	}
+	if rejectSigned(w, r, previousTranscripts.Signed, req.ReportID) {
+		return
+	}
+
+	appendRequest := inferenceService.ReportRequest{
+		ID:           req.ReportID,
+		ProviderID:   userID,
+		Duration:     req.Duration,
+		VisitContext: req.VisitContext,
+	}
+	if req.UploadID != "" {
+		audioBytes, upload, ok := h.readUpload(w, r, userID, req.UploadID)
+		if !ok {
+			return
+		}
+		appendRequest.AudioBytes = audioBytes
+		appendRequest.AudioContentType = upload.ContentType
+	} else {
+		audioBytes, contentType, ok := h.readFormAudio(w, r)
+		if !ok {
+			return
+		}
+		appendRequest.AudioBytes = audioBytes
+		appendRequest.AudioContentType = contentType
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	logger.Info("Appending recording to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
+	appendErr := h.inferenceService.AppendRecording(r.Context(), &appendRequest, &utils.SafeResponseWriter{ResponseWriter: w})
+
+	// The merged transcript may be stored even if regeneration failed afterwards, so record it either way.
+	if updated, err := h.reportsService.GetTranscription(r.Context(), req.ReportID); err != nil {
+		logger.Error("Error fetching merged transcript", zap.String("ReportID", req.ReportID), zap.Error(err))
+	} else if updated.Transcript != previousTranscripts.Transcript {
+		if _, err := h.recordTranscriptRevision(r.Context(), req.ReportID, userID, previousTranscripts.Transcript, updated.Transcript, updated.UsedDiarization, transcriptRevisions.SourceAppend); err != nil {
+			logger.Error("Error recording transcript revision", zap.String("ReportID", req.ReportID), zap.Error(err))
+		}
+		if req.UploadID != "" {
+			if err := h.uploadStore.Delete(r.Context(), req.UploadID); err != nil {
+				logger.Warn("Error deleting consumed upload", zap.String("UploadID", req.UploadID), zap.Error(err))
+			}
+		}
+	}
+	if appendErr != nil {
+		logger.Error("Error appending recording", zap.String("ReportID", req.ReportID), zap.Error(appendErr))
+		return
+	}
+
+	logger.Info("Recording appended and report regenerated successfully", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
+}
+
+// normalizeReportRequest validates the report mode, session roster and languages of a generation
+// request, defaulting the mode, assigning participant IDs and normalizing language codes.
+func normalizeReportRequest(req *inferenceService.ReportRequest) error {
+	mode, err := reports.ParseMode(req.Mode)
+	if err != nil {
+		return err
+	}
+	req.Mode = mode
+
+	participants, err := transcriber.NormalizeRoster(req.Participants)
+	if err != nil {
+		return err
+	}
+	if len(participants) > 0 && mode == reports.ModeDictation {
+		return errors.New("a dictation cannot have session participants")
+	}
+	req.Participants = participants
+
+	if req.NoteLanguage, err = normalizeLanguage(req.NoteLanguage); err != nil {
+		return err
+	}
+	if req.PatientLanguage, err = normalizeLanguage(req.PatientLanguage); err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizeLanguage maps an optional language choice onto a supported language code.
+func normalizeLanguage(language string) (string, error) {
+	if language == "" {
+		return "", nil
+	}
+	code, ok := transcriber.NormalizeLanguage(language)
+	if !ok {
+		return "", fmt.Errorf("unsupported language: %s", language)
+	}
+	return code, nil
+}
+
+// readUpload loads the audio of a finalized upload owned by the provider. It writes the error
+// response itself and returns false when the request cannot continue.
+func (h *reportsHandler) readUpload(w http.ResponseWriter, r *http.Request, userID, uploadID string) ([]byte, uploads.Upload, bool) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	upload, err := h.uploadStore.Get(r.Context(), uploadID)
+	if err != nil {
+		if errors.Is(err, uploads.ErrUploadNotFound) {
+			http.Error(w, "upload not found", http.StatusNotFound)
+			return nil, uploads.Upload{}, false
+		}
+		logger.Error("Error fetching upload", zap.String("UploadID", uploadID), zap.Error(err))
+		http.Error(w, "error fetching upload", http.StatusBadRequest)
+		return nil, uploads.Upload{}, false
+	}
+	if upload.ProviderID != userID {
+		logger.Error("Unauthorized access to upload", zap.String("UserID", userID), zap.String("UploadID", uploadID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, uploads.Upload{}, false
+	}
+	if upload.Status != uploads.StatusFinalized {
+		http.Error(w, uploads.ErrUploadNotFinalized.Error(), http.StatusConflict)
+		return nil, uploads.Upload{}, false
+	}
+	if upload.Size > h.maxAudioSize {
+		logger.Warn("Upload exceeds the audio size limit", zap.String("UploadID", uploadID), zap.Int64("Size", upload.Size))
+		http.Error(w, "recording exceeds the maximum audio size", http.StatusRequestEntityTooLarge)
+		return nil, uploads.Upload{}, false
+	}
+
+	audio, err := h.uploadStore.Open(r.Context(), uploadID)
+	if err != nil {
+		logger.Error("Error opening upload", zap.String("UploadID", uploadID), zap.Error(err))
+		http.Error(w, "error opening upload", http.StatusInternalServerError)
+		return nil, uploads.Upload{}, false
+	}
+	defer audio.Close()
+	// The transcription backends need the whole recording in one request; the size check above bounds this read.
+	audioBytes, err := io.ReadAll(io.LimitReader(audio, h.maxAudioSize))
+	if err != nil {
+		logger.Error("Error reading upload", zap.String("UploadID", uploadID), zap.Error(err))
+		http.Error(w, "error reading upload", http.StatusInternalServerError)
+		return nil, uploads.Upload{}, false
+	}
+	return audioBytes, upload, true
+}
+
+// parseAudioForm parses a multipart form carrying a recording, rejecting bodies larger than the audio
+// size limit before they are read. It writes the error response itself and returns false when the
+// request cannot continue.
+func (h *reportsHandler) parseAudioForm(w http.ResponseWriter, r *http.Request) bool {
+	logger := contextLogger.FromCtx(r.Context())
+
+	if r.ContentLength > h.maxAudioSize+maxFormOverhead {
+		http.Error(w, "recording exceeds the maximum audio size", http.StatusRequestEntityTooLarge)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxAudioSize+maxFormOverhead)
+	if err := r.ParseMultipartForm(maxFormMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "recording exceeds the maximum audio size", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		logger.Error("Failed to parse form", zap.Error(err))
+		http.Error(w, "failed to parse form", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// readFormAudio reads the "audio" file of a form parsed by parseAudioForm. It writes the error
+// response itself and returns false when the request cannot continue.
+func (h *reportsHandler) readFormAudio(w http.ResponseWriter, r *http.Request) ([]byte, string, bool) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	file, header, err := r.FormFile("audio")
+	if err != nil {
+		logger.Error("Failed to get audio file", zap.Error(err))
+		http.Error(w, "failed to get audio file", http.StatusBadRequest)
+		return nil, "", false
+	}
+	defer file.Close()
+	if header.Size > h.maxAudioSize {
+		http.Error(w, "recording exceeds the maximum audio size", http.StatusRequestEntityTooLarge)
+		return nil, "", false
+	}
+
+	audioBytes, err := io.ReadAll(file)
+	if err != nil {
+		logger.Error("Failed to read audio", zap.Error(err))
+		http.Error(w, "failed to read audio", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	return audioBytes, header.Header.Get("Content-Type"), true
+}
+
 func (h *reportsHandler) RegenerateReport(w http.ResponseWriter, r *http.Request) {
 	logger := contextLogger.FromCtx(r.Context())
 
@@ -180,42 +701,31 @@ func (h *reportsHandler) RegenerateReport(w http.ResponseWriter, r *http.Request
 		http.Error(w, "error regenerating report", http.StatusInternalServerError)
 		return
 	}
+	if rejectSigned(w, r, report.IsSigned(), req.ID) {
+		return
+	}
+	if report.Version != req.Version {
+		writeVersionConflict(w, report.Version)
+		return
+	}
+	if report.IsRegenerating(time.Now()) {
+		writeUpdateError(w, reports.ErrReportRegenerating, "error regenerating report")
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionRegenerateReport, req.ID) {
+		return
+	}
 
 	// Set up headers for streaming response.
 	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
-	contentChan := make(chan inferenceService.ContentChanPayload, 6)
-	errChan := make(chan error)
-	go func() {
-		logger.Info("Regeneration pipeline started", zap.String("UserID", userID), zap.String("ReportID", req.ID))
-		if err := h.inferenceService.RegenerateReport(r.Context(), &req, &utils.SafeResponseWriter{ResponseWriter: w}); err != nil {
-			logger.Error("Error during report regeneration pipeline", zap.Error(err))
-			errChan <- err
-			return
-		}
-		errChan <- nil
-	}()
-
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "streaming not supported", http.StatusInternalServerError)
-		return
-	}
-
-	for content := range contentChan {
-		if err := json.NewEncoder(w).Encode(content); err != nil {
-			logger.Error("Error writing response", zap.Error(err))
-			http.Error(w, "error writing response", http.StatusInternalServerError)
-			return
-		}
-		flusher.Flush()
-	}
-
-	if err := <-errChan; err != nil {
+	// The report is locked before anything is streamed, so a conflict still gets its own status code.
+	logger.Info("Regeneration pipeline started", zap.String("UserID", userID), zap.String("ReportID", req.ID))
+	if err := h.inferenceService.RegenerateReport(r.Context(), &req, &utils.SafeResponseWriter{ResponseWriter: w}); err != nil {
 		logger.Error("Error regenerating report", zap.Error(err))
-		http.Error(w, "error regenerating report", http.StatusInternalServerError)
+		writeUpdateError(w, err, "error regenerating report")
 		return
 	}
 
@@ -251,6 +761,9 @@ func (h *reportsHandler) LearnStyle(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewReport, req.ReportID) {
+		return
+	}
 
 	if err = h.inferenceService.LearnStyle(r.Context(), providerID, req.ContentSection, req.Previous, req.Current); err != nil {
 		logger.Error("Learning style failed", zap.Error(err))
@@ -290,6 +803,9 @@ func (h *reportsHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewReport, req.ReportID) {
+		return
+	}
 
 	if err := json.NewEncoder(w).Encode(report); err != nil {
 		logger.Error("Error encoding report", zap.Error(err))
@@ -318,7 +834,7 @@ func (h *reportsHandler) ChangeReportName(w http.ResponseWriter, r *http.Request
 	}
 	defer r.Body.Close()
 
-	logger.Info("Attempting to change report name", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.String("NewName", req.NewName))
+	logger.Info("Attempting to change report name", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), contextLogger.PHI("NewName", req.NewName))
 
 	report, err := h.reportsService.Get(r.Context(), req.ReportID)
 	if err != nil {
@@ -331,15 +847,23 @@ func (h *reportsHandler) ChangeReportName(w http.ResponseWriter, r *http.Request
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if rejectSigned(w, r, report.IsSigned(), req.ReportID) {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionUpdateReport, req.ReportID) {
+		return
+	}
 
 	updates := bson.D{bson.E{Key: reports.Name, Value: req.NewName}}
-	if err = h.reportsService.UpdateReport(r.Context(), req.ReportID, updates); err != nil {
+	version, err := h.reportsService.UpdateReport(r.Context(), req.ReportID, req.Version, updates)
+	if err != nil {
 		logger.Error("Error updating report", zap.Error(err))
-		http.Error(w, "error updating report", http.StatusInternalServerError)
+		writeUpdateError(w, err, "error updating report")
 		return
 	}
+	writeVersion(w, r, version)
 
-	logger.Info("Report name changed successfully", zap.String("ReportID", req.ReportID), zap.String("NewName", req.NewName))
+	logger.Info("Report name changed successfully", zap.String("ReportID", req.ReportID), contextLogger.PHI("NewName", req.NewName))
 }
 
 func (h *reportsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
@@ -373,7 +897,10 @@ func (h *reportsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionUpdateReport, req.ReportID) {
+		return
+	}
+
 	if err = h.reportsService.MarkRead(r.Context(), req.ReportID); err != nil {
 		logger.Error("Error marking report as read", zap.Error(err))
 		http.Error(w, "error marking report as read", http.StatusInternalServerError)
@@ -414,6 +941,9 @@ func (h *reportsHandler) MarkUnread(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionUpdateReport, req.ReportID) {
+		return
+	}
 
 	if err = h.reportsService.MarkUnread(r.Context(), req.ReportID); err != nil {
 		logger.Error("Error marking report as unread", zap.Error(err))
@@ -455,6 +985,9 @@ func (h *reportsHandler) GetTranscript(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewTranscript, req.ReportID) {
+		return
+	}
 
 	if err := json.NewEncoder(w).Encode(retrievedReportTranscripts); err != nil {
 		logger.Error("Error encoding transcript", zap.Error(err))
@@ -465,7 +998,9 @@ func (h *reportsHandler) GetTranscript(w http.ResponseWriter, r *http.Request) {
 	logger.Info("Transcript fetched successfully", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
 }
 
-func (h *reportsHandler) UpdateContentSection(w http.ResponseWriter, r *http.Request) {
+// ExportTranscript serves a report's transcript as a download in the format given by the
+// format query parameter (vtt, srt, txt or json).
+func (h *reportsHandler) ExportTranscript(w http.ResponseWriter, r *http.Request) {
 	logger := contextLogger.FromCtx(r.Context())
 
 	userID, ok := middleware.GetProviderIDFromContext(r.Context())
@@ -475,7 +1010,88 @@ func (h *reportsHandler) UpdateContentSection(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	var req UpdateContentData
+	reportID := chi.URLParam(r, "reportID")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = transcriber.ExportFormatText
+	}
+
+	report, err := h.reportsService.Get(r.Context(), reportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusNotFound)
+		return
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", reportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	transcript, err := h.reportsService.GetTranscription(r.Context(), reportID)
+	if err != nil {
+		logger.Error("Error fetching transcript", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching transcript", http.StatusInternalServerError)
+		return
+	}
+	if strings.TrimSpace(transcript.Transcript) == "" {
+		http.Error(w, "report has no transcript", http.StatusNotFound)
+		return
+	}
+
+	// A transcript made without diarization has no timings, so it is exported as one cue spanning the visit.
+	turns := []transcriber.TranscriptTurn{{StartTime: 0, EndTime: report.Duration, Text: transcript.Transcript}}
+	if transcript.UsedDiarization {
+		turns = transcript.DiarizedTranscript
+	}
+
+	exported, err := transcriber.ExportTranscript(turns, transcript.UsedDiarization, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionExportTranscript, reportID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", exported.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exportFilename(report, "transcript", exported.Extension)}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(exported.Content)))
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(exported.Content); err != nil {
+		logger.Error("Error writing transcript export", zap.String("ReportID", reportID), zap.Error(err))
+		return
+	}
+
+	logger.Info("Transcript exported successfully", zap.String("UserID", userID), zap.String("ReportID", reportID), zap.String("Format", exported.Extension))
+}
+
+// exportFilename builds a download name from the report name, falling back to the report id.
+func exportFilename(report reports.Report, kind, extension string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '"' || r < ' ' {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(report.Name))
+	if name == "" {
+		name = report.ID.Hex()
+	}
+	return fmt.Sprintf("%s-%s.%s", name, kind, extension)
+}
+
+// RelabelSpeaker reassigns a speaker label across the whole diarized transcript and marks the
+// sections generated from it as stale so they can be regenerated.
+func (h *reportsHandler) RelabelSpeaker(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RelabelSpeakerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Invalid request body", zap.Error(err))
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -483,31 +1099,78 @@ func (h *reportsHandler) UpdateContentSection(w http.ResponseWriter, r *http.Req
 	}
 	defer r.Body.Close()
 
-	logger.Info("Attempting to update content section", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.String("ContentSection", req.ContentSection))
+	logger.Info("Attempting to relabel speaker", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.String("FromSpeaker", req.FromSpeaker), zap.String("ToSpeaker", req.ToSpeaker))
 
-	report, err := h.reportsService.Get(r.Context(), req.ReportID)
+	retrievedReportTranscripts, err := h.reportsService.GetTranscription(r.Context(), req.ReportID)
 	if err != nil {
-		logger.Error("Error fetching report", zap.Error(err))
-		http.Error(w, "error updating report", http.StatusInternalServerError)
+		logger.Error("Error fetching report transcript", zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusInternalServerError)
 		return
 	}
-	if report.ProviderID != userID {
+	if retrievedReportTranscripts.ProviderID != userID {
 		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if rejectSigned(w, r, retrievedReportTranscripts.Signed, req.ReportID) {
+		return
+	}
+	if !retrievedReportTranscripts.UsedDiarization {
+		logger.Warn("Cannot relabel speakers of a transcript without diarization", zap.String("ReportID", req.ReportID))
+		http.Error(w, "transcript has no speaker labels", http.StatusBadRequest)
+		return
+	}
 
-	updates := bson.D{bson.E{Key: req.ContentSection, Value: bson.D{bson.E{Key: "data", Value: req.Content}}}}
-	if err = h.reportsService.UpdateReport(r.Context(), req.ReportID, updates); err != nil {
-		logger.Error("Error updating report", zap.Error(err))
-		http.Error(w, "error updating report", http.StatusInternalServerError)
+	relabeledTurns, changed, err := transcriber.RelabelSpeaker(retrievedReportTranscripts.DiarizedTranscript, req.FromSpeaker, req.ToSpeaker, retrievedReportTranscripts.Participants...)
+	if err != nil {
+		logger.Warn("Invalid speaker relabel request", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if changed == 0 {
+		logger.Warn("No turns to relabel for speaker label", zap.String("ReportID", req.ReportID), zap.String("FromSpeaker", req.FromSpeaker))
+		http.Error(w, "no turns found for speaker", http.StatusBadRequest)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionUpdateTranscript, req.ReportID) {
 		return
 	}
 
-	logger.Info("Content section updated successfully", zap.String("ReportID", req.ReportID), zap.String("ContentSection", req.ContentSection))
+	updatedTranscript, err := transcriber.DiarizedTranscriptToString(relabeledTurns)
+	if err != nil {
+		logger.Error("Error serializing relabeled transcript", zap.Error(err))
+		http.Error(w, "error relabeling speaker", http.StatusInternalServerError)
+		return
+	}
+	version, err := h.updateTranscript(r.Context(), req.ReportID, userID, retrievedReportTranscripts.Transcript, updatedTranscript, true, transcriptRevisions.SourceRelabel, req.Version)
+	if err != nil {
+		logger.Error("Error updating transcript", zap.Error(err))
+		writeUpdateError(w, err, "error relabeling speaker")
+		return
+	}
+	if err = h.reportsService.MarkSectionsStale(r.Context(), req.ReportID, reports.TranscriptDerivedSections...); err != nil {
+		logger.Error("Error marking sections stale", zap.Error(err))
+		http.Error(w, "error relabeling speaker", http.StatusInternalServerError)
+		return
+	}
+
+	retrievedReportTranscripts.Transcript = updatedTranscript
+	retrievedReportTranscripts.DiarizedTranscript = relabeledTurns
+	retrievedReportTranscripts.SpeakerMapping = transcriber.SpeakerMapping(relabeledTurns)
+	retrievedReportTranscripts.Version = version
+	if err := json.NewEncoder(w).Encode(retrievedReportTranscripts); err != nil {
+		logger.Error("Error encoding transcript", zap.Error(err))
+		http.Error(w, "error encoding transcript", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Speaker relabeled successfully", zap.String("ReportID", req.ReportID), zap.Int("TurnsChanged", changed))
 }
 
-func (h *reportsHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
+// UpdateTranscript replaces a report's transcript with a corrected version, records the change in the
+// revision history and marks the derived sections stale. When regeneration is requested, the targeted
+// sections are regenerated from the corrected transcript and streamed back like a new report.
+func (h *reportsHandler) UpdateTranscript(w http.ResponseWriter, r *http.Request) {
 	logger := contextLogger.FromCtx(r.Context())
 
 	userID, ok := middleware.GetProviderIDFromContext(r.Context())
@@ -517,7 +1180,7 @@ func (h *reportsHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req DeleteReportRequest
+	var req UpdateTranscriptRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Invalid request body", zap.Error(err))
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -525,45 +1188,959 @@ func (h *reportsHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	logger.Info("Attempting to delete reports", zap.String("UserID", userID), zap.Strings("ReportIDs", req.ReportIDs))
-
-	if err := h.verifyReportBelongsToProvider(r.Context(), userID, req.ReportIDs...); err != nil {
-		logger.Error("Unauthorized access to report during deletion", zap.Error(err))
-		http.Error(w, "unauthorized access to report", http.StatusUnauthorized)
+	noteLanguage, noteErr := normalizeLanguage(req.NoteLanguage)
+	patientLanguage, patientErr := normalizeLanguage(req.PatientLanguage)
+	if err := errors.Join(noteErr, patientErr); err != nil {
+		logger.Warn("Invalid regeneration language", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := h.runDeleteReports(r.Context(), req.ReportIDs...); err != nil {
-		logger.Error("Error deleting report", zap.Error(err))
-		http.Error(w, "error deleting report", http.StatusInternalServerError)
+	logger.Info("Attempting to update transcript", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.Bool("Regenerate", req.Regenerate))
+
+	retrievedReportTranscripts, err := h.reportsService.GetTranscription(r.Context(), req.ReportID)
+	if err != nil {
+		logger.Error("Error fetching report transcript", zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusInternalServerError)
+		return
+	}
+	if retrievedReportTranscripts.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if rejectSigned(w, r, retrievedReportTranscripts.Signed, req.ReportID) {
 		return
 	}
 
-	logger.Info("Reports deleted successfully", zap.Strings("ReportIDs", req.ReportIDs))
-	w.WriteHeader(http.StatusOK)
-}
-
-func (h *reportsHandler) verifyReportBelongsToProvider(r context.Context, providerID string, reportIDs ...string) error {
-	logger := contextLogger.FromCtx(r)
-	for _, reportID := range reportIDs {
-		report, err := h.reportsService.Get(r, reportID)
+	var updatedTranscript string
+	var editedTurns []transcriber.TranscriptTurn
+	if retrievedReportTranscripts.UsedDiarization {
+		if err := transcriber.ValidateTranscriptTurns(req.DiarizedTranscript); err != nil {
+			logger.Warn("Invalid diarized transcript", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		editedTurns = transcriber.CarryConfidence(retrievedReportTranscripts.DiarizedTranscript, req.DiarizedTranscript)
+		editedTurns = transcriber.CarrySpeakerLabels(retrievedReportTranscripts.DiarizedTranscript, editedTurns)
+		updatedTranscript, err = transcriber.DiarizedTranscriptToString(editedTurns)
 		if err != nil {
-			logger.Error("Error verifying report ownership", zap.String("ReportID", reportID), zap.Error(err))
-			return fmt.Errorf("error verifying report belongs to provider: %s", providerID)
+			logger.Error("Error serializing diarized transcript", zap.Error(err))
+			http.Error(w, "error updating transcript", http.StatusInternalServerError)
+			return
 		}
-		if providerID != report.ProviderID {
-			logger.Error("Unauthorized access detected", zap.String("ReportID", reportID), zap.String("ProviderID", providerID))
-			return fmt.Errorf("unauthorized access to report: %s", reportID)
+	} else {
+		if strings.TrimSpace(req.Transcript) == "" {
+			logger.Warn("Empty transcript provided", zap.String("ReportID", req.ReportID))
+			http.Error(w, "transcript cannot be empty", http.StatusBadRequest)
+			return
 		}
+		updatedTranscript = req.Transcript
 	}
-	return nil
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionUpdateTranscript, req.ReportID) {
+		return
+	}
+
+	version, err := h.updateTranscript(r.Context(), req.ReportID, userID, retrievedReportTranscripts.Transcript, updatedTranscript, retrievedReportTranscripts.UsedDiarization, transcriptRevisions.SourceEdit, req.Version)
+	if err != nil {
+		logger.Error("Error updating transcript", zap.Error(err))
+		writeUpdateError(w, err, "error updating transcript")
+		return
+	}
+	if err = h.reportsService.MarkSectionsStale(r.Context(), req.ReportID, reports.TranscriptDerivedSections...); err != nil {
+		logger.Error("Error marking sections stale", zap.Error(err))
+		http.Error(w, "error updating transcript", http.StatusInternalServerError)
+		return
+	}
+
+	if !req.Regenerate {
+		retrievedReportTranscripts.Transcript = updatedTranscript
+		// The response carries the turns as stored, with the speaker labels and confidences carried over.
+		retrievedReportTranscripts.DiarizedTranscript = editedTurns
+		retrievedReportTranscripts.SpeakerMapping = transcriber.SpeakerMapping(editedTurns)
+		retrievedReportTranscripts.LowConfidenceSpans = transcriber.LowConfidenceSpans(editedTurns, transcriber.DefaultConfidenceThreshold)
+		retrievedReportTranscripts.Version = version
+		if err := json.NewEncoder(w).Encode(retrievedReportTranscripts); err != nil {
+			logger.Error("Error encoding transcript", zap.Error(err))
+			http.Error(w, "error encoding transcript", http.StatusInternalServerError)
+			return
+		}
+		logger.Info("Transcript updated successfully", zap.String("ReportID", req.ReportID))
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionRegenerateReport, req.ReportID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	regenerationRequest := inferenceService.ReportRequest{
+		ID:              req.ReportID,
+		ProviderID:      userID,
+		VisitContext:    req.VisitContext,
+		Sections:        req.Sections,
+		NoteLanguage:    noteLanguage,
+		PatientLanguage: patientLanguage,
+		Version:         version,
+	}
+	logger.Info("Regeneration from corrected transcript started", zap.String("ReportID", req.ReportID))
+	if err := h.inferenceService.RegenerateFromTranscript(r.Context(), &regenerationRequest, &utils.SafeResponseWriter{ResponseWriter: w}); err != nil {
+		logger.Error("Error regenerating report from transcript", zap.Error(err))
+		return
+	}
+
+	logger.Info("Transcript updated and sections regenerated successfully", zap.String("ReportID", req.ReportID))
 }
 
-func (h *reportsHandler) runDeleteReports(r context.Context, reportIDs ...string) error {
-	logger := contextLogger.FromCtx(r)
-	for _, reportID := range reportIDs {
-		if err := h.reportsService.Delete(r, reportID); err != nil {
-			logger.Error("Error deleting report", zap.String("ReportID", reportID), zap.Error(err))
+// GetTranscriptRevisions returns the revision history of a report's transcript, oldest first.
+func (h *reportsHandler) GetTranscriptRevisions(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req GetReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.verifyReportBelongsToProvider(r.Context(), userID, req.ReportID); err != nil {
+		logger.Error("Unauthorized access to report", zap.Error(err))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	reportObjectID, err := primitive.ObjectIDFromHex(req.ReportID)
+	if err != nil {
+		logger.Error("Invalid report ID", zap.Error(err))
+		http.Error(w, "invalid report ID", http.StatusBadRequest)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewRevisions, req.ReportID) {
+		return
+	}
+	revisions, err := h.transcriptRevisionStore.GetByReportID(r.Context(), reportObjectID)
+	if err != nil {
+		logger.Error("Error fetching transcript revisions", zap.Error(err))
+		http.Error(w, "error fetching transcript revisions", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		logger.Error("Error encoding transcript revisions", zap.Error(err))
+		http.Error(w, "error encoding transcript revisions", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Transcript revisions fetched successfully", zap.String("ReportID", req.ReportID), zap.Int("Revisions", len(revisions)))
+}
+
+// GetSectionRevisions returns the revision history of a report section, oldest first.
+func (h *reportsHandler) GetSectionRevisions(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SectionRevisionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	report, ok := h.revisedSectionReport(w, r, userID, req.ReportID, req.Section)
+	if !ok {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewNoteRevisions, req.ReportID) {
+		return
+	}
+	revisions, err := h.sectionRevisionStore.GetBySection(r.Context(), report.ID, req.Section)
+	if err != nil {
+		logger.Error("Error fetching section revisions", zap.String("ReportID", req.ReportID), zap.String("Section", req.Section), zap.Error(err))
+		http.Error(w, "error fetching section revisions", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		logger.Error("Error encoding section revisions", zap.Error(err))
+		http.Error(w, "error encoding section revisions", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Section revisions fetched successfully", zap.String("ReportID", req.ReportID), zap.String("Section", req.Section), zap.Int("Revisions", len(revisions)))
+}
+
+// DiffSectionRevisions returns the word-level diff between two revisions of a report section.
+func (h *reportsHandler) DiffSectionRevisions(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SectionDiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	report, ok := h.revisedSectionReport(w, r, userID, req.ReportID, req.Section)
+	if !ok {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewNoteRevisions, req.ReportID) {
+		return
+	}
+	from, ok := h.sectionRevision(w, r, report.ID, req.Section, req.From)
+	if !ok {
+		return
+	}
+	to, ok := h.sectionRevision(w, r, report.ID, req.Section, req.To)
+	if !ok {
+		return
+	}
+
+	response := SectionDiffResponse{From: from.Revision, To: to.Revision, Chunks: sectionRevisions.WordDiff(from.Content, to.Content)}
+	if response.Chunks == nil {
+		response.Chunks = []sectionRevisions.DiffChunk{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Error encoding section diff", zap.Error(err))
+		http.Error(w, "error encoding section diff", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Section revisions compared", zap.String("ReportID", req.ReportID), zap.String("Section", req.Section), zap.Int("From", req.From), zap.Int("To", req.To))
+}
+
+// RestoreSectionRevision brings back the content of an earlier revision of a report section. The history is
+// never rewritten: the restored content is recorded as the section's newest revision.
+func (h *reportsHandler) RestoreSectionRevision(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RestoreSectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	report, ok := h.revisedSectionReport(w, r, userID, req.ReportID, req.Section)
+	if !ok {
+		return
+	}
+	if rejectSigned(w, r, report.IsSigned(), req.ReportID) {
+		return
+	}
+	restored, ok := h.sectionRevision(w, r, report.ID, req.Section, req.Revision)
+	if !ok {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionRestoreRevision, req.ReportID) {
+		return
+	}
+
+	updates := bson.D{bson.E{Key: req.Section, Value: bson.D{bson.E{Key: reports.ContentData, Value: restored.Content}}}}
+	revision, version, err := h.updateSection(r.Context(), report, req.Version, updates, sectionRevisions.SectionRevision{
+		ReportID:     report.ID,
+		ProviderID:   report.ProviderID,
+		Section:      req.Section,
+		Source:       sectionRevisions.SourceRestore,
+		AuthorID:     userID,
+		Content:      restored.Content,
+		RestoredFrom: restored.Revision,
+	})
+	if err != nil {
+		logger.Error("Error restoring section revision", zap.String("ReportID", req.ReportID), zap.String("Section", req.Section), zap.Error(err))
+		writeUpdateError(w, err, "error restoring section revision")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(RestoreSectionResponse{SectionRevision: revision, Version: version}); err != nil {
+		logger.Error("Error encoding section revision", zap.Error(err))
+		return
+	}
+
+	logger.Info("Section revision restored", zap.String("ReportID", req.ReportID), zap.String("Section", req.Section), zap.Int("RestoredFrom", restored.Revision), zap.Int("Revision", revision.Revision))
+}
+
+// revisedSectionReport fetches a report whose section history is requested and checks the provider owns it
+// and the section is one of its content sections. It writes the error response itself and returns false when
+// the request cannot proceed.
+func (h *reportsHandler) revisedSectionReport(w http.ResponseWriter, r *http.Request, userID, reportID, section string) (reports.Report, bool) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	report, err := h.reportsService.Get(r.Context(), reportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusInternalServerError)
+		return reports.Report{}, false
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", reportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return reports.Report{}, false
+	}
+	if _, ok := report.Section(section); !ok {
+		http.Error(w, "invalid section", http.StatusBadRequest)
+		return reports.Report{}, false
+	}
+	return report, true
+}
+
+// sectionRevision fetches one revision of a report section, answering 404 Not Found when it does not exist.
+func (h *reportsHandler) sectionRevision(w http.ResponseWriter, r *http.Request, reportID primitive.ObjectID, section string, number int) (sectionRevisions.SectionRevision, bool) {
+	revision, err := h.sectionRevisionStore.Get(r.Context(), reportID, section, number)
+	if err != nil {
+		if errors.Is(err, sectionRevisions.ErrRevisionNotFound) {
+			http.Error(w, fmt.Sprintf("revision %d not found", number), http.StatusNotFound)
+			return sectionRevisions.SectionRevision{}, false
+		}
+		contextLogger.FromCtx(r.Context()).Error("Error fetching section revision", zap.String("ReportID", reportID.Hex()), zap.String("Section", section), zap.Int("Revision", number), zap.Error(err))
+		http.Error(w, "error fetching section revision", http.StatusInternalServerError)
+		return sectionRevisions.SectionRevision{}, false
+	}
+	return revision, true
+}
+
+// GetAudio streams the decrypted visit recording of a report back for playback.
+func (h *reportsHandler) GetAudio(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	reportID := chi.URLParam(r, "reportID")
+	report, err := h.reportsService.Get(r.Context(), reportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusInternalServerError)
+		return
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", reportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if report.AudioBlobID == "" {
+		http.Error(w, "no audio retained for this report", http.StatusNotFound)
+		return
+	}
+
+	blob, err := h.audioStore.Get(r.Context(), report.AudioBlobID)
+	if err != nil {
+		if errors.Is(err, audioStore.ErrAudioNotFound) {
+			http.Error(w, "audio is no longer retained", http.StatusGone)
+			return
+		}
+		logger.Error("Error fetching audio record", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching audio", http.StatusInternalServerError)
+		return
+	}
+
+	audio, err := h.audioStore.Open(r.Context(), report.AudioBlobID)
+	if err != nil {
+		if errors.Is(err, audioStore.ErrAudioNotFound) {
+			http.Error(w, "audio is no longer retained", http.StatusGone)
+			return
+		}
+		logger.Error("Error opening audio", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error opening audio", http.StatusInternalServerError)
+		return
+	}
+	defer audio.Close()
+	// Recordings are encrypted as a stream and cannot be seeked, so the recording is decrypted into memory
+	// to serve range requests. Its size is bounded by the upload limit.
+	content, err := io.ReadAll(audio)
+	if err != nil {
+		logger.Error("Error reading audio", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error reading audio", http.StatusInternalServerError)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionViewAudio, reportID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", blob.ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, "", blob.CreatedAt.Time(), bytes.NewReader(content))
+
+	logger.Info("Audio streamed successfully", zap.String("UserID", userID), zap.String("ReportID", reportID))
+}
+
+func (h *reportsHandler) GenerateAfterVisitSummary(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req AfterVisitSummaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ReadingLevel != 0 && (req.ReadingLevel < inferenceService.MinReadingLevel || req.ReadingLevel > inferenceService.MaxReadingLevel) {
+		http.Error(w, fmt.Sprintf("reading level must be between %d and %d", inferenceService.MinReadingLevel, inferenceService.MaxReadingLevel), http.StatusBadRequest)
+		return
+	}
+	if err := h.verifyReportBelongsToProvider(r.Context(), userID, req.ReportID); err != nil {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionGenerateAfterVisit, req.ReportID) {
+		return
+	}
+	logger.Info("Generating after-visit summary", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.Int("ReadingLevel", req.ReadingLevel))
+
+	summary, err := h.inferenceService.GenerateAfterVisitSummary(r.Context(), req.ReportID, req.ReadingLevel)
+	if err != nil {
+		logger.Error("Error generating after-visit summary", zap.Error(err))
+		http.Error(w, "error generating after-visit summary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		logger.Error("Error encoding after-visit summary", zap.Error(err))
+		http.Error(w, "error encoding after-visit summary", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("After-visit summary generated successfully", zap.String("ReportID", req.ReportID), zap.Float64("Grade", summary.Grade))
+}
+
+// recordTranscriptRevision appends the updated transcript to the report's revision history.
+// The first change also snapshots the originally generated transcript so it is never lost.
+func (h *reportsHandler) recordTranscriptRevision(ctx context.Context, reportID, providerID, previous, updated string, usedDiarization bool, source string) (transcriptRevisions.TranscriptRevision, error) {
+	reportObjectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return transcriptRevisions.TranscriptRevision{}, fmt.Errorf("invalid report ID: %w", err)
+	}
+
+	if previous != "" {
+		original := transcriptRevisions.TranscriptRevision{
+			ReportID:        reportObjectID,
+			ProviderID:      providerID,
+			Transcript:      previous,
+			UsedDiarization: usedDiarization,
+		}
+		if _, err := h.transcriptRevisionStore.InsertOriginal(ctx, original); err != nil {
+			return transcriptRevisions.TranscriptRevision{}, fmt.Errorf("error recording original transcript: %w", err)
+		}
+	}
+
+	revision := transcriptRevisions.TranscriptRevision{
+		ReportID:        reportObjectID,
+		ProviderID:      providerID,
+		Source:          source,
+		Transcript:      updated,
+		UsedDiarization: usedDiarization,
+	}
+	revision, err = h.transcriptRevisionStore.Insert(ctx, revision)
+	if err != nil {
+		return transcriptRevisions.TranscriptRevision{}, fmt.Errorf("error recording transcript revision: %w", err)
+	}
+	return revision, nil
+}
+
+// updateTranscript records the edited transcript as a revision and then stores it on the report. The
+// revision is written first so no stored edit is missing from the history; it is discarded again when
+// the report write is rejected.
+func (h *reportsHandler) updateTranscript(ctx context.Context, reportID, providerID, previous, updated string, usedDiarization bool, source string, version int64) (int64, error) {
+	revision, err := h.recordTranscriptRevision(ctx, reportID, providerID, previous, updated, usedDiarization, source)
+	if err != nil {
+		return 0, err
+	}
+	newVersion, err := h.reportsService.UpdateTranscript(ctx, reportID, version, updated)
+	if err != nil {
+		if discardErr := h.transcriptRevisionStore.Discard(ctx, revision.ID); discardErr != nil {
+			contextLogger.FromCtx(ctx).Error("Error discarding transcript revision", zap.String("ReportID", reportID), zap.Error(discardErr))
+		}
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+// updateSection records the new content of a report section as a revision and then stores updates on the
+// report. The revision is written first so no stored change is missing from the history; it is discarded
+// again when the report write is rejected.
+func (h *reportsHandler) updateSection(ctx context.Context, report reports.Report, version int64, updates bson.D, revision sectionRevisions.SectionRevision) (sectionRevisions.SectionRevision, int64, error) {
+	previous, _ := report.Section(revision.Section)
+	revision, err := sectionRevisions.Record(ctx, h.sectionRevisionStore, revision, previous.Data)
+	if err != nil {
+		return sectionRevisions.SectionRevision{}, 0, err
+	}
+	newVersion, err := h.reportsService.UpdateReport(ctx, report.ID.Hex(), version, updates)
+	if err != nil {
+		if discardErr := h.sectionRevisionStore.Discard(ctx, revision.ID); discardErr != nil {
+			contextLogger.FromCtx(ctx).Error("Error discarding section revision", zap.String("ReportID", report.ID.Hex()), zap.String("Section", revision.Section), zap.Error(discardErr))
+		}
+		return sectionRevisions.SectionRevision{}, 0, err
+	}
+	return revision, newVersion, nil
+}
+
+func (h *reportsHandler) UpdateContentSection(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateContentData
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	logger.Info("Attempting to update content section", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.String("ContentSection", req.ContentSection))
+
+	report, err := h.reportsService.Get(r.Context(), req.ReportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.Error(err))
+		http.Error(w, "error updating report", http.StatusInternalServerError)
+		return
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if rejectSigned(w, r, report.IsSigned(), req.ReportID) {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionUpdateReport, req.ReportID) {
+		return
+	}
+
+	updates := bson.D{bson.E{Key: req.ContentSection, Value: bson.D{bson.E{Key: "data", Value: req.Content}}}}
+	revision := sectionRevisions.SectionRevision{
+		ReportID:   report.ID,
+		ProviderID: report.ProviderID,
+		Section:    req.ContentSection,
+		Source:     sectionRevisions.SourceEdit,
+		AuthorID:   userID,
+		Content:    req.Content,
+	}
+	_, version, err := h.updateSection(r.Context(), report, req.Version, updates, revision)
+	if err != nil {
+		logger.Error("Error updating report", zap.String("ReportID", req.ReportID), zap.String("ContentSection", req.ContentSection), zap.Error(err))
+		writeUpdateError(w, err, "error updating report")
+		return
+	}
+	writeVersion(w, r, version)
+
+	logger.Info("Content section updated successfully", zap.String("ReportID", req.ReportID), zap.String("ContentSection", req.ContentSection))
+}
+
+func (h *reportsHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req DeleteReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	logger.Info("Attempting to delete reports", zap.String("UserID", userID), zap.Strings("ReportIDs", req.ReportIDs))
+
+	// Signed reports are checked before any report is deleted, so a request is not left half done.
+	for _, reportID := range req.ReportIDs {
+		report, err := h.reportsService.Get(r.Context(), reportID)
+		if err != nil || report.ProviderID != userID {
+			logger.Error("Unauthorized access to report during deletion", zap.String("ReportID", reportID), zap.Error(err))
+			http.Error(w, "unauthorized access to report", http.StatusUnauthorized)
+			return
+		}
+		if rejectSigned(w, r, report.IsSigned(), reportID) {
+			return
+		}
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionDeleteReport, req.ReportIDs...) {
+		return
+	}
+
+	if err := h.runDeleteReports(r.Context(), req.ReportIDs...); err != nil {
+		logger.Error("Error deleting report", zap.Error(err))
+		if errors.Is(err, reports.ErrReportOnLegalHold) {
+			http.Error(w, "report is under a legal hold", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, reports.ErrReportSigned) {
+			http.Error(w, "report is signed; add an addendum instead", http.StatusConflict)
+			return
+		}
+		http.Error(w, "error deleting report", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Reports deleted successfully", zap.Strings("ReportIDs", req.ReportIDs))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SignReport signs a report whose generation succeeded, recording the signer, the attestation and a hash of
+// the signed content. The report is locked afterwards: corrections are appended with AddAddendum. Notes of a
+// supervised provider await co-signature, and the supervisor is notified.
+func (h *reportsHandler) SignReport(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SignReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	report, err := h.reportsService.Get(r.Context(), req.ReportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusNotFound)
+		return
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if report.IsSigned() {
+		http.Error(w, reports.ErrReportSigned.Error(), http.StatusConflict)
+		return
+	}
+	if report.Status != reports.StatusSuccess {
+		http.Error(w, reports.ErrReportNotReady.Error(), http.StatusConflict)
+		return
+	}
+	if report.Version != req.Version {
+		writeVersionConflict(w, report.Version)
+		return
+	}
+	attestation := strings.TrimSpace(req.Attestation)
+	if attestation == "" {
+		attestation = reports.DefaultAttestation
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionSignReport, req.ReportID) {
+		return
+	}
+
+	signer, err := h.userStore.Get(r.Context(), userID)
+	if err != nil {
+		logger.Error("Error fetching signer", zap.String("UserID", userID), zap.Error(err))
+		http.Error(w, "error signing report", http.StatusInternalServerError)
+		return
+	}
+	signature := reports.Signature{
+		SignerID:    userID,
+		SignerName:  signer.Name,
+		SignedAt:    primitive.NewDateTimeFromTime(time.Now()),
+		Attestation: attestation,
+		ContentHash: report.ContentHash(),
+	}
+	if err := h.reportsService.Sign(r.Context(), req.ReportID, req.Version, signature, signer.SupervisorID); err != nil {
+		logger.Error("Error signing report", zap.String("ReportID", req.ReportID), zap.Error(err))
+		if errors.Is(err, reports.ErrReportSigned) || errors.Is(err, reports.ErrReportNotReady) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeUpdateError(w, err, "error signing report")
+		return
+	}
+
+	response := SignReportResponse{Status: reports.StatusSigned, Signature: signature}
+	if signer.SupervisorID != "" {
+		response.Status = reports.StatusPendingCoSignature
+		emailsender.NotifyUser(r.Context(), h.userStore, h.emailSender, signer.SupervisorID, "Note awaiting your co-signature",
+			fmt.Sprintf("%s signed a note that awaits your co-signature. Review it in your co-signature inbox.", signerName(signer)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Error encoding signature", zap.Error(err))
+		http.Error(w, "error encoding signature", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Report signed", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.String("Status", response.Status))
+}
+
+// AddAddendum appends a timestamped correction to a signed report and returns it.
+func (h *reportsHandler) AddAddendum(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req AddendumRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if strings.TrimSpace(req.Content) == "" {
+		http.Error(w, "addendum content cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.reportsService.Get(r.Context(), req.ReportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", req.ReportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusNotFound)
+		return
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", req.ReportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !report.IsSigned() {
+		http.Error(w, "only signed reports take addenda; edit the report instead", http.StatusConflict)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionAddAddendum, req.ReportID) {
+		return
+	}
+
+	author, err := h.userStore.Get(r.Context(), userID)
+	if err != nil {
+		logger.Error("Error fetching addendum author", zap.String("UserID", userID), zap.Error(err))
+		http.Error(w, "error adding addendum", http.StatusInternalServerError)
+		return
+	}
+	addendum, err := h.reportsService.AddAddendum(r.Context(), req.ReportID, reports.Addendum{
+		AuthorID:   userID,
+		AuthorName: author.Name,
+		Content:    strings.TrimSpace(req.Content),
+		CreatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+	})
+	if err != nil {
+		logger.Error("Error adding addendum", zap.String("ReportID", req.ReportID), zap.Error(err))
+		if errors.Is(err, reports.ErrReportNotSigned) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "error adding addendum", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(addendum); err != nil {
+		logger.Error("Error encoding addendum", zap.Error(err))
+		return
+	}
+
+	logger.Info("Addendum added", zap.String("UserID", userID), zap.String("ReportID", req.ReportID), zap.String("AddendumID", addendum.ID.Hex()))
+}
+
+// ExportNote downloads a report as a plain-text note, with its signature and addenda once it is signed.
+func (h *reportsHandler) ExportNote(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	reportID := chi.URLParam(r, "reportID")
+	report, err := h.reportsService.Get(r.Context(), reportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusNotFound)
+		return
+	}
+	if report.ProviderID != userID {
+		logger.Error("Unauthorized access to report", zap.String("UserID", userID), zap.String("ReportID", reportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionExportNote, reportID) {
+		return
+	}
+
+	content := []byte(reports.FormatNoteText(report))
+	w.Header().Set("Content-Type", reports.NoteExportContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exportFilename(report, "note", "txt")}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(content); err != nil {
+		logger.Error("Error writing note export", zap.String("ReportID", reportID), zap.Error(err))
+		return
+	}
+
+	logger.Info("Note exported successfully", zap.String("UserID", userID), zap.String("ReportID", reportID), zap.Bool("Signed", report.IsSigned()))
+}
+
+// signerName is how a user is named in notifications about their notes.
+func signerName(u user.User) string {
+	if name := strings.TrimSpace(u.Name); name != "" {
+		return name
+	}
+	return "A provider"
+}
+
+// rejectSigned refuses a change to a signed report with 409 Conflict and returns true when it did.
+func rejectSigned(w http.ResponseWriter, r *http.Request, signed bool, reportID string) bool {
+	if !signed {
+		return false
+	}
+	contextLogger.FromCtx(r.Context()).Warn("Attempt to change a signed report", zap.String("ReportID", reportID))
+	http.Error(w, "report is signed; add an addendum instead", http.StatusConflict)
+	return true
+}
+
+// writeUpdateError responds to a failed report write, with 409 Conflict when the report was signed meanwhile
+// or the write was based on a stale version of it.
+func writeUpdateError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, reports.ErrReportSigned) {
+		http.Error(w, "report is signed; add an addendum instead", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, reports.ErrReportRegenerating) {
+		http.Error(w, "report is being regenerated; try again once it completes", http.StatusConflict)
+		return
+	}
+	var conflict *reports.VersionConflictError
+	if errors.As(err, &conflict) {
+		writeVersionConflict(w, conflict.Current)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// writeVersionConflict rejects a write based on a stale version of a report with 409 Conflict and the current version.
+func writeVersionConflict(w http.ResponseWriter, current int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(VersionConflictResponse{Error: "report was changed by another write; reload it and try again", Version: current})
+}
+
+// writeVersion responds to a successful report write with the report's new version.
+func writeVersion(w http.ResponseWriter, r *http.Request, version int64) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ReportVersionResponse{Version: version}); err != nil {
+		contextLogger.FromCtx(r.Context()).Error("Error encoding report version", zap.Error(err))
+	}
+}
+
+func (h *reportsHandler) verifyReportBelongsToProvider(r context.Context, providerID string, reportIDs ...string) error {
+	logger := contextLogger.FromCtx(r)
+	for _, reportID := range reportIDs {
+		report, err := h.reportsService.Get(r, reportID)
+		if err != nil {
+			logger.Error("Error verifying report ownership", zap.String("ReportID", reportID), zap.Error(err))
+			return fmt.Errorf("error verifying report belongs to provider: %s", providerID)
+		}
+		if providerID != report.ProviderID {
+			logger.Error("Unauthorized access detected", zap.String("ReportID", reportID), zap.String("ProviderID", providerID))
+			return fmt.Errorf("unauthorized access to report: %s", reportID)
+		}
+	}
+	return nil
+}
+
+// auditCreated records a report created by a generation pipeline. The report only exists once the
+// pipeline is streaming, so a failure to record it is logged instead of refusing the request.
+func (h *reportsHandler) auditCreated(ctx context.Context, reportID string) {
+	if reportID == "" {
+		return
+	}
+	if _, err := h.auditLog.Append(ctx, middleware.NewAuditEntry(ctx, auditLog.ActionCreateReport, reportID)); err != nil {
+		contextLogger.FromCtx(ctx).Error("Error recording report creation", zap.String("ReportID", reportID), zap.Error(err))
+	}
+}
+
+func (h *reportsHandler) runDeleteReports(r context.Context, reportIDs ...string) error {
+	logger := contextLogger.FromCtx(r)
+	for _, reportID := range reportIDs {
+		if err := h.reportsService.Delete(r, reportID); err != nil {
+			logger.Error("Error deleting report", zap.String("ReportID", reportID), zap.Error(err))
+			return err
+		}
+		reportObjectID, err := primitive.ObjectIDFromHex(reportID)
+		if err != nil {
+			return fmt.Errorf("invalid report ID: %w", err)
+		}
+		if err := h.transcriptRevisionStore.DeleteByReportID(r, reportObjectID); err != nil {
+			logger.Error("Error deleting transcript revisions", zap.String("ReportID", reportID), zap.Error(err))
+			return err
+		}
+		if err := h.sectionRevisionStore.DeleteByReportID(r, reportObjectID); err != nil {
+			logger.Error("Error deleting section revisions", zap.String("ReportID", reportID), zap.Error(err))
+			return err
+		}
+		if err := h.audioStore.DeleteByReportID(r, reportObjectID); err != nil {
+			logger.Error("Error deleting report audio", zap.String("ReportID", reportID), zap.Error(err))
 			return err
 		}
 	}
@@ -2,24 +2,37 @@ package reportsHandler
 
 import (
 	"Medscribe/api/middleware"
+	"Medscribe/audioStore"
+	auditLog "Medscribe/auditLogStore"
+	emailsender "Medscribe/emailService"
 	inferenceService "Medscribe/inference/service"
 	"Medscribe/reports"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	transcriptRevisions "Medscribe/transcriptRevisionStore"
+	transcriber "Medscribe/transcription"
+	uploads "Medscribe/uploadStore"
 	"Medscribe/user"
+	"Medscribe/utils"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 )
 
@@ -28,8 +41,41 @@ const (
 	testUserID    = "test123"
 	testReportID  = "report123"
 	testAudioData = "test audio data"
+	// testMaxAudioSize is the recording size limit the test handlers enforce.
+	testMaxAudioSize = 1 << 20
 )
 
+// newTestHandler builds a handler around the report, inference and user mocks. Every access is audited
+// successfully, and the remaining stores are mocks without expectations.
+func newTestHandler(reportsStore *reports.MockReportsStore, inference *inferenceService.MockInferenceService, userStore *user.MockUserStore, logger *zap.Logger) ReportsHandler {
+	return newRevisionedTestHandler(reportsStore, inference, userStore, new(transcriptRevisions.MockTranscriptRevisionStore), new(sectionRevisions.MockSectionRevisionStore), logger)
+}
+
+// newRevisionedTestHandler is newTestHandler for requests that record transcript or section revisions.
+func newRevisionedTestHandler(reportsStore *reports.MockReportsStore, inference *inferenceService.MockInferenceService, userStore *user.MockUserStore, transcripts *transcriptRevisions.MockTranscriptRevisionStore, sections *sectionRevisions.MockSectionRevisionStore, logger *zap.Logger) ReportsHandler {
+	auditStore := new(auditLog.MockAuditLogStore)
+	auditStore.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil)
+	return NewReportsHandler(reportsStore, inference, userStore, transcripts, sections, new(audioStore.MockAudioStore), new(uploads.MockUploadStore), testMaxAudioSize, auditStore, new(emailsender.MockEmailSender), logger)
+}
+
+// withReportRoute authenticates the request as the test user and routes it to the report.
+func withReportRoute(req *http.Request, reportID string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("reportID", reportID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	return req.WithContext(context.WithValue(ctx, middleware.CtxKeyUserID, testUserID))
+}
+
+// streamPayloads makes a mocked pipeline stream payloads to the response writer it is given.
+func streamPayloads(payloads ...inferenceService.ContentChanPayload) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		w := args.Get(2).(*utils.SafeResponseWriter)
+		for _, payload := range payloads {
+			json.NewEncoder(w).Encode(payload)
+		}
+	}
+}
+
 func TestGenerateReport(t *testing.T) {
 	logger, err := zap.NewDevelopment()
 	assert.Nil(t, err)
@@ -37,7 +83,7 @@ func TestGenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		// Create multipart form data
 		body := &bytes.Buffer{}
@@ -78,20 +124,13 @@ func TestGenerateReport(t *testing.T) {
 
 		// Setup mock expectations
 		mockInference.On("GenerateReportPipeline",
+			mock.Anything,
 			mock.MatchedBy(func(req *inferenceService.ReportRequest) bool {
 				return req.ProviderID == testUserID &&
 					string(req.AudioBytes) == testAudioData
 			}),
-			mock.AnythingOfType("chan inferenceService.ContentChanPayload"),
-		).Run(func(args mock.Arguments) {
-			ch := args.Get(1).(chan inferenceService.ContentChanPayload)
-
-			for _, content := range contentToBeStreamed {
-				ch <- content
-			}
-
-			close(ch)
-		}).Return(nil).Once()
+			mock.AnythingOfType("*utils.SafeResponseWriter"),
+		).Run(streamPayloads(contentToBeStreamed...)).Return(nil).Once()
 
 		rr := httptest.NewRecorder()
 		handler.GenerateReport(rr, req)
@@ -117,11 +156,11 @@ func TestGenerateReport(t *testing.T) {
 		mockInference.AssertExpectations(t)
 	})
 
-	t.Run("should return internal server error when GenerateReportPipeline fails", func(t *testing.T) {
+	t.Run("should stop streaming when GenerateReportPipeline fails", func(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		// Create multipart form data
 		body := &bytes.Buffer{}
@@ -151,19 +190,20 @@ func TestGenerateReport(t *testing.T) {
 		req = req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testUserID))
 
 		// Setup mock to return error
+		failure := inferenceService.ContentChanPayload{Key: reports.Status, Value: reports.StatusFailed}
 		mockInference.On("GenerateReportPipeline",
+			mock.Anything,
 			mock.AnythingOfType("*inferenceService.ReportRequest"),
-			mock.AnythingOfType("chan inferenceService.ContentChanPayload"),
-		).Run(func(args mock.Arguments) {
-			ch := args.Get(1).(chan inferenceService.ContentChanPayload)
-			close(ch)
-		}).Return(errors.New("pipeline error")).Once()
+			mock.AnythingOfType("*utils.SafeResponseWriter"),
+		).Run(streamPayloads(failure)).Return(errors.New("pipeline error")).Once()
 
 		rr := httptest.NewRecorder()
 		handler.GenerateReport(rr, req)
 
-		assert.Equal(t, http.StatusInternalServerError, rr.Code, "should return 500 status code")
-		assert.Contains(t, rr.Body.String(), "error generating report", "should return error message")
+		// The stream has started, so the failure is reported in it rather than with the status code
+		var update inferenceService.ContentChanPayload
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&update))
+		assert.Equal(t, failure, update)
 
 		mockInference.AssertExpectations(t)
 	})
@@ -172,7 +212,7 @@ func TestGenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -196,7 +236,7 @@ func TestGenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -226,6 +266,93 @@ func TestGenerateReport(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
 		assert.Contains(t, rr.Body.String(), "failed to get audio")
 	})
+
+	t.Run("should reject audio larger than the size limit", func(t *testing.T) {
+		mockInference := new(inferenceService.MockInferenceService)
+		handler := newTestHandler(new(reports.MockReportsStore), mockInference, new(user.MockUserStore), logger)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("metadata", `{"name":"Test Report","duration":30}`))
+		part, err := writer.CreateFormFile("audio", "test.mp3")
+		require.NoError(t, err)
+		_, err = part.Write(bytes.Repeat([]byte("a"), testMaxAudioSize+maxFormOverhead))
+		require.NoError(t, err)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/reports", body)
+		// An unknown length makes the handler rely on the body limit rather than the declared size.
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req = req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testUserID))
+
+		rr := httptest.NewRecorder()
+		handler.GenerateReport(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		mockInference.AssertNotCalled(t, "GenerateReportPipeline", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject an upload larger than the size limit before reading it", func(t *testing.T) {
+		mockInference := new(inferenceService.MockInferenceService)
+		uploadStore := new(uploads.MockUploadStore)
+		uploadID := primitive.NewObjectID()
+		uploadStore.On("Get", mock.Anything, uploadID.Hex()).Return(uploads.Upload{ID: uploadID, ProviderID: testUserID, Size: testMaxAudioSize + 1, Status: uploads.StatusFinalized}, nil)
+		handler := NewReportsHandler(new(reports.MockReportsStore), mockInference, new(user.MockUserStore), new(transcriptRevisions.MockTranscriptRevisionStore), new(sectionRevisions.MockSectionRevisionStore), new(audioStore.MockAudioStore), uploadStore, testMaxAudioSize, new(auditLog.MockAuditLogStore), new(emailsender.MockEmailSender), logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/reports/upload", bytes.NewBufferString(fmt.Sprintf(`{"uploadID":%q}`, uploadID.Hex())))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testUserID))
+
+		rr := httptest.NewRecorder()
+		handler.GenerateReportFromUpload(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		uploadStore.AssertNotCalled(t, "Open", mock.Anything, mock.Anything)
+		mockInference.AssertNotCalled(t, "GenerateReportPipeline", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGenerateReportFromTranscript(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("should generate from the supplied transcript only", func(t *testing.T) {
+		mockInference := new(inferenceService.MockInferenceService)
+		handler := newTestHandler(new(reports.MockReportsStore), mockInference, new(user.MockUserStore), logger)
+		mockInference.On("GenerateReportPipeline", mock.Anything, mock.MatchedBy(func(req *inferenceService.ReportRequest) bool {
+			return req.ProviderID == testUserID &&
+				req.PatientName == "Jane" &&
+				req.ID == "" &&
+				req.SubjectiveStyle == "" &&
+				req.ImportedTranscript != nil &&
+				req.ImportedTranscript.Text == "Doctor: How are you?" &&
+				req.AudioBytes == nil
+		}), mock.Anything).Return(nil)
+
+		body := `{"patientName":"Jane","transcript":"Doctor: How are you?","transcriptFormat":"txt","ID":"report-9","SubjectiveStyle":"terse","AudioBytes":"YXVkaW8="}`
+		req := httptest.NewRequest(http.MethodPost, "/reports/transcript", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testUserID))
+
+		rr := httptest.NewRecorder()
+		handler.GenerateReportFromTranscript(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockInference.AssertExpectations(t)
+	})
+
+	t.Run("should reject an oversized request", func(t *testing.T) {
+		mockInference := new(inferenceService.MockInferenceService)
+		handler := newTestHandler(new(reports.MockReportsStore), mockInference, new(user.MockUserStore), logger)
+
+		body := `{"transcript":"` + strings.Repeat("a", maxTranscriptRequestSize) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/reports/transcript", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testUserID))
+
+		rr := httptest.NewRecorder()
+		handler.GenerateReportFromTranscript(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		mockInference.AssertNotCalled(t, "GenerateReportPipeline", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestRegenerateReport(t *testing.T) {
@@ -236,7 +363,7 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -268,17 +395,11 @@ func TestRegenerateReport(t *testing.T) {
 
 		mockInference.On("RegenerateReport",
 			mock.Anything,
-			mock.AnythingOfType("chan inferenceService.ContentChanPayload"),
 			mock.MatchedBy(func(r *inferenceService.ReportRequest) bool {
 				return r.ID == testReportID && r.ProviderID == testUserID
 			}),
-		).Run(func(args mock.Arguments) {
-			ch := args.Get(1).(chan inferenceService.ContentChanPayload)
-			for _, content := range contentToBeStreamed {
-				ch <- content
-			}
-			close(ch)
-		}).Return(nil).Once()
+			mock.AnythingOfType("*utils.SafeResponseWriter"),
+		).Run(streamPayloads(contentToBeStreamed...)).Return(nil).Once()
 
 		handler.RegenerateReport(rr, httpReq)
 
@@ -307,7 +428,7 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodPost, "/reports/regenerate", nil)
 		rr := httptest.NewRecorder()
@@ -322,7 +443,7 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodPost, "/reports/regenerate", bytes.NewBuffer(invalidBody))
@@ -339,7 +460,7 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := inferenceService.ReportRequest{
 			ID:         testReportID,
@@ -368,7 +489,7 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: "different-user-id",
@@ -402,7 +523,7 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -426,12 +547,9 @@ func TestRegenerateReport(t *testing.T) {
 
 		mockInference.On("RegenerateReport",
 			mock.Anything,
-			mock.AnythingOfType("chan inferenceService.ContentChanPayload"),
 			mock.Anything,
-		).Run(func(args mock.Arguments) {
-			ch := args.Get(1).(chan inferenceService.ContentChanPayload)
-			close(ch)
-		}).Return(errors.New("regeneration error")).Once()
+			mock.AnythingOfType("*utils.SafeResponseWriter"),
+		).Return(errors.New("regeneration error")).Once()
 
 		handler.RegenerateReport(rr, httpReq)
 
@@ -441,6 +559,63 @@ func TestRegenerateReport(t *testing.T) {
 		MockReportsStore.AssertExpectations(t)
 		mockInference.AssertExpectations(t)
 	})
+
+	regenerateRequest := func(t *testing.T, version int64) *http.Request {
+		body, err := json.Marshal(inferenceService.ReportRequest{ID: testReportID, Version: version})
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest(http.MethodPost, "/reports/regenerate", bytes.NewBuffer(body))
+		return httpReq.WithContext(context.WithValue(httpReq.Context(), middleware.CtxKeyUserID, testUserID))
+	}
+
+	t.Run("should return conflict with the current version when the request is stale", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		mockInference := new(inferenceService.MockInferenceService)
+		handler := newTestHandler(MockReportsStore, mockInference, new(user.MockUserStore), logger)
+		MockReportsStore.On("Get", mock.Anything, testReportID).
+			Return(reports.Report{ProviderID: testUserID, Version: 4}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.RegenerateReport(rr, regenerateRequest(t, 3))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		var conflict VersionConflictResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&conflict))
+		assert.Equal(t, int64(4), conflict.Version)
+		mockInference.AssertNotCalled(t, "RegenerateReport", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return conflict while the report is regenerating", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		mockInference := new(inferenceService.MockInferenceService)
+		handler := newTestHandler(MockReportsStore, mockInference, new(user.MockUserStore), logger)
+		lease := primitive.NewDateTimeFromTime(time.Now().Add(reports.RegenerationLease))
+		MockReportsStore.On("Get", mock.Anything, testReportID).
+			Return(reports.Report{ProviderID: testUserID, Version: 4, Regenerating: &lease}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.RegenerateReport(rr, regenerateRequest(t, 4))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "being regenerated")
+		mockInference.AssertNotCalled(t, "RegenerateReport", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return conflict when another regeneration takes the lock first", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		mockInference := new(inferenceService.MockInferenceService)
+		handler := newTestHandler(MockReportsStore, mockInference, new(user.MockUserStore), logger)
+		MockReportsStore.On("Get", mock.Anything, testReportID).
+			Return(reports.Report{ProviderID: testUserID, Version: 4}, nil).Once()
+		mockInference.On("RegenerateReport", mock.Anything, mock.Anything, mock.Anything).
+			Return(fmt.Errorf("RegenerateReport: %w", reports.ErrReportRegenerating)).Once()
+
+		rr := httptest.NewRecorder()
+		handler.RegenerateReport(rr, regenerateRequest(t, 4))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "being regenerated")
+		mockInference.AssertExpectations(t)
+	})
 }
 
 func TestGetReport(t *testing.T) {
@@ -451,7 +626,7 @@ func TestGetReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		expectedReport := reports.Report{
 			ProviderID: testUserID,
@@ -495,7 +670,7 @@ func TestGetReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodGet, "/reports/GetReport", nil)
 		rr := httptest.NewRecorder()
@@ -510,7 +685,7 @@ func TestGetReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodGet, "/reports/GetReport", bytes.NewBuffer(invalidBody))
@@ -527,7 +702,7 @@ func TestGetReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := GetReportRequest{
 			ReportID: testReportID,
@@ -554,7 +729,7 @@ func TestGetReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: "different-user-id",
@@ -593,7 +768,7 @@ func TestLearnStyle(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -636,7 +811,7 @@ func TestLearnStyle(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodPost, "/reports/LearnStyle", nil)
 		rr := httptest.NewRecorder()
@@ -651,7 +826,7 @@ func TestLearnStyle(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodPost, "/reports/LearnStyle", bytes.NewBuffer(invalidBody))
@@ -668,7 +843,7 @@ func TestLearnStyle(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := LearnStyleRequest{
 			ReportID:       testReportID,
@@ -697,7 +872,7 @@ func TestLearnStyle(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: "different-user-id",
@@ -731,7 +906,7 @@ func TestLearnStyle(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -780,7 +955,7 @@ func TestGetTranscript(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		expectedTranscript := "test transcript"
 
@@ -796,17 +971,17 @@ func TestGetTranscript(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		MockReportsStore.On("GetTranscription", mock.Anything, testReportID).
-			Return(testUserID, expectedTranscript, nil).Once()
+			Return(reports.RetrievedReportTranscripts{ProviderID: testUserID, Transcript: expectedTranscript}, nil).Once()
 
 		handler.GetTranscript(rr, httpReq)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 
-		var response string
+		var response reports.RetrievedReportTranscripts
 		err = json.NewDecoder(rr.Body).Decode(&response)
 		assert.NoError(t, err)
 
-		assert.Equal(t, expectedTranscript, response)
+		assert.Equal(t, expectedTranscript, response.Transcript)
 
 		MockReportsStore.AssertExpectations(t)
 	})
@@ -815,7 +990,7 @@ func TestGetTranscript(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodGet, "/reports/GetTranscript", nil)
 		rr := httptest.NewRecorder()
@@ -829,7 +1004,7 @@ func TestGetTranscript(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodGet, "/reports/GetTranscript", bytes.NewBuffer(invalidBody))
@@ -843,6 +1018,120 @@ func TestGetTranscript(t *testing.T) {
 	})
 }
 
+func TestExportTranscript(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	report := reports.Report{ID: primitive.NewObjectID(), ProviderID: testUserID, Name: "Checkup", Duration: 42}
+
+	t.Run("should export a stored diarized transcript", func(t *testing.T) {
+		reportsStore := new(reports.MockReportsStore)
+		reportsStore.On("Get", mock.Anything, testReportID).Return(report, nil)
+		reportsStore.On("GetTranscription", mock.Anything, testReportID).Return(reports.RetrievedReportTranscripts{
+			ProviderID:      testUserID,
+			Transcript:      `[{"speaker":"Doctor","startTime":0,"endTime":2,"text":"How are you?"}]`,
+			UsedDiarization: true,
+			DiarizedTranscript: []transcriber.TranscriptTurn{
+				{Speaker: "Doctor", StartTime: 0, EndTime: 2, Text: "How are you?"},
+				{Speaker: "Patient", StartTime: 2, EndTime: 4, Text: "Much better."},
+			},
+		}, nil)
+		handler := newTestHandler(reportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), logger)
+
+		req := withReportRoute(httptest.NewRequest(http.MethodGet, "/reports/transcript/"+testReportID+"/export?format=vtt", nil), testReportID)
+		rr := httptest.NewRecorder()
+		handler.ExportTranscript(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/vtt; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "Checkup-transcript.vtt")
+		assert.Contains(t, rr.Body.String(), "<v Doctor>How are you?")
+		assert.Contains(t, rr.Body.String(), "<v Patient>Much better.")
+	})
+
+	t.Run("should export a transcript made without diarization as one cue", func(t *testing.T) {
+		reportsStore := new(reports.MockReportsStore)
+		reportsStore.On("Get", mock.Anything, testReportID).Return(report, nil)
+		reportsStore.On("GetTranscription", mock.Anything, testReportID).Return(reports.RetrievedReportTranscripts{ProviderID: testUserID, Transcript: "How are you? Much better."}, nil)
+		handler := newTestHandler(reportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), logger)
+
+		req := withReportRoute(httptest.NewRequest(http.MethodGet, "/reports/transcript/"+testReportID+"/export?format=srt", nil), testReportID)
+		rr := httptest.NewRecorder()
+		handler.ExportTranscript(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "00:00:00,000 --> 00:00:42,000")
+		assert.Contains(t, rr.Body.String(), "How are you? Much better.")
+	})
+
+	t.Run("should return not found when the report has no transcript", func(t *testing.T) {
+		reportsStore := new(reports.MockReportsStore)
+		reportsStore.On("Get", mock.Anything, testReportID).Return(report, nil)
+		reportsStore.On("GetTranscription", mock.Anything, testReportID).Return(reports.RetrievedReportTranscripts{ProviderID: testUserID}, nil)
+		handler := newTestHandler(reportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), logger)
+
+		req := withReportRoute(httptest.NewRequest(http.MethodGet, "/reports/transcript/"+testReportID+"/export", nil), testReportID)
+		rr := httptest.NewRecorder()
+		handler.ExportTranscript(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestUpdateTranscript(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	reportID := primitive.NewObjectID()
+
+	t.Run("should return the turns as stored", func(t *testing.T) {
+		reportsStore := new(reports.MockReportsStore)
+		transcriptStore := new(transcriptRevisions.MockTranscriptRevisionStore)
+		handler := newRevisionedTestHandler(reportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), transcriptStore, new(sectionRevisions.MockSectionRevisionStore), logger)
+
+		stored := []transcriber.TranscriptTurn{
+			{Speaker: "Doctor", Label: "Speaker1", StartTime: 0, EndTime: 2, Text: "How are you?", Confidence: 0.95},
+			{Speaker: "Patient", Label: "Speaker2", StartTime: 2, EndTime: 4, Text: "Much beter.", Confidence: 0.4},
+		}
+		reportsStore.On("GetTranscription", mock.Anything, reportID.Hex()).Return(reports.RetrievedReportTranscripts{
+			ProviderID:         testUserID,
+			Transcript:         "stored",
+			DiarizedTranscript: stored,
+			UsedDiarization:    true,
+			Version:            3,
+		}, nil)
+		transcriptStore.On("InsertOriginal", mock.Anything, mock.Anything).Return(false, nil)
+		transcriptStore.On("Insert", mock.Anything, mock.Anything).Return(transcriptRevisions.TranscriptRevision{ID: primitive.NewObjectID(), Revision: 2}, nil)
+		var updated string
+		reportsStore.On("UpdateTranscript", mock.Anything, reportID.Hex(), int64(3), mock.Anything).
+			Run(func(args mock.Arguments) { updated = args.String(3) }).Return(int64(4), nil)
+		reportsStore.On("MarkSectionsStale", mock.Anything, reportID.Hex(), mock.Anything).Return(nil)
+
+		// The client sends the turns back without their raw labels, correcting the second one.
+		body, err := json.Marshal(UpdateTranscriptRequest{ReportID: reportID.Hex(), Version: 3, DiarizedTranscript: []transcriber.TranscriptTurn{
+			{Speaker: "Doctor", StartTime: 0, EndTime: 2, Text: "How are you?"},
+			{Speaker: "Patient", StartTime: 2, EndTime: 4, Text: "Much better."},
+		}})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPatch, "/reports/updateTranscript", bytes.NewBuffer(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testUserID))
+		rr := httptest.NewRecorder()
+
+		handler.UpdateTranscript(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response reports.RetrievedReportTranscripts
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, int64(4), response.Version)
+		require.Len(t, response.DiarizedTranscript, 2)
+		assert.Equal(t, "Speaker1", response.DiarizedTranscript[0].Label)
+		assert.Equal(t, 0.95, response.DiarizedTranscript[0].Confidence, "unchanged turns keep their confidence")
+		assert.Equal(t, "Speaker2", response.DiarizedTranscript[1].Label)
+		assert.Zero(t, response.DiarizedTranscript[1].Confidence, "edited turns lose their confidence")
+		assert.Equal(t, map[string]string{"Speaker1": "Doctor", "Speaker2": "Patient"}, response.SpeakerMapping)
+
+		var storedTurns []transcriber.TranscriptTurn
+		require.NoError(t, json.Unmarshal([]byte(updated), &storedTurns))
+		assert.Equal(t, storedTurns, response.DiarizedTranscript)
+	})
+}
+
 func TestChangeReportName(t *testing.T) {
 	logger, err := zap.NewDevelopment()
 	assert.Nil(t, err)
@@ -851,7 +1140,7 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -872,9 +1161,9 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore.On("Get", mock.Anything, testReportID).
 			Return(existingReport, nil).Once()
 
-		MockReportsStore.On("UpdateReport", mock.Anything, testReportID, bson.D{
+		MockReportsStore.On("UpdateReport", mock.Anything, testReportID, int64(0), bson.D{
 			{Key: "name", Value: "New Report Name"},
-		}).Return(nil).Once()
+		}).Return(int64(1), nil).Once()
 
 		handler.ChangeReportName(rr, httpReq)
 
@@ -887,7 +1176,7 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodPost, "/reports/ChangeName", nil)
 		rr := httptest.NewRecorder()
@@ -902,7 +1191,7 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodPost, "/reports/ChangeName", bytes.NewBuffer(invalidBody))
@@ -921,7 +1210,7 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := ChangeNameRequest{
 			ReportID: testReportID,
@@ -949,7 +1238,7 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: "different-user-id",
@@ -982,7 +1271,7 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -1003,9 +1292,9 @@ func TestChangeReportName(t *testing.T) {
 		MockReportsStore.On("Get", mock.Anything, testReportID).
 			Return(existingReport, nil).Once()
 
-		MockReportsStore.On("UpdateReport", mock.Anything, testReportID, bson.D{
+		MockReportsStore.On("UpdateReport", mock.Anything, testReportID, int64(0), bson.D{
 			{Key: "name", Value: "New Report Name"},
-		}).Return(errors.New("update failed")).Once()
+		}).Return(int64(0), errors.New("update failed")).Once()
 
 		handler.ChangeReportName(rr, httpReq)
 
@@ -1023,7 +1312,7 @@ func TestMarkReadAndUnread(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -1057,7 +1346,7 @@ func TestMarkReadAndUnread(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -1092,46 +1381,125 @@ func TestUpdateContentData(t *testing.T) {
 	logger, err := zap.NewDevelopment()
 	assert.Nil(t, err)
 
-	t.Run("should update report when request is valid", func(t *testing.T) {
-		MockReportsStore := new(reports.MockReportsStore)
-		mockInference := new(inferenceService.MockInferenceService)
-		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
-
-		existingReport := reports.Report{
-			ProviderID: testUserID,
-			Name:       "Test Report",
-		}
-
+	reportID := primitive.NewObjectID()
+	existingReport := reports.Report{
+		ID:         reportID,
+		ProviderID: testUserID,
+		Name:       "Test Report",
+		Subjective: reports.ReportContent{Data: "generated subjective content"},
+		Version:    3,
+	}
+	updateRequest := func(t *testing.T, version int64) (*http.Request, UpdateContentData) {
 		req := UpdateContentData{
-			ReportID:       testReportID,
+			ReportID:       reportID.Hex(),
 			ContentSection: reports.Subjective,
 			Content:        "updated subjective content",
+			Version:        version,
 		}
 		body, err := json.Marshal(req)
 		require.NoError(t, err)
-
 		httpReq := httptest.NewRequest(http.MethodPost, "/reports/UpdateReport", bytes.NewBuffer(body))
-		httpReq = httpReq.WithContext(context.WithValue(httpReq.Context(), middleware.CtxKeyUserID, testUserID))
+		return httpReq.WithContext(context.WithValue(httpReq.Context(), middleware.CtxKeyUserID, testUserID)), req
+	}
+	editRevision := mock.MatchedBy(func(r sectionRevisions.SectionRevision) bool {
+		return r.Source == sectionRevisions.SourceEdit && r.Content == "updated subjective content"
+	})
+
+	t.Run("should update report when request is valid", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		sectionStore := new(sectionRevisions.MockSectionRevisionStore)
+		handler := newRevisionedTestHandler(MockReportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), new(transcriptRevisions.MockTranscriptRevisionStore), sectionStore, logger)
+		httpReq, req := updateRequest(t, 3)
 		rr := httptest.NewRecorder()
 
-		MockReportsStore.On("Get", mock.Anything, testReportID).
+		MockReportsStore.On("Get", mock.Anything, req.ReportID).
 			Return(existingReport, nil).Once()
-
-		MockReportsStore.On("UpdateReport", mock.Anything, testReportID, bson.D{bson.E{Key: req.ContentSection, Value: bson.D{bson.E{Key: "data", Value: req.Content}}}}).Return(nil).Once()
+		sectionStore.On("InsertOriginal", mock.Anything, mock.Anything).Return(false, nil).Once()
+		sectionStore.On("Insert", mock.Anything, editRevision).Return(sectionRevisions.SectionRevision{ID: primitive.NewObjectID(), Revision: 2}, nil).Once()
+		MockReportsStore.On("UpdateReport", mock.Anything, req.ReportID, int64(3), bson.D{bson.E{Key: req.ContentSection, Value: bson.D{bson.E{Key: "data", Value: req.Content}}}}).Return(int64(4), nil).Once()
 
 		handler.UpdateContentSection(rr, httpReq)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ReportVersionResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, int64(4), response.Version)
 
 		MockReportsStore.AssertExpectations(t)
+		sectionStore.AssertExpectations(t)
+	})
+
+	t.Run("should return conflict and discard the revision when the report changed", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		sectionStore := new(sectionRevisions.MockSectionRevisionStore)
+		handler := newRevisionedTestHandler(MockReportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), new(transcriptRevisions.MockTranscriptRevisionStore), sectionStore, logger)
+		httpReq, req := updateRequest(t, 2)
+		rr := httptest.NewRecorder()
+		revisionID := primitive.NewObjectID()
+
+		MockReportsStore.On("Get", mock.Anything, req.ReportID).
+			Return(existingReport, nil).Once()
+		sectionStore.On("InsertOriginal", mock.Anything, mock.Anything).Return(false, nil).Once()
+		sectionStore.On("Insert", mock.Anything, editRevision).Return(sectionRevisions.SectionRevision{ID: revisionID, Revision: 2}, nil).Once()
+		MockReportsStore.On("UpdateReport", mock.Anything, req.ReportID, int64(2), mock.Anything).Return(int64(0), &reports.VersionConflictError{Current: 3}).Once()
+		sectionStore.On("Discard", mock.Anything, revisionID).Return(nil).Once()
+
+		handler.UpdateContentSection(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		var conflict VersionConflictResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&conflict))
+		assert.Equal(t, int64(3), conflict.Version)
+
+		MockReportsStore.AssertExpectations(t)
+		sectionStore.AssertExpectations(t)
+	})
+
+	t.Run("should return conflict while the report is regenerating", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		sectionStore := new(sectionRevisions.MockSectionRevisionStore)
+		handler := newRevisionedTestHandler(MockReportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), new(transcriptRevisions.MockTranscriptRevisionStore), sectionStore, logger)
+		httpReq, req := updateRequest(t, 3)
+		rr := httptest.NewRecorder()
+		revisionID := primitive.NewObjectID()
+
+		MockReportsStore.On("Get", mock.Anything, req.ReportID).
+			Return(existingReport, nil).Once()
+		sectionStore.On("InsertOriginal", mock.Anything, mock.Anything).Return(false, nil).Once()
+		sectionStore.On("Insert", mock.Anything, editRevision).Return(sectionRevisions.SectionRevision{ID: revisionID, Revision: 2}, nil).Once()
+		MockReportsStore.On("UpdateReport", mock.Anything, req.ReportID, int64(3), mock.Anything).Return(int64(0), reports.ErrReportRegenerating).Once()
+		sectionStore.On("Discard", mock.Anything, revisionID).Return(nil).Once()
+
+		handler.UpdateContentSection(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "being regenerated")
+		sectionStore.AssertExpectations(t)
+	})
+
+	t.Run("should fail without updating the report when the revision cannot be recorded", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		sectionStore := new(sectionRevisions.MockSectionRevisionStore)
+		handler := newRevisionedTestHandler(MockReportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), new(transcriptRevisions.MockTranscriptRevisionStore), sectionStore, logger)
+		httpReq, req := updateRequest(t, 3)
+		rr := httptest.NewRecorder()
+
+		MockReportsStore.On("Get", mock.Anything, req.ReportID).
+			Return(existingReport, nil).Once()
+		sectionStore.On("InsertOriginal", mock.Anything, mock.Anything).Return(false, nil).Once()
+		sectionStore.On("Insert", mock.Anything, editRevision).Return(sectionRevisions.SectionRevision{}, errors.New("connection reset")).Once()
+
+		handler.UpdateContentSection(rr, httpReq)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		MockReportsStore.AssertNotCalled(t, "UpdateReport", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("should return unauthorized when user not authenticated", func(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodPost, "/reports/UpdateReport", nil)
 		rr := httptest.NewRecorder()
@@ -1146,7 +1514,7 @@ func TestUpdateContentData(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodPost, "/reports/UpdateReport", bytes.NewBuffer(invalidBody))
@@ -1165,7 +1533,7 @@ func TestUpdateContentData(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := UpdateContentData{
 			ReportID:       testReportID,
@@ -1198,17 +1566,22 @@ func TestDeleteReport(t *testing.T) {
 
 	t.Run("should delete report when request is valid", func(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
-		mockInference := new(inferenceService.MockInferenceService)
-		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
-
+		transcriptStore := new(transcriptRevisions.MockTranscriptRevisionStore)
+		sectionStore := new(sectionRevisions.MockSectionRevisionStore)
+		audio := new(audioStore.MockAudioStore)
+		auditStore := new(auditLog.MockAuditLogStore)
+		auditStore.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil)
+		handler := NewReportsHandler(MockReportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), transcriptStore, sectionStore, audio, new(uploads.MockUploadStore), testMaxAudioSize, auditStore, new(emailsender.MockEmailSender), logger)
+
+		reportID := primitive.NewObjectID()
 		existingReport := reports.Report{
+			ID:         reportID,
 			ProviderID: testUserID,
 			Name:       "Test Report",
 		}
 
 		req := DeleteReportRequest{
-			ReportIDs: []string{testReportID},
+			ReportIDs: []string{reportID.Hex()},
 		}
 		body, err := json.Marshal(req)
 		require.NoError(t, err)
@@ -1217,24 +1590,54 @@ func TestDeleteReport(t *testing.T) {
 		httpReq = httpReq.WithContext(context.WithValue(httpReq.Context(), middleware.CtxKeyUserID, testUserID))
 		rr := httptest.NewRecorder()
 
-		MockReportsStore.On("Get", mock.Anything, testReportID).
+		MockReportsStore.On("Get", mock.Anything, reportID.Hex()).
 			Return(existingReport, nil).Once()
 
-		MockReportsStore.On("Delete", mock.Anything, testReportID).
+		MockReportsStore.On("Delete", mock.Anything, reportID.Hex()).
 			Return(nil).Once()
+		transcriptStore.On("DeleteByReportID", mock.Anything, reportID).Return(nil).Once()
+		sectionStore.On("DeleteByReportID", mock.Anything, reportID).Return(nil).Once()
+		audio.On("DeleteByReportID", mock.Anything, reportID).Return(nil).Once()
 
 		handler.DeleteReport(rr, httpReq)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 
 		MockReportsStore.AssertExpectations(t)
+		transcriptStore.AssertExpectations(t)
+		sectionStore.AssertExpectations(t)
+		audio.AssertExpectations(t)
+	})
+
+	t.Run("should refuse to delete a signed report", func(t *testing.T) {
+		MockReportsStore := new(reports.MockReportsStore)
+		transcriptStore := new(transcriptRevisions.MockTranscriptRevisionStore)
+		sectionStore := new(sectionRevisions.MockSectionRevisionStore)
+		handler := newRevisionedTestHandler(MockReportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), transcriptStore, sectionStore, logger)
+
+		unsigned, signed := primitive.NewObjectID(), primitive.NewObjectID()
+		MockReportsStore.On("Get", mock.Anything, unsigned.Hex()).Return(reports.Report{ID: unsigned, ProviderID: testUserID}, nil).Once()
+		MockReportsStore.On("Get", mock.Anything, signed.Hex()).Return(reports.Report{ID: signed, ProviderID: testUserID, Signature: &reports.Signature{SignerID: testUserID}}, nil).Once()
+
+		body, err := json.Marshal(DeleteReportRequest{ReportIDs: []string{unsigned.Hex(), signed.Hex()}})
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest(http.MethodDelete, "/reports/DeleteReport", bytes.NewBuffer(body))
+		httpReq = httpReq.WithContext(context.WithValue(httpReq.Context(), middleware.CtxKeyUserID, testUserID))
+		rr := httptest.NewRecorder()
+
+		handler.DeleteReport(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		MockReportsStore.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		transcriptStore.AssertNotCalled(t, "DeleteByReportID", mock.Anything, mock.Anything)
+		sectionStore.AssertNotCalled(t, "DeleteByReportID", mock.Anything, mock.Anything)
 	})
 
 	t.Run("should return unauthorized when user not authenticated", func(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := httptest.NewRequest(http.MethodDelete, "/reports/DeleteReport", nil)
 		rr := httptest.NewRecorder()
@@ -1249,7 +1652,7 @@ func TestDeleteReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		invalidBody := []byte(`{"invalid json`)
 		req := httptest.NewRequest(http.MethodDelete, "/reports/DeleteReport", bytes.NewBuffer(invalidBody))
@@ -1266,7 +1669,7 @@ func TestDeleteReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		req := DeleteReportRequest{
 			ReportIDs: []string{testReportID},
@@ -1293,7 +1696,7 @@ func TestDeleteReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: "different-user-id",
@@ -1325,7 +1728,7 @@ func TestDeleteReport(t *testing.T) {
 		MockReportsStore := new(reports.MockReportsStore)
 		mockInference := new(inferenceService.MockInferenceService)
 		mockUser := new(user.MockUserStore)
-		handler := NewReportsHandler(MockReportsStore, mockInference, mockUser, logger)
+		handler := newTestHandler(MockReportsStore, mockInference, mockUser, logger)
 
 		existingReport := reports.Report{
 			ProviderID: testUserID,
@@ -1356,3 +1759,48 @@ func TestDeleteReport(t *testing.T) {
 		MockReportsStore.AssertExpectations(t)
 	})
 }
+
+func TestGetAudio(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	blobID := primitive.NewObjectID().Hex()
+	createdAt := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	recording := "0123456789"
+
+	newAudioRequest := func(rangeHeader string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/reports/audio/"+testReportID, nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		return withReportRoute(req, testReportID)
+	}
+	newAudioHandler := func() ReportsHandler {
+		reportsStore := new(reports.MockReportsStore)
+		reportsStore.On("Get", mock.Anything, testReportID).Return(reports.Report{ProviderID: testUserID, AudioBlobID: blobID}, nil)
+		audio := new(audioStore.MockAudioStore)
+		audio.On("Get", mock.Anything, blobID).Return(audioStore.AudioBlob{ContentType: "audio/mpeg", Size: int64(len(recording)), CreatedAt: primitive.NewDateTimeFromTime(createdAt)}, nil)
+		audio.On("Open", mock.Anything, blobID).Return(io.NopCloser(strings.NewReader(recording)), nil)
+		auditStore := new(auditLog.MockAuditLogStore)
+		auditStore.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil)
+		return NewReportsHandler(reportsStore, new(inferenceService.MockInferenceService), new(user.MockUserStore), new(transcriptRevisions.MockTranscriptRevisionStore), new(sectionRevisions.MockSectionRevisionStore), audio, new(uploads.MockUploadStore), testMaxAudioSize, auditStore, new(emailsender.MockEmailSender), logger)
+	}
+
+	t.Run("should serve the whole recording", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newAudioHandler().GetAudio(rr, newAudioRequest(""))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "audio/mpeg", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "bytes", rr.Header().Get("Accept-Ranges"))
+		assert.Equal(t, createdAt.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+		assert.Equal(t, recording, rr.Body.String())
+	})
+
+	t.Run("should serve the requested range", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newAudioHandler().GetAudio(rr, newAudioRequest("bytes=2-5"))
+
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, "bytes 2-5/10", rr.Header().Get("Content-Range"))
+		assert.Equal(t, "2345", rr.Body.String())
+	})
+}
@@ -0,0 +1,302 @@
+package supervisorHandler
+
+import (
+	"Medscribe/api/middleware"
+	auditLog "Medscribe/auditLogStore"
+	emailsender "Medscribe/emailService"
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	"Medscribe/user"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// ReviewCommentRequest comments on a note awaiting co-signature, or returns it for changes with the comment.
+type ReviewCommentRequest struct {
+	ReportID string `json:"reportID"`
+	Comment  string `json:"comment"`
+}
+
+// CoSignRequest co-signs a note. Attestation defaults to reports.DefaultAttestation.
+type CoSignRequest struct {
+	ReportID    string `json:"reportID"`
+	Attestation string `json:"attestation"`
+}
+
+// SupervisorHandler serves the co-signature workflow of supervising physicians. Supervisors only see the
+// notes their supervisees signed for their review.
+type SupervisorHandler interface {
+	Inbox(w http.ResponseWriter, r *http.Request)
+	Comment(w http.ResponseWriter, r *http.Request)
+	ReturnForChanges(w http.ResponseWriter, r *http.Request)
+	CoSign(w http.ResponseWriter, r *http.Request)
+}
+
+type supervisorHandler struct {
+	reports     reports.Reports
+	users       user.UserStore
+	auditLog    auditLog.AuditLogStore
+	emailSender emailsender.EmailSender
+}
+
+func NewSupervisorHandler(reportsStore reports.Reports, userStore user.UserStore, auditLogStore auditLog.AuditLogStore, emailSender emailsender.EmailSender) SupervisorHandler {
+	return &supervisorHandler{
+		reports:     reportsStore,
+		users:       userStore,
+		auditLog:    auditLogStore,
+		emailSender: emailSender,
+	}
+}
+
+// Inbox lists the notes awaiting the supervisor's co-signature, oldest first.
+func (h *supervisorHandler) Inbox(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	supervisorID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	pending, err := h.reports.PendingCoSignatures(r.Context(), supervisorID)
+	if err != nil {
+		logger.Error("Error fetching notes awaiting co-signature", zap.String("SupervisorID", supervisorID), zap.Error(err))
+		http.Error(w, "error fetching co-signature inbox", http.StatusInternalServerError)
+		return
+	}
+	reportIDs := make([]string, len(pending))
+	for i, report := range pending {
+		reportIDs[i] = report.ID.Hex()
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionListReviews, reportIDs...) {
+		return
+	}
+	if pending == nil {
+		pending = []reports.Report{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pending); err != nil {
+		logger.Error("Error encoding co-signature inbox", zap.Error(err))
+		http.Error(w, "error encoding co-signature inbox", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Co-signature inbox fetched", zap.String("SupervisorID", supervisorID), zap.Int("Pending", len(pending)))
+}
+
+// Comment adds the supervisor's comment to a note awaiting their co-signature and notifies its author.
+func (h *supervisorHandler) Comment(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	supervisorID, req, report, ok := h.reviewRequest(w, r)
+	if !ok {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionCommentReview, req.ReportID) {
+		return
+	}
+
+	comment, err := h.reports.AddReviewComment(r.Context(), req.ReportID, h.newComment(r.Context(), supervisorID, req.Comment))
+	if err != nil {
+		logger.Error("Error adding review comment", zap.String("ReportID", req.ReportID), zap.Error(err))
+		writeReviewError(w, err, "error adding comment")
+		return
+	}
+	emailsender.NotifyUser(r.Context(), h.users, h.emailSender, report.ProviderID, "New comment on your note",
+		fmt.Sprintf("%s commented on a note awaiting their co-signature.", displayName(comment.AuthorName)))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(comment); err != nil {
+		logger.Error("Error encoding review comment", zap.Error(err))
+		return
+	}
+
+	logger.Info("Review comment added", zap.String("SupervisorID", supervisorID), zap.String("ReportID", req.ReportID))
+}
+
+// ReturnForChanges sends a note back to its author with the supervisor's comment. The note is unsigned so the
+// author can edit it and sign it again, which puts it back in the supervisor's inbox.
+func (h *supervisorHandler) ReturnForChanges(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	supervisorID, req, report, ok := h.reviewRequest(w, r)
+	if !ok {
+		return
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionReturnReport, req.ReportID) {
+		return
+	}
+
+	comment, err := h.reports.ReturnForChanges(r.Context(), req.ReportID, h.newComment(r.Context(), supervisorID, req.Comment))
+	if err != nil {
+		logger.Error("Error returning note for changes", zap.String("ReportID", req.ReportID), zap.Error(err))
+		writeReviewError(w, err, "error returning note")
+		return
+	}
+	emailsender.NotifyUser(r.Context(), h.users, h.emailSender, report.ProviderID, "Note returned for changes",
+		fmt.Sprintf("%s returned a note for changes. Edit it and sign it again to resubmit it for co-signature.", displayName(comment.AuthorName)))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(comment); err != nil {
+		logger.Error("Error encoding review comment", zap.Error(err))
+		return
+	}
+
+	logger.Info("Note returned for changes", zap.String("SupervisorID", supervisorID), zap.String("ReportID", req.ReportID))
+}
+
+// CoSign records the supervisor's signature on a note awaiting it and notifies its author. The note must still
+// hold the content its author signed.
+func (h *supervisorHandler) CoSign(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	supervisorID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CoSignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	report, ok := h.reviewedReport(w, r, supervisorID, req.ReportID)
+	if !ok {
+		return
+	}
+	contentHash := report.ContentHash()
+	if report.Signature == nil || report.Signature.ContentHash != contentHash {
+		logger.Error("Note content does not match its signature", zap.String("ReportID", req.ReportID))
+		http.Error(w, "note content changed since it was signed", http.StatusConflict)
+		return
+	}
+	attestation := strings.TrimSpace(req.Attestation)
+	if attestation == "" {
+		attestation = reports.DefaultAttestation
+	}
+	if !middleware.RecordAccess(w, r, h.auditLog, auditLog.ActionCoSignReport, req.ReportID) {
+		return
+	}
+
+	signature := reports.Signature{
+		SignerID:    supervisorID,
+		SignerName:  h.userName(r.Context(), supervisorID),
+		SignedAt:    primitive.NewDateTimeFromTime(time.Now()),
+		Attestation: attestation,
+		ContentHash: contentHash,
+	}
+	if err := h.reports.CoSign(r.Context(), req.ReportID, signature); err != nil {
+		logger.Error("Error co-signing note", zap.String("ReportID", req.ReportID), zap.Error(err))
+		writeReviewError(w, err, "error co-signing note")
+		return
+	}
+	emailsender.NotifyUser(r.Context(), h.users, h.emailSender, report.ProviderID, "Note co-signed",
+		fmt.Sprintf("%s co-signed your note.", displayName(signature.SignerName)))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(signature); err != nil {
+		logger.Error("Error encoding co-signature", zap.Error(err))
+		return
+	}
+
+	logger.Info("Note co-signed", zap.String("SupervisorID", supervisorID), zap.String("ReportID", req.ReportID))
+}
+
+// reviewRequest decodes a comment on a note and checks it awaits the supervisor's co-signature. It writes the
+// error response itself and returns false when the request cannot proceed.
+func (h *supervisorHandler) reviewRequest(w http.ResponseWriter, r *http.Request) (string, ReviewCommentRequest, reports.Report, bool) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	supervisorID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", ReviewCommentRequest{}, reports.Report{}, false
+	}
+
+	var req ReviewCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return "", ReviewCommentRequest{}, reports.Report{}, false
+	}
+	defer r.Body.Close()
+
+	if strings.TrimSpace(req.Comment) == "" {
+		http.Error(w, "comment cannot be empty", http.StatusBadRequest)
+		return "", ReviewCommentRequest{}, reports.Report{}, false
+	}
+	report, ok := h.reviewedReport(w, r, supervisorID, req.ReportID)
+	return supervisorID, req, report, ok
+}
+
+// reviewedReport fetches a note and checks the user is its supervisor and it awaits their co-signature.
+func (h *supervisorHandler) reviewedReport(w http.ResponseWriter, r *http.Request, supervisorID, reportID string) (reports.Report, bool) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	report, err := h.reports.Get(r.Context(), reportID)
+	if err != nil {
+		logger.Error("Error fetching report", zap.String("ReportID", reportID), zap.Error(err))
+		http.Error(w, "error fetching report", http.StatusNotFound)
+		return reports.Report{}, false
+	}
+	if report.CoSignature == nil || report.CoSignature.SupervisorID != supervisorID {
+		logger.Error("Unauthorized access to report", zap.String("SupervisorID", supervisorID), zap.String("ReportID", reportID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return reports.Report{}, false
+	}
+	if !report.AwaitsCoSignature() {
+		http.Error(w, reports.ErrNotPendingCoSignature.Error(), http.StatusConflict)
+		return reports.Report{}, false
+	}
+	return report, true
+}
+
+func (h *supervisorHandler) newComment(ctx context.Context, supervisorID, content string) reports.ReviewComment {
+	return reports.ReviewComment{
+		AuthorID:   supervisorID,
+		AuthorName: h.userName(ctx, supervisorID),
+		Content:    strings.TrimSpace(content),
+		CreatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+	}
+}
+
+// userName returns the user's name to record with their comments and signatures, empty if it cannot be found.
+func (h *supervisorHandler) userName(ctx context.Context, userID string) string {
+	u, err := h.users.Get(ctx, userID)
+	if err != nil {
+		contextLogger.FromCtx(ctx).Warn("Error fetching user name", zap.String("UserID", userID), zap.Error(err))
+		return ""
+	}
+	return u.Name
+}
+
+// writeReviewError responds to a failed review, with 409 Conflict when the note no longer awaits co-signature.
+func writeReviewError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, reports.ErrNotPendingCoSignature) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// displayName is how the supervisor is named in notifications to their supervisees.
+func displayName(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return "Your supervisor"
+}
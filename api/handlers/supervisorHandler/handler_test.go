@@ -0,0 +1,251 @@
+package supervisorHandler
+
+import (
+	"Medscribe/api/middleware"
+	auditLog "Medscribe/auditLogStore"
+	emailsender "Medscribe/emailService"
+	"Medscribe/reports"
+	"Medscribe/user"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testSupervisorID = "supervisor-1"
+	testProviderID   = "provider-1"
+)
+
+type testDeps struct {
+	reports *reports.MockReportsStore
+	users   *user.MockUserStore
+	audit   *auditLog.MockAuditLogStore
+	email   *emailsender.MockEmailSender
+	handler SupervisorHandler
+}
+
+func newTestDeps() testDeps {
+	d := testDeps{
+		reports: new(reports.MockReportsStore),
+		users:   new(user.MockUserStore),
+		audit:   new(auditLog.MockAuditLogStore),
+		email:   new(emailsender.MockEmailSender),
+	}
+	d.handler = NewSupervisorHandler(d.reports, d.users, d.audit, d.email)
+	d.users.On("Get", mock.Anything, testSupervisorID).Return(user.User{Name: "Dr. Chen"}, nil).Maybe()
+	d.users.On("Get", mock.Anything, testProviderID).Return(user.User{Email: "provider@example.com"}, nil).Maybe()
+	return d
+}
+
+func newRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	return req.WithContext(context.WithValue(req.Context(), middleware.CtxKeyUserID, testSupervisorID))
+}
+
+// pendingReport returns a note signed by testProviderID and awaiting testSupervisorID's co-signature.
+func pendingReport() reports.Report {
+	report := reports.Report{
+		ID:         primitive.NewObjectID(),
+		ProviderID: testProviderID,
+		Status:     reports.StatusPendingCoSignature,
+		Subjective: reports.ReportContent{Data: "headache for three days"},
+		CoSignature: &reports.CoSignature{
+			SupervisorID: testSupervisorID,
+			State:        reports.CoSignaturePending,
+		},
+	}
+	report.Signature = &reports.Signature{SignerID: testProviderID, ContentHash: report.ContentHash()}
+	return report
+}
+
+func auditEntry(action string, reportIDs ...string) interface{} {
+	return mock.MatchedBy(func(entry auditLog.Entry) bool {
+		return entry.Actor == testSupervisorID && entry.Action == action && assert.ObjectsAreEqual(reportIDs, entry.ReportIDs)
+	})
+}
+
+func TestInbox(t *testing.T) {
+	t.Run("should list the notes awaiting co-signature and record the access", func(t *testing.T) {
+		d := newTestDeps()
+		first, second := pendingReport(), pendingReport()
+		d.reports.On("PendingCoSignatures", mock.Anything, testSupervisorID).Return([]reports.Report{first, second}, nil).Once()
+		d.audit.On("Append", mock.Anything, auditEntry(auditLog.ActionListReviews, first.ID.Hex(), second.ID.Hex())).Return(auditLog.Entry{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.Inbox(rr, newRequest(http.MethodGet, "/supervisor/inbox", ""))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var pending []reports.Report
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&pending))
+		assert.Len(t, pending, 2)
+		d.audit.AssertExpectations(t)
+	})
+
+	t.Run("should return an empty list when nothing awaits co-signature", func(t *testing.T) {
+		d := newTestDeps()
+		d.reports.On("PendingCoSignatures", mock.Anything, testSupervisorID).Return(nil, nil).Once()
+		d.audit.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.Inbox(rr, newRequest(http.MethodGet, "/supervisor/inbox", ""))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, "[]", rr.Body.String())
+	})
+}
+
+func TestComment(t *testing.T) {
+	t.Run("should add the comment and notify the author", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+		d.audit.On("Append", mock.Anything, auditEntry(auditLog.ActionCommentReview, report.ID.Hex())).Return(auditLog.Entry{}, nil).Once()
+		d.reports.On("AddReviewComment", mock.Anything, report.ID.Hex(), mock.MatchedBy(func(c reports.ReviewComment) bool {
+			return c.AuthorID == testSupervisorID && c.AuthorName == "Dr. Chen" && c.Content == "clarify the dose"
+		})).Return(reports.ReviewComment{AuthorID: testSupervisorID, AuthorName: "Dr. Chen", Content: "clarify the dose"}, nil).Once()
+		d.email.On("SendEmail", "provider@example.com", "New comment on your note", mock.Anything, mock.Anything).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.Comment(rr, newRequest(http.MethodPost, "/supervisor/comment", `{"reportID":"`+report.ID.Hex()+`","comment":" clarify the dose "}`))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		d.reports.AssertExpectations(t)
+		d.email.AssertExpectations(t)
+	})
+
+	t.Run("should still succeed when the email cannot be sent", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+		d.audit.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil).Once()
+		d.reports.On("AddReviewComment", mock.Anything, report.ID.Hex(), mock.Anything).Return(reports.ReviewComment{}, nil).Once()
+		d.email.On("SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp unavailable")).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.Comment(rr, newRequest(http.MethodPost, "/supervisor/comment", `{"reportID":"`+report.ID.Hex()+`","comment":"looks good"}`))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+}
+
+func TestReviewRejections(t *testing.T) {
+	otherSupervisor := pendingReport()
+	otherSupervisor.CoSignature.SupervisorID = "supervisor-2"
+	returned := pendingReport()
+	returned.Status, returned.CoSignature.State = reports.StatusSuccess, reports.CoSignatureReturned
+	unsupervised := pendingReport()
+	unsupervised.CoSignature = nil
+
+	tests := []struct {
+		name         string
+		report       reports.Report
+		comment      string
+		expectedCode int
+	}{
+		{"empty comment", pendingReport(), "  ", http.StatusBadRequest},
+		{"another supervisor's note", otherSupervisor, "looks good", http.StatusUnauthorized},
+		{"note without supervisor", unsupervised, "looks good", http.StatusUnauthorized},
+		{"note already returned", returned, "looks good", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDeps()
+			d.reports.On("Get", mock.Anything, tt.report.ID.Hex()).Return(tt.report, nil).Maybe()
+
+			rr := httptest.NewRecorder()
+			d.handler.ReturnForChanges(rr, newRequest(http.MethodPatch, "/supervisor/return", `{"reportID":"`+tt.report.ID.Hex()+`","comment":"`+tt.comment+`"}`))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			d.reports.AssertNotCalled(t, "ReturnForChanges", mock.Anything, mock.Anything, mock.Anything)
+			d.email.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReturnForChanges(t *testing.T) {
+	t.Run("should return the note and notify the author", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+		d.audit.On("Append", mock.Anything, auditEntry(auditLog.ActionReturnReport, report.ID.Hex())).Return(auditLog.Entry{}, nil).Once()
+		d.reports.On("ReturnForChanges", mock.Anything, report.ID.Hex(), mock.Anything).Return(reports.ReviewComment{AuthorName: "Dr. Chen"}, nil).Once()
+		d.email.On("SendEmail", "provider@example.com", "Note returned for changes", mock.Anything, mock.Anything).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.ReturnForChanges(rr, newRequest(http.MethodPatch, "/supervisor/return", `{"reportID":"`+report.ID.Hex()+`","comment":"add the allergy history"}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		d.reports.AssertExpectations(t)
+		d.email.AssertExpectations(t)
+	})
+
+	t.Run("should conflict when the note stopped awaiting co-signature", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+		d.audit.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, nil).Once()
+		d.reports.On("ReturnForChanges", mock.Anything, report.ID.Hex(), mock.Anything).Return(reports.ReviewComment{}, reports.ErrNotPendingCoSignature).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.ReturnForChanges(rr, newRequest(http.MethodPatch, "/supervisor/return", `{"reportID":"`+report.ID.Hex()+`","comment":"add the allergy history"}`))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		d.email.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCoSign(t *testing.T) {
+	t.Run("should co-sign the content the author signed and notify them", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+		d.audit.On("Append", mock.Anything, auditEntry(auditLog.ActionCoSignReport, report.ID.Hex())).Return(auditLog.Entry{}, nil).Once()
+		d.reports.On("CoSign", mock.Anything, report.ID.Hex(), mock.MatchedBy(func(s reports.Signature) bool {
+			return s.SignerID == testSupervisorID && s.SignerName == "Dr. Chen" && s.Attestation == reports.DefaultAttestation &&
+				s.ContentHash == report.Signature.ContentHash
+		})).Return(nil).Once()
+		d.email.On("SendEmail", "provider@example.com", "Note co-signed", mock.Anything, mock.Anything).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.CoSign(rr, newRequest(http.MethodPatch, "/supervisor/cosign", `{"reportID":"`+report.ID.Hex()+`"}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		d.reports.AssertExpectations(t)
+		d.email.AssertExpectations(t)
+	})
+
+	t.Run("should refuse a note whose content changed since it was signed", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		report.Subjective.Data = "edited after signing"
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.CoSign(rr, newRequest(http.MethodPatch, "/supervisor/cosign", `{"reportID":"`+report.ID.Hex()+`"}`))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		d.reports.AssertNotCalled(t, "CoSign", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse when the co-signature cannot be recorded in the audit log", func(t *testing.T) {
+		d := newTestDeps()
+		report := pendingReport()
+		d.reports.On("Get", mock.Anything, report.ID.Hex()).Return(report, nil).Once()
+		d.audit.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, errors.New("connection reset")).Once()
+
+		rr := httptest.NewRecorder()
+		d.handler.CoSign(rr, newRequest(http.MethodPatch, "/supervisor/cosign", `{"reportID":"`+report.ID.Hex()+`"}`))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		d.reports.AssertNotCalled(t, "CoSign", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,232 @@
+package uploadHandler
+
+import (
+	"Medscribe/api/middleware"
+	contextLogger "Medscribe/logger"
+	uploads "Medscribe/uploadStore"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Headers of the resumable upload protocol.
+const (
+	UploadOffsetHeader = "Upload-Offset"
+	UploadLengthHeader = "Upload-Length"
+)
+
+// MaxChunkSize caps the body of a single PATCH so one request never holds a whole recording.
+const MaxChunkSize = 16 << 20
+
+type CreateUploadRequest struct {
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+	Checksum    string `json:"checksum"`
+}
+
+type FinalizeUploadRequest struct {
+	Checksum string `json:"checksum"`
+}
+
+// UploadHandler implements a tus-style resumable upload protocol: create an upload, PATCH chunks
+// at increasing offsets (asking for the current offset with HEAD after an interruption), then finalize it.
+type UploadHandler interface {
+	CreateUpload(w http.ResponseWriter, r *http.Request)
+	GetUploadOffset(w http.ResponseWriter, r *http.Request)
+	AppendChunk(w http.ResponseWriter, r *http.Request)
+	FinalizeUpload(w http.ResponseWriter, r *http.Request)
+}
+
+type uploadHandler struct {
+	uploadStore uploads.UploadStore
+}
+
+func NewUploadHandler(uploadStore uploads.UploadStore) UploadHandler {
+	return &uploadHandler{uploadStore: uploadStore}
+}
+
+func (h *uploadHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	upload, err := h.uploadStore.Create(r.Context(), userID, req.ContentType, req.Size, req.Checksum)
+	if err != nil {
+		if errors.Is(err, uploads.ErrUploadTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		logger.Error("Error creating upload", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", upload.ID.Hex())
+	setOffsetHeaders(w, upload)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(upload); err != nil {
+		logger.Error("Error encoding upload", zap.Error(err))
+		return
+	}
+
+	logger.Info("Upload created", zap.String("UserID", userID), zap.String("UploadID", upload.ID.Hex()), zap.Int64("Size", upload.Size))
+}
+
+// GetUploadOffset tells the client how many bytes were received so it can resume an interrupted upload.
+func (h *uploadHandler) GetUploadOffset(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	upload, ok := h.ownedUpload(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	setOffsetHeaders(w, upload)
+	w.WriteHeader(http.StatusOK)
+	logger.Info("Upload offset fetched", zap.String("UploadID", upload.ID.Hex()), zap.Int64("Offset", upload.Offset))
+}
+
+// AppendChunk stores the request body at the offset given in the Upload-Offset header.
+func (h *uploadHandler) AppendChunk(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	upload, ok := h.ownedUpload(w, r)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(UploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "missing or invalid Upload-Offset header", http.StatusBadRequest)
+		return
+	}
+	if r.ContentLength > MaxChunkSize {
+		http.Error(w, "chunk exceeds the maximum chunk size", http.StatusRequestEntityTooLarge)
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, MaxChunkSize)
+	defer body.Close()
+
+	updated, err := h.uploadStore.Append(r.Context(), upload.ID.Hex(), offset, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, uploads.ErrOffsetMismatch):
+			logger.Warn("Upload chunk rejected", zap.String("UploadID", upload.ID.Hex()), zap.Error(err))
+			if current, getErr := h.uploadStore.Get(r.Context(), upload.ID.Hex()); getErr == nil {
+				setOffsetHeaders(w, current)
+			}
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, uploads.ErrUploadFinalized):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, uploads.ErrUploadTooLarge), errors.As(err, &maxBytesErr):
+			logger.Warn("Upload chunk rejected", zap.String("UploadID", upload.ID.Hex()), zap.Error(err))
+			http.Error(w, "chunk exceeds the declared upload size or maximum chunk size", http.StatusRequestEntityTooLarge)
+		default:
+			logger.Error("Error appending upload chunk", zap.String("UploadID", upload.ID.Hex()), zap.Error(err))
+			http.Error(w, "error storing upload chunk", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setOffsetHeaders(w, updated)
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("Upload chunk stored", zap.String("UploadID", updated.ID.Hex()), zap.Int64("Offset", updated.Offset), zap.Int64("Size", updated.Size))
+}
+
+// FinalizeUpload checks that the upload is complete and matches its checksum, after which it can be used to generate a report.
+func (h *uploadHandler) FinalizeUpload(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	upload, ok := h.ownedUpload(w, r)
+	if !ok {
+		return
+	}
+
+	var req FinalizeUploadRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Invalid request body", zap.Error(err))
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	defer r.Body.Close()
+
+	finalized, err := h.uploadStore.Finalize(r.Context(), upload.ID.Hex(), req.Checksum)
+	if err != nil {
+		switch {
+		case errors.Is(err, uploads.ErrUploadIncomplete):
+			setOffsetHeaders(w, upload)
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, uploads.ErrChecksumMismatch):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			logger.Error("Error finalizing upload", zap.String("UploadID", upload.ID.Hex()), zap.Error(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(finalized); err != nil {
+		logger.Error("Error encoding upload", zap.Error(err))
+		return
+	}
+	logger.Info("Upload finalized", zap.String("UploadID", finalized.ID.Hex()), zap.Int64("Size", finalized.Size))
+}
+
+// ownedUpload loads the upload named in the URL and checks it belongs to the authenticated provider.
+// It writes the error response itself and returns false when the request cannot continue.
+func (h *uploadHandler) ownedUpload(w http.ResponseWriter, r *http.Request) (uploads.Upload, bool) {
+	logger := contextLogger.FromCtx(r.Context())
+
+	userID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("Unauthorized access attempt")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return uploads.Upload{}, false
+	}
+
+	uploadID := chi.URLParam(r, "uploadID")
+	upload, err := h.uploadStore.Get(r.Context(), uploadID)
+	if err != nil {
+		if errors.Is(err, uploads.ErrUploadNotFound) {
+			http.Error(w, "upload not found", http.StatusNotFound)
+			return uploads.Upload{}, false
+		}
+		logger.Error("Error fetching upload", zap.String("UploadID", uploadID), zap.Error(err))
+		http.Error(w, "error fetching upload", http.StatusBadRequest)
+		return uploads.Upload{}, false
+	}
+	if upload.ProviderID != userID {
+		logger.Error("Unauthorized access to upload", zap.String("UserID", userID), zap.String("UploadID", uploadID))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return uploads.Upload{}, false
+	}
+	return upload, true
+}
+
+func setOffsetHeaders(w http.ResponseWriter, upload uploads.Upload) {
+	w.Header().Set(UploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(UploadLengthHeader, strconv.FormatInt(upload.Size, 10))
+}
@@ -0,0 +1,169 @@
+package uploadHandler
+
+import (
+	"Medscribe/api/middleware"
+	uploads "Medscribe/uploadStore"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testUserID = "test123"
+
+func newUploadRequest(method, uploadID string, body []byte, userID string) *http.Request {
+	req := httptest.NewRequest(method, "/upload/"+uploadID, bytes.NewReader(body))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("uploadID", uploadID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	if userID != "" {
+		ctx = context.WithValue(ctx, middleware.CtxKeyUserID, userID)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestCreateUpload(t *testing.T) {
+	t.Run("should create an upload and return its location", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		upload := uploads.Upload{ID: primitive.NewObjectID(), ProviderID: testUserID, Size: 1024, Status: uploads.StatusPending}
+		mockStore.On("Create", mock.Anything, testUserID, "audio/wav", int64(1024), "").Return(upload, nil).Once()
+
+		body, err := json.Marshal(CreateUploadRequest{Size: 1024, ContentType: "audio/wav"})
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.CreateUpload(rr, newUploadRequest(http.MethodPost, "create", body, testUserID))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, upload.ID.Hex(), rr.Header().Get("Location"))
+		assert.Equal(t, "0", rr.Header().Get(UploadOffsetHeader))
+		assert.Equal(t, "1024", rr.Header().Get(UploadLengthHeader))
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("should reject uploads over the size limit", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		mockStore.On("Create", mock.Anything, testUserID, "audio/wav", int64(1<<40), "").Return(uploads.Upload{}, uploads.ErrUploadTooLarge).Once()
+
+		body, err := json.Marshal(CreateUploadRequest{Size: 1 << 40, ContentType: "audio/wav"})
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.CreateUpload(rr, newUploadRequest(http.MethodPost, "create", body, testUserID))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+}
+
+func TestAppendChunk(t *testing.T) {
+	uploadID := primitive.NewObjectID()
+
+	t.Run("should store a chunk at the current offset", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		upload := uploads.Upload{ID: uploadID, ProviderID: testUserID, Size: 8}
+		mockStore.On("Get", mock.Anything, uploadID.Hex()).Return(upload, nil).Once()
+		updated := upload
+		updated.Offset = 4
+		mockStore.On("Append", mock.Anything, uploadID.Hex(), int64(0), mock.Anything).Return(updated, nil).Once()
+
+		req := newUploadRequest(http.MethodPatch, uploadID.Hex(), []byte("RIFF"), testUserID)
+		req.Header.Set(UploadOffsetHeader, "0")
+		rr := httptest.NewRecorder()
+		handler.AppendChunk(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "4", rr.Header().Get(UploadOffsetHeader))
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("should return the current offset on a mismatch", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		upload := uploads.Upload{ID: uploadID, ProviderID: testUserID, Size: 8, Offset: 4}
+		mockStore.On("Get", mock.Anything, uploadID.Hex()).Return(upload, nil).Twice()
+		mockStore.On("Append", mock.Anything, uploadID.Hex(), int64(0), mock.Anything).Return(uploads.Upload{}, uploads.ErrOffsetMismatch).Once()
+
+		req := newUploadRequest(http.MethodPatch, uploadID.Hex(), []byte("RIFF"), testUserID)
+		req.Header.Set(UploadOffsetHeader, "0")
+		rr := httptest.NewRecorder()
+		handler.AppendChunk(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, "4", rr.Header().Get(UploadOffsetHeader))
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("should require the offset header", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		mockStore.On("Get", mock.Anything, uploadID.Hex()).Return(uploads.Upload{ID: uploadID, ProviderID: testUserID}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.AppendChunk(rr, newUploadRequest(http.MethodPatch, uploadID.Hex(), []byte("RIFF"), testUserID))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockStore.AssertNotCalled(t, "Append", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject uploads owned by another provider", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		mockStore.On("Get", mock.Anything, uploadID.Hex()).Return(uploads.Upload{ID: uploadID, ProviderID: "other"}, nil).Once()
+
+		req := newUploadRequest(http.MethodPatch, uploadID.Hex(), []byte("RIFF"), testUserID)
+		req.Header.Set(UploadOffsetHeader, "0")
+		rr := httptest.NewRecorder()
+		handler.AppendChunk(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestFinalizeUpload(t *testing.T) {
+	uploadID := primitive.NewObjectID()
+
+	t.Run("should report a checksum mismatch", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		mockStore.On("Get", mock.Anything, uploadID.Hex()).Return(uploads.Upload{ID: uploadID, ProviderID: testUserID, Size: 4, Offset: 4}, nil).Once()
+		mockStore.On("Finalize", mock.Anything, uploadID.Hex(), "abc").Return(uploads.Upload{}, uploads.ErrChecksumMismatch).Once()
+
+		body, err := json.Marshal(FinalizeUploadRequest{Checksum: "abc"})
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.FinalizeUpload(rr, newUploadRequest(http.MethodPost, uploadID.Hex(), body, testUserID))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("should refuse incomplete uploads", func(t *testing.T) {
+		mockStore := new(uploads.MockUploadStore)
+		handler := NewUploadHandler(mockStore)
+
+		mockStore.On("Get", mock.Anything, uploadID.Hex()).Return(uploads.Upload{ID: uploadID, ProviderID: testUserID, Size: 8, Offset: 4}, nil).Once()
+		mockStore.On("Finalize", mock.Anything, uploadID.Hex(), "").Return(uploads.Upload{}, uploads.ErrUploadIncomplete).Once()
+
+		rr := httptest.NewRecorder()
+		handler.FinalizeUpload(rr, newUploadRequest(http.MethodPost, uploadID.Hex(), nil, testUserID))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, "4", rr.Header().Get(UploadOffsetHeader))
+	})
+}
@@ -2,9 +2,11 @@ package userhandler
 
 import (
 	"Medscribe/api/middleware"
+	auditLog "Medscribe/auditLogStore"
 	emailsender "Medscribe/emailService"
 	contextLogger "Medscribe/logger"
 	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
 	"Medscribe/user"
 	verificationStore "Medscribe/verificationTokenStore"
 	"encoding/json"
@@ -44,6 +46,9 @@ type AuthResponse struct {
 	PatientInstructionsStyle string           `json:"patientInstructionsStyle"`
 	PlanningStyle            string           `json:"planningStyle"`
 	SummaryStyle             string           `json:"summaryStyle"`
+	Vocabulary               []string         `json:"vocabulary"`
+	AudioRetentionDays       *int             `json:"audioRetentionDays"`
+	RetentionPolicy          user.RetentionPolicy `json:"retentionPolicy"`
 	UserID                   string           `json:"userID"`
 }
 type UpdateProfileSettingsRequest struct {
@@ -52,12 +57,34 @@ type UpdateProfileSettingsRequest struct {
 	NewPassword              string `json:"newPassword" validate:"required"`
 }
 
+type UpdateVocabularyRequest struct {
+	Vocabulary []string `json:"vocabulary"`
+}
+
+type UpdateVocabularyResponse struct {
+	Vocabulary []string `json:"vocabulary"`
+}
+
+// UpdateAudioRetentionRequest sets how many days recordings are kept. Null restores the default and zero
+// stops recordings being kept.
+type UpdateAudioRetentionRequest struct {
+	Days *int `json:"days"`
+}
+
+// UpdateRetentionPolicyRequest sets the provider's retention periods in days. Zero uses the default.
+type UpdateRetentionPolicyRequest struct {
+	Policy user.RetentionPolicy `json:"policy"`
+}
+
 type UserHandler interface {
 	InitializeSighUp(w http.ResponseWriter, r *http.Request)
 	FinalizeSignUp(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
 	GetMe(w http.ResponseWriter, r *http.Request)
 	UpdateProfileSettings(w http.ResponseWriter, r *http.Request)
+	UpdateVocabulary(w http.ResponseWriter, r *http.Request)
+	UpdateAudioRetention(w http.ResponseWriter, r *http.Request)
+	UpdateRetentionPolicy(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
 }
 
@@ -67,15 +94,17 @@ type userHandler struct {
 	authMiddleware middleware.AuthMiddleware
 	verificationStore verificationStore.VerificationStore
 	emailSender 	emailsender.EmailSender
+	auditLog       auditLog.AuditLogStore
 }
 
-func NewUserHandler(userStore user.UserStore, reports reports.Reports, authMiddleware middleware.AuthMiddleware, verificationStore verificationStore.VerificationStore ,emailSender emailsender.EmailSender) UserHandler {
+func NewUserHandler(userStore user.UserStore, reports reports.Reports, authMiddleware middleware.AuthMiddleware, verificationStore verificationStore.VerificationStore ,emailSender emailsender.EmailSender, auditLogStore auditLog.AuditLogStore) UserHandler {
 	return &userHandler{
 		userStore:      userStore,
 		reports:        reports,
 		authMiddleware: authMiddleware,
 		verificationStore: verificationStore,
 		emailSender: 	emailSender,
+		auditLog:       auditLogStore,
 	}
 }
 
@@ -98,7 +127,7 @@ func (h *userHandler) InitializeSighUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if doesEmailExist{
-		logger.Warn("email already exists", zap.String("email", req.Email))
+		logger.Warn("email already exists", contextLogger.HashedPHI("email", req.Email))
 		http.Error(w, "email already exists", http.StatusConflict)
 		return
 	}
@@ -113,7 +142,7 @@ func (h *userHandler) InitializeSighUp(w http.ResponseWriter, r *http.Request) {
 	err = h.verificationStore.PutBufferedUserDocument(r.Context(),token ,req.Name, req.Email, req.Password)
 	if err != nil {
 		if err == verificationStore.ErrVerificationDocumentAlreadyExists {
-			logger.Warn("Cannot generate Verification token this soon", zap.String("token", token))
+			logger.Warn("Cannot generate Verification token this soon", contextLogger.Secret("token", token))
 			fmt.Println("BRONCO")
 			http.Error(w, "Too Soon to generate Verification Token", http.StatusConflict)
 			return
@@ -145,12 +174,11 @@ func (h *userHandler) FinalizeSignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
-	fmt.Println("Received token:", req.Token)
 
 	BufferedProviderDocument, err := h.verificationStore.GetBufferedUserDocument(r.Context(), req.Token)
 	if err != nil {
 		if err == verificationStore.ErrVerificationDocumentNotFound {
-			logger.Warn("verification token not found", zap.String("token", req.Token))
+			logger.Warn("verification token not found", contextLogger.Secret("token", req.Token))
 			http.Error(w, "verification token not found", http.StatusBadRequest)
 			return
 		}
@@ -169,15 +197,15 @@ func (h *userHandler) FinalizeSignUp(w http.ResponseWriter, r *http.Request) {
 
 	// Log the start of putting the user into the database.
 	logger.Info("starting to store new user in the database",
-		zap.String("name", BufferedProviderDocument.Name),
-		zap.String("email", BufferedProviderDocument.Email),
+		contextLogger.PHI("name", BufferedProviderDocument.Name),
+		contextLogger.HashedPHI("email", BufferedProviderDocument.Email),
 	)
 
 	ProviderID, err := h.userStore.Put(r.Context(), BufferedProviderDocument.Name, BufferedProviderDocument.Email, BufferedProviderDocument.Password)
 	if err != nil {
 		if err.Error() == fmt.Sprintf("user already exists with this email: %s", BufferedProviderDocument.Email) {
 			logger.Warn("user already exists",
-				zap.String("email", BufferedProviderDocument.Email),
+				contextLogger.HashedPHI("email", BufferedProviderDocument.Email),
 			)
 			http.Error(w, "email already in use", http.StatusConflict)
 			return
@@ -216,8 +244,8 @@ func (h *userHandler) FinalizeSignUp(w http.ResponseWriter, r *http.Request) {
 	// Log a successful signup and response.
 	logger.Info("user signup successful",
 		zap.String("provider_id", ProviderID),
-		zap.String("name", BufferedProviderDocument.Name),
-		zap.String("email", BufferedProviderDocument.Email),
+		contextLogger.PHI("name", BufferedProviderDocument.Name),
+		contextLogger.HashedPHI("email", BufferedProviderDocument.Email),
 	)
 }
 
@@ -232,13 +260,13 @@ func (h *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	logger.Info("attempting user login",
-		zap.String("email", req.Email),
+		contextLogger.HashedPHI("email", req.Email),
 	)
 
 	user, err := h.userStore.GetByAuth(r.Context(), req.Email, req.Password)
 	if err != nil {
 		logger.Warn("authentication failed",
-			zap.String("email", req.Email),
+			contextLogger.HashedPHI("email", req.Email),
 			zap.Error(err),
 		)
 		return
@@ -247,8 +275,8 @@ func (h *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	logger.Info("user authenticated successfully",
 		zap.String("provider_id", userID),
-		zap.String("email", req.Email),
-		zap.String("name", user.Name),
+		contextLogger.HashedPHI("email", req.Email),
+		contextLogger.PHI("name", user.Name),
 	)
 
 	if err := h.authMiddleware.AttachInitialTokens(w, userID); err != nil {
@@ -263,6 +291,9 @@ func (h *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to get reports", http.StatusInternalServerError)
 		return
 	}
+	if !h.auditListing(w, r, userID, reports) {
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err = json.NewEncoder(w).Encode(AuthResponse{
@@ -275,6 +306,9 @@ func (h *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	AssessmentAndPlanStyle:   user.AssessmentAndPlanStyle,
 		PatientInstructionsStyle: user.PatientInstructionsStyle,
 		SummaryStyle:             user.SummaryStyle,
+		Vocabulary:               user.Vocabulary,
+		AudioRetentionDays:       user.AudioRetentionDays,
+		RetentionPolicy:          user.RetentionPolicy(),
 		UserID:                   userID,
 	}); err != nil {
 		logger.Error("failed to encode auth response", zap.Error(err))
@@ -285,8 +319,8 @@ func (h *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	// Log a successful login and response.
 	logger.Info("user login successful",
 		zap.String("user_id", userID),
-		zap.String("email", user.Email),
-		zap.String("name", user.Name),
+		contextLogger.HashedPHI("email", user.Email),
+		contextLogger.PHI("name", user.Name),
 	)
 }
 
@@ -303,7 +337,7 @@ func (h *userHandler) InitializeForgotPassword(w http.ResponseWriter, r *http.Re
 	err := h.verificationStore.PutResetPasswordDetails(r.Context(), req.Token, req.Email)
 	if err != nil {	
 		if err == verificationStore.ErrVerificationDocumentAlreadyExists {
-			logger.Warn("Cannot generate Verification token this soon", zap.String("token", req.Token))
+			logger.Warn("Cannot generate Verification token this soon", contextLogger.Secret("token", req.Token))
 			return 
 		}
 		logger.Error("failed to store reset password details", zap.Error(err))
@@ -312,8 +346,8 @@ func (h *userHandler) InitializeForgotPassword(w http.ResponseWriter, r *http.Re
 	}
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", r.Host, req.Token)
 	logger.Info("sending reset password email",
-		zap.String("email", req.Email),
-		zap.String("reset_link", resetLink),
+		contextLogger.HashedPHI("email", req.Email),
+		contextLogger.Secret("reset_link", resetLink),
 	)
 	err = h.emailSender.SendEmail(req.Email, "Please Reset your password!", fmt.Sprintf("Your reset password token is: %s", req.Token), emailsender.GeneratePasswordResetHTMLBody(resetLink))
 	if err != nil {
@@ -348,6 +382,9 @@ func (h *userHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to fetch reports", http.StatusInternalServerError)
 		return
 	}
+	if !h.auditListing(w, r, userID, reports) {
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(AuthResponse{
 		ID:                       user.ID.Hex(),
@@ -359,6 +396,8 @@ func (h *userHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 		AssessmentAndPlanStyle:   user.AssessmentAndPlanStyle,
 		PatientInstructionsStyle: user.PatientInstructionsStyle,
 		SummaryStyle:             user.SummaryStyle,
+		Vocabulary:               user.Vocabulary,
+		AudioRetentionDays:       user.AudioRetentionDays,
 		UserID:                   userID,
 	})
 	if err != nil {
@@ -370,8 +409,8 @@ func (h *userHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	// Log a successful retrieval of user details.
 	logger.Info("successfully retrieved user details",
 		zap.String("user_id", userID),
-		zap.String("name", user.Name),
-		zap.String("email", user.Email),
+		contextLogger.PHI("name", user.Name),
+		contextLogger.HashedPHI("email", user.Email),
 		zap.Int("report_count", len(reports)),
 	)
 }
@@ -413,6 +452,119 @@ func (h *userHandler) UpdateProfileSettings(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Content-Type", "application/json")
 }
 
+// UpdateVocabulary replaces the provider's custom transcription vocabulary (medication names,
+// colleagues, facilities) used to bias and correct future transcriptions.
+func (h *userHandler) UpdateVocabulary(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+	providerID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("unauthorized access attempt to update vocabulary")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateVocabularyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("failed to decode update vocabulary request", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	vocabulary := transcriber.NormalizeVocabulary(req.Vocabulary)
+	if err := user.ValidateVocabulary(vocabulary); err != nil {
+		logger.Warn("invalid vocabulary", zap.String("user_id", providerID), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userStore.UpdateVocabulary(r.Context(), providerID, vocabulary); err != nil {
+		logger.Error("failed to update vocabulary", zap.String("user_id", providerID), zap.Error(err))
+		http.Error(w, "failed to update vocabulary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(UpdateVocabularyResponse{Vocabulary: vocabulary}); err != nil {
+		logger.Error("failed to encode update vocabulary response", zap.Error(err))
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("vocabulary updated", zap.String("user_id", providerID), zap.Int("term_count", len(vocabulary)))
+}
+// UpdateAudioRetention sets how many days the provider's visit recordings are retained before being purged.
+func (h *userHandler) UpdateAudioRetention(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+	providerID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("unauthorized access attempt to update audio retention")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateAudioRetentionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("failed to decode update audio retention request", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := user.ValidateAudioRetention(req.Days); err != nil {
+		logger.Warn("invalid audio retention", zap.String("user_id", providerID), zap.Intp("days", req.Days))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userStore.UpdateAudioRetention(r.Context(), providerID, req.Days); err != nil {
+		logger.Error("failed to update audio retention", zap.String("user_id", providerID), zap.Error(err))
+		http.Error(w, "failed to update audio retention", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("audio retention updated", zap.String("user_id", providerID), zap.Intp("days", req.Days))
+	w.WriteHeader(http.StatusOK)
+}
+
+// UpdateRetentionPolicy sets how many days the provider's recordings, transcripts, notes and token usage are retained before being purged.
+func (h *userHandler) UpdateRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	logger := contextLogger.FromCtx(r.Context())
+	providerID, ok := middleware.GetProviderIDFromContext(r.Context())
+	if !ok {
+		logger.Warn("unauthorized access attempt to update retention policy")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateRetentionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("failed to decode update retention policy request", zap.Error(err))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := req.Policy.Validate(); err != nil {
+		logger.Warn("invalid retention policy", zap.String("user_id", providerID), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userStore.UpdateRetentionPolicy(r.Context(), providerID, req.Policy); err != nil {
+		logger.Error("failed to update retention policy", zap.String("user_id", providerID), zap.Error(err))
+		http.Error(w, "failed to update retention policy", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("retention policy updated",
+		zap.String("user_id", providerID),
+		zap.Intp("audio_days", req.Policy.AudioDays),
+		zap.Int("transcript_days", req.Policy.TranscriptDays),
+		zap.Int("note_days", req.Policy.NoteDays),
+		zap.Int("token_usage_days", req.Policy.TokenUsageDays),
+	)
+	w.WriteHeader(http.StatusOK)
+}
 
 func (h *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	logger := contextLogger.FromCtx(r.Context())
@@ -444,4 +596,22 @@ func (h *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	logger.Info("user logout successful")
-}
\ No newline at end of file
+}
+
+// auditListing records that the user was sent their reports. Login runs before the user is in the request
+// context, so the actor is set explicitly. It writes the error response itself and returns false when the
+// access could not be recorded.
+func (h *userHandler) auditListing(w http.ResponseWriter, r *http.Request, userID string, listed []reports.Report) bool {
+	reportIDs := make([]string, len(listed))
+	for i, report := range listed {
+		reportIDs[i] = report.ID.Hex()
+	}
+	entry := middleware.NewAuditEntry(r.Context(), auditLog.ActionListReports, reportIDs...)
+	entry.Actor = userID
+	if _, err := h.auditLog.Append(r.Context(), entry); err != nil {
+		contextLogger.FromCtx(r.Context()).Error("failed to record report listing", zap.String("user_id", userID), zap.Error(err))
+		http.Error(w, "failed to record report access", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	auditLog "Medscribe/auditLogStore"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewAuditEntry(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/report/get", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+
+	var entry auditLog.Entry
+	MetadataMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), CtxKeyUserID, "provider-a")
+		entry = NewAuditEntry(ctx, auditLog.ActionViewReport, "report-1")
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "provider-a", entry.Actor)
+	assert.Equal(t, auditLog.ActionViewReport, entry.Action)
+	assert.Equal(t, []string{"report-1"}, entry.ReportIDs)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	assert.Equal(t, "Mozilla/5.0", entry.UserAgent)
+	assert.NotEmpty(t, entry.CorrelationID)
+}
+
+func TestRecordAccess(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/report/get", nil)
+	req = req.WithContext(context.WithValue(req.Context(), CtxKeyUserID, "provider-a"))
+
+	t.Run("recorded", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		store.On("Append", mock.Anything, mock.MatchedBy(func(entry auditLog.Entry) bool {
+			return entry.Actor == "provider-a" && entry.Action == auditLog.ActionViewReport
+		})).Return(auditLog.Entry{}, nil)
+		rr := httptest.NewRecorder()
+
+		assert.True(t, RecordAccess(rr, req, store, auditLog.ActionViewReport, "report-1"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("not recorded", func(t *testing.T) {
+		store := new(auditLog.MockAuditLogStore)
+		store.On("Append", mock.Anything, mock.Anything).Return(auditLog.Entry{}, errors.New("unavailable"))
+		rr := httptest.NewRecorder()
+
+		assert.False(t, RecordAccess(rr, req, store, auditLog.ActionViewReport, "report-1"))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	admin := NewAdminMiddleware([]string{"admin-1", "admin-2"})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name     string
+		userID   string
+		wantCode int
+	}{
+		{name: "admin", userID: "admin-2", wantCode: http.StatusNoContent},
+		{name: "provider", userID: "provider-a", wantCode: http.StatusForbidden},
+		{name: "unauthenticated", wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/auditLog", nil)
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), CtxKeyUserID, tt.userID))
+			}
+			rr := httptest.NewRecorder()
+			admin(next).ServeHTTP(rr, req)
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
@@ -91,7 +91,6 @@ func (am *AuthMiddleware) Middleware(next http.Handler) http.Handler {
 }
 
 func (am *AuthMiddleware) verifyToken(tokenString string) (*Claims, error) {
-	am.logger.Debug("JWT Secret in middleware", zap.String("secret", am.jwtSecret))
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -120,7 +119,6 @@ func (am *AuthMiddleware) GenerateRefreshToken(userID string) (string, error) {
 }
 
 func (am *AuthMiddleware) AttachInitialTokens(w http.ResponseWriter, userID string) error {
-	am.logger.Debug("JWT Secret in middleware", zap.String("secret", am.jwtSecret))
 	accessToken, err := am.GenerateAccessToken(userID)
 	if err != nil {
 		am.logger.Error("Error generating initial access token", zap.Error(err))
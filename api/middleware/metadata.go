@@ -86,3 +86,22 @@ func getIPAddress(r *http.Request) string {
 	}
 	return ip
 }
+
+// RequestMetadata is the metadata MetadataMiddleware stored in a request's context.
+type RequestMetadata struct {
+	CorrelationID string
+	IPAddress     string
+	UserAgent     string
+}
+
+// GetRequestMetadata returns the request metadata in ctx. Fields are empty when MetadataMiddleware did not run.
+func GetRequestMetadata(ctx context.Context) RequestMetadata {
+	correlationID, _ := ctx.Value(ctxKeyCorrelationID).(string)
+	ipAddress, _ := ctx.Value(ctxKeyIPAddress).(string)
+	userAgent, _ := ctx.Value(ctxKeyUserAgent).(string)
+	return RequestMetadata{
+		CorrelationID: correlationID,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"Medscribe/api/handlers/adminHandler"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func AdminRoutes(handler adminHandler.AdminHandler) *chi.Mux {
+	r := chi.NewRouter()
+
+	r.Get("/auditLog", handler.QueryAuditLog)
+
+	r.Get("/auditLog/export", handler.ExportAuditLog)
+
+	r.Get("/auditLog/verify", handler.VerifyAuditLog)
+
+	r.Patch("/supervisor", handler.SetSupervisor)
+
+	r.Patch("/legalHold", handler.SetLegalHold)
+
+	return r
+}
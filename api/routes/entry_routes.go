@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"Medscribe/api/handlers/adminHandler"
 	"Medscribe/api/handlers/reportsHandler"
+	"Medscribe/api/handlers/supervisorHandler"
+	"Medscribe/api/handlers/uploadHandler"
 	userhandler "Medscribe/api/handlers/userHandler"
 	"net/http"
 	"os"
@@ -15,8 +18,13 @@ import (
 type APIConfig struct {
 	UserHandler        userhandler.UserHandler
 	ReportsHandler     reportsHandler.ReportsHandler
+	UploadHandler      uploadHandler.UploadHandler
+	AdminHandler       adminHandler.AdminHandler
+	SupervisorHandler  supervisorHandler.SupervisorHandler
 	AuthMiddleware     func(http.Handler) http.Handler
 	MetadataMiddleware func(http.Handler) http.Handler
+	// AdminMiddleware runs after AuthMiddleware and only lets administrators through.
+	AdminMiddleware func(http.Handler) http.Handler
 }
 
 func mountRoutes(config APIConfig) *chi.Mux {
@@ -39,6 +47,21 @@ func mountRoutes(config APIConfig) *chi.Mux {
 		r.Mount("/", ReportRoutes(config.ReportsHandler))
 	})
 
+	r.Route("/upload", func(r chi.Router) {
+		r.Use(config.AuthMiddleware)
+		r.Mount("/", UploadRoutes(config.UploadHandler))
+	})
+
+	r.Route("/supervisor", func(r chi.Router) {
+		r.Use(config.AuthMiddleware)
+		r.Mount("/", SupervisorRoutes(config.SupervisorHandler))
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(config.AuthMiddleware, config.AdminMiddleware)
+		r.Mount("/", AdminRoutes(config.AdminHandler))
+	})
+
 	// Static frontend fallback
 	r.Handle("/*", spaHandler{
 		staticPath: "./MedscribeUI/dist",
@@ -58,8 +81,9 @@ func getCORSHandler() func(http.Handler) http.Handler {
 			"https://www.medscribe.pro",
 			"https://dev.medscribe.pro",
 		},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{"GET", "HEAD", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", uploadHandler.UploadOffsetHeader},
+		ExposedHeaders:   []string{"Location", uploadHandler.UploadOffsetHeader, uploadHandler.UploadLengthHeader},
 		AllowCredentials: true,
 	}).Handler
 }
@@ -11,6 +11,12 @@ func ReportRoutes(handler reportsHandler.ReportsHandler) *chi.Mux {
 
 	r.Post("/generate", handler.GenerateReport)
 
+	r.Post("/generateFromUpload", handler.GenerateReportFromUpload)
+
+	r.Post("/generateFromTranscript", handler.GenerateReportFromTranscript)
+
+	r.Post("/appendRecording", handler.AppendRecording)
+
 	r.Patch("/regenerate", handler.RegenerateReport)
 
 	r.Patch("/changeName", handler.ChangeReportName)
@@ -25,9 +31,33 @@ func ReportRoutes(handler reportsHandler.ReportsHandler) *chi.Mux {
 
 	r.Post("/getTranscript", handler.GetTranscript)
 
+	r.Get("/transcript/{reportID}/export", handler.ExportTranscript)
+
 	r.Patch("/markRead", handler.MarkRead)
 
 	r.Patch("/markUnread", handler.MarkUnread)
 
+	r.Patch("/relabelSpeaker", handler.RelabelSpeaker)
+
+	r.Patch("/updateTranscript", handler.UpdateTranscript)
+
+	r.Post("/getTranscriptRevisions", handler.GetTranscriptRevisions)
+
+	r.Post("/getSectionRevisions", handler.GetSectionRevisions)
+
+	r.Post("/diffSectionRevisions", handler.DiffSectionRevisions)
+
+	r.Patch("/restoreSectionRevision", handler.RestoreSectionRevision)
+
+	r.Get("/audio/{reportID}", handler.GetAudio)
+
+	r.Post("/afterVisitSummary", handler.GenerateAfterVisitSummary)
+
+	r.Patch("/sign", handler.SignReport)
+
+	r.Post("/addendum", handler.AddAddendum)
+
+	r.Get("/note/{reportID}/export", handler.ExportNote)
+
 	return r
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"Medscribe/api/handlers/supervisorHandler"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SupervisorRoutes(handler supervisorHandler.SupervisorHandler) *chi.Mux {
+	r := chi.NewRouter()
+
+	r.Get("/inbox", handler.Inbox)
+
+	r.Post("/comment", handler.Comment)
+
+	r.Patch("/return", handler.ReturnForChanges)
+
+	r.Patch("/cosign", handler.CoSign)
+
+	return r
+}
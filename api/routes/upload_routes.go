@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"Medscribe/api/handlers/uploadHandler"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func UploadRoutes(handler uploadHandler.UploadHandler) *chi.Mux {
+	r := chi.NewRouter()
+
+	r.Post("/create", handler.CreateUpload)
+
+	r.Head("/{uploadID}", handler.GetUploadOffset)
+
+	r.Patch("/{uploadID}", handler.AppendChunk)
+
+	r.Post("/{uploadID}/finalize", handler.FinalizeUpload)
+
+	return r
+}
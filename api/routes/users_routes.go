@@ -18,6 +18,12 @@ func UserRoutes(handler userhandler.UserHandler, authMiddleware func(http.Handle
 
 	r.With(authMiddleware).Patch("/editProfileSettings", handler.UpdateProfileSettings)
 
+	r.With(authMiddleware).Patch("/updateVocabulary", handler.UpdateVocabulary)
+
+	r.With(authMiddleware).Patch("/updateAudioRetention", handler.UpdateAudioRetention)
+
+	r.With(authMiddleware).Patch("/updateRetentionPolicy", handler.UpdateRetentionPolicy)
+
 	r.With(authMiddleware).Post("/logout", handler.Logout)
 
 	return r
@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSampleRate = 16000
+
+// synthesize builds 16 bit mono PCM from alternating stretches of tone (amplitude > 0) and silence.
+func synthesize(parts ...[2]float64) PCM {
+	pcm := PCM{Channels: 1, SampleRate: testSampleRate, BitsPerSample: 16}
+	for _, part := range parts {
+		seconds, amplitude := part[0], part[1]
+		for i := 0; i < int(seconds*testSampleRate); i++ {
+			// Amplitudes above 1 clip at full scale like an overdriven input.
+			level := max(-1, min(1, amplitude*math.Sin(2*math.Pi*220*float64(i)/testSampleRate)))
+			sample := int16(level * math.MaxInt16)
+			pcm.Data = binary.LittleEndian.AppendUint16(pcm.Data, uint16(sample))
+		}
+	}
+	return pcm
+}
+
+func TestParseWAV(t *testing.T) {
+	t.Run("should round trip encoded PCM", func(t *testing.T) {
+		pcm := synthesize([2]float64{0.5, 0.5})
+		parsed, err := ParseWAV(pcm.EncodeWAV())
+		require.NoError(t, err)
+		assert.Equal(t, pcm, parsed)
+		assert.InDelta(t, 0.5, parsed.Duration(), 1e-9)
+	})
+
+	t.Run("should reject compressed audio", func(t *testing.T) {
+		_, err := ParseWAV([]byte("\x1aE\xdf\xa3webm"))
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+
+	t.Run("should reject non PCM WAV", func(t *testing.T) {
+		data := synthesize([2]float64{0.1, 0.5}).EncodeWAV()
+		binary.LittleEndian.PutUint16(data[20:22], 3)
+		_, err := ParseWAV(data)
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestTrimSilence(t *testing.T) {
+	opts := DefaultTrimOptions()
+
+	t.Run("should trim long silences and map times back", func(t *testing.T) {
+		audio := synthesize([2]float64{3, 0}, [2]float64{2, 0.5}, [2]float64{10, 0}, [2]float64{2, 0.5}, [2]float64{1, 0.001}, [2]float64{1, 0.5})
+		result, err := TrimSilence(audio.EncodeWAV(), opts)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 19, result.OriginalDuration, 0.01)
+		// 2s + 2s + 1s of tone, the 1s pause, and padding around the long silences.
+		assert.InDelta(t, 2+0.3+0.3+2+1+1+0.3, result.TrimmedDuration, 0.1)
+
+		trimmed, err := ParseWAV(result.Audio)
+		require.NoError(t, err)
+		assert.InDelta(t, result.TrimmedDuration, trimmed.Duration(), 1e-9)
+
+		// 1s into the second tone is 16s into the original recording.
+		secondTone := 0.3 + 2 + 0.3 + 0.3 + 1
+		assert.InDelta(t, 3+2+10+1, result.TimeMap.ToOriginal(secondTone), 0.05)
+		// The first tone starts after the leading silence.
+		assert.InDelta(t, 3, result.TimeMap.ToOriginal(0.3), 0.05)
+	})
+
+	t.Run("should leave audio without long silences untouched", func(t *testing.T) {
+		data := synthesize([2]float64{2, 0.5}, [2]float64{1, 0}, [2]float64{2, 0.5}).EncodeWAV()
+		result, err := TrimSilence(data, opts)
+		require.NoError(t, err)
+		assert.Equal(t, data, result.Audio)
+		assert.Empty(t, result.TimeMap.Segments)
+		assert.Equal(t, 7.5, result.TimeMap.ToOriginal(7.5))
+	})
+
+	t.Run("should leave fully silent audio untouched", func(t *testing.T) {
+		data := synthesize([2]float64{5, 0}).EncodeWAV()
+		result, err := TrimSilence(data, opts)
+		require.NoError(t, err)
+		assert.Equal(t, data, result.Audio)
+	})
+}
+
+// stereo interleaves two mono recordings of the same length.
+func stereo(left, right PCM) PCM {
+	pcm := PCM{Channels: 2, SampleRate: left.SampleRate, BitsPerSample: 16}
+	for i := 0; i < len(left.Data); i += 2 {
+		pcm.Data = append(pcm.Data, left.Data[i:i+2]...)
+		pcm.Data = append(pcm.Data, right.Data[i:i+2]...)
+	}
+	return pcm
+}
+
+func warningCodes(q Quality) []string {
+	codes := []string{}
+	for _, w := range q.Warnings {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestAnalyzeQuality(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pcm      PCM
+		warnings []string
+	}{
+		{name: "clean speech", pcm: synthesize([2]float64{4, 0.3}, [2]float64{1, 0}), warnings: []string{}},
+		{name: "muted microphone", pcm: synthesize([2]float64{5, 0.0005}), warnings: []string{WarningMuted, WarningMostlySilent}},
+		{name: "clipped", pcm: synthesize([2]float64{5, 1.5}), warnings: []string{WarningClipping}},
+		{name: "mostly silent", pcm: synthesize([2]float64{1, 0.3}, [2]float64{9, 0}), warnings: []string{WarningMostlySilent}},
+		{name: "one sided telehealth capture", pcm: stereo(synthesize([2]float64{5, 0.3}), synthesize([2]float64{5, 0.001})), warnings: []string{WarningChannelImbalance}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quality := AnalyzeQuality(tc.pcm)
+			assert.Equal(t, tc.warnings, warningCodes(quality))
+			assert.Equal(t, len(tc.warnings) == 0, quality.Reliable())
+			assert.Len(t, quality.ChannelLoudnessDB, tc.pcm.Channels)
+		})
+	}
+
+	t.Run("should measure loudness and silence", func(t *testing.T) {
+		quality := AnalyzeQuality(synthesize([2]float64{5, 0.5}, [2]float64{5, 0}))
+		// A full scale sine is -3 dBFS, so half scale over half the recording is about -12 dBFS.
+		assert.InDelta(t, -12, quality.LoudnessDB, 0.5)
+		assert.InDelta(t, 0.5, quality.SilenceRatio, 0.01)
+		assert.Zero(t, quality.ChannelImbalanceDB)
+	})
+}
@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quality warning codes.
+const (
+	WarningMuted            = "muted"
+	WarningQuiet            = "quiet"
+	WarningClipping         = "clipping"
+	WarningMostlySilent     = "mostly_silent"
+	WarningChannelImbalance = "channel_imbalance"
+)
+
+// Thresholds above (or below) which a recording is likely to give an unreliable transcript.
+const (
+	mutedLoudnessDB         = -55
+	quietLoudnessDB         = -40
+	clippingSampleLevel     = 0.999
+	maxClippingRatio        = 0.01
+	silentFrameDB           = -50
+	maxSilenceRatio         = 0.8
+	maxChannelImbalanceDB   = 20
+	qualityFrameDurationSec = 0.03
+)
+
+// QualityWarning explains why a recording may transcribe poorly.
+type QualityWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Quality summarizes the technical quality of a recording.
+type Quality struct {
+	// LoudnessDB is the RMS level of the whole recording in dBFS.
+	LoudnessDB float64 `json:"loudnessDB"`
+	// ClippingRatio is the share of samples at full scale.
+	ClippingRatio float64 `json:"clippingRatio"`
+	// SilenceRatio is the share of the recording quieter than silentFrameDB.
+	SilenceRatio float64 `json:"silenceRatio"`
+	// ChannelLoudnessDB is the RMS level of each channel in dBFS.
+	ChannelLoudnessDB []float64 `json:"channelLoudnessDB"`
+	// ChannelImbalanceDB is the level difference between the loudest and quietest channel, zero for mono.
+	ChannelImbalanceDB float64          `json:"channelImbalanceDB"`
+	Warnings           []QualityWarning `json:"warnings"`
+}
+
+// Reliable reports whether no warnings were raised.
+func (q Quality) Reliable() bool {
+	return len(q.Warnings) == 0
+}
+
+// AnalyzeWAV computes the quality summary of PCM WAV audio. Other formats fail with ErrUnsupportedFormat.
+func AnalyzeWAV(data []byte) (Quality, error) {
+	pcm, err := ParseWAV(data)
+	if err != nil {
+		return Quality{}, err
+	}
+	return AnalyzeQuality(pcm), nil
+}
+
+// AnalyzeQuality computes loudness, clipping, silence and channel balance and raises warnings
+// for recordings that are likely to give an unreliable transcript.
+func AnalyzeQuality(pcm PCM) Quality {
+	frames := pcm.Frames()
+	quality := Quality{ChannelLoudnessDB: make([]float64, pcm.Channels), Warnings: []QualityWarning{}}
+	if frames == 0 {
+		quality.LoudnessDB = toDB(0)
+		for c := range quality.ChannelLoudnessDB {
+			quality.ChannelLoudnessDB[c] = toDB(0)
+		}
+		quality.SilenceRatio = 1
+		quality.Warnings = append(quality.Warnings, QualityWarning{Code: WarningMuted, Message: "The recording contains no audio."})
+		return quality
+	}
+
+	channelSums := make([]float64, pcm.Channels)
+	var total float64
+	clipped := 0
+	for f := 0; f < frames; f++ {
+		for c := 0; c < pcm.Channels; c++ {
+			s := pcm.Sample(f, c)
+			channelSums[c] += s * s
+			total += s * s
+			if math.Abs(s) >= clippingSampleLevel {
+				clipped++
+			}
+		}
+	}
+
+	quality.LoudnessDB = toDB(math.Sqrt(total / float64(frames*pcm.Channels)))
+	quality.ClippingRatio = float64(clipped) / float64(frames*pcm.Channels)
+	for c, sum := range channelSums {
+		quality.ChannelLoudnessDB[c] = toDB(math.Sqrt(sum / float64(frames)))
+	}
+	if pcm.Channels > 1 {
+		loudest, quietest := quality.ChannelLoudnessDB[0], quality.ChannelLoudnessDB[0]
+		for _, db := range quality.ChannelLoudnessDB[1:] {
+			loudest, quietest = max(loudest, db), min(quietest, db)
+		}
+		quality.ChannelImbalanceDB = loudest - quietest
+	}
+
+	loudness := frameLoudness(pcm, max(int(qualityFrameDurationSec*float64(pcm.SampleRate)), 1))
+	silent := 0
+	for _, db := range loudness {
+		if db < silentFrameDB {
+			silent++
+		}
+	}
+	quality.SilenceRatio = float64(silent) / float64(len(loudness))
+
+	quality.Warnings = qualityWarnings(quality)
+	return quality
+}
+
+func qualityWarnings(q Quality) []QualityWarning {
+	warnings := []QualityWarning{}
+	switch {
+	case q.LoudnessDB < mutedLoudnessDB:
+		warnings = append(warnings, QualityWarning{Code: WarningMuted, Message: fmt.Sprintf("The recording is nearly inaudible (%.0f dBFS); the microphone may be muted.", q.LoudnessDB)})
+	case q.LoudnessDB < quietLoudnessDB:
+		warnings = append(warnings, QualityWarning{Code: WarningQuiet, Message: fmt.Sprintf("The recording is very quiet (%.0f dBFS); speech may be missed.", q.LoudnessDB)})
+	}
+	if q.ClippingRatio > maxClippingRatio {
+		warnings = append(warnings, QualityWarning{Code: WarningClipping, Message: fmt.Sprintf("%.1f%% of the recording is clipped; the input gain is too high.", q.ClippingRatio*100)})
+	}
+	if q.SilenceRatio > maxSilenceRatio {
+		warnings = append(warnings, QualityWarning{Code: WarningMostlySilent, Message: fmt.Sprintf("%.0f%% of the recording is silent.", q.SilenceRatio*100)})
+	}
+	if q.ChannelImbalanceDB > maxChannelImbalanceDB {
+		warnings = append(warnings, QualityWarning{Code: WarningChannelImbalance, Message: fmt.Sprintf("One channel is %.0f dB quieter than the other; only one side of the conversation may have been captured.", q.ChannelImbalanceDB)})
+	}
+	return warnings
+}
+
+func toDB(rms float64) float64 {
+	return 20 * math.Log10(rms+1e-10)
+}
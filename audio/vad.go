@@ -0,0 +1,191 @@
+package audio
+
+import (
+	"math"
+	"sort"
+)
+
+// TrimOptions tunes silence detection. The zero value is not usable; start from DefaultTrimOptions.
+type TrimOptions struct {
+	// FrameDuration is the analysis window in seconds.
+	FrameDuration float64
+	// MinSilence is the shortest silence, in seconds, that is trimmed. Shorter pauses are part of speech.
+	MinSilence float64
+	// Padding is the silence, in seconds, kept on each side of speech so words are not clipped.
+	Padding float64
+	// MarginDB is how far above the estimated noise floor a frame must be to count as speech.
+	MarginDB float64
+	// MinThresholdDB and MaxThresholdDB bound the speech threshold, in dBFS, so a near silent
+	// or a very noisy recording cannot push it to an extreme.
+	MinThresholdDB float64
+	MaxThresholdDB float64
+}
+
+func DefaultTrimOptions() TrimOptions {
+	return TrimOptions{
+		FrameDuration:  0.03,
+		MinSilence:     2,
+		Padding:        0.3,
+		MarginDB:       10,
+		MinThresholdDB: -60,
+		MaxThresholdDB: -35,
+	}
+}
+
+// noiseFloorPercentile picks the frame loudness treated as background noise.
+const noiseFloorPercentile = 0.1
+
+// Segment maps a stretch of kept audio in the trimmed recording back to the original one.
+type Segment struct {
+	TrimmedStart  float64 `json:"trimmedStart"`
+	OriginalStart float64 `json:"originalStart"`
+	Duration      float64 `json:"duration"`
+}
+
+// TimeMap translates times in trimmed audio back to the original recording timeline.
+// An empty map is the identity.
+type TimeMap struct {
+	Segments []Segment `json:"segments"`
+}
+
+// ToOriginal converts a time in the trimmed audio to the matching time in the original recording.
+func (m TimeMap) ToOriginal(t float64) float64 {
+	if len(m.Segments) == 0 {
+		return t
+	}
+	// The last segment starting at or before t contains it; times past the end extend the last segment.
+	i := sort.Search(len(m.Segments), func(i int) bool { return m.Segments[i].TrimmedStart > t }) - 1
+	if i < 0 {
+		i = 0
+	}
+	segment := m.Segments[i]
+	return segment.OriginalStart + max(t-segment.TrimmedStart, 0)
+}
+
+// TrimResult is the outcome of silence trimming.
+type TrimResult struct {
+	Audio            []byte
+	TimeMap          TimeMap
+	OriginalDuration float64
+	TrimmedDuration  float64
+}
+
+// TrimSilence removes long silent stretches from PCM WAV audio using an energy based voice activity
+// detector with an adaptive noise floor. Audio that is not PCM WAV fails with ErrUnsupportedFormat.
+// When nothing is worth trimming the original bytes are returned with an empty time map.
+func TrimSilence(data []byte, opts TrimOptions) (TrimResult, error) {
+	pcm, err := ParseWAV(data)
+	if err != nil {
+		return TrimResult{}, err
+	}
+
+	duration := pcm.Duration()
+	unchanged := TrimResult{Audio: data, OriginalDuration: duration, TrimmedDuration: duration}
+
+	frameLength := max(int(opts.FrameDuration*float64(pcm.SampleRate)), 1)
+	loudness := frameLoudness(pcm, frameLength)
+	if len(loudness) == 0 {
+		return unchanged, nil
+	}
+	threshold := speechThreshold(loudness, opts)
+
+	keep := keptFrames(loudness, threshold, opts)
+	if keep == nil {
+		return unchanged, nil
+	}
+
+	frameSize := pcm.frameSize()
+	trimmed := PCM{Channels: pcm.Channels, SampleRate: pcm.SampleRate, BitsPerSample: pcm.BitsPerSample}
+	var timeMap TimeMap
+	for _, r := range keep {
+		start := r[0] * frameLength
+		end := min(r[1]*frameLength, pcm.Frames())
+		timeMap.Segments = append(timeMap.Segments, Segment{
+			TrimmedStart:  float64(len(trimmed.Data)/frameSize) / float64(pcm.SampleRate),
+			OriginalStart: float64(start) / float64(pcm.SampleRate),
+			Duration:      float64(end-start) / float64(pcm.SampleRate),
+		})
+		trimmed.Data = append(trimmed.Data, pcm.Data[start*frameSize:end*frameSize]...)
+	}
+
+	return TrimResult{
+		Audio:            trimmed.EncodeWAV(),
+		TimeMap:          timeMap,
+		OriginalDuration: duration,
+		TrimmedDuration:  trimmed.Duration(),
+	}, nil
+}
+
+// frameLoudness returns the RMS level in dBFS of each analysis frame across all channels.
+func frameLoudness(pcm PCM, frameLength int) []float64 {
+	frames := pcm.Frames()
+	loudness := make([]float64, 0, (frames+frameLength-1)/frameLength)
+	for start := 0; start < frames; start += frameLength {
+		end := min(start+frameLength, frames)
+		var sum float64
+		for f := start; f < end; f++ {
+			for c := 0; c < pcm.Channels; c++ {
+				s := pcm.Sample(f, c)
+				sum += s * s
+			}
+		}
+		rms := math.Sqrt(sum / float64((end-start)*pcm.Channels))
+		loudness = append(loudness, 20*math.Log10(rms+1e-10))
+	}
+	return loudness
+}
+
+func speechThreshold(loudness []float64, opts TrimOptions) float64 {
+	sorted := append([]float64(nil), loudness...)
+	sort.Float64s(sorted)
+	noiseFloor := sorted[int(float64(len(sorted)-1)*noiseFloorPercentile)]
+	return min(max(noiseFloor+opts.MarginDB, opts.MinThresholdDB), opts.MaxThresholdDB)
+}
+
+// keptFrames returns the [start, end) frame ranges to keep, or nil when no silence is long enough to trim.
+func keptFrames(loudness []float64, threshold float64, opts TrimOptions) [][2]int {
+	minSilence := int(math.Ceil(opts.MinSilence / opts.FrameDuration))
+	padding := int(math.Round(opts.Padding / opts.FrameDuration))
+
+	var keep [][2]int
+	trimmedAny := false
+	segmentStart := 0
+	for i := 0; i < len(loudness); {
+		if loudness[i] >= threshold {
+			i++
+			continue
+		}
+		silenceStart := i
+		for i < len(loudness) && loudness[i] < threshold {
+			i++
+		}
+		if i-silenceStart < minSilence {
+			continue
+		}
+
+		// Keep padding on the speech side of the silence; leading and trailing silence needs none on the outside.
+		cutStart, cutEnd := silenceStart+padding, i-padding
+		if silenceStart == 0 {
+			cutStart = 0
+		}
+		if i == len(loudness) {
+			cutEnd = len(loudness)
+		}
+		if cutEnd <= cutStart {
+			continue
+		}
+		if cutStart > segmentStart {
+			keep = append(keep, [2]int{segmentStart, cutStart})
+		}
+		segmentStart = cutEnd
+		trimmedAny = true
+	}
+	if !trimmedAny || (len(keep) == 0 && segmentStart >= len(loudness)) {
+		// Either there is nothing to trim or the whole recording is silent; leave it to the transcriber.
+		return nil
+	}
+	if segmentStart < len(loudness) {
+		keep = append(keep, [2]int{segmentStart, len(loudness)})
+	}
+	return keep
+}
@@ -0,0 +1,140 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+)
+
+// ErrUnsupportedFormat is returned for audio that is not uncompressed integer PCM in a WAV container.
+var ErrUnsupportedFormat = errors.New("audio is not PCM WAV")
+
+// PCM is decoded WAV audio. Data holds the interleaved little endian samples as stored in the file.
+type PCM struct {
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+	Data          []byte
+}
+
+// ParseWAV reads the format and sample data of a PCM WAV file. Compressed recordings
+// (webm, m4a, mp3) are rejected with ErrUnsupportedFormat.
+func ParseWAV(data []byte) (PCM, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return PCM{}, ErrUnsupportedFormat
+	}
+
+	var pcm PCM
+	var haveFormat bool
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+		// Recorders that stream to disk often leave the chunk size unset, so clamp it to what is there.
+		if size < 0 || size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return PCM{}, fmt.Errorf("malformed WAV format chunk: %w", ErrUnsupportedFormat)
+			}
+			format := binary.LittleEndian.Uint16(body[0:2])
+			if format == wavFormatExtensible && len(body) >= 26 {
+				format = binary.LittleEndian.Uint16(body[24:26])
+			}
+			if format != wavFormatPCM {
+				return PCM{}, fmt.Errorf("WAV format %d: %w", format, ErrUnsupportedFormat)
+			}
+			pcm.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			pcm.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			pcm.BitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return PCM{}, fmt.Errorf("WAV data chunk before format chunk: %w", ErrUnsupportedFormat)
+			}
+			pcm.Data = body
+			if err := pcm.validate(); err != nil {
+				return PCM{}, err
+			}
+			pcm.Data = body[:len(body)-len(body)%pcm.frameSize()]
+			return pcm, nil
+		}
+		offset += 8 + size + size%2
+	}
+	return PCM{}, fmt.Errorf("WAV file has no data chunk: %w", ErrUnsupportedFormat)
+}
+
+func (p PCM) validate() error {
+	if p.Channels < 1 || p.SampleRate < 1 {
+		return fmt.Errorf("WAV has %d channels at %d Hz: %w", p.Channels, p.SampleRate, ErrUnsupportedFormat)
+	}
+	switch p.BitsPerSample {
+	case 8, 16, 24, 32:
+		return nil
+	}
+	return fmt.Errorf("WAV has %d bits per sample: %w", p.BitsPerSample, ErrUnsupportedFormat)
+}
+
+// frameSize is the number of bytes holding one sample for every channel.
+func (p PCM) frameSize() int {
+	return p.Channels * p.BitsPerSample / 8
+}
+
+// Frames is the number of sample frames in the audio.
+func (p PCM) Frames() int {
+	return len(p.Data) / p.frameSize()
+}
+
+// Duration is the length of the audio in seconds.
+func (p PCM) Duration() float64 {
+	return float64(p.Frames()) / float64(p.SampleRate)
+}
+
+// Sample returns the sample of a channel in a frame, scaled to [-1, 1].
+func (p PCM) Sample(frame, channel int) float64 {
+	bytesPerSample := p.BitsPerSample / 8
+	i := frame*p.frameSize() + channel*bytesPerSample
+	switch p.BitsPerSample {
+	case 8:
+		// 8 bit WAV samples are unsigned.
+		return (float64(p.Data[i]) - 128) / 128
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(p.Data[i:]))) / math.MaxInt16
+	case 24:
+		v := int32(p.Data[i]) | int32(p.Data[i+1])<<8 | int32(int8(p.Data[i+2]))<<16
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(p.Data[i:]))) / math.MaxInt32
+	}
+}
+
+// EncodeWAV writes the audio as a canonical PCM WAV file.
+func (p PCM) EncodeWAV() []byte {
+	var b bytes.Buffer
+	b.Grow(44 + len(p.Data))
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+len(p.Data)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, uint16(wavFormatPCM))
+	binary.Write(&b, binary.LittleEndian, uint16(p.Channels))
+	binary.Write(&b, binary.LittleEndian, uint32(p.SampleRate))
+	binary.Write(&b, binary.LittleEndian, uint32(p.SampleRate*p.frameSize()))
+	binary.Write(&b, binary.LittleEndian, uint16(p.frameSize()))
+	binary.Write(&b, binary.LittleEndian, uint16(p.BitsPerSample))
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(p.Data)))
+	b.Write(p.Data)
+	return b.Bytes()
+}
@@ -0,0 +1,60 @@
+package audioStore
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockAudioStore struct {
+	mock.Mock
+}
+
+func (m *MockAudioStore) Save(ctx context.Context, reportID primitive.ObjectID, providerID, contentType string, audio io.Reader, retentionDays *int, legalHold bool) (AudioBlob, error) {
+	args := m.Called(ctx, reportID, providerID, contentType, audio, retentionDays, legalHold)
+	return args.Get(0).(AudioBlob), args.Error(1)
+}
+
+func (m *MockAudioStore) Get(ctx context.Context, blobID string) (AudioBlob, error) {
+	args := m.Called(ctx, blobID)
+	return args.Get(0).(AudioBlob), args.Error(1)
+}
+
+func (m *MockAudioStore) Open(ctx context.Context, blobID string) (io.ReadCloser, error) {
+	args := m.Called(ctx, blobID)
+	if rc := args.Get(0); rc != nil {
+		return rc.(io.ReadCloser), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAudioStore) Delete(ctx context.Context, blobID string) error {
+	args := m.Called(ctx, blobID)
+	return args.Error(0)
+}
+
+func (m *MockAudioStore) DeleteByReportID(ctx context.Context, reportID primitive.ObjectID) error {
+	args := m.Called(ctx, reportID)
+	return args.Error(0)
+}
+
+func (m *MockAudioStore) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAudioStore) PurgeExpiredRecordings(ctx context.Context, now time.Time) ([]AudioBlob, error) {
+	args := m.Called(ctx, now)
+	if blobs := args.Get(0); blobs != nil {
+		return blobs.([]AudioBlob), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAudioStore) SetLegalHold(ctx context.Context, reportID primitive.ObjectID, hold bool) error {
+	args := m.Called(ctx, reportID, hold)
+	return args.Error(0)
+}
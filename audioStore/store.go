@@ -0,0 +1,236 @@
+package audioStore
+
+import (
+	"Medscribe/blobStore"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// purgeBatchSize caps how many expired recordings are removed per purge pass.
+const purgeBatchSize = 100
+
+var ErrAudioNotFound = errors.New("audio not found or no longer retained")
+
+// AudioBlob describes a retained visit recording. The audio itself lives encrypted in the blob store under ID.
+type AudioBlob struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReportID    primitive.ObjectID `bson:"reportId" json:"reportID"`
+	ProviderID  string             `bson:"providerId" json:"providerID"`
+	ContentType string             `bson:"contentType" json:"contentType"`
+	Size        int64              `bson:"size" json:"size"`
+	CreatedAt   primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	ExpiresAt   primitive.DateTime `bson:"expiresAt" json:"expiresAt"`
+	// LegalHold keeps the recording past ExpiresAt while its report is under a legal hold.
+	LegalHold bool `bson:"legalHold,omitempty" json:"legalHold,omitempty"`
+}
+
+type AudioStore interface {
+	Save(ctx context.Context, reportID primitive.ObjectID, providerID, contentType string, audio io.Reader, retentionDays *int, legalHold bool) (AudioBlob, error)
+	Get(ctx context.Context, blobID string) (AudioBlob, error)
+	Open(ctx context.Context, blobID string) (io.ReadCloser, error)
+	Delete(ctx context.Context, blobID string) error
+	DeleteByReportID(ctx context.Context, reportID primitive.ObjectID) error
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+	PurgeExpiredRecordings(ctx context.Context, now time.Time) ([]AudioBlob, error)
+	SetLegalHold(ctx context.Context, reportID primitive.ObjectID, hold bool) error
+}
+
+type audioStore struct {
+	collection       *mongo.Collection
+	blobs            blobStore.BlobStore
+	defaultRetention int
+}
+
+// NewAudioStore tracks retained recordings in collection and keeps their bytes in blobs.
+// defaultRetentionDays applies to providers who have not configured their own retention period.
+func NewAudioStore(collection *mongo.Collection, blobs blobStore.BlobStore, defaultRetentionDays int) AudioStore {
+	return &audioStore{collection: collection, blobs: blobs, defaultRetention: defaultRetentionDays}
+}
+
+// Save streams the audio into the blob store and records when it should be purged. legalHold carries over
+// the hold of the report, so a recording appended to a held report is kept like the ones before it.
+// A nil retentionDays uses the default period; zero expires the recording at once, so the next purge removes it.
+func (s *audioStore) Save(ctx context.Context, reportID primitive.ObjectID, providerID, contentType string, audio io.Reader, retentionDays *int, legalHold bool) (AudioBlob, error) {
+	if reportID.IsZero() {
+		return AudioBlob{}, errors.New("reportId cannot be empty")
+	}
+	if providerID == "" {
+		return AudioBlob{}, errors.New("providerId cannot be empty")
+	}
+	days := s.defaultRetention
+	if retentionDays != nil {
+		days = *retentionDays
+	}
+
+	now := time.Now()
+	blob := AudioBlob{
+		ID:          primitive.NewObjectID(),
+		ReportID:    reportID,
+		ProviderID:  providerID,
+		ContentType: contentType,
+		CreatedAt:   primitive.NewDateTimeFromTime(now),
+		ExpiresAt:   primitive.NewDateTimeFromTime(now.AddDate(0, 0, days)),
+		LegalHold:   legalHold,
+	}
+
+	counter := &countingReader{r: audio}
+	if err := s.blobs.Put(ctx, blob.ID.Hex(), counter); err != nil {
+		return AudioBlob{}, fmt.Errorf("failed to store audio: %v", err)
+	}
+	blob.Size = counter.n
+
+	if _, err := s.collection.InsertOne(ctx, blob); err != nil {
+		if deleteErr := s.blobs.Delete(ctx, blob.ID.Hex()); deleteErr != nil {
+			return AudioBlob{}, fmt.Errorf("failed to insert audio record: %v (cleanup failed: %v)", err, deleteErr)
+		}
+		return AudioBlob{}, fmt.Errorf("failed to insert audio record: %v", err)
+	}
+	return blob, nil
+}
+
+// Get returns the metadata of a recording that is still within its retention period.
+func (s *audioStore) Get(ctx context.Context, blobID string) (AudioBlob, error) {
+	objectID, err := primitive.ObjectIDFromHex(blobID)
+	if err != nil {
+		return AudioBlob{}, fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	var blob AudioBlob
+	err = s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&blob)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return AudioBlob{}, ErrAudioNotFound
+		}
+		return AudioBlob{}, fmt.Errorf("failed to retrieve audio record: %v", err)
+	}
+	if blob.ExpiresAt.Time().Before(time.Now()) {
+		return AudioBlob{}, ErrAudioNotFound
+	}
+	return blob, nil
+}
+
+// Open returns a decrypted stream of the recording. The caller must close it.
+func (s *audioStore) Open(ctx context.Context, blobID string) (io.ReadCloser, error) {
+	if _, err := s.Get(ctx, blobID); err != nil {
+		return nil, err
+	}
+	rc, err := s.blobs.Get(ctx, blobID)
+	if err != nil {
+		if errors.Is(err, blobStore.ErrBlobNotFound) {
+			return nil, ErrAudioNotFound
+		}
+		return nil, fmt.Errorf("failed to open audio: %v", err)
+	}
+	return rc, nil
+}
+
+// Delete removes a single recording, e.g. one saved for a report change that did not go through.
+// A recording under a legal hold is kept.
+func (s *audioStore) Delete(ctx context.Context, blobID string) error {
+	objectID, err := primitive.ObjectIDFromHex(blobID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+	_, _, err = s.deleteBatch(ctx, bson.M{"_id": objectID, "legalHold": bson.M{"$ne": true}}, 0)
+	return err
+}
+
+// DeleteByReportID removes every recording attached to a report.
+func (s *audioStore) DeleteByReportID(ctx context.Context, reportID primitive.ObjectID) error {
+	if reportID.IsZero() {
+		return errors.New("invalid reportId")
+	}
+	_, _, err := s.deleteBatch(ctx, bson.M{"reportId": reportID, "legalHold": bson.M{"$ne": true}}, 0)
+	return err
+}
+
+// SetLegalHold places or releases a legal hold on every recording attached to a report.
+func (s *audioStore) SetLegalHold(ctx context.Context, reportID primitive.ObjectID, hold bool) error {
+	if reportID.IsZero() {
+		return errors.New("invalid reportId")
+	}
+	update := bson.M{"$unset": bson.M{"legalHold": ""}}
+	if hold {
+		update = bson.M{"$set": bson.M{"legalHold": true}}
+	}
+	if _, err := s.collection.UpdateMany(ctx, bson.M{"reportId": reportID}, update); err != nil {
+		return fmt.Errorf("failed to set legal hold on audio: %v", err)
+	}
+	return nil
+}
+
+// PurgeExpired deletes recordings whose retention period ended before now and returns how many were removed.
+func (s *audioStore) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	purged, err := s.PurgeExpiredRecordings(ctx, now)
+	return len(purged), err
+}
+
+// PurgeExpiredRecordings deletes recordings whose retention period ended before now, skipping those under a
+// legal hold, and returns the ones this call removed. A recording another instance removed first is not returned.
+func (s *audioStore) PurgeExpiredRecordings(ctx context.Context, now time.Time) ([]AudioBlob, error) {
+	var purged []AudioBlob
+	for {
+		filter := bson.M{"expiresAt": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}, "legalHold": bson.M{"$ne": true}}
+		deleted, found, err := s.deleteBatch(ctx, filter, purgeBatchSize)
+		purged = append(purged, deleted...)
+		if err != nil {
+			return purged, err
+		}
+		if found < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// deleteBatch removes the record before its blob, and only while the recording is still not under a legal hold,
+// so a hold placed after the recording was listed keeps its audio. It returns the recordings whose record this
+// call deleted and how many matched the filter. Only the instance that deleted a record deletes its blob.
+func (s *audioStore) deleteBatch(ctx context.Context, filter bson.M, limit int64) ([]AudioBlob, int, error) {
+	opts := options.Find()
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find audio records: %v", err)
+	}
+	var blobs []AudioBlob
+	if err := cursor.All(ctx, &blobs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode audio records: %v", err)
+	}
+
+	var deleted []AudioBlob
+	for _, blob := range blobs {
+		result, err := s.collection.DeleteOne(ctx, bson.M{"_id": blob.ID, "legalHold": bson.M{"$ne": true}})
+		if err != nil {
+			return deleted, len(blobs), fmt.Errorf("failed to delete audio record %s: %v", blob.ID.Hex(), err)
+		}
+		if result.DeletedCount == 0 {
+			continue
+		}
+		deleted = append(deleted, blob)
+		if err := s.blobs.Delete(ctx, blob.ID.Hex()); err != nil {
+			return deleted, len(blobs), fmt.Errorf("failed to delete audio %s: %v", blob.ID.Hex(), err)
+		}
+	}
+	return deleted, len(blobs), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
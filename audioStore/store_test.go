@@ -0,0 +1,158 @@
+package audioStore
+
+import (
+	"Medscribe/blobStore"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func newTestBlobs(t *testing.T, keys ...string) blobStore.BlobStore {
+	blobs, err := blobStore.NewLocalBlobStore(t.TempDir())
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, blobs.Put(context.Background(), key, bytes.NewReader([]byte("audio"))))
+	}
+	return blobs
+}
+
+func recordsResponse(mt *mtest.T, ids ...primitive.ObjectID) bson.D {
+	ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+	documents := make([]bson.D, len(ids))
+	for i, id := range ids {
+		documents[i] = bson.D{{Key: "_id", Value: id}, {Key: "reportId", Value: primitive.NewObjectID()}, {Key: "providerId", Value: "provider"}}
+	}
+	return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, documents...)
+}
+
+func deletedResponse(n int) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n})
+}
+
+func TestPurgeExpiredRecordings(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	now := time.Now()
+
+	mt.Run("deletes the blob once its record is gone", func(mt *mtest.T) {
+		expired := primitive.NewObjectID()
+		blobs := newTestBlobs(t, expired.Hex())
+		store := &audioStore{collection: mt.Coll, blobs: blobs}
+		mt.AddMockResponses(recordsResponse(mt, expired), deletedResponse(1))
+
+		purged, err := store.PurgeExpiredRecordings(context.Background(), now)
+		require.NoError(t, err)
+		require.Len(t, purged, 1)
+		assert.Equal(t, expired, purged[0].ID)
+		_, err = blobs.Get(context.Background(), expired.Hex())
+		assert.True(t, errors.Is(err, blobStore.ErrBlobNotFound))
+	})
+
+	mt.Run("keeps the blob of a recording held since it was listed", func(mt *mtest.T) {
+		held := primitive.NewObjectID()
+		blobs := newTestBlobs(t, held.Hex())
+		store := &audioStore{collection: mt.Coll, blobs: blobs}
+		mt.AddMockResponses(recordsResponse(mt, held), deletedResponse(0))
+
+		purged, err := store.PurgeExpiredRecordings(context.Background(), now)
+		require.NoError(t, err)
+		assert.Empty(t, purged)
+		deleteFilter := mt.GetAllStartedEvents()[1].Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+		_, err = deleteFilter.LookupErr("legalHold")
+		assert.NoError(t, err, "the delete re-checks the legal hold")
+		rc, err := blobs.Get(context.Background(), held.Hex())
+		require.NoError(t, err)
+		rc.Close()
+	})
+
+	mt.Run("keeps the blob when its record cannot be deleted", func(mt *mtest.T) {
+		expired := primitive.NewObjectID()
+		blobs := newTestBlobs(t, expired.Hex())
+		store := &audioStore{collection: mt.Coll, blobs: blobs}
+		mt.AddMockResponses(recordsResponse(mt, expired), mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}))
+
+		_, err := store.PurgeExpiredRecordings(context.Background(), now)
+		assert.ErrorContains(t, err, "failed to delete audio record")
+		rc, err := blobs.Get(context.Background(), expired.Hex())
+		require.NoError(t, err)
+		rc.Close()
+	})
+}
+
+func TestSaveCarriesLegalHold(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("held report", func(mt *mtest.T) {
+		store := &audioStore{collection: mt.Coll, blobs: newTestBlobs(t), defaultRetention: 30}
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		blob, err := store.Save(context.Background(), primitive.NewObjectID(), "provider", "audio/wav", bytes.NewReader([]byte("audio")), nil, true)
+		require.NoError(t, err)
+		assert.True(t, blob.LegalHold)
+		assert.Equal(t, int64(5), blob.Size)
+
+		inserted := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.True(t, inserted.Lookup("legalHold").Boolean())
+	})
+}
+
+func TestSaveRetention(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	zero, week := 0, 7
+
+	for _, tc := range []struct {
+		name          string
+		retentionDays *int
+		want          int
+	}{
+		{name: "unset uses the default", retentionDays: nil, want: 30},
+		{name: "zero expires at once", retentionDays: &zero, want: 0},
+		{name: "provider period", retentionDays: &week, want: 7},
+	} {
+		mt.Run(tc.name, func(mt *mtest.T) {
+			store := &audioStore{collection: mt.Coll, blobs: newTestBlobs(t), defaultRetention: 30}
+			mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+			blob, err := store.Save(context.Background(), primitive.NewObjectID(), "provider", "audio/wav", bytes.NewReader([]byte("audio")), tc.retentionDays, false)
+			require.NoError(t, err)
+			assert.Equal(t, blob.CreatedAt.Time().AddDate(0, 0, tc.want), blob.ExpiresAt.Time())
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("removes the record and its blob", func(mt *mtest.T) {
+		unused := primitive.NewObjectID()
+		blobs := newTestBlobs(t, unused.Hex())
+		store := &audioStore{collection: mt.Coll, blobs: blobs}
+		mt.AddMockResponses(recordsResponse(mt, unused), deletedResponse(1))
+
+		require.NoError(t, store.Delete(context.Background(), unused.Hex()))
+		_, err := blobs.Get(context.Background(), unused.Hex())
+		assert.True(t, errors.Is(err, blobStore.ErrBlobNotFound))
+	})
+
+	mt.Run("keeps a held recording", func(mt *mtest.T) {
+		held := primitive.NewObjectID()
+		blobs := newTestBlobs(t, held.Hex())
+		store := &audioStore{collection: mt.Coll, blobs: blobs}
+		mt.AddMockResponses(recordsResponse(mt))
+
+		require.NoError(t, store.Delete(context.Background(), held.Hex()))
+		findFilter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		_, err := findFilter.LookupErr("legalHold")
+		assert.NoError(t, err, "held recordings are not selected")
+		rc, err := blobs.Get(context.Background(), held.Hex())
+		require.NoError(t, err)
+		rc.Close()
+	})
+}
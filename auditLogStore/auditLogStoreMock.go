@@ -0,0 +1,41 @@
+package auditLog
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditLogStore struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogStore) Append(ctx context.Context, entry Entry) (Entry, error) {
+	args := m.Called(ctx, entry)
+	return args.Get(0).(Entry), args.Error(1)
+}
+
+func (m *MockAuditLogStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	args := m.Called(ctx, filter)
+	if entries := args.Get(0); entries != nil {
+		return entries.([]Entry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAuditLogStore) Export(ctx context.Context, filter Filter, write func(Entry) error) error {
+	args := m.Called(ctx, filter, write)
+	if entries, ok := args.Get(0).([]Entry); ok {
+		for _, entry := range entries {
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAuditLogStore) Verify(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,89 @@
+package auditLog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ChainError reports the first entry at which the chain does not verify.
+type ChainError struct {
+	Sequence int64
+	Reason   string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log chain broken at sequence %d: %s", e.Sequence, e.Reason)
+}
+
+// hashedFields is what an entry's hash covers. Its field order is fixed, so the encoding is stable.
+type hashedFields struct {
+	Sequence      int64    `json:"sequence"`
+	Actor         string   `json:"actor"`
+	Action        string   `json:"action"`
+	ReportIDs     []string `json:"reportIds"`
+	IPAddress     string   `json:"ipAddress"`
+	UserAgent     string   `json:"userAgent"`
+	CorrelationID string   `json:"correlationId"`
+	Timestamp     int64    `json:"timestamp"`
+	PrevHash      string   `json:"prevHash"`
+}
+
+// hashEntry returns the hex SHA-256 of the entry's fields other than its ID and hash.
+func hashEntry(entry Entry) string {
+	reportIDs := entry.ReportIDs
+	if reportIDs == nil {
+		reportIDs = []string{}
+	}
+	encoded, _ := json.Marshal(hashedFields{
+		Sequence:      entry.Sequence,
+		Actor:         entry.Actor,
+		Action:        entry.Action,
+		ReportIDs:     reportIDs,
+		IPAddress:     entry.IPAddress,
+		UserAgent:     entry.UserAgent,
+		CorrelationID: entry.CorrelationID,
+		Timestamp:     int64(entry.Timestamp),
+		PrevHash:      entry.PrevHash,
+	})
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// chain returns the entry linked after last, or as the first entry when last is nil.
+func chain(last *Entry, entry Entry) Entry {
+	entry.Sequence = 1
+	entry.PrevHash = ""
+	if last != nil {
+		entry.Sequence = last.Sequence + 1
+		entry.PrevHash = last.Hash
+	}
+	entry.Hash = hashEntry(entry)
+	return entry
+}
+
+// chainVerifier checks entries one at a time, in sequence order from the first.
+type chainVerifier struct {
+	last     *Entry
+	verified int64
+}
+
+func (v *chainVerifier) next(entry Entry) error {
+	wantSequence, wantPrevHash := int64(1), ""
+	if v.last != nil {
+		wantSequence, wantPrevHash = v.last.Sequence+1, v.last.Hash
+	}
+
+	switch {
+	case entry.Sequence != wantSequence:
+		return &ChainError{Sequence: wantSequence, Reason: fmt.Sprintf("entry missing, found sequence %d", entry.Sequence)}
+	case entry.PrevHash != wantPrevHash:
+		return &ChainError{Sequence: entry.Sequence, Reason: "previous hash does not match the entry before it"}
+	case entry.Hash != hashEntry(entry):
+		return &ChainError{Sequence: entry.Sequence, Reason: "hash does not match the entry's contents"}
+	}
+	v.last = &entry
+	v.verified++
+	return nil
+}
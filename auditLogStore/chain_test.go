@@ -0,0 +1,140 @@
+package auditLog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func buildChain(n int) []Entry {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	entries := make([]Entry, 0, n)
+	var last *Entry
+	for i := 0; i < n; i++ {
+		entry := chain(last, Entry{
+			Actor:         "provider-a",
+			Action:        ActionViewReport,
+			ReportIDs:     []string{primitive.NewObjectID().Hex()},
+			IPAddress:     "203.0.113.7",
+			UserAgent:     "Mozilla/5.0",
+			CorrelationID: "corr",
+			Timestamp:     primitive.NewDateTimeFromTime(start.Add(time.Duration(i) * time.Minute)),
+		})
+		entries = append(entries, entry)
+		last = &entries[len(entries)-1]
+	}
+	return entries
+}
+
+func verifyAll(entries []Entry) (int64, error) {
+	var verifier chainVerifier
+	for _, entry := range entries {
+		if err := verifier.next(entry); err != nil {
+			return verifier.verified, err
+		}
+	}
+	return verifier.verified, nil
+}
+
+func TestChain(t *testing.T) {
+	entries := buildChain(3)
+
+	assert.Equal(t, int64(1), entries[0].Sequence)
+	assert.Empty(t, entries[0].PrevHash)
+	for i := 1; i < len(entries); i++ {
+		assert.Equal(t, int64(i+1), entries[i].Sequence)
+		assert.Equal(t, entries[i-1].Hash, entries[i].PrevHash)
+	}
+	assert.Len(t, entries[0].Hash, 64)
+	assert.Equal(t, entries[0].Hash, hashEntry(entries[0]), "hashing is deterministic")
+
+	withoutReports := entries[0]
+	withoutReports.ReportIDs = nil
+	emptyReports := entries[0]
+	emptyReports.ReportIDs = []string{}
+	assert.Equal(t, hashEntry(withoutReports), hashEntry(emptyReports), "a nil and an empty report list hash alike")
+}
+
+func TestChainVerifier(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func([]Entry) []Entry
+		wantVerified int64
+		wantSequence int64
+		wantReason   string
+	}{
+		{
+			name:         "intact chain",
+			tamper:       func(entries []Entry) []Entry { return entries },
+			wantVerified: 5,
+		},
+		{
+			name: "changed field",
+			tamper: func(entries []Entry) []Entry {
+				entries[2].Actor = "provider-b"
+				return entries
+			},
+			wantVerified: 2,
+			wantSequence: 3,
+			wantReason:   "hash does not match",
+		},
+		{
+			name: "changed field with recomputed hash",
+			tamper: func(entries []Entry) []Entry {
+				entries[2].Action = ActionListReports
+				entries[2].Hash = hashEntry(entries[2])
+				return entries
+			},
+			wantVerified: 3,
+			wantSequence: 4,
+			wantReason:   "previous hash",
+		},
+		{
+			name: "removed entry",
+			tamper: func(entries []Entry) []Entry {
+				return append(entries[:1:1], entries[2:]...)
+			},
+			wantVerified: 1,
+			wantSequence: 2,
+			wantReason:   "entry missing",
+		},
+		{
+			name: "removed first entry",
+			tamper: func(entries []Entry) []Entry {
+				return entries[1:]
+			},
+			wantVerified: 0,
+			wantSequence: 1,
+			wantReason:   "entry missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := verifyAll(tt.tamper(buildChain(5)))
+			assert.Equal(t, tt.wantVerified, verified)
+			if tt.wantReason == "" {
+				require.NoError(t, err)
+				return
+			}
+			var chainErr *ChainError
+			require.True(t, errors.As(err, &chainErr))
+			assert.Equal(t, tt.wantSequence, chainErr.Sequence)
+			assert.Contains(t, chainErr.Reason, tt.wantReason)
+		})
+	}
+}
+
+func TestFilterQuery(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	query := Filter{ReportID: "report-1", From: from, AfterSequence: 40}.query()
+
+	assert.Equal(t, "report-1", query[ReportIDsField])
+	assert.NotContains(t, query, ActorField)
+	assert.Contains(t, query[TimestampField], "$gte")
+	assert.NotContains(t, query[TimestampField], "$lt")
+	assert.NotContains(t, Filter{}.query(), TimestampField)
+}
@@ -0,0 +1,280 @@
+package auditLog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Audited actions
+const (
+	ActionCreateReport       = "report.create"
+	ActionListReports        = "report.list"
+	ActionViewReport         = "report.view"
+	ActionUpdateReport       = "report.update"
+	ActionRegenerateReport   = "report.regenerate"
+	ActionDeleteReport       = "report.delete"
+	ActionLegalHold          = "report.legalHold"
+	ActionSignReport         = "report.sign"
+	ActionAddAddendum        = "report.addendum"
+	ActionExportNote         = "report.export"
+	ActionViewNoteRevisions  = "report.revisions.view"
+	ActionRestoreRevision    = "report.revisions.restore"
+	ActionListReviews        = "review.list"
+	ActionCommentReview      = "review.comment"
+	ActionReturnReport       = "review.return"
+	ActionCoSignReport       = "review.cosign"
+	ActionSetSupervisor      = "user.supervisor"
+	ActionViewTranscript     = "transcript.view"
+	ActionUpdateTranscript   = "transcript.update"
+	ActionExportTranscript   = "transcript.export"
+	ActionViewRevisions      = "transcript.revisions.view"
+	ActionViewAudio          = "audio.view"
+	ActionAppendRecording    = "audio.append"
+	ActionGenerateAfterVisit = "afterVisitSummary.generate"
+	ActionQueryAuditLog      = "auditLog.query"
+	ActionExportAuditLog     = "auditLog.export"
+	ActionVerifyAuditLog     = "auditLog.verify"
+)
+
+const (
+	SequenceField  = "sequence"
+	ActorField     = "actor"
+	ActionField    = "action"
+	ReportIDsField = "reportIds"
+	TimestampField = "timestamp"
+	// maxAppendAttempts bounds the retries when another instance appends the same sequence number first.
+	maxAppendAttempts = 10
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// Entry records one access to report data. Entries are chained: Hash covers every other field, including
+// PrevHash, the hash of the entry before it, so changing or removing an entry breaks the chain after it.
+type Entry struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Sequence      int64              `bson:"sequence" json:"sequence"`
+	Actor         string             `bson:"actor" json:"actor"`
+	Action        string             `bson:"action" json:"action"`
+	ReportIDs     []string           `bson:"reportIds,omitempty" json:"reportIDs,omitempty"`
+	IPAddress     string             `bson:"ipAddress" json:"ipAddress"`
+	UserAgent     string             `bson:"userAgent" json:"userAgent"`
+	CorrelationID string             `bson:"correlationId" json:"correlationID"`
+	Timestamp     primitive.DateTime `bson:"timestamp" json:"timestamp"`
+	PrevHash      string             `bson:"prevHash" json:"prevHash"`
+	Hash          string             `bson:"hash" json:"hash"`
+}
+
+// Filter selects entries. Empty fields match everything; From is inclusive and To exclusive.
+type Filter struct {
+	Actor    string
+	Action   string
+	ReportID string
+	From     time.Time
+	To       time.Time
+	// AfterSequence pages through results: only entries with a greater sequence number are returned.
+	AfterSequence int64
+	Limit         int64
+}
+
+// AuditLogStore is append-only: entries can be added and read, never changed or deleted.
+type AuditLogStore interface {
+	Append(ctx context.Context, entry Entry) (Entry, error)
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+	Export(ctx context.Context, filter Filter, write func(Entry) error) error
+	Verify(ctx context.Context) (int64, error)
+}
+
+type auditLogStore struct {
+	collection *mongo.Collection
+	now        func() time.Time
+
+	// mu makes this process a single writer: appends are chained one at a time, so concurrent requests
+	// never race each other for a sequence number.
+	mu sync.Mutex
+	// last is the newest entry this process knows of, valid while loaded is set. It saves reading the log
+	// on every append and is reloaded when another instance has appended since.
+	last   *Entry
+	loaded bool
+}
+
+// NewAuditLogStore creates the store and the unique index on the sequence number that keeps the chain
+// linear when several instances append at once.
+func NewAuditLogStore(ctx context.Context, collection *mongo.Collection) (AuditLogStore, error) {
+	if collection == nil {
+		return nil, errors.New("mongodb collection cannot be nil")
+	}
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: SequenceField, Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: ReportIDsField, Value: 1}, {Key: SequenceField, Value: 1}}},
+		{Keys: bson.D{{Key: ActorField, Value: 1}, {Key: SequenceField, Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log indexes: %v", err)
+	}
+	return &auditLogStore{collection: collection, now: time.Now}, nil
+}
+
+// Append chains the entry to the last one and stores it. The sequence number, timestamp and hashes are set
+// here. Appends from this process are serialized; when another instance takes the same sequence number
+// first, the last entry is read again and the entry chained after it.
+func (s *auditLogStore) Append(ctx context.Context, entry Entry) (Entry, error) {
+	if entry.Actor == "" {
+		return Entry{}, errors.New("actor cannot be empty")
+	}
+	if entry.Action == "" {
+		return Entry{}, errors.New("action cannot be empty")
+	}
+	entry.ID = primitive.NilObjectID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		if !s.loaded {
+			last, err := s.latest(ctx)
+			if err != nil {
+				return Entry{}, err
+			}
+			s.last, s.loaded = last, true
+		}
+		// The timestamp is taken under the lock so timestamps never decrease along the chain.
+		entry.Timestamp = primitive.NewDateTimeFromTime(s.now())
+		chained := chain(s.last, entry)
+
+		result, err := s.collection.InsertOne(ctx, chained)
+		if mongo.IsDuplicateKeyError(err) {
+			s.loaded = false
+			continue
+		}
+		if err != nil {
+			// The entry may have been written before the error, so the next append reads the log again.
+			s.loaded = false
+			return Entry{}, fmt.Errorf("failed to append audit log entry: %v", err)
+		}
+		chained.ID = result.InsertedID.(primitive.ObjectID)
+		s.last = &chained
+		return chained, nil
+	}
+	return Entry{}, fmt.Errorf("failed to append audit log entry: sequence still taken after %d attempts", maxAppendAttempts)
+}
+
+// latest returns the entry with the highest sequence number, or nil when the log is empty.
+func (s *auditLogStore) latest(ctx context.Context) (*Entry, error) {
+	opts := options.FindOne().SetSort(bson.M{SequenceField: -1})
+	var last Entry
+	err := s.collection.FindOne(ctx, bson.M{}, opts).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last audit log entry: %v", err)
+	}
+	return &last, nil
+}
+
+// Query returns up to filter.Limit matching entries in sequence order.
+func (s *auditLogStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	if limit > maxQueryLimit {
+		limit = maxQueryLimit
+	}
+
+	opts := options.Find().SetSort(bson.M{SequenceField: 1}).SetLimit(limit)
+	cursor, err := s.collection.Find(ctx, filter.query(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %v", err)
+	}
+	entries := []Entry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit log entries: %v", err)
+	}
+	return entries, nil
+}
+
+// Export streams every matching entry to write in sequence order. filter.Limit is ignored.
+func (s *auditLogStore) Export(ctx context.Context, filter Filter, write func(Entry) error) error {
+	opts := options.Find().SetSort(bson.M{SequenceField: 1})
+	cursor, err := s.collection.Find(ctx, filter.query(), opts)
+	if err != nil {
+		return fmt.Errorf("failed to export audit log: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry Entry
+		if err := cursor.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to decode audit log entry: %v", err)
+		}
+		if err := write(entry); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to export audit log: %v", err)
+	}
+	return nil
+}
+
+// Verify walks the whole chain and returns how many entries were verified. It returns a *ChainError at the
+// first entry that was changed, or that follows a removed one.
+func (s *auditLogStore) Verify(ctx context.Context) (int64, error) {
+	opts := options.Find().SetSort(bson.M{SequenceField: 1})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit log: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var verifier chainVerifier
+	for cursor.Next(ctx) {
+		var entry Entry
+		if err := cursor.Decode(&entry); err != nil {
+			return verifier.verified, fmt.Errorf("failed to decode audit log entry: %v", err)
+		}
+		if err := verifier.next(entry); err != nil {
+			return verifier.verified, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return verifier.verified, fmt.Errorf("failed to read audit log: %v", err)
+	}
+	return verifier.verified, nil
+}
+
+func (f Filter) query() bson.M {
+	query := bson.M{}
+	if f.Actor != "" {
+		query[ActorField] = f.Actor
+	}
+	if f.Action != "" {
+		query[ActionField] = f.Action
+	}
+	if f.ReportID != "" {
+		query[ReportIDsField] = f.ReportID
+	}
+	timestamp := bson.M{}
+	if !f.From.IsZero() {
+		timestamp["$gte"] = primitive.NewDateTimeFromTime(f.From)
+	}
+	if !f.To.IsZero() {
+		timestamp["$lt"] = primitive.NewDateTimeFromTime(f.To)
+	}
+	if len(timestamp) > 0 {
+		query[TimestampField] = timestamp
+	}
+	if f.AfterSequence > 0 {
+		query[SequenceField] = bson.M{"$gt": f.AfterSequence}
+	}
+	return query
+}
@@ -0,0 +1,108 @@
+package auditLog
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func lastResponse(mt *mtest.T, last *Entry) bson.D {
+	ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+	if last == nil {
+		return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch)
+	}
+	return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+		{Key: "_id", Value: last.ID},
+		{Key: SequenceField, Value: last.Sequence},
+		{Key: "hash", Value: last.Hash},
+	})
+}
+
+func duplicateKeyResponse() bson.D {
+	return mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"})
+}
+
+func testEntry() Entry {
+	return Entry{Actor: "provider-a", Action: ActionViewReport, ReportIDs: []string{"report-1"}}
+}
+
+func TestAppendChainsConcurrentEntries(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("concurrent appends", func(mt *mtest.T) {
+		const appends = 20
+		store := &auditLogStore{collection: mt.Coll, now: time.Now}
+		// The log is read once; every append after that chains onto the entry appended before it.
+		mt.AddMockResponses(lastResponse(mt, nil))
+		for i := 0; i < appends; i++ {
+			mt.AddMockResponses(mtest.CreateSuccessResponse())
+		}
+
+		var wg sync.WaitGroup
+		entries := make([]Entry, appends)
+		errs := make([]error, appends)
+		for i := 0; i < appends; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				entries[i], errs[i] = store.Append(context.Background(), testEntry())
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Sequence < entries[j].Sequence })
+		_, err := verifyAll(entries)
+		assert.NoError(t, err, "concurrent appends form one unbroken chain")
+		assert.Equal(t, int64(appends), entries[appends-1].Sequence)
+	})
+}
+
+func TestAppendRereadsAfterAnotherInstanceAppends(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("sequence taken elsewhere", func(mt *mtest.T) {
+		store := &auditLogStore{collection: mt.Coll, now: time.Now}
+		elsewhere := buildChain(3)
+		mt.AddMockResponses(
+			lastResponse(mt, &elsewhere[0]), duplicateKeyResponse(),
+			lastResponse(mt, &elsewhere[2]), mtest.CreateSuccessResponse(),
+		)
+
+		appended, err := store.Append(context.Background(), testEntry())
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), appended.Sequence)
+		assert.Equal(t, elsewhere[2].Hash, appended.PrevHash)
+
+		mt.ClearEvents()
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		next, err := store.Append(context.Background(), testEntry())
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), next.Sequence)
+		assert.Equal(t, appended.Hash, next.PrevHash)
+		assert.Len(t, mt.GetAllStartedEvents(), 1, "the last entry is not read again")
+	})
+
+	mt.Run("failed insert", func(mt *mtest.T) {
+		store := &auditLogStore{collection: mt.Coll, now: time.Now}
+		mt.AddMockResponses(lastResponse(mt, nil), mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 6, Message: "host unreachable"}))
+		_, err := store.Append(context.Background(), testEntry())
+		require.Error(t, err)
+
+		// The failed insert may have landed, so the log is read again before the next append.
+		written := buildChain(1)
+		mt.AddMockResponses(lastResponse(mt, &written[0]), mtest.CreateSuccessResponse())
+		appended, err := store.Append(context.Background(), testEntry())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), appended.Sequence)
+	})
+}
@@ -0,0 +1,150 @@
+package blobStore
+
+import (
+	"Medscribe/encryption"
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// encryptedBlobMagic identifies blobs written by the encrypted store, followed by a format version.
+var encryptedBlobMagic = []byte("MSBE")
+
+const encryptedBlobVersion = 1
+
+var ErrKeyMismatch = errors.New("blob was encrypted with a different master key")
+
+type encryptedBlobStore struct {
+	blobs     BlobStore
+	masterKey *encryption.MasterKey
+}
+
+// NewEncryptedBlobStore wraps a BlobStore so every blob is encrypted at rest with its own
+// AES-GCM data key. The data key is wrapped with the master key and stored in the blob header,
+// so the underlying store never sees plaintext or an unwrapped key.
+func NewEncryptedBlobStore(blobs BlobStore, masterKey *encryption.MasterKey) BlobStore {
+	return &encryptedBlobStore{blobs: blobs, masterKey: masterKey}
+}
+
+func (s *encryptedBlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	dataKey, err := encryption.NewDataKey()
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := s.masterKey.WrapKey(dataKey)
+	if err != nil {
+		return err
+	}
+
+	// Encrypt on the fly into the underlying store so the audio is never buffered whole in memory.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.encrypt(pw, r, key, dataKey, wrappedKey))
+	}()
+
+	if err := s.blobs.Put(ctx, key, pr); err != nil {
+		pr.CloseWithError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *encryptedBlobStore) encrypt(w io.Writer, r io.Reader, key string, dataKey, wrappedKey []byte) error {
+	if err := writeHeader(w, s.masterKey.ID(), wrappedKey); err != nil {
+		return err
+	}
+	ew, err := encryption.NewEncryptingWriter(w, dataKey, []byte(key))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(ew, r); err != nil {
+		return fmt.Errorf("failed to encrypt blob: %w", err)
+	}
+	return ew.Close()
+}
+
+func (s *encryptedBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	rc, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(rc)
+	keyID, wrappedKey, err := readHeader(br)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	if keyID != s.masterKey.ID() {
+		rc.Close()
+		return nil, ErrKeyMismatch
+	}
+	dataKey, err := s.masterKey.UnwrapKey(wrappedKey)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	dr, err := encryption.NewDecryptingReader(br, dataKey, []byte(key))
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return &decryptingReadCloser{Reader: dr, closer: rc}, nil
+}
+
+func (s *encryptedBlobStore) Delete(ctx context.Context, key string) error {
+	return s.blobs.Delete(ctx, key)
+}
+
+type decryptingReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (d *decryptingReadCloser) Close() error {
+	return d.closer.Close()
+}
+
+// writeHeader writes magic, version, master key id and the wrapped data key.
+func writeHeader(w io.Writer, keyID string, wrappedKey []byte) error {
+	header := make([]byte, 0, len(encryptedBlobMagic)+4+len(keyID)+len(wrappedKey))
+	header = append(header, encryptedBlobMagic...)
+	header = append(header, encryptedBlobVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrappedKey)))
+	header = append(header, wrappedKey...)
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write blob header: %w", err)
+	}
+	return nil
+}
+
+func readHeader(r io.Reader) (string, []byte, error) {
+	prefix := make([]byte, len(encryptedBlobMagic)+2)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return "", nil, fmt.Errorf("failed to read blob header: %w", err)
+	}
+	if string(prefix[:len(encryptedBlobMagic)]) != string(encryptedBlobMagic) {
+		return "", nil, errors.New("blob is not encrypted")
+	}
+	if version := prefix[len(encryptedBlobMagic)]; version != encryptedBlobVersion {
+		return "", nil, fmt.Errorf("unsupported blob encryption version: %d", version)
+	}
+
+	keyID := make([]byte, prefix[len(encryptedBlobMagic)+1])
+	if _, err := io.ReadFull(r, keyID); err != nil {
+		return "", nil, fmt.Errorf("failed to read blob key id: %w", err)
+	}
+	var wrappedKeyLength uint16
+	if err := binary.Read(r, binary.BigEndian, &wrappedKeyLength); err != nil {
+		return "", nil, fmt.Errorf("failed to read wrapped key length: %w", err)
+	}
+	wrappedKey := make([]byte, wrappedKeyLength)
+	if _, err := io.ReadFull(r, wrappedKey); err != nil {
+		return "", nil, fmt.Errorf("failed to read wrapped key: %w", err)
+	}
+	return string(keyID), wrappedKey, nil
+}
@@ -0,0 +1,71 @@
+package blobStore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+	storage "google.golang.org/api/storage/v1"
+)
+
+type gcsBlobStore struct {
+	service *storage.Service
+	bucket  string
+}
+
+// NewGCSBlobStore stores blobs as objects in a Google Cloud Storage bucket using the
+// application default credentials, i.e. the Cloud Run service account in production.
+func NewGCSBlobStore(ctx context.Context, bucket string) (BlobStore, error) {
+	if bucket == "" {
+		return nil, errors.New("bucket cannot be empty")
+	}
+	service, err := storage.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+	return &gcsBlobStore{service: service, bucket: bucket}, nil
+}
+
+func (s *gcsBlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	object := &storage.Object{Name: key, ContentType: "application/octet-stream"}
+	if _, err := s.service.Objects.Insert(s.bucket, object).Media(r).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to upload blob: %w", err)
+	}
+	return nil
+}
+
+func (s *gcsBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.service.Objects.Get(s.bucket, key).Context(ctx).Download()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to download blob: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object. Deleting an object that does not exist is not an error.
+func (s *gcsBlobStore) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if err := s.service.Objects.Delete(s.bucket, key).Context(ctx).Do(); err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
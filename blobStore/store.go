@@ -0,0 +1,28 @@
+package blobStore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+var blobKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// BlobStore persists opaque binary objects, such as visit audio, under a caller chosen key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// validateKey keeps keys to a safe character set so they can be used as file names and object names alike.
+func validateKey(key string) error {
+	if !blobKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+	return nil
+}
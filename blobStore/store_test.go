@@ -0,0 +1,102 @@
+package blobStore
+
+import (
+	"Medscribe/encryption"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMasterKey(t *testing.T) *encryption.MasterKey {
+	t.Helper()
+	raw, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	masterKey, err := encryption.NewMasterKey(raw)
+	require.NoError(t, err)
+	return masterKey
+}
+
+func readBlob(t *testing.T, store BlobStore, key string) []byte {
+	t.Helper()
+	rc, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return data
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	t.Run("should store and retrieve a blob", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "audio-1", bytes.NewReader([]byte("RIFF"))))
+		assert.Equal(t, []byte("RIFF"), readBlob(t, store, "audio-1"))
+	})
+
+	t.Run("should report missing blobs", func(t *testing.T) {
+		_, err := store.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("should delete blobs idempotently", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "audio-2", bytes.NewReader([]byte("data"))))
+		require.NoError(t, store.Delete(ctx, "audio-2"))
+		require.NoError(t, store.Delete(ctx, "audio-2"))
+		_, err := store.Get(ctx, "audio-2")
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("should reject keys that escape the root", func(t *testing.T) {
+		for _, key := range []string{"../secret", "a/b", "", ".hidden"} {
+			assert.Error(t, store.Put(ctx, key, bytes.NewReader(nil)), key)
+		}
+	})
+}
+
+func TestEncryptedBlobStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	local, err := NewLocalBlobStore(root)
+	require.NoError(t, err)
+	masterKey := newTestMasterKey(t)
+	store := NewEncryptedBlobStore(local, masterKey)
+
+	audio := bytes.Repeat([]byte("visit audio "), 20000)
+	require.NoError(t, store.Put(ctx, "audio-1", bytes.NewReader(audio)))
+
+	t.Run("should encrypt the blob at rest", func(t *testing.T) {
+		raw, err := os.ReadFile(filepath.Join(root, "audio-1"))
+		require.NoError(t, err)
+		assert.False(t, bytes.Contains(raw, []byte("visit audio")))
+	})
+
+	t.Run("should decrypt the blob on read", func(t *testing.T) {
+		assert.Equal(t, audio, readBlob(t, store, "audio-1"))
+	})
+
+	t.Run("should refuse to read with another master key", func(t *testing.T) {
+		_, err := NewEncryptedBlobStore(local, newTestMasterKey(t)).Get(ctx, "audio-1")
+		assert.ErrorIs(t, err, ErrKeyMismatch)
+	})
+
+	t.Run("should not decrypt a blob copied under another key", func(t *testing.T) {
+		raw, err := os.ReadFile(filepath.Join(root, "audio-1"))
+		require.NoError(t, err)
+		require.NoError(t, local.Put(ctx, "audio-copy", bytes.NewReader(raw)))
+
+		rc, err := store.Get(ctx, "audio-copy")
+		require.NoError(t, err)
+		defer rc.Close()
+		_, err = io.ReadAll(rc)
+		assert.Error(t, err)
+	})
+}
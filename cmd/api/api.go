@@ -1,20 +1,34 @@
 package main
 
 import (
+	"Medscribe/api/handlers/adminHandler"
 	"Medscribe/api/handlers/reportsHandler"
+	"Medscribe/api/handlers/supervisorHandler"
+	"Medscribe/api/handlers/uploadHandler"
 	userhandler "Medscribe/api/handlers/userHandler"
 	"Medscribe/api/middleware"
 	"Medscribe/api/routes"
+	"Medscribe/audioStore"
+	auditLog "Medscribe/auditLogStore"
+	"Medscribe/blobStore"
 	"Medscribe/config"
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
 	emailsender "Medscribe/emailService"
 	inferenceService "Medscribe/inference/service"
 	inferencestorre "Medscribe/inference/store"
 	contextLogger "Medscribe/logger"
+	purgeRecords "Medscribe/purgeRecordStore"
 	"Medscribe/reports"
 	reportsTokenUsage "Medscribe/reportsTokenUsageStore"
+	"Medscribe/retention"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	transcriptRevisions "Medscribe/transcriptRevisionStore"
+	uploads "Medscribe/uploadStore"
 	transcriber "Medscribe/transcription"
 	geminiTranscriber "Medscribe/transcription/google"
 	"Medscribe/user"
+	"Medscribe/utils"
 	verificationStore "Medscribe/verificationTokenStore"
 	"context"
 	"fmt"
@@ -31,6 +45,9 @@ import (
 	// For WithCredentialsJSON
 )
 
+// purgeInterval is how often expired recordings and uploads are purged.
+const purgeInterval = time.Hour
+
 type mockTranscriber struct{}
 
 const sample1 = `
@@ -461,11 +478,11 @@ But I started you very low.
 And when I see you, I'll go up on it for you.
 Okay.
 `
-func (m *mockTranscriber) Transcribe(ctx context.Context, audio []byte) (string, error) {
+func (m *mockTranscriber) Transcribe(ctx context.Context, audio []byte, opts transcriber.Options) (string, error) {
 	return sample1, nil
 }
 
-func (m *mockTranscriber) TranscribeWithDiarization(ctx context.Context, audio []byte) ([]transcriber.TranscriptTurn, error) {
+func (m *mockTranscriber) TranscribeWithDiarization(ctx context.Context, audio []byte, opts transcriber.Options) ([]transcriber.TranscriptTurn, error) {
 	return []transcriber.TranscriptTurn{}, nil
 }
 
@@ -494,7 +511,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	logger.Info("🌐 Connecting to MongoDB", zap.String("uri", cfg.MongoURI))
+	logger.Info("🌐 Connecting to MongoDB", contextLogger.Secret("uri", cfg.MongoURI))
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
 	if err != nil {
 		logger.Fatal("❌ Failed to connect to MongoDB", zap.Error(err))
@@ -514,10 +531,56 @@ func main() {
 	userColl := db.Collection(cfg.MongoUserCollection)
 	reportsColl := db.Collection(cfg.MongoReportCollection) //TODO: Change back to MongoReportCollection
 	verificationColl := db.Collection(cfg.MongoVerificationTokenCollection)
+	transcriptRevisionColl := db.Collection(cfg.MongoTranscriptRevisionCollection)
 
 	// creating stores
 	userStore := user.NewUserStore(userColl)
-	reportsStore := reports.NewReportsStore(reportsColl)
+	reportKeyring, err := encryption.LoadKeyring(cfg.ReportEncryptionKey, cfg.ReportEncryptionKeyFile, cfg.ReportEncryptionPreviousKeys)
+	if err != nil {
+		logger.Fatal("❌ Failed to load report encryption key", zap.Error(err))
+	}
+	dataKeys := dataKeyStore.NewDataKeyStore(db.Collection(cfg.MongoDataKeyCollection), reportKeyring)
+	reportsStore := reports.NewEncryptedReportsStore(reportsColl, dataKeys)
+	reportCipher := reports.NewFieldCipher(dataKeys)
+	logger.Info("🔐 Report encryption enabled", zap.String("masterKeyId", reportKeyring.Current().ID()))
+	transcriptRevisionStore, err := transcriptRevisions.NewTranscriptRevisionStore(ctx, transcriptRevisionColl, reportCipher)
+	if err != nil {
+		logger.Fatal("❌ Failed to create transcript revision store", zap.Error(err))
+	}
+	sectionRevisionStore, err := sectionRevisions.NewSectionRevisionStore(ctx, db.Collection(cfg.MongoSectionRevisionCollection), reportCipher)
+	if err != nil {
+		logger.Fatal("❌ Failed to create section revision store", zap.Error(err))
+	}
+	auditLogStore, err := auditLog.NewAuditLogStore(ctx, db.Collection(cfg.MongoAuditLogCollection))
+	if err != nil {
+		logger.Fatal("❌ Failed to create audit log store", zap.Error(err))
+	}
+	if len(cfg.AdminUserIDs) == 0 {
+		logger.Warn("⚠️ No administrators configured; set ADMIN_USER_IDS to query the audit log")
+	}
+
+	audioMasterKey, err := encryption.LoadMasterKey(cfg.AudioEncryptionKey, cfg.AudioEncryptionKeyFile)
+	if err != nil {
+		logger.Fatal("❌ Failed to load audio encryption key", zap.Error(err))
+	}
+	var audioBlobs blobStore.BlobStore
+	switch cfg.AudioBlobBackend {
+	case "gcs":
+		audioBlobs, err = blobStore.NewGCSBlobStore(context.Background(), cfg.AudioBlobBucket)
+	case "local":
+		audioBlobs, err = blobStore.NewLocalBlobStore(cfg.AudioBlobLocalPath)
+	default:
+		err = fmt.Errorf("unknown audio blob backend: %s", cfg.AudioBlobBackend)
+	}
+	if err != nil {
+		logger.Fatal("❌ Failed to create audio blob store", zap.Error(err))
+	}
+	encryptedBlobs := blobStore.NewEncryptedBlobStore(audioBlobs, audioMasterKey)
+	retainedAudioStore := audioStore.NewAudioStore(db.Collection(cfg.MongoAudioBlobCollection), encryptedBlobs, cfg.AudioRetentionDays)
+	maxAudioSize := int64(cfg.MaxUploadSizeMB) << 20
+	uploadStore := uploads.NewUploadStore(db.Collection(cfg.MongoUploadCollection), encryptedBlobs, maxAudioSize)
+	purgeCtx := contextLogger.WithCtx(context.Background(), logger)
+	utils.StartPurger(purgeCtx, "uploads", uploadStore, purgeInterval)
 
 	if cfg.Env == "production" {
 		// in production we will use the metadata server to to leverage the cloud run service account to auth with vertex ai
@@ -545,6 +608,23 @@ func main() {
 
 	reportsTokenUsage := reportsTokenUsage.NewTokenUsageStore(db.Collection(cfg.MongoReportTokenUsageCollection))
 
+	retentionPurger := retention.NewPurger(
+		reportsStore,
+		transcriptRevisionStore,
+		sectionRevisionStore,
+		reportsTokenUsage,
+		retainedAudioStore,
+		userStore,
+		purgeRecords.NewPurgeRecordStore(db.Collection(cfg.MongoPurgeRecordCollection)),
+		user.RetentionPolicy{
+			AudioDays:      &cfg.AudioRetentionDays,
+			TranscriptDays: cfg.TranscriptRetentionDays,
+			NoteDays:       cfg.NoteRetentionDays,
+			TokenUsageDays: cfg.TokenUsageRetentionDays,
+		},
+	)
+	utils.StartPurger(purgeCtx, "retention", retentionPurger, purgeInterval)
+
 	//creating services
 	// azureTranscriber := azure.NewAzureTranscriber(cfg.OpenAISpeechURL, cfg.OpenAIDiarizationSpeechURL, cfg.OpenAIAPIKey)
 	geminiTranscriber := geminiTranscriber.NewGeminiTranscriberStore(geminiClient)
@@ -557,6 +637,8 @@ func main() {
 		// &mockInferStore{},
 		userStore,
 		reportsTokenUsage,
+		retainedAudioStore,
+		sectionRevisionStore,
 		true,
 	)
 
@@ -565,19 +647,33 @@ func main() {
 		logger.Fatal("❌ Failed to create verification store", zap.Error(err))
 	}
 
-	fmt.Printf("Email Configuration:\nSMTP Server: %s\nSMTP Port: %d\nEmail Username: %s\nEmail Password: %s\nEmail From: %s\nEmail From Name: %s\n", cfg.SMTPServer, cfg.SMTPPort, cfg.EmailUsername, cfg.EmailPassword, cfg.EmailFrom, cfg.EmailFromName)
+	logger.Info("📧 Email configuration",
+		zap.String("smtpServer", cfg.SMTPServer),
+		zap.Int("smtpPort", cfg.SMTPPort),
+		zap.String("emailUsername", cfg.EmailUsername),
+		zap.Bool("passwordSet", cfg.EmailPassword != ""),
+		zap.String("emailFrom", cfg.EmailFrom),
+		zap.String("emailFromName", cfg.EmailFromName),
+	)
 	emailSenderService := emailsender.NewEmailSenderStore(cfg.SMTPServer, cfg.SMTPPort, cfg.EmailUsername, cfg.EmailPassword, cfg.EmailFrom, cfg.EmailFromName,cfg.EMAILskipTLS)
 
 	// instantiating api
 	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret, logger, cfg.Env)
-	userHandler := userhandler.NewUserHandler(userStore, reportsStore, *authMiddleware, verificationStore, emailSenderService)
-	reportsHandler := reportsHandler.NewReportsHandler(reportsStore, inferenceService, userStore, logger)
+	userHandler := userhandler.NewUserHandler(userStore, reportsStore, *authMiddleware, verificationStore, emailSenderService, auditLogStore)
+	reportsHandler := reportsHandler.NewReportsHandler(reportsStore, inferenceService, userStore, transcriptRevisionStore, sectionRevisionStore, retainedAudioStore, uploadStore, maxAudioSize, auditLogStore, emailSenderService, logger)
+	uploadHandler := uploadHandler.NewUploadHandler(uploadStore)
+	adminHandler := adminHandler.NewAdminHandler(auditLogStore, userStore, reportsStore, retainedAudioStore)
+	supervisorHandler := supervisorHandler.NewSupervisorHandler(reportsStore, userStore, auditLogStore, emailSenderService)
 
 	router := routes.EntryRoutes(routes.APIConfig{
 		UserHandler:        userHandler,
 		ReportsHandler:     reportsHandler,
+		UploadHandler:      uploadHandler,
+		AdminHandler:       adminHandler,
+		SupervisorHandler:  supervisorHandler,
 		AuthMiddleware:     authMiddleware.Middleware,
 		MetadataMiddleware: middleware.MetadataMiddleware,
+		AdminMiddleware:    middleware.NewAdminMiddleware(cfg.AdminUserIDs),
 		
 	})
 
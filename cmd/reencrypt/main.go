@@ -0,0 +1,94 @@
+// Command reencrypt brings stored report content up to date with the current report encryption keys.
+// It rewraps data keys still wrapped with a previous master key, optionally rotates every provider's
+// data key, and re-seals report fields and their revision history that are in plaintext or under an
+// older data key.
+package main
+
+import (
+	"Medscribe/config"
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	transcriptRevisions "Medscribe/transcriptRevisionStore"
+	"context"
+	"flag"
+	"log"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+func main() {
+	rotate := flag.Bool("rotate-data-keys", false, "create a new data key version for every provider before re-encrypting")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		log.Fatalf("❌ Critical error loading config: %v", err)
+	}
+	logger := contextLogger.Get(cfg.Env)
+	defer logger.Sync()
+
+	keyring, err := encryption.LoadKeyring(cfg.ReportEncryptionKey, cfg.ReportEncryptionKeyFile, cfg.ReportEncryptionPreviousKeys)
+	if err != nil {
+		logger.Fatal("❌ Failed to load report encryption key", zap.Error(err))
+	}
+	if keyring == nil {
+		logger.Fatal("❌ Report encryption is not configured; set REPORT_ENCRYPTION_KEY or REPORT_ENCRYPTION_KEY_FILE")
+	}
+
+	ctx := contextLogger.WithCtx(context.Background(), logger)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		logger.Fatal("❌ Failed to connect to MongoDB", zap.Error(err))
+	}
+	defer client.Disconnect(ctx)
+
+	db := client.Database(cfg.MongoDBName)
+	dataKeys := dataKeyStore.NewDataKeyStore(db.Collection(cfg.MongoDataKeyCollection), keyring)
+	reportsStore := reports.NewEncryptedReportsStore(db.Collection(cfg.MongoReportCollection), dataKeys)
+	cipher := reports.NewFieldCipher(dataKeys)
+	transcriptRevisionStore, err := transcriptRevisions.NewTranscriptRevisionStore(ctx, db.Collection(cfg.MongoTranscriptRevisionCollection), cipher)
+	if err != nil {
+		logger.Fatal("❌ Failed to create transcript revision store", zap.Error(err))
+	}
+	sectionRevisionStore, err := sectionRevisions.NewSectionRevisionStore(ctx, db.Collection(cfg.MongoSectionRevisionCollection), cipher)
+	if err != nil {
+		logger.Fatal("❌ Failed to create section revision store", zap.Error(err))
+	}
+
+	rewrapped, err := dataKeys.RewrapAll(ctx)
+	if err != nil {
+		logger.Fatal("❌ Failed to rewrap data keys", zap.Error(err))
+	}
+	logger.Info("✅ Rewrapped data keys", zap.Int("count", rewrapped), zap.String("masterKeyId", keyring.Current().ID()))
+
+	if *rotate {
+		rotated, err := dataKeys.RotateAll(ctx)
+		if err != nil {
+			logger.Fatal("❌ Failed to rotate data keys", zap.Error(err))
+		}
+		logger.Info("✅ Rotated data keys", zap.Int("count", rotated))
+	}
+
+	reencrypted, err := reportsStore.ReencryptAll(ctx)
+	if err != nil {
+		logger.Fatal("❌ Failed to re-encrypt reports", zap.Error(err), zap.Int("reencrypted", reencrypted))
+	}
+	logger.Info("✅ Re-encrypted reports", zap.Int("count", reencrypted))
+
+	reencrypted, err = transcriptRevisionStore.ReencryptAll(ctx)
+	if err != nil {
+		logger.Fatal("❌ Failed to re-encrypt transcript revisions", zap.Error(err), zap.Int("reencrypted", reencrypted))
+	}
+	logger.Info("✅ Re-encrypted transcript revisions", zap.Int("count", reencrypted))
+
+	reencrypted, err = sectionRevisionStore.ReencryptAll(ctx)
+	if err != nil {
+		logger.Fatal("❌ Failed to re-encrypt section revisions", zap.Error(err), zap.Int("reencrypted", reencrypted))
+	}
+	logger.Info("✅ Re-encrypted section revisions", zap.Int("count", reencrypted))
+}
@@ -20,6 +20,37 @@ type Config struct {
 	MongoFreedVisits                        string
 	MongoDistillAnalysis                    string
 	MongoVerificationTokenCollection        string
+	MongoTranscriptRevisionCollection       string
+	MongoSectionRevisionCollection          string
+	MongoAudioBlobCollection                string
+	MongoUploadCollection                   string
+	MongoDataKeyCollection                  string
+	// MaxUploadSizeMB caps a recording, whether uploaded in chunks or as a form file. Transcription
+	// needs the whole recording in memory, so this also bounds what one request holds.
+	MaxUploadSizeMB                         int
+	// AudioEncryptionKey or AudioEncryptionKeyFile holds the master key retained recordings are encrypted
+	// with. Exactly one of them must be set.
+	AudioEncryptionKey                      string
+	AudioEncryptionKeyFile                  string
+	// ReportEncryptionKey or ReportEncryptionKeyFile holds the master key that wraps the per-provider data keys
+	// report content and its revision history are encrypted with. Exactly one of them must be set.
+	ReportEncryptionKey                     string
+	ReportEncryptionKeyFile                 string
+	// ReportEncryptionPreviousKeys are master keys replaced by a rotation, kept until their data keys are rewrapped.
+	ReportEncryptionPreviousKeys            []string
+	AudioBlobBackend                        string
+	AudioBlobLocalPath                      string
+	AudioBlobBucket                         string
+	AudioRetentionDays                      int
+	// TranscriptRetentionDays, NoteRetentionDays and TokenUsageRetentionDays are the default retention
+	// periods providers can override. Zero keeps the data indefinitely.
+	TranscriptRetentionDays                 int
+	NoteRetentionDays                       int
+	TokenUsageRetentionDays                 int
+	MongoPurgeRecordCollection              string
+	MongoAuditLogCollection                 string
+	// AdminUserIDs are the users allowed to query and export the audit log.
+	AdminUserIDs                            []string
 	VerificationTokenTTL int
 	OpenAIChatURL                           string
 	OpenAISpeechURL                         string
@@ -101,6 +132,97 @@ func LoadConfig(testEnv string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	mongoTranscriptRevisionColl, err := getEnvStrict("MONGODB_TRANSCRIPT_REVISION_COLLECTION", "transcriptRevisions")
+	if err != nil {
+		return nil, err
+	}
+	mongoSectionRevisionColl, err := getEnvStrict("MONGODB_SECTION_REVISION_COLLECTION", "sectionRevisions")
+	if err != nil {
+		return nil, err
+	}
+	mongoAudioBlobColl, err := getEnvStrict("MONGODB_AUDIO_BLOB_COLLECTION", "audioBlobs")
+	if err != nil {
+		return nil, err
+	}
+	mongoUploadColl, err := getEnvStrict("MONGODB_UPLOAD_COLLECTION", "uploads")
+	if err != nil {
+		return nil, err
+	}
+	maxUploadSizeMBString, err := getEnvStrict("MAX_UPLOAD_SIZE_MB", "100")
+	if err != nil {
+		return nil, err
+	}
+	maxUploadSizeMB, err := strconv.Atoi(maxUploadSizeMBString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_UPLOAD_SIZE_MB: %w", err)
+	}
+	audioEncryptionKey, audioEncryptionKeyFile, err := getKeyEnvStrict("AUDIO_ENCRYPTION_KEY")
+	if err != nil {
+		return nil, err
+	}
+	mongoDataKeyColl, err := getEnvStrict("MONGODB_DATA_KEY_COLLECTION", "dataKeys")
+	if err != nil {
+		return nil, err
+	}
+	reportEncryptionKey, reportEncryptionKeyFile, err := getKeyEnvStrict("REPORT_ENCRYPTION_KEY")
+	if err != nil {
+		return nil, err
+	}
+	var reportEncryptionPreviousKeys []string
+	for _, key := range strings.Split(os.Getenv("REPORT_ENCRYPTION_PREVIOUS_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			reportEncryptionPreviousKeys = append(reportEncryptionPreviousKeys, key)
+		}
+	}
+	audioBlobBackend, err := getEnvStrict("AUDIO_BLOB_BACKEND", "local")
+	if err != nil {
+		return nil, err
+	}
+	audioBlobLocalPath, err := getEnvStrict("AUDIO_BLOB_LOCAL_PATH", "./data/audio")
+	if err != nil {
+		return nil, err
+	}
+	var audioBlobBucket string
+	if audioBlobBackend == "gcs" {
+		audioBlobBucket, err = getEnvStrict("AUDIO_BLOB_BUCKET", "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	audioRetentionDaysString, err := getEnvStrict("AUDIO_RETENTION_DAYS", "30")
+	if err != nil {
+		return nil, err
+	}
+	audioRetentionDays, err := strconv.Atoi(audioRetentionDaysString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUDIO_RETENTION_DAYS: %w", err)
+	}
+	retentionDays := make(map[string]int)
+	for _, key := range []string{"TRANSCRIPT_RETENTION_DAYS", "NOTE_RETENTION_DAYS", "TOKEN_USAGE_RETENTION_DAYS"} {
+		daysString, err := getEnvStrict(key, "0")
+		if err != nil {
+			return nil, err
+		}
+		days, err := strconv.Atoi(daysString)
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid %s: %q", key, daysString)
+		}
+		retentionDays[key] = days
+	}
+	mongoPurgeRecordColl, err := getEnvStrict("MONGODB_PURGE_RECORD_COLLECTION", "purgeRecords")
+	if err != nil {
+		return nil, err
+	}
+	mongoAuditLogColl, err := getEnvStrict("MONGODB_AUDIT_LOG_COLLECTION", "auditLog")
+	if err != nil {
+		return nil, err
+	}
+	var adminUserIDs []string
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			adminUserIDs = append(adminUserIDs, id)
+		}
+	}
 	openAIChatURL, err := getEnvStrict("OPENAI_API_CHAT_URL", "")
 	if err != nil {
 		return nil, err
@@ -199,6 +321,27 @@ func LoadConfig(testEnv string) (*Config, error) {
 		MongoFreedVisits:                mongoFreedVisits,
 		MongoVerificationTokenCollection: mongoVerificationTokenColl,
 		VerificationTokenTTL: 	 120,
+		MongoTranscriptRevisionCollection: mongoTranscriptRevisionColl,
+		MongoSectionRevisionCollection:    mongoSectionRevisionColl,
+		MongoAudioBlobCollection:        mongoAudioBlobColl,
+		MongoUploadCollection:           mongoUploadColl,
+		MaxUploadSizeMB:                 maxUploadSizeMB,
+		MongoDataKeyCollection:          mongoDataKeyColl,
+		AudioEncryptionKey:              audioEncryptionKey,
+		AudioEncryptionKeyFile:          audioEncryptionKeyFile,
+		ReportEncryptionKey:             reportEncryptionKey,
+		ReportEncryptionKeyFile:         reportEncryptionKeyFile,
+		ReportEncryptionPreviousKeys:    reportEncryptionPreviousKeys,
+		AudioBlobBackend:                audioBlobBackend,
+		AudioBlobLocalPath:              audioBlobLocalPath,
+		AudioBlobBucket:                 audioBlobBucket,
+		AudioRetentionDays:              audioRetentionDays,
+		TranscriptRetentionDays:         retentionDays["TRANSCRIPT_RETENTION_DAYS"],
+		NoteRetentionDays:               retentionDays["NOTE_RETENTION_DAYS"],
+		TokenUsageRetentionDays:         retentionDays["TOKEN_USAGE_RETENTION_DAYS"],
+		MongoPurgeRecordCollection:      mongoPurgeRecordColl,
+		MongoAuditLogCollection:         mongoAuditLogColl,
+		AdminUserIDs:                    adminUserIDs,
 		MongoDistillAnalysis:            mongoDistillAnalysis,
 		OpenAIChatURL:                   openAIChatURL,
 		OpenAISpeechURL:                 openAISpeechURL,
@@ -234,6 +377,21 @@ func getEnvStrict(key, fallback string) (string, error) {
 	return val, nil
 }
 
+// getKeyEnvStrict reads a key that is given either inline in key or as a path in key_FILE. Exactly one
+// of the two must be set.
+func getKeyEnvStrict(key string) (string, string, error) {
+	fileKey := key + "_FILE"
+	val := os.Getenv(key)
+	file := os.Getenv(fileKey)
+	if val != "" && file != "" {
+		return "", "", fmt.Errorf("only one of %s and %s may be set", key, fileKey)
+	}
+	if val == "" && file == "" {
+		return "", "", fmt.Errorf("missing required environment variable: %s or %s", key, fileKey)
+	}
+	return val, file, nil
+}
+
 func getEnvStrictConditional(prodKey, devKey string, isProd bool) (string, error) {
 	if isProd {
 		return getEnvStrict(prodKey, "")
@@ -0,0 +1,36 @@
+package dataKeyStore
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDataKeyStore struct {
+	mock.Mock
+}
+
+func (m *MockDataKeyStore) Current(ctx context.Context, providerID string) (DataKey, error) {
+	args := m.Called(ctx, providerID)
+	return args.Get(0).(DataKey), args.Error(1)
+}
+
+func (m *MockDataKeyStore) Get(ctx context.Context, providerID string, version int) (DataKey, error) {
+	args := m.Called(ctx, providerID, version)
+	return args.Get(0).(DataKey), args.Error(1)
+}
+
+func (m *MockDataKeyStore) Rotate(ctx context.Context, providerID string) (DataKey, error) {
+	args := m.Called(ctx, providerID)
+	return args.Get(0).(DataKey), args.Error(1)
+}
+
+func (m *MockDataKeyStore) RotateAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDataKeyStore) RewrapAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
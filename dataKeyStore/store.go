@@ -0,0 +1,265 @@
+package dataKeyStore
+
+import (
+	"Medscribe/encryption"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// currentKeyTTL is how long the current version of a provider's key is cached before it is looked up
+// again, so a rotation on another instance is picked up.
+const currentKeyTTL = 5 * time.Minute
+
+var ErrDataKeyNotFound = errors.New("data key not found")
+
+// DataKey is an unwrapped version of a provider's data key.
+type DataKey struct {
+	ProviderID string
+	Version    int
+	Key        []byte
+}
+
+// wrappedDataKey is how a data key is persisted: wrapped with the master key whose ID it records.
+type wrappedDataKey struct {
+	ID          string    `bson:"_id"`
+	ProviderID  string    `bson:"providerId"`
+	Version     int       `bson:"version"`
+	MasterKeyID string    `bson:"masterKeyId"`
+	WrappedKey  []byte    `bson:"wrappedKey"`
+	CreatedAt   time.Time `bson:"createdAt"`
+}
+
+type DataKeyStore interface {
+	// Current returns the newest version of the provider's data key, creating the first one if needed.
+	Current(ctx context.Context, providerID string) (DataKey, error)
+	// Get returns a specific version of the provider's data key.
+	Get(ctx context.Context, providerID string, version int) (DataKey, error)
+	// Rotate creates a new version of the provider's data key. Older versions stay readable.
+	Rotate(ctx context.Context, providerID string) (DataKey, error)
+	// RotateAll rotates the data key of every provider that has one and returns how many were rotated.
+	RotateAll(ctx context.Context) (int, error)
+	// RewrapAll rewraps data keys wrapped with a previous master key with the current one and returns
+	// how many were rewrapped.
+	RewrapAll(ctx context.Context) (int, error)
+}
+
+type dataKeyStore struct {
+	collection *mongo.Collection
+	keyring    *encryption.Keyring
+
+	mu      sync.Mutex
+	keys    map[string]DataKey
+	current map[string]cachedVersion
+}
+
+type cachedVersion struct {
+	version   int
+	expiresAt time.Time
+}
+
+// NewDataKeyStore keeps per-provider data keys in collection, wrapped with the keyring's master keys.
+// Unwrapped keys are cached in memory and never persisted.
+func NewDataKeyStore(collection *mongo.Collection, keyring *encryption.Keyring) DataKeyStore {
+	return &dataKeyStore{
+		collection: collection,
+		keyring:    keyring,
+		keys:       make(map[string]DataKey),
+		current:    make(map[string]cachedVersion),
+	}
+}
+
+func dataKeyID(providerID string, version int) string {
+	return fmt.Sprintf("%s/%d", providerID, version)
+}
+
+func (s *dataKeyStore) Current(ctx context.Context, providerID string) (DataKey, error) {
+	if providerID == "" {
+		return DataKey{}, errors.New("providerId cannot be empty")
+	}
+
+	s.mu.Lock()
+	cached, ok := s.current[providerID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return s.Get(ctx, providerID, cached.version)
+	}
+
+	var latest wrappedDataKey
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+	err := s.collection.FindOne(ctx, bson.M{"providerId": providerID}, opts).Decode(&latest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return s.create(ctx, providerID, 1)
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to find data key: %v", err)
+	}
+
+	key, err := s.unwrap(latest)
+	if err != nil {
+		return DataKey{}, err
+	}
+	s.cache(key, true)
+	return key, nil
+}
+
+func (s *dataKeyStore) Get(ctx context.Context, providerID string, version int) (DataKey, error) {
+	id := dataKeyID(providerID, version)
+	s.mu.Lock()
+	key, ok := s.keys[id]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var wrapped wrappedDataKey
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&wrapped)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return DataKey{}, fmt.Errorf("%w: version %d", ErrDataKeyNotFound, version)
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to find data key: %v", err)
+	}
+
+	key, err = s.unwrap(wrapped)
+	if err != nil {
+		return DataKey{}, err
+	}
+	s.cache(key, false)
+	return key, nil
+}
+
+func (s *dataKeyStore) Rotate(ctx context.Context, providerID string) (DataKey, error) {
+	current, err := s.Current(ctx, providerID)
+	if err != nil {
+		return DataKey{}, err
+	}
+	return s.create(ctx, providerID, current.Version+1)
+}
+
+func (s *dataKeyStore) RotateAll(ctx context.Context) (int, error) {
+	providerIDs, err := s.collection.Distinct(ctx, "providerId", bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list providers with data keys: %v", err)
+	}
+
+	rotated := 0
+	for _, providerID := range providerIDs {
+		id, ok := providerID.(string)
+		if !ok {
+			continue
+		}
+		// Rotate from the stored latest version, not a cached one.
+		s.mu.Lock()
+		delete(s.current, id)
+		s.mu.Unlock()
+		if _, err := s.Rotate(ctx, id); err != nil {
+			return rotated, fmt.Errorf("failed to rotate data key of provider %s: %w", id, err)
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+func (s *dataKeyStore) RewrapAll(ctx context.Context) (int, error) {
+	masterKey := s.keyring.Current()
+	cursor, err := s.collection.Find(ctx, bson.M{"masterKeyId": bson.M{"$ne": masterKey.ID()}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find data keys to rewrap: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	rewrapped := 0
+	for cursor.Next(ctx) {
+		var wrapped wrappedDataKey
+		if err := cursor.Decode(&wrapped); err != nil {
+			return rewrapped, fmt.Errorf("failed to decode data key: %v", err)
+		}
+		key, err := s.unwrap(wrapped)
+		if err != nil {
+			return rewrapped, err
+		}
+		rewrappedKey, err := masterKey.WrapKey(key.Key)
+		if err != nil {
+			return rewrapped, fmt.Errorf("failed to rewrap data key: %w", err)
+		}
+
+		// Only replace the key if no other instance rewrapped it in the meantime.
+		filter := bson.M{"_id": wrapped.ID, "masterKeyId": wrapped.MasterKeyID}
+		update := bson.M{"$set": bson.M{"masterKeyId": masterKey.ID(), "wrappedKey": rewrappedKey}}
+		result, err := s.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return rewrapped, fmt.Errorf("failed to store rewrapped data key: %v", err)
+		}
+		rewrapped += int(result.ModifiedCount)
+	}
+	if err := cursor.Err(); err != nil {
+		return rewrapped, fmt.Errorf("failed to iterate data keys: %v", err)
+	}
+	return rewrapped, nil
+}
+
+// create stores a new version of the provider's data key. If another instance created the same
+// version first, that key is used instead.
+func (s *dataKeyStore) create(ctx context.Context, providerID string, version int) (DataKey, error) {
+	raw, err := encryption.NewDataKey()
+	if err != nil {
+		return DataKey{}, err
+	}
+	masterKey := s.keyring.Current()
+	wrappedKey, err := masterKey.WrapKey(raw)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	_, err = s.collection.InsertOne(ctx, wrappedDataKey{
+		ID:          dataKeyID(providerID, version),
+		ProviderID:  providerID,
+		Version:     version,
+		MasterKeyID: masterKey.ID(),
+		WrappedKey:  wrappedKey,
+		CreatedAt:   time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		key, err := s.Get(ctx, providerID, version)
+		if err != nil {
+			return DataKey{}, err
+		}
+		s.cache(key, true)
+		return key, nil
+	}
+	if err != nil {
+		return DataKey{}, fmt.Errorf("failed to insert data key: %v", err)
+	}
+
+	key := DataKey{ProviderID: providerID, Version: version, Key: raw}
+	s.cache(key, true)
+	return key, nil
+}
+
+func (s *dataKeyStore) unwrap(wrapped wrappedDataKey) (DataKey, error) {
+	masterKey, err := s.keyring.Key(wrapped.MasterKeyID)
+	if err != nil {
+		return DataKey{}, err
+	}
+	raw, err := masterKey.UnwrapKey(wrapped.WrappedKey)
+	if err != nil {
+		return DataKey{}, err
+	}
+	return DataKey{ProviderID: wrapped.ProviderID, Version: wrapped.Version, Key: raw}, nil
+}
+
+func (s *dataKeyStore) cache(key DataKey, current bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[dataKeyID(key.ProviderID, key.Version)] = key
+	if current && key.Version >= s.current[key.ProviderID].version {
+		s.current[key.ProviderID] = cachedVersion{version: key.Version, expiresAt: time.Now().Add(currentKeyTTL)}
+	}
+}
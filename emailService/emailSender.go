@@ -1,9 +1,13 @@
 package emailsender
 
 import (
+	contextLogger "Medscribe/logger"
+	"Medscribe/user"
+	"context"
 	"crypto/tls"
 	"fmt"
 
+	"go.uber.org/zap"
 	"gopkg.in/gomail.v2"
 )
 
@@ -23,6 +27,25 @@ type EmailSender interface {
 	SendEmail(to, subject, body, htmlBody string) error
 }
 
+// SendNotification emails a short notice with the message as the plain text body.
+func SendNotification(sender EmailSender, to, subject, message string) error {
+	return sender.SendEmail(to, subject, message, GenerateNotificationHTMLBody(subject, message))
+}
+
+// NotifyUser emails a short notice to the user with userID. The change it reports has already been
+// recorded, so a failure to send it is logged rather than returned.
+func NotifyUser(ctx context.Context, users user.UserStore, sender EmailSender, userID, subject, message string) {
+	logger := contextLogger.FromCtx(ctx)
+	recipient, err := users.Get(ctx, userID)
+	if err != nil {
+		logger.Error("Error fetching notification recipient", zap.String("UserID", userID), zap.Error(err))
+		return
+	}
+	if err := SendNotification(sender, recipient.Email, subject, message); err != nil {
+		logger.Error("Error sending notification", zap.String("UserID", userID), zap.String("Subject", subject), zap.Error(err))
+	}
+}
+
 // newEmailSenderStore creates a new email sender store with default values
 // and returns an EmailSender interface.
 func NewEmailSenderStore(server string, port int, username, password, fromEmail, fromName string, skipTLS bool) EmailSender {
@@ -47,7 +70,6 @@ func NewEmailSenderStore(server string, port int, username, password, fromEmail,
 
 // SendEmail sends an email using the configuration in the emailSenderStore.
 func (s *emailSenderStore) SendEmail(to, subject, body, htmlBody string) error {
-	fmt.Println("Sending email to:", to)
 	m := gomail.NewMessage()
 	m.SetAddressHeader("From", s.fromEmail, s.fromName)
 	m.SetHeader("To", to)
@@ -0,0 +1,14 @@
+package emailsender
+
+import "github.com/stretchr/testify/mock"
+
+// MockEmailSender is a mock implementation of the EmailSender interface.
+type MockEmailSender struct {
+	mock.Mock
+}
+
+// SendEmail mocks the SendEmail method.
+func (m *MockEmailSender) SendEmail(to, subject, body, htmlBody string) error {
+	args := m.Called(to, subject, body, htmlBody)
+	return args.Error(0)
+}
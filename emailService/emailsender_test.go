@@ -1,12 +1,16 @@
 package emailsender
 
 import (
+	"Medscribe/user"
+	"context"
 	"crypto/tls"
+	"errors"
 	"os"
 	"strconv"
 	"testing"
 
 	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/mock"
 )
 
 // TestSendEmailSuccess tests sending a successful email.
@@ -55,3 +59,26 @@ func TestSendEmailSuccess(t *testing.T) {
 
 	t.Logf("TestSendEmailSuccess: Email sent successfully!")
 }
+
+func TestNotifyUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("emails the user", func(t *testing.T) {
+		users := new(user.MockUserStore)
+		users.On("Get", ctx, "provider-1").Return(user.User{Email: "provider@example.com"}, nil)
+		sender := new(MockEmailSender)
+		sender.On("SendEmail", "provider@example.com", "Note co-signed", "Your note was co-signed.", mock.Anything).Return(nil)
+
+		NotifyUser(ctx, users, sender, "provider-1", "Note co-signed", "Your note was co-signed.")
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("skips users that cannot be found", func(t *testing.T) {
+		users := new(user.MockUserStore)
+		users.On("Get", ctx, "provider-1").Return(user.User{}, errors.New("not found"))
+		sender := new(MockEmailSender)
+
+		NotifyUser(ctx, users, sender, "provider-1", "Note co-signed", "Your note was co-signed.")
+		sender.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
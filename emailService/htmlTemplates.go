@@ -1,6 +1,9 @@
 package emailsender
 
-import "fmt"
+import (
+	"fmt"
+	"html"
+)
 
 
 func GenerateVerificationHTMLBody(code string, message string) string {
@@ -129,4 +132,51 @@ func GeneratePasswordResetHTMLBody(resetLink string) string {
 </body>
 </html>`, resetLink, resetLink, resetLink)
 	return htmlBody
-}
\ No newline at end of file
+}
+// GenerateNotificationHTMLBody renders a short notice, such as a change in a note's review. The heading and
+// message are escaped.
+func GenerateNotificationHTMLBody(heading string, message string) string {
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>%s</title>
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            background-color: #f4f4f4;
+            margin: 0;
+            padding: 0;
+        }
+        .container {
+            max-width: 600px;
+            margin: 20px auto;
+            background-color: #ffffff;
+            padding: 20px;
+            border-radius: 5px;
+            box-shadow: 0 2px 5px rgba(0,0,0,0.1);
+        }
+        h1 {
+            color: #007bff;
+        }
+        p {
+            color: #555;
+        }
+        .instructions {
+            font-size: 14px;
+            color: #6c757d;
+            margin-top: 20px;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>Medscribe: %s</h1>
+        <p>%s</p>
+        <p class="instructions">Sign in to Medscribe to see the note.</p>
+    </div>
+</body>
+</html>`, html.EscapeString(heading), html.EscapeString(heading), html.EscapeString(message))
+	return htmlBody
+}
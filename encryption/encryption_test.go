@@ -0,0 +1,189 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	return key
+}
+
+func TestMasterKey(t *testing.T) {
+	raw := newTestKey(t)
+	masterKey, err := ParseMasterKey(base64.StdEncoding.EncodeToString(raw))
+	require.NoError(t, err)
+	assert.Len(t, masterKey.ID(), 8)
+
+	t.Run("should round trip a wrapped data key", func(t *testing.T) {
+		dataKey := newTestKey(t)
+		wrapped, err := masterKey.WrapKey(dataKey)
+		require.NoError(t, err)
+		assert.NotContains(t, string(wrapped), string(dataKey))
+
+		unwrapped, err := masterKey.UnwrapKey(wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, dataKey, unwrapped)
+	})
+
+	t.Run("should not unwrap with a different master key", func(t *testing.T) {
+		wrapped, err := masterKey.WrapKey(newTestKey(t))
+		require.NoError(t, err)
+
+		other, err := NewMasterKey(newTestKey(t))
+		require.NoError(t, err)
+		_, err = other.UnwrapKey(wrapped)
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject keys of the wrong size", func(t *testing.T) {
+		_, err := ParseMasterKey(base64.StdEncoding.EncodeToString([]byte("short")))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestSealOpen(t *testing.T) {
+	key := newTestKey(t)
+	sealed, err := Seal(key, []byte("Patient reports improved sleep."), []byte("report-1"))
+	require.NoError(t, err)
+
+	plaintext, err := Open(key, sealed, []byte("report-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "Patient reports improved sleep.", string(plaintext))
+
+	_, err = Open(key, sealed, []byte("report-2"))
+	assert.Error(t, err, "additional data must match")
+}
+
+func encryptStream(t *testing.T, key, plaintext, additionalData []byte) []byte {
+	t.Helper()
+	var encrypted bytes.Buffer
+	w, err := NewEncryptingWriter(&encrypted, key, additionalData)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return encrypted.Bytes()
+}
+
+func TestStream(t *testing.T) {
+	key := newTestKey(t)
+	additionalData := []byte("blob-1")
+
+	sizes := map[string]int{
+		"empty":                0,
+		"smaller than segment": 1000,
+		"exact segment":        segmentSize,
+		"multiple segments":    3*segmentSize + 17,
+	}
+	for name, size := range sizes {
+		t.Run("should round trip "+name, func(t *testing.T) {
+			plaintext := make([]byte, size)
+			_, err := rand.Read(plaintext)
+			require.NoError(t, err)
+
+			encrypted := encryptStream(t, key, plaintext, additionalData)
+			r, err := NewDecryptingReader(bytes.NewReader(encrypted), key, additionalData)
+			require.NoError(t, err)
+			decrypted, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+		})
+	}
+
+	plaintext := bytes.Repeat([]byte("a"), 2*segmentSize+10)
+	encrypted := encryptStream(t, key, plaintext, additionalData)
+
+	t.Run("should detect truncation on a segment boundary", func(t *testing.T) {
+		truncated := encrypted[:noncePrefixSize+segmentSize+16]
+		r, err := NewDecryptingReader(bytes.NewReader(truncated), key, additionalData)
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		assert.Error(t, err)
+	})
+
+	t.Run("should detect tampering", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(tampered)-1] ^= 0xff
+		r, err := NewDecryptingReader(bytes.NewReader(tampered), key, additionalData)
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject mismatched additional data", func(t *testing.T) {
+		r, err := NewDecryptingReader(bytes.NewReader(encrypted), key, []byte("blob-2"))
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyring(t *testing.T) {
+	previous, err := NewMasterKey(newTestKey(t))
+	require.NoError(t, err)
+	current, err := NewMasterKey(newTestKey(t))
+	require.NoError(t, err)
+	keyring := NewKeyring(current, previous)
+
+	assert.Equal(t, current, keyring.Current())
+
+	t.Run("should unwrap data keys wrapped with a previous key", func(t *testing.T) {
+		dataKey := newTestKey(t)
+		wrapped, err := previous.WrapKey(dataKey)
+		require.NoError(t, err)
+
+		key, err := keyring.Key(previous.ID())
+		require.NoError(t, err)
+		unwrapped, err := key.UnwrapKey(wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, dataKey, unwrapped)
+	})
+
+	t.Run("should reject unknown keys", func(t *testing.T) {
+		_, err := keyring.Key("deadbeef")
+		assert.ErrorIs(t, err, ErrUnknownMasterKey)
+	})
+}
+
+func TestReadMasterKeyFile(t *testing.T) {
+	raw := newTestKey(t)
+	path := t.TempDir() + "/master.key"
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(raw)+"\n"), 0o600))
+
+	fromFile, err := ReadMasterKeyFile(path)
+	require.NoError(t, err)
+	expected, err := NewMasterKey(raw)
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID(), fromFile.ID())
+}
+
+func TestLoadMasterKey(t *testing.T) {
+	raw := newTestKey(t)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	path := t.TempDir() + "/master.key"
+	require.NoError(t, os.WriteFile(path, []byte(encoded), 0o600))
+	expected, err := NewMasterKey(raw)
+	require.NoError(t, err)
+
+	fromKey, err := LoadMasterKey(encoded, "")
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID(), fromKey.ID())
+
+	fromFile, err := LoadMasterKey("", path)
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID(), fromFile.ID())
+
+	_, err = LoadMasterKey("", "")
+	assert.Error(t, err)
+}
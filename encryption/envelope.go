@@ -0,0 +1,133 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size in bytes of master and data keys (AES-256).
+const KeySize = 32
+
+// wrappedKeyAdditionalData binds wrapped data keys to their purpose so they cannot be swapped for other ciphertexts.
+var wrappedKeyAdditionalData = []byte("medscribe:data-key")
+
+var (
+	ErrInvalidKey       = errors.New("encryption key must be 32 bytes")
+	ErrMalformedPayload = errors.New("encrypted payload is malformed")
+)
+
+// MasterKey is the key-encryption key used to wrap the per-object data keys (envelope encryption).
+// Only wrapped data keys are ever persisted; the master key itself stays in configuration.
+type MasterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// ParseMasterKey decodes a base64 encoded 32 byte master key.
+func ParseMasterKey(encoded string) (*MasterKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode master key: %w", err)
+	}
+	return NewMasterKey(key)
+}
+
+// NewMasterKey creates a master key from raw key bytes.
+func NewMasterKey(key []byte) (*MasterKey, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &MasterKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// ID returns a short fingerprint of the master key, stored next to wrapped keys to support rotation.
+func (k *MasterKey) ID() string {
+	return k.id
+}
+
+// WrapKey encrypts a data key with the master key.
+func (k *MasterKey) WrapKey(dataKey []byte) ([]byte, error) {
+	if len(dataKey) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return seal(k.aead, dataKey, wrappedKeyAdditionalData)
+}
+
+// UnwrapKey decrypts a data key previously wrapped with WrapKey.
+func (k *MasterKey) UnwrapKey(wrapped []byte) ([]byte, error) {
+	dataKey, err := open(k.aead, wrapped, wrappedKeyAdditionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// NewDataKey generates a random data key.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with AES-GCM under the given data key. The random nonce is prepended to the ciphertext.
+func Seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return seal(aead, plaintext, additionalData)
+}
+
+// Open decrypts a ciphertext produced by Seal.
+func Open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, ciphertext, additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
+
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrMalformedPayload
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return plaintext, nil
+}
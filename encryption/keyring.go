@@ -0,0 +1,82 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrUnknownMasterKey = errors.New("data key was wrapped with an unknown master key")
+
+// Keyring holds the current master key, which wraps new data keys, and the master keys it replaced,
+// which can still unwrap data keys until they are rewrapped.
+type Keyring struct {
+	current *MasterKey
+	keys    map[string]*MasterKey
+}
+
+// NewKeyring creates a keyring that wraps with current and unwraps with current or any previous key.
+func NewKeyring(current *MasterKey, previous ...*MasterKey) *Keyring {
+	keys := map[string]*MasterKey{current.ID(): current}
+	for _, key := range previous {
+		keys[key.ID()] = key
+	}
+	return &Keyring{current: current, keys: keys}
+}
+
+// Current returns the master key new data keys are wrapped with.
+func (k *Keyring) Current() *MasterKey {
+	return k.current
+}
+
+// Key returns the master key with the given ID.
+func (k *Keyring) Key(id string) (*MasterKey, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, id)
+	}
+	return key, nil
+}
+
+// ReadMasterKeyFile reads a base64 encoded master key from a file, such as a mounted secret.
+func ReadMasterKeyFile(path string) (*MasterKey, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+	return ParseMasterKey(strings.TrimSpace(string(encoded)))
+}
+
+// LoadMasterKey reads a master key given either base64 encoded or as a key file.
+func LoadMasterKey(encodedKey, keyFile string) (*MasterKey, error) {
+	if encodedKey != "" {
+		return ParseMasterKey(encodedKey)
+	}
+	if keyFile != "" {
+		return ReadMasterKeyFile(keyFile)
+	}
+	return nil, errors.New("no master key or key file given")
+}
+
+// LoadKeyring builds a keyring from a base64 master key or a key file, plus the base64 master keys it
+// replaced. It returns nil when neither a key nor a key file is given.
+func LoadKeyring(encodedKey, keyFile string, previous []string) (*Keyring, error) {
+	if encodedKey == "" && keyFile == "" {
+		return nil, nil
+	}
+	current, err := LoadMasterKey(encodedKey, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	previousKeys := make([]*MasterKey, 0, len(previous))
+	for _, encoded := range previous {
+		key, err := ParseMasterKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous master key: %w", err)
+		}
+		previousKeys = append(previousKeys, key)
+	}
+	return NewKeyring(current, previousKeys...), nil
+}
@@ -0,0 +1,174 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// segmentSize is the amount of plaintext sealed per AES-GCM segment when streaming.
+const segmentSize = 64 * 1024
+
+// noncePrefixSize leaves room in the 12 byte GCM nonce for a 4 byte segment counter and a final-segment flag.
+const noncePrefixSize = 7
+
+var ErrTruncatedStream = errors.New("encrypted stream is truncated")
+
+// segmentNonce derives the nonce for a segment. The final flag stops an attacker from
+// truncating the stream on a segment boundary without detection.
+func segmentNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type encryptingWriter struct {
+	w              io.Writer
+	aead           cipher.AEAD
+	prefix         []byte
+	additionalData []byte
+	counter        uint32
+	buf            []byte
+	closed         bool
+}
+
+// NewEncryptingWriter returns a writer that encrypts everything written to it into w as a
+// sequence of AES-GCM segments, so large payloads such as audio never have to be held in memory.
+// Close must be called to seal the final segment; it does not close w.
+func NewEncryptingWriter(w io.Writer, key, additionalData []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce prefix: %w", err)
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, fmt.Errorf("failed to write stream header: %w", err)
+	}
+	return &encryptingWriter{
+		w:              w,
+		aead:           aead,
+		prefix:         prefix,
+		additionalData: additionalData,
+		buf:            make([]byte, 0, segmentSize+1),
+	}, nil
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encrypting writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+		// A segment is only flushed once more data follows it, so the last one can always be marked final on Close.
+		if len(e.buf) > segmentSize {
+			if err := e.flush(e.buf[:segmentSize], false); err != nil {
+				return written, err
+			}
+			e.buf = append(e.buf[:0], e.buf[segmentSize:]...)
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptingWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.flush(e.buf, true)
+}
+
+func (e *encryptingWriter) flush(segment []byte, final bool) error {
+	sealed := e.aead.Seal(nil, segmentNonce(e.prefix, e.counter, final), segment, e.additionalData)
+	if _, err := e.w.Write(sealed); err != nil {
+		return fmt.Errorf("failed to write encrypted segment: %w", err)
+	}
+	e.counter++
+	return nil
+}
+
+type decryptingReader struct {
+	r              *bufio.Reader
+	aead           cipher.AEAD
+	prefix         []byte
+	additionalData []byte
+	counter        uint32
+	segment        []byte
+	plaintext      []byte
+	done           bool
+}
+
+// NewDecryptingReader returns a reader that decrypts a stream produced by NewEncryptingWriter.
+// Tampered, reordered or truncated streams fail with an error instead of returning partial plaintext silently.
+func NewDecryptingReader(r io.Reader, key, additionalData []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, fmt.Errorf("failed to read stream header: %w", err)
+	}
+	return &decryptingReader{
+		r:              bufio.NewReader(r),
+		aead:           aead,
+		prefix:         prefix,
+		additionalData: additionalData,
+		segment:        make([]byte, segmentSize+aead.Overhead()),
+	}, nil
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.plaintext) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plaintext)
+	d.plaintext = d.plaintext[n:]
+	return n, nil
+}
+
+func (d *decryptingReader) next() error {
+	n, err := io.ReadFull(d.r, d.segment)
+	final := false
+	switch {
+	case err == io.EOF:
+		return ErrTruncatedStream
+	case err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		return fmt.Errorf("failed to read encrypted segment: %w", err)
+	default:
+		if _, peekErr := d.r.Peek(1); peekErr == io.EOF {
+			final = true
+		}
+	}
+
+	plaintext, err := d.aead.Open(d.segment[:0], segmentNonce(d.prefix, d.counter, final), d.segment[:n], d.additionalData)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt segment %d: %w", d.counter, err)
+	}
+	d.counter++
+	d.plaintext = plaintext
+	d.done = final
+	return nil
+}
@@ -0,0 +1,198 @@
+package inferenceService
+
+import (
+	Chat "Medscribe/inference/store"
+	contextLogger "Medscribe/logger"
+	"Medscribe/readability"
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+	"Medscribe/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// Reading levels are US school grades.
+const (
+	DefaultReadingLevel = 6
+	MinReadingLevel     = 3
+	MaxReadingLevel     = 12
+)
+
+const (
+	// readingLevelTolerance is how far above the target grade a summary may score before it is simplified.
+	readingLevelTolerance = 1.0
+	// maxSimplificationAttempts caps the re-prompts spent simplifying a summary that reads too hard.
+	maxSimplificationAttempts = 2
+)
+
+const afterVisitSummarySystemPrompt = `
+You are writing an after-visit summary for a patient, on behalf of their provider, from the provider's clinical note.
+The patient will read it at home without the provider present, so it must be clear, warm and accurate.
+
+Rules:
+- Write for a reader at a US grade %d reading level: short sentences, everyday words, and no medical jargon. When a medical term is needed, explain it in plain words.
+- Write in %s, speaking to the patient as "you".
+- Use only what is in the clinical note. Never add diagnoses, medications, doses, dates or advice that are not in it.
+- Keep medication names and doses exactly as written.
+- If the note has nothing for a section, return an empty string for it.
+
+Return only a JSON object with these keys, each holding plain text with no markdown:
+"whatWeDiscussed": the main concerns from the visit and what the provider found.
+"medicationChanges": medicines that were started, stopped or changed, with how to take them.
+"nextAppointment": when to come back and any tests or referrals to schedule.
+"whenToSeekHelp": warning signs that mean the patient should call the office or get emergency care.
+`
+
+const simplifyAfterVisitSummaryPrompt = `
+This after-visit summary scores at a US grade %.1f reading level, but it must read at grade %d or below.
+Rewrite it with shorter sentences and simpler, shorter words. Keep every fact, medication name, dose and date, and keep the same JSON keys.
+
+%s
+`
+
+// GenerateAfterVisitSummary writes the patient-facing after-visit summary of a report at the given
+// reading level and stores it on the report. Summaries in English are scored with Flesch-Kincaid and
+// re-prompted for simpler wording while they read above the target level.
+func (s *inferenceService) GenerateAfterVisitSummary(ctx context.Context, reportID string, readingLevel int) (reports.AfterVisitSummary, error) {
+	logger := contextLogger.FromCtx(ctx)
+
+	if readingLevel == 0 {
+		readingLevel = DefaultReadingLevel
+	}
+	if readingLevel < MinReadingLevel || readingLevel > MaxReadingLevel {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: reading level must be between %d and %d", MinReadingLevel, MaxReadingLevel)
+	}
+
+	// Stage 1: Gather the clinician-reviewed note the summary is written from
+	report, err := s.reportsStore.Get(ctx, reportID)
+	if err != nil {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: error fetching report: %w", err)
+	}
+	note := afterVisitSummarySource(report)
+	if note == "" {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: report has no content to summarize")
+	}
+	language := report.PatientLanguage
+	if language == "" {
+		language = transcriber.LanguageEnglish
+	}
+
+	// Identifiers are replaced with placeholders before the note is sent, and restored in the summary once it is final.
+	pseudonyms, err := newPseudonymizer(&ReportRequest{PatientName: report.Name, Participants: report.Participants})
+	if err != nil {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: error pseudonymizing report: %w", err)
+	}
+	note, err = pseudonyms.pseudonymize(ctx, note)
+	if err != nil {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: error pseudonymizing note: %w", err)
+	}
+	if err := pseudonyms.checkLeaks(note); err != nil {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: %w", err)
+	}
+
+	// Stage 2: Generate the summary
+	logger.Info("GenerateAfterVisitSummary: generating summary", zap.String("ReportID", reportID), zap.Int("readingLevel", readingLevel), zap.String("language", language))
+	tokenUsage := utils.NewSafeMap[int]()
+	systemPrompt := fmt.Sprintf(afterVisitSummarySystemPrompt, readingLevel, transcriber.LanguageName(language))
+	if pseudonyms.hasPlaceholders() {
+		systemPrompt += pseudonymizedSystemPrompt
+	}
+	summary, tokens, err := s.queryAfterVisitSummary(ctx, systemPrompt, note)
+	if err != nil {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: %w", err)
+	}
+
+	// Stage 3: Simplify until it reads at the target level. The score only applies to English.
+	if language == transcriber.LanguageEnglish {
+		grade := readability.FleschKincaidGrade(afterVisitSummaryText(summary))
+		for attempt := 1; attempt <= maxSimplificationAttempts && grade > float64(readingLevel)+readingLevelTolerance; attempt++ {
+			logger.Info("GenerateAfterVisitSummary: simplifying summary", zap.Float64("grade", grade), zap.Int("attempt", attempt))
+			current, err := json.Marshal(summary)
+			if err != nil {
+				return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: error encoding summary: %w", err)
+			}
+			if err := pseudonyms.checkLeaks(string(current)); err != nil {
+				return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: %w", err)
+			}
+			simplified, simplifyTokens, err := s.queryAfterVisitSummary(ctx, systemPrompt, fmt.Sprintf(simplifyAfterVisitSummaryPrompt, grade, readingLevel, current))
+			tokens += simplifyTokens
+			if err != nil {
+				logger.Warn("GenerateAfterVisitSummary: keeping the summary that could not be simplified", zap.Error(err))
+				break
+			}
+			// Keep whichever version reads more easily.
+			if simplifiedGrade := readability.FleschKincaidGrade(afterVisitSummaryText(simplified)); simplifiedGrade < grade {
+				summary, grade = simplified, simplifiedGrade
+			}
+		}
+	}
+	summary.WhatWeDiscussed = pseudonyms.reidentify(summary.WhatWeDiscussed)
+	summary.MedicationChanges = pseudonyms.reidentify(summary.MedicationChanges)
+	summary.NextAppointment = pseudonyms.reidentify(summary.NextAppointment)
+	summary.WhenToSeekHelp = pseudonyms.reidentify(summary.WhenToSeekHelp)
+	if language == transcriber.LanguageEnglish {
+		summary.Grade = readability.FleschKincaidGrade(afterVisitSummaryText(summary))
+	}
+	summary.Language = language
+	summary.ReadingLevel = readingLevel
+	summary.GeneratedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	// Stage 4: Store the summary and its token usage
+	if err := s.reportsStore.SetAfterVisitSummary(ctx, reportID, summary); err != nil {
+		return reports.AfterVisitSummary{}, fmt.Errorf("GenerateAfterVisitSummary: error storing summary: %w", err)
+	}
+	tokenUsage.Set(reports.AfterVisitSummaryKey+"Tokens", tokens)
+	if err := s.recordTokenUsage(ctx, reportID, report.ProviderID, tokenUsage); err != nil {
+		logger.Error("GenerateAfterVisitSummary: error recording token usage", zap.Error(err))
+	}
+	return summary, nil
+}
+
+// queryAfterVisitSummary queries the chat model for a summary and parses its JSON sections.
+func (s *inferenceService) queryAfterVisitSummary(ctx context.Context, systemPrompt, prompt string) (reports.AfterVisitSummary, int, error) {
+	response, err := s.chat.Query(ctx, systemPrompt, prompt, Chat.MaxTokens)
+	if err != nil {
+		return reports.AfterVisitSummary{}, 0, fmt.Errorf("error querying chat model: %w", err)
+	}
+	var summary reports.AfterVisitSummary
+	if err := json.Unmarshal([]byte(stripCodeFence(response.Content)), &summary); err != nil {
+		return reports.AfterVisitSummary{}, response.Usage.TotalTokens, fmt.Errorf("error parsing summary: %w", err)
+	}
+	if strings.TrimSpace(summary.WhatWeDiscussed) == "" {
+		return reports.AfterVisitSummary{}, response.Usage.TotalTokens, fmt.Errorf("summary has no discussion section")
+	}
+	return summary, response.Usage.TotalTokens, nil
+}
+
+// afterVisitSummarySource assembles the sections of a report the after-visit summary draws on.
+func afterVisitSummarySource(report reports.Report) string {
+	sections := []struct {
+		title   string
+		content string
+	}{
+		{"Summary", report.Summary.Data},
+		{"Subjective", report.Subjective.Data},
+		{"Assessment and Plan", report.AssessmentAndPlan.Data},
+		{"Patient Instructions", report.PatientInstructions.Data},
+	}
+
+	var source strings.Builder
+	for _, section := range sections {
+		if strings.TrimSpace(section.content) == "" {
+			continue
+		}
+		source.WriteString(fmt.Sprintf("%s:\n%s\n\n", section.title, section.content))
+	}
+	return strings.TrimSpace(source.String())
+}
+
+// afterVisitSummaryText joins the sections of a summary for scoring.
+func afterVisitSummaryText(summary reports.AfterVisitSummary) string {
+	return strings.Join([]string{summary.WhatWeDiscussed, summary.MedicationChanges, summary.NextAppointment, summary.WhenToSeekHelp}, "\n")
+}
@@ -0,0 +1,29 @@
+package inferenceService
+
+import (
+	"Medscribe/reports"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAfterVisitSummarySource(t *testing.T) {
+	report := reports.Report{
+		Subjective:          reports.ReportContent{Data: "Headaches for two weeks."},
+		AssessmentAndPlan:   reports.ReportContent{Data: "Tension headache. Start ibuprofen 400 mg as needed."},
+		PatientInstructions: reports.ReportContent{Data: "  "},
+	}
+
+	assert.Equal(t, "Subjective:\nHeadaches for two weeks.\n\nAssessment and Plan:\nTension headache. Start ibuprofen 400 mg as needed.", afterVisitSummarySource(report))
+	assert.Equal(t, "", afterVisitSummarySource(reports.Report{}))
+}
+
+func TestAfterVisitSummaryText(t *testing.T) {
+	summary := reports.AfterVisitSummary{
+		WhatWeDiscussed:   "You have headaches.",
+		MedicationChanges: "Take ibuprofen when you need it.",
+		WhenToSeekHelp:    "Call us if you feel worse.",
+	}
+
+	assert.Equal(t, "You have headaches.\nTake ibuprofen when you need it.\n\nCall us if you feel worse.", afterVisitSummaryText(summary))
+}
@@ -0,0 +1,164 @@
+package inferenceService
+
+import (
+	"Medscribe/audio"
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+	"Medscribe/utils"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// AppendRecording adds another recording of the same visit to an existing report, e.g. after an
+// interruption. The recording is retained and transcribed the same way as the report's transcript,
+// merged after it on the visit timeline, and the transcript-derived sections are regenerated
+// against the combined transcript.
+func (s *inferenceService) AppendRecording(ctx context.Context, reportRequest *ReportRequest, w *utils.SafeResponseWriter) error {
+	logger := contextLogger.FromCtx(ctx)
+
+	// Stage 1: Load the report and its transcript
+	logger.Info("AppendRecording: loading report", zap.String("report_id", reportRequest.ID))
+	report, err := s.reportsStore.Get(ctx, reportRequest.ID)
+	if err != nil {
+		return fmt.Errorf("AppendRecording: error fetching report: %w", err)
+	}
+	transcripts, err := s.reportsStore.GetTranscription(ctx, reportRequest.ID)
+	if err != nil {
+		return fmt.Errorf("AppendRecording: error fetching transcript: %w", err)
+	}
+	reportRequest.Participants = report.Participants
+	duration, err := recordingDuration(reportRequest)
+	if err != nil {
+		return fmt.Errorf("AppendRecording: %w", err)
+	}
+
+	// Stage 2: Retain the new recording. Like the first one, failing to retain it does not stop the append.
+	segment := reports.AudioSegment{
+		Offset:     report.Duration,
+		Duration:   duration,
+		RecordedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if blob, err := s.saveAudio(ctx, reportRequest.ID, reportRequest, report.LegalHold != nil); err != nil {
+		logger.Error("AppendRecording: error storing audio", zap.String("ReportID", reportRequest.ID), zap.Error(err))
+	} else {
+		segment.AudioBlobID = blob.ID.Hex()
+	}
+	// Until the merged transcript is stored nothing references the new recording, so it is removed
+	// if the append fails before then.
+	appended := false
+	defer func() {
+		if appended || segment.AudioBlobID == "" {
+			return
+		}
+		if err := s.audioStore.Delete(ctx, segment.AudioBlobID); err != nil {
+			logger.Error("AppendRecording: error deleting unused audio", zap.String("AudioBlobID", segment.AudioBlobID), zap.Error(err))
+		}
+	}()
+
+	// Stage 3: Transcribe the recording in the report's transcript mode and merge it after the stored transcript
+	logger.Info("AppendRecording: transcribing appended recording")
+	transcriptionAudio, timeMap := s.trimSilence(ctx, reportRequest)
+	segment.TrimmedDuration = reportRequest.TrimmedDuration
+
+	var merged string
+	var mergedTurns []transcriber.TranscriptTurn
+	if transcripts.UsedDiarization {
+		rawTranscript, err := s.processWithDiarization(ctx, reportRequest, transcriptionAudio, timeMap)
+		if err != nil {
+			return fmt.Errorf("AppendRecording: error creating transcript: %w", err)
+		}
+		appendedTurns, err := transcriber.StringToDiarizedTranscript(rawTranscript)
+		if err != nil {
+			return fmt.Errorf("AppendRecording: error unmarshaling diarized transcript: %w", err)
+		}
+		// Diarization numbers the speakers of every recording afresh, so their labels are qualified with the recording
+		appendedTurns = transcriber.PrefixSpeakerLabels(appendedTurns, fmt.Sprintf("recording%d/", max(len(report.AudioSegments), 1)+1))
+		mergedTurns = append(append([]transcriber.TranscriptTurn{}, transcripts.DiarizedTranscript...), transcriber.OffsetTurns(appendedTurns, report.Duration)...)
+		merged, err = transcriber.DiarizedTranscriptToString(mergedTurns)
+		if err != nil {
+			return fmt.Errorf("AppendRecording: error serializing merged transcript: %w", err)
+		}
+	} else {
+		rawTranscript, err := s.processWithoutDiarization(ctx, reportRequest, transcriptionAudio)
+		if err != nil {
+			return fmt.Errorf("AppendRecording: error creating transcript: %w", err)
+		}
+		merged = strings.TrimSpace(transcripts.Transcript + "\n\n" + rawTranscript)
+	}
+
+	// The first recording is recorded as a segment too once the report has more than one.
+	segments := []reports.AudioSegment{segment}
+	if len(report.AudioSegments) == 0 {
+		first := reports.AudioSegment{
+			AudioBlobID:     report.AudioBlobID,
+			Duration:        report.Duration,
+			TrimmedDuration: report.TrimmedDuration,
+			RecordedAt:      report.TimeStamp,
+		}
+		segments = append([]reports.AudioSegment{first}, segments...)
+	}
+	combinedDuration := report.Duration + duration
+	version, err := s.reportsStore.AppendRecording(ctx, reportRequest.ID, report.Version, report.Duration, combinedDuration, merged, segments...)
+	if err != nil {
+		return fmt.Errorf("AppendRecording: error storing merged transcript: %w", err)
+	}
+	appended = true
+	// Until regeneration finishes the sections no longer reflect the whole visit.
+	if err := s.reportsStore.MarkSectionsStale(ctx, reportRequest.ID, reports.TranscriptDerivedSections...); err != nil {
+		logger.Error("AppendRecording: error marking sections stale", zap.Error(err))
+	}
+
+	sendContentToFrontend(w, ContentChanPayload{
+		Key: reports.Transcript,
+		Value: reports.RetrievedReportTranscripts{
+			Transcript:         merged,
+			DiarizedTranscript: mergedTurns,
+			SpeakerMapping:     transcriber.SpeakerMapping(mergedTurns),
+			ProviderID:         reportRequest.ProviderID,
+			UsedDiarization:    transcripts.UsedDiarization,
+			LowConfidenceSpans: transcriber.LowConfidenceSpans(mergedTurns, transcriber.DefaultConfidenceThreshold),
+			Version:            version,
+		},
+	})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.Duration, Value: combinedDuration})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.AudioSegments, Value: append(report.AudioSegments, segments...)})
+
+	// Stage 4: Regenerate every transcript-derived section against the combined transcript
+	logger.Info("AppendRecording: regenerating report sections", zap.String("report_id", reportRequest.ID))
+	return s.RegenerateFromTranscript(ctx, &ReportRequest{
+		ID:           reportRequest.ID,
+		ProviderID:   reportRequest.ProviderID,
+		VisitContext: reportRequest.VisitContext,
+		Sections:     regeneratedSections(report),
+		Version:      version,
+	}, w)
+}
+
+// regeneratedSections lists every transcript-derived section of the report, including the member
+// notes of a group session.
+func regeneratedSections(report reports.Report) []string {
+	sections := append([]string{}, reports.TranscriptDerivedSections...)
+	if transcriber.IsGroupSession(report.Participants) {
+		sections = append(sections, reports.MemberNotes)
+	}
+	return sections
+}
+
+// recordingDuration is the length of the request's recording in seconds, as reported by the
+// client or read from PCM audio when the client did not send one.
+func recordingDuration(reportRequest *ReportRequest) (float64, error) {
+	if reportRequest.Duration > 0 {
+		return reportRequest.Duration, nil
+	}
+	pcm, err := audio.ParseWAV(reportRequest.AudioBytes)
+	if err != nil {
+		return 0, fmt.Errorf("recording duration is required for this audio format")
+	}
+	return pcm.Duration(), nil
+}
@@ -0,0 +1,66 @@
+package inferenceService
+
+import (
+	"Medscribe/audioStore"
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+	"Medscribe/user"
+	"Medscribe/utils"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newAppendTestService() (*inferenceService, *reports.MockReportsStore, *audioStore.MockAudioStore, *transcriber.MockTranscription) {
+	store := new(reports.MockReportsStore)
+	audio := new(audioStore.MockAudioStore)
+	transcription := new(transcriber.MockTranscription)
+	users := new(user.MockUserStore)
+	users.On("Get", mock.Anything, "provider-1").Return(user.User{}, nil)
+	return &inferenceService{reportsStore: store, audioStore: audio, transcriptionService: transcription, userStore: users}, store, audio, transcription
+}
+
+func TestAppendRecordingDeletesAudioOfFailedAppend(t *testing.T) {
+	ctx := context.Background()
+	reportID := primitive.NewObjectID().Hex()
+	blob := audioStore.AudioBlob{ID: primitive.NewObjectID()}
+	request := func() *ReportRequest {
+		return &ReportRequest{ID: reportID, ProviderID: "provider-1", Duration: 60, AudioBytes: []byte("audio")}
+	}
+
+	t.Run("merged transcript cannot be stored", func(t *testing.T) {
+		s, store, audio, transcription := newAppendTestService()
+		store.On("Get", ctx, reportID).Return(reports.Report{Version: 3, Duration: 120}, nil)
+		store.On("GetTranscription", ctx, reportID).Return(reports.RetrievedReportTranscripts{Transcript: "Earlier."}, nil)
+		audio.On("Save", ctx, mock.Anything, "provider-1", mock.Anything, mock.Anything, (*int)(nil), false).Return(blob, nil)
+		transcription.On("Transcribe", ctx, mock.Anything, mock.Anything).Return("Later.", nil)
+		store.On("AppendRecording", ctx, reportID, int64(3), 120.0, 180.0, "Earlier.\n\nLater.", mock.Anything).Return(int64(0), &reports.VersionConflictError{Current: 4})
+		audio.On("Delete", ctx, blob.ID.Hex()).Return(nil).Once()
+
+		err := s.AppendRecording(ctx, request(), &utils.SafeResponseWriter{ResponseWriter: httptest.NewRecorder()})
+
+		var conflict *reports.VersionConflictError
+		assert.ErrorAs(t, err, &conflict)
+		audio.AssertExpectations(t)
+	})
+
+	t.Run("recording cannot be transcribed", func(t *testing.T) {
+		s, store, audio, transcription := newAppendTestService()
+		store.On("Get", ctx, reportID).Return(reports.Report{Version: 3, Duration: 120}, nil)
+		store.On("GetTranscription", ctx, reportID).Return(reports.RetrievedReportTranscripts{Transcript: "Earlier."}, nil)
+		audio.On("Save", ctx, mock.Anything, "provider-1", mock.Anything, mock.Anything, (*int)(nil), false).Return(blob, nil)
+		transcription.On("Transcribe", ctx, mock.Anything, mock.Anything).Return("", errors.New("backend unavailable"))
+		audio.On("Delete", ctx, blob.ID.Hex()).Return(nil).Once()
+
+		err := s.AppendRecording(ctx, request(), &utils.SafeResponseWriter{ResponseWriter: httptest.NewRecorder()})
+
+		assert.Error(t, err)
+		audio.AssertExpectations(t)
+		store.AssertNotCalled(t, "AppendRecording", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
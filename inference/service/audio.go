@@ -0,0 +1,100 @@
+package inferenceService
+
+import (
+	"Medscribe/audio"
+	"Medscribe/audioStore"
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	"Medscribe/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// defaultAudioContentType is assumed when the upload did not declare one.
+const defaultAudioContentType = "audio/wav"
+
+// audioQualityWarningKey is the event sent to the client when the recording is likely to transcribe poorly.
+const audioQualityWarningKey = "audioQualityWarning"
+
+// retainAudio stores the encrypted recording of the visit and links it to the report, so a failed
+// report can be retried or the transcript re-derived later. Failing to retain the audio is logged
+// but does not stop the report from being generated.
+func (s *inferenceService) retainAudio(ctx context.Context, reportID string, reportRequest *ReportRequest, w *utils.SafeResponseWriter) {
+	logger := contextLogger.FromCtx(ctx)
+
+	// A legal hold may have been placed on the report since the pipeline created it.
+	var held bool
+	if report, err := s.reportsStore.Get(ctx, reportID); err != nil {
+		logger.Warn("retainAudio: could not check legal hold", zap.String("ReportID", reportID), zap.Error(err))
+	} else {
+		held = report.LegalHold != nil
+	}
+
+	blob, err := s.saveAudio(ctx, reportID, reportRequest, held)
+	if err != nil {
+		logger.Error("retainAudio: error storing audio", zap.String("ReportID", reportID), zap.Error(err))
+		return
+	}
+	if err := s.reportsStore.SetAudioBlobID(ctx, reportID, blob.ID.Hex()); err != nil {
+		logger.Error("retainAudio: error linking audio to report", zap.String("ReportID", reportID), zap.Error(err))
+		return
+	}
+
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.AudioBlobID, Value: blob.ID.Hex()})
+	logger.Info("retainAudio: audio retained", zap.String("ReportID", reportID), zap.String("AudioBlobID", blob.ID.Hex()))
+}
+
+// saveAudio stores the request's recording for the report under the provider's retention period, held
+// when the report is under a legal hold.
+func (s *inferenceService) saveAudio(ctx context.Context, reportID string, reportRequest *ReportRequest, legalHold bool) (audioStore.AudioBlob, error) {
+	logger := contextLogger.FromCtx(ctx)
+
+	reportObjectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return audioStore.AudioBlob{}, fmt.Errorf("invalid report id: %w", err)
+	}
+
+	var retentionDays *int
+	if provider, err := s.userStore.Get(ctx, reportRequest.ProviderID); err != nil {
+		logger.Warn("saveAudio: using default audio retention", zap.Error(err))
+	} else {
+		retentionDays = provider.AudioRetentionDays
+	}
+
+	contentType := reportRequest.AudioContentType
+	if contentType == "" {
+		contentType = defaultAudioContentType
+	}
+
+	return s.audioStore.Save(ctx, reportObjectID, reportRequest.ProviderID, contentType, bytes.NewReader(reportRequest.AudioBytes), retentionDays, legalHold)
+}
+
+// checkAudioQuality analyzes the recording before it is transcribed, stores the summary on the report
+// and warns the client when the recording is likely to give an unreliable transcript, so a bad
+// recording is caught before LLM tokens are spent on it. Generation continues either way.
+func (s *inferenceService) checkAudioQuality(ctx context.Context, reportID string, reportRequest *ReportRequest, w *utils.SafeResponseWriter) {
+	logger := contextLogger.FromCtx(ctx)
+
+	quality, err := audio.AnalyzeWAV(reportRequest.AudioBytes)
+	if err != nil {
+		if !errors.Is(err, audio.ErrUnsupportedFormat) {
+			logger.Warn("checkAudioQuality: could not analyze audio", zap.String("ReportID", reportID), zap.Error(err))
+		}
+		return
+	}
+
+	if err := s.reportsStore.SetAudioQuality(ctx, reportID, quality); err != nil {
+		logger.Error("checkAudioQuality: error storing audio quality", zap.String("ReportID", reportID), zap.Error(err))
+	}
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.AudioQuality, Value: quality})
+
+	if !quality.Reliable() {
+		logger.Warn("checkAudioQuality: recording may give an unreliable transcript", zap.String("ReportID", reportID), zap.Any("Warnings", quality.Warnings))
+		sendContentToFrontend(w, ContentChanPayload{Key: audioQualityWarningKey, Value: quality.Warnings})
+	}
+}
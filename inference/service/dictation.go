@@ -0,0 +1,111 @@
+package inferenceService
+
+import (
+	"Medscribe/reports"
+	"regexp"
+	"strings"
+)
+
+// dictationCues maps the spoken headings a provider may use while dictating to the report
+// section they introduce. Longer phrases come first so "assessment and plan" wins over "assessment".
+var dictationCues = []struct {
+	phrase  string
+	section string
+}{
+	{`assessment\s+(?:and|&)\s+plan`, reports.AssessmentAndPlan},
+	{`history\s+of\s+(?:the\s+)?present\s+illness`, reports.Subjective},
+	{`physical\s+exam(?:ination)?`, reports.Objective},
+	{`patient\s+instructions`, reports.PatientInstructions},
+	{`discharge\s+instructions`, reports.PatientInstructions},
+	{`chief\s+complaint`, reports.Subjective},
+	{`subjective`, reports.Subjective},
+	{`objective`, reports.Objective},
+	{`assessment`, reports.AssessmentAndPlan},
+	{`impression`, reports.AssessmentAndPlan},
+	{`plan`, reports.AssessmentAndPlan},
+	{`instructions`, reports.PatientInstructions},
+	{`summary`, reports.Summary},
+}
+
+// dictationCuePattern matches a spoken heading followed by a written or spoken colon,
+// e.g. "Assessment: ..." or "assessment colon ...". Requiring the colon keeps ordinary
+// sentences such as "the plan is to follow up" from being treated as headings.
+var dictationCuePattern = func() *regexp.Regexp {
+	phrases := make([]string, len(dictationCues))
+	for i, cue := range dictationCues {
+		phrases[i] = "(" + cue.phrase + ")"
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(phrases, "|") + `)\s*(?::|,?\s+colon\b[:.,]?)`)
+}()
+
+// DictatedSections is a dictation split on its spoken section cues.
+type DictatedSections struct {
+	// Sections holds the content dictated under each section's cues, keyed by section name.
+	Sections map[string]string
+	// Unrouted is the narration dictated before the first cue.
+	Unrouted string
+}
+
+// SplitDictation routes the content following each spoken section cue to the matching report section.
+// Content runs until the next cue; several cues for the same section are joined in the order
+// they were dictated, each under its spoken heading. A dictation without cues is returned unrouted.
+func SplitDictation(dictation string) DictatedSections {
+	split := DictatedSections{Sections: map[string]string{}}
+	matches := dictationCuePattern.FindAllStringSubmatchIndex(dictation, -1)
+	if len(matches) == 0 {
+		split.Unrouted = strings.TrimSpace(dictation)
+		return split
+	}
+
+	split.Unrouted = strings.TrimSpace(dictation[:matches[0][0]])
+	for i, match := range matches {
+		end := len(dictation)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		content := strings.TrimSpace(dictation[match[1]:end])
+		if content == "" {
+			continue
+		}
+
+		heading, section := matchedCue(dictation, match)
+		entry := headingCase(heading) + ": " + content
+		if existing := split.Sections[section]; existing != "" {
+			entry = existing + "\n" + entry
+		}
+		split.Sections[section] = entry
+	}
+	return split
+}
+
+// matchedCue returns the spoken heading of a cue match and the section it belongs to.
+// Each cue phrase is its own capture group, so the group that matched identifies the cue.
+func matchedCue(dictation string, match []int) (string, string) {
+	for i, cue := range dictationCues {
+		start, end := match[2*i+2], match[2*i+3]
+		if start >= 0 {
+			return dictation[start:end], cue.section
+		}
+	}
+	return "", ""
+}
+
+// headingCase normalizes a spoken heading for display, e.g. "ASSESSMENT  and plan" becomes "Assessment and plan".
+func headingCase(heading string) string {
+	heading = strings.ToLower(strings.Join(strings.Fields(heading), " "))
+	return strings.ToUpper(heading[:1]) + heading[1:]
+}
+
+// dictatedSectionTranscript returns what a section is generated from in dictation mode. A section
+// with spoken cues is generated only from the content dictated under them, plus any narration
+// preceding the first cue; other sections draw on the whole dictation.
+func dictatedSectionTranscript(split DictatedSections, dictation, section string) (string, bool) {
+	content, ok := split.Sections[section]
+	if !ok {
+		return dictation, false
+	}
+	if split.Unrouted != "" {
+		content = split.Unrouted + "\n" + content
+	}
+	return content, true
+}
@@ -0,0 +1,92 @@
+package inferenceService
+
+import (
+	"Medscribe/reports"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitDictation(t *testing.T) {
+	tests := []struct {
+		name      string
+		dictation string
+		sections  map[string]string
+		unrouted  string
+	}{
+		{
+			name:      "no cues",
+			dictation: " I saw the patient today for follow up. The plan is to continue metformin. ",
+			sections:  map[string]string{},
+			unrouted:  "I saw the patient today for follow up. The plan is to continue metformin.",
+		},
+		{
+			name:      "spoken colons",
+			dictation: "Subjective colon she reports two weeks of cough. Objective colon lungs are clear. Assessment colon acute bronchitis.",
+			sections: map[string]string{
+				reports.Subjective:        "Subjective: she reports two weeks of cough.",
+				reports.Objective:         "Objective: lungs are clear.",
+				reports.AssessmentAndPlan: "Assessment: acute bronchitis.",
+			},
+		},
+		{
+			name:      "written colons and preamble",
+			dictation: "This is a dictation for John. SUBJECTIVE: knee pain. Physical exam: mild effusion.",
+			sections: map[string]string{
+				reports.Subjective: "Subjective: knee pain.",
+				reports.Objective:  "Physical exam: mild effusion.",
+			},
+			unrouted: "This is a dictation for John.",
+		},
+		{
+			name:      "assessment and plan wins over assessment",
+			dictation: "Assessment and plan, colon. Hypertension, increase lisinopril.",
+			sections: map[string]string{
+				reports.AssessmentAndPlan: "Assessment and plan: Hypertension, increase lisinopril.",
+			},
+		},
+		{
+			name:      "several cues for one section are joined",
+			dictation: "Assessment: migraine. Plan: start sumatriptan. Patient instructions: keep a headache diary.",
+			sections: map[string]string{
+				reports.AssessmentAndPlan:   "Assessment: migraine.\nPlan: start sumatriptan.",
+				reports.PatientInstructions: "Patient instructions: keep a headache diary.",
+			},
+		},
+		{
+			name:      "cue without content is skipped",
+			dictation: "Objective colon Summary colon routine visit.",
+			sections: map[string]string{
+				reports.Summary: "Summary: routine visit.",
+			},
+		},
+		{
+			name:      "heading words without a colon stay in the content",
+			dictation: "Subjective: the plan we discussed last time helped.",
+			sections: map[string]string{
+				reports.Subjective: "Subjective: the plan we discussed last time helped.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split := SplitDictation(tt.dictation)
+			assert.Equal(t, tt.sections, split.Sections)
+			assert.Equal(t, tt.unrouted, split.Unrouted)
+		})
+	}
+}
+
+func TestDictatedSectionTranscript(t *testing.T) {
+	dictation := "Dictating for Jane. Assessment: sinusitis."
+	split := SplitDictation(dictation)
+
+	transcript, routed := dictatedSectionTranscript(split, dictation, reports.AssessmentAndPlan)
+	assert.True(t, routed)
+	assert.Equal(t, "Dictating for Jane.\nAssessment: sinusitis.", transcript)
+
+	transcript, routed = dictatedSectionTranscript(split, dictation, reports.Summary)
+	assert.False(t, routed)
+	assert.Equal(t, dictation, transcript)
+}
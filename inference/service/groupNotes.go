@@ -0,0 +1,133 @@
+package inferenceService
+
+import (
+	Chat "Medscribe/inference/store"
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+	"Medscribe/utils"
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const groupMemberTaskDescription = `
+Generate an **individual progress note for one member of a group therapy session** based on the provided transcript. The group's shared sections are written separately; this note documents only the member named in the request.
+
+**Content Requirements:**
+- Describe the member's participation: how engaged they were, the topics they raised and how they responded to other members and to the facilitator.
+- Document the member's own statements about symptoms, mood, progress toward goals, stressors and coping, attributing them to the member.
+- Note interventions the facilitator directed at this member and the member's response, and any risk concerns the member voiced.
+- Mention other members only as needed to give context to this member's participation, without names or identifying details.
+- If the member did not speak, document only that they attended without verbal participation, if the transcript shows they were present.
+
+**Formatting and Style:**
+- Write concise clinical prose in the third person, in the order above.
+- Output MUST be plain text without any markdown formatting.
+- Adhere strictly to the OMISSION RULE: Do not include information not present in the transcript. Do not use placeholders like 'N/A'.
+`
+
+const groupMemberNotePromptTemplate = `
+--- BACKGROUND CONTEXT (FOR YOUR INFORMATION ONLY - DO NOT INCLUDE IN OUTPUT) ---
+Provider Name: %s
+Group Member: %s (speaker label %s)
+--- END BACKGROUND CONTEXT ---
+
+--- SESSION PARTICIPANTS (the speaker labels in the transcript are these IDs) ---
+%s--- END SESSION PARTICIPANTS ---
+
+--- TRANSCRIPT ---
+%s
+--- END TRANSCRIPT ---
+
+GENERATE ONLY THE INDIVIDUAL NOTE FOR %s. Do NOT include a title or heading. Your response should contain ONLY the narrative text.
+`
+
+// generateMemberNotes writes one note per patient of a group session from the shared transcript,
+// concurrently, and sends the finished notes to the client in roster order. Like the shared sections,
+// the prompts carry placeholders in place of identifiers, restored in the notes.
+func (s *inferenceService) generateMemberNotes(
+	ctx context.Context,
+	reportRequest *ReportRequest,
+	w *utils.SafeResponseWriter,
+	tokenUsage *utils.SafeMap[int],
+) ([]reports.MemberNote, error) {
+	logger := contextLogger.FromCtx(ctx)
+	members := transcriber.RosterPatients(reportRequest.Participants)
+	logger.Info("generateMemberNotes: generating group member notes", zap.Int("members", len(members)))
+
+	pseudonyms, err := newPseudonymizer(reportRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing report request: %w", err)
+	}
+	transcript, err := pseudonyms.pseudonymize(ctx, reportRequest.TranscribedAudio)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing transcript: %w", err)
+	}
+	participants, err := pseudonyms.pseudonymizeRoster(ctx, reportRequest.Participants)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing participants: %w", err)
+	}
+	roster := transcriber.FormatRoster(participants)
+	if err := pseudonyms.checkLeaks(transcript, roster); err != nil {
+		return nil, fmt.Errorf("error generating group member notes: %w", err)
+	}
+
+	systemPrompt := fmt.Sprintf("%s\n%s\n%s\n%s", baseSystemPrompt, groupMemberTaskDescription, defaultReturnFormatSystemPrompt, defaultWarningsSystemPrompt)
+	if pseudonyms.hasPlaceholders() {
+		systemPrompt += pseudonymizedSystemPrompt
+	}
+
+	// The pseudonymized roster lists the members in the same order.
+	pseudonymizedMembers := transcriber.RosterPatients(participants)
+	notes := make([]reports.MemberNote, len(members))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, member := range members {
+		g.Go(func() error {
+			name := pseudonymizedMembers[i].DisplayName()
+			prompt := fmt.Sprintf(groupMemberNotePromptTemplate,
+				reportRequest.ProviderName, name, member.ID, roster, transcript, name)
+			response, err := s.chat.Query(ctx, systemPrompt, prompt, Chat.MaxTokens)
+			if err != nil {
+				return fmt.Errorf("error generating note for group member %s: %w", member.ID, err)
+			}
+			tokenUsage.Set(member.ID+"NoteTokens", response.Usage.TotalTokens)
+			notes[i] = reports.MemberNote{
+				ParticipantID: member.ID,
+				Name:          member.Name,
+				Note:          reports.ReportContent{Data: pseudonyms.reidentify(response.Content)},
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.MemberNotes, Value: notes})
+	return notes, nil
+}
+
+// storeMemberNotes generates and stores the member notes of a group session. Sessions that are not
+// group sessions have no member notes.
+func (s *inferenceService) storeMemberNotes(
+	ctx context.Context,
+	reportID string,
+	reportRequest *ReportRequest,
+	w *utils.SafeResponseWriter,
+	tokenUsage *utils.SafeMap[int],
+) error {
+	if !transcriber.IsGroupSession(reportRequest.Participants) {
+		return nil
+	}
+	notes, err := s.generateMemberNotes(ctx, reportRequest, w, tokenUsage)
+	if err != nil {
+		return err
+	}
+	if err := s.reportsStore.SetMemberNotes(ctx, reportID, notes); err != nil {
+		return fmt.Errorf("error storing member notes: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package inferenceService
 
 import (
+	"Medscribe/reports"
+	"Medscribe/utils"
 	"context"
 
 	"github.com/stretchr/testify/mock"
@@ -10,13 +12,23 @@ type MockInferenceService struct {
 	mock.Mock
 }
 
-func (m *MockInferenceService) GenerateReportPipeline(ctx context.Context, report *ReportRequest, contentChan chan ContentChanPayload) error {
-	args := m.Called(report, contentChan)
+func (m *MockInferenceService) GenerateReportPipeline(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error {
+	args := m.Called(ctx, report, w)
 	return args.Error(0)
 }
 
-func (m *MockInferenceService) RegenerateReport(ctx context.Context, contentChan chan ContentChanPayload, report *ReportRequest) error {
-	args := m.Called(ctx, contentChan, report)
+func (m *MockInferenceService) RegenerateReport(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error {
+	args := m.Called(ctx, report, w)
+	return args.Error(0)
+}
+
+func (m *MockInferenceService) AppendRecording(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error {
+	args := m.Called(ctx, report, w)
+	return args.Error(0)
+}
+
+func (m *MockInferenceService) RegenerateFromTranscript(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error {
+	args := m.Called(ctx, report, w)
 	return args.Error(0)
 }
 
@@ -24,3 +36,8 @@ func (m *MockInferenceService) LearnStyle(ctx context.Context, reportID, content
 	args := m.Called(ctx, reportID, contentSection, content)
 	return args.Error(0)
 }
+
+func (m *MockInferenceService) GenerateAfterVisitSummary(ctx context.Context, reportID string, readingLevel int) (reports.AfterVisitSummary, error) {
+	args := m.Called(ctx, reportID, readingLevel)
+	return args.Get(0).(reports.AfterVisitSummary), args.Error(1)
+}
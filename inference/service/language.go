@@ -0,0 +1,125 @@
+package inferenceService
+
+import (
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// expectedLanguages lists the languages transcription should prepare for. It is empty, meaning
+// English, when neither the note nor the patient language was chosen.
+func (r *ReportRequest) expectedLanguages() []string {
+	languages := make([]string, 0, 2)
+	for _, language := range []string{r.NoteLanguage, r.PatientLanguage} {
+		if language != "" && (len(languages) == 0 || languages[0] != language) {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// detectLanguages records the languages spoken in the transcript and settles the note and patient
+// languages the request left open. The note language follows what the provider spoke (all of a
+// dictation), defaulting to English; the patient language follows what the patient spoke or, without
+// diarization, the most spoken language other than the note language.
+func (r *ReportRequest) detectLanguages(transcript string, turns []transcriber.TranscriptTurn) {
+	var detection transcriber.LanguageDetection
+	speakers := map[string]string{}
+	if len(turns) > 0 {
+		detection = transcriber.DetectTurnsLanguage(turns)
+		speakers = transcriber.SpeakerLanguages(turns)
+	} else {
+		detection = transcriber.DetectLanguage(transcript)
+	}
+	r.TranscriptLanguages = detection.Codes()
+
+	if r.NoteLanguage == "" {
+		r.NoteLanguage = transcriber.LanguageEnglish
+		if language, ok := speakers[transcriber.RoleProvider]; ok {
+			r.NoteLanguage = language
+		} else if r.dictated() && detection.Primary != "" {
+			r.NoteLanguage = detection.Primary
+		}
+	}
+
+	if r.PatientLanguage == "" {
+		r.PatientLanguage = r.NoteLanguage
+		if language, ok := r.patientSpeakerLanguage(speakers); ok {
+			r.PatientLanguage = language
+		} else if len(turns) == 0 && !r.dictated() {
+			for _, language := range r.TranscriptLanguages {
+				if language != r.NoteLanguage {
+					r.PatientLanguage = language
+					break
+				}
+			}
+		}
+	}
+}
+
+// patientSpeakerLanguage returns the language the patient spoke, taking the first patient of the
+// roster for sessions with one.
+func (r *ReportRequest) patientSpeakerLanguage(speakers map[string]string) (string, bool) {
+	if language, ok := speakers[transcriber.RolePatient]; ok {
+		return language, true
+	}
+	for _, patient := range transcriber.RosterPatients(r.Participants) {
+		if language, ok := speakers[patient.ID]; ok {
+			return language, true
+		}
+	}
+	return "", false
+}
+
+// sectionLanguage returns the name of the language a section is written in, or an empty string
+// when the visit is English only and the default prompts apply.
+func (r *ReportRequest) sectionLanguage(section string) string {
+	if !r.multilingual() {
+		return ""
+	}
+	language := r.NoteLanguage
+	if section == reports.PatientInstructions && r.PatientLanguage != "" {
+		language = r.PatientLanguage
+	}
+	if language == "" {
+		language = transcriber.LanguageEnglish
+	}
+	return transcriber.LanguageName(language)
+}
+
+// multilingual reports whether anything but English was spoken or requested.
+func (r *ReportRequest) multilingual() bool {
+	for _, language := range append([]string{r.NoteLanguage, r.PatientLanguage}, r.TranscriptLanguages...) {
+		if language != "" && language != transcriber.LanguageEnglish {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionUpdates returns the metadata updates that apply to a section being rewritten. A new patient
+// language only applies to the patient instructions and a new note language to every other section;
+// language codes are spelled out for the prompt.
+func sectionUpdates(updates bson.D, section string) bson.D {
+	if updates == nil {
+		return nil
+	}
+	filtered := bson.D{}
+	for _, update := range updates {
+		switch update.Key {
+		case reports.PatientLanguage:
+			if section != reports.PatientInstructions {
+				continue
+			}
+			update = bson.E{Key: "language", Value: transcriber.LanguageName(update.Value.(string))}
+		case reports.NoteLanguage:
+			if section == reports.PatientInstructions {
+				continue
+			}
+			update = bson.E{Key: "language", Value: transcriber.LanguageName(update.Value.(string))}
+		}
+		filtered = append(filtered, update)
+	}
+	return filtered
+}
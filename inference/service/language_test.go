@@ -0,0 +1,102 @@
+package inferenceService
+
+import (
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDetectLanguages(t *testing.T) {
+	spanishVisit := []transcriber.TranscriptTurn{
+		{Speaker: transcriber.RoleProvider, Text: "How have you been sleeping? Any side effects from the medication?"},
+		{Speaker: transcriber.RolePatient, Text: "No duermo bien y tengo dolor de cabeza por las noches."},
+	}
+
+	tests := []struct {
+		name            string
+		request         ReportRequest
+		transcript      string
+		turns           []transcriber.TranscriptTurn
+		noteLanguage    string
+		patientLanguage string
+		detected        []string
+	}{
+		{
+			name:            "diarized visit follows each speaker",
+			turns:           spanishVisit,
+			noteLanguage:    transcriber.LanguageEnglish,
+			patientLanguage: transcriber.LanguageSpanish,
+			detected:        []string{transcriber.LanguageEnglish, transcriber.LanguageSpanish},
+		},
+		{
+			name:            "chosen languages are kept",
+			request:         ReportRequest{NoteLanguage: transcriber.LanguageSpanish, PatientLanguage: transcriber.LanguageEnglish},
+			turns:           spanishVisit,
+			noteLanguage:    transcriber.LanguageSpanish,
+			patientLanguage: transcriber.LanguageEnglish,
+			detected:        []string{transcriber.LanguageEnglish, transcriber.LanguageSpanish},
+		},
+		{
+			name:            "undiarized visit takes the other language for the patient",
+			transcript:      "How are you feeling with the medication today? Me siento mejor pero tengo dolor en el estómago por las mañanas.",
+			noteLanguage:    transcriber.LanguageEnglish,
+			patientLanguage: transcriber.LanguageSpanish,
+			detected:        []string{transcriber.LanguageSpanish, transcriber.LanguageEnglish},
+		},
+		{
+			name:            "dictation is documented in the language dictated",
+			request:         ReportRequest{Mode: reports.ModeDictation},
+			transcript:      "La paciente tiene dolor de cabeza desde hace una semana. No tiene fiebre.",
+			noteLanguage:    transcriber.LanguageSpanish,
+			patientLanguage: transcriber.LanguageSpanish,
+			detected:        []string{transcriber.LanguageSpanish},
+		},
+		{
+			name:            "undetectable transcript defaults to english",
+			transcript:      "Okay.",
+			noteLanguage:    transcriber.LanguageEnglish,
+			patientLanguage: transcriber.LanguageEnglish,
+			detected:        []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+			request.detectLanguages(tt.transcript, tt.turns)
+			assert.Equal(t, tt.noteLanguage, request.NoteLanguage)
+			assert.Equal(t, tt.patientLanguage, request.PatientLanguage)
+			assert.Equal(t, tt.detected, request.TranscriptLanguages)
+		})
+	}
+}
+
+func TestSectionLanguage(t *testing.T) {
+	english := ReportRequest{NoteLanguage: transcriber.LanguageEnglish, PatientLanguage: transcriber.LanguageEnglish, TranscriptLanguages: []string{transcriber.LanguageEnglish}}
+	assert.Equal(t, "", english.sectionLanguage(reports.Subjective))
+
+	mixed := ReportRequest{NoteLanguage: transcriber.LanguageEnglish, PatientLanguage: transcriber.LanguageHaitianCreole}
+	assert.Equal(t, "English", mixed.sectionLanguage(reports.Subjective))
+	assert.Equal(t, "Haitian Creole", mixed.sectionLanguage(reports.PatientInstructions))
+}
+
+func TestSectionUpdates(t *testing.T) {
+	updates := bson.D{
+		{Key: reports.Pronouns, Value: reports.SHE},
+		{Key: reports.NoteLanguage, Value: transcriber.LanguageSpanish},
+		{Key: reports.PatientLanguage, Value: transcriber.LanguageHaitianCreole},
+	}
+
+	assert.Equal(t, bson.D{
+		{Key: reports.Pronouns, Value: reports.SHE},
+		{Key: "language", Value: "Spanish"},
+	}, sectionUpdates(updates, reports.Objective))
+	assert.Equal(t, bson.D{
+		{Key: reports.Pronouns, Value: reports.SHE},
+		{Key: "language", Value: "Haitian Creole"},
+	}, sectionUpdates(updates, reports.PatientInstructions))
+	assert.Nil(t, sectionUpdates(nil, reports.Objective))
+}
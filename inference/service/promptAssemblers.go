@@ -2,12 +2,17 @@ package inferenceService
 
 import (
 	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
 	"fmt"
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// PromptVersion identifies the prompts report sections are generated with and is recorded with every
+// generated section revision. Bump it whenever a prompt or task description changes.
+const PromptVersion = "v1"
+
 const (
 	baseSystemPrompt = `
 You are an AI medical assistant acting on behalf of the provider.
@@ -66,6 +71,54 @@ GENERATE ONLY THE REQUIRED CLINICAL NOTE SECTION CONTENT for '%s' BASED ON THE T
 ***IMPORTANT: Your response MUST start directly with the narrative content for the requested section (%s). Do NOT include any section title or heading (like '%s:', 'Subjective:', 'Objective:', etc.) in your output. Your response should contain ONLY the narrative text.***
 `
 
+	userDictationPromptTemplate = `
+You are an AI medical assistant acting as the provider. The provider has dictated this visit alone, narrating in the first person; there is no conversation with the patient in the transcript. Your paramount responsibility is to turn the dictation into the requested section of the clinical visit report with the utmost correctness, adhering meticulously to the Task Instructions provided below. This report may be presented as evidence in a legal setting.
+
+--- BACKGROUND CONTEXT (FOR YOUR INFORMATION ONLY - DO NOT INCLUDE IN OUTPUT) ---
+Patient Name: %s
+Provider Name: %s
+SOAP Section to Generate: %s
+--- END BACKGROUND CONTEXT ---
+
+**OPERATING PRINCIPLES FOR DICTATED NOTES:**
+
+* **The Narrator Is the Provider:** "I", "me" and "my" refer to the provider. Statements such as "I examined", "I reviewed" or "I recommended" are the provider's own findings and decisions; write them in standard clinical documentation voice (e.g., "On examination...", "Recommended...").
+* **Reported Versus Observed:** Attribute information to the patient only when the provider says the patient reported it (e.g., "she tells me", "he reports"). Everything else is the provider's observation, assessment or plan.
+* **Dictation Artifacts:** Drop spoken formatting commands and fillers such as "period", "comma", "new paragraph", "scratch that" or "correction", applying any correction the provider dictated to the text it replaces.
+* **Spoken Section Headings:** Headings such as "assessment colon" mark where the provider intended content to go. Do not reproduce the heading words themselves as content.
+* **Correctness Above All:** Document only what was dictated. Do not add findings, diagnoses, medications or plans the provider did not state.
+* **Anomaly Correction (Justified):** Correct clear transcription errors (e.g., "minute" to "mg") ONLY when the context overwhelmingly supports the correction.
+
+--- END TASK INSTRUCTIONS ---
+
+--- DICTATION (Analyze this dictation to perform the task with a focus on correctness) ---
+%s
+--- END DICTATION ---
+
+GENERATE ONLY THE REQUIRED CLINICAL NOTE SECTION CONTENT for '%s' BASED ON THE TASK INSTRUCTIONS ABOVE.
+***IMPORTANT: Your response MUST start directly with the narrative content for the requested section (%s). Do NOT include any section title or heading (like '%s:', 'Subjective:', 'Objective:', etc.) in your output. Your response should contain ONLY the narrative text.***
+`
+
+	// sectionLanguageInstruction is appended when the visit was not conducted, or is not documented, in English only.
+	sectionLanguageInstruction = `--- OUTPUT LANGUAGE ---
+Write this section in %s. The transcript may be in other languages, or switch between them; translate faithfully into %s without adding, softening or omitting anything. Keep medication names, doses and numbers exact, and keep a patient's own words in their original language only when quoting them is clinically important, followed by a translation.
+
+`
+
+	// sessionParticipantsInstruction is appended for sessions with a roster, such as family,
+	// interpreter-mediated or group visits.
+	sessionParticipantsInstruction = `--- SESSION PARTICIPANTS (the speaker labels in the transcript are these IDs) ---
+%s--- END SESSION PARTICIPANTS ---
+
+Attribute every statement to the participant who made it, using their name or their relationship to the patient (e.g., "the patient's mother reports..."). Never present what a family member, caregiver or other attendee said as the patient's own report. Statements an interpreter relays on the patient's behalf are the patient's; document the interpreter's presence but not their relaying. In a group session, distinguish the members and do not merge their statements.
+
+`
+
+	// dictatedSectionInstruction is appended when the provider dictated content under this section's spoken heading.
+	dictatedSectionInstruction = `The dictation above is the content the provider explicitly dictated for this section under a spoken heading. Document all of it in this section, preserving the provider's findings and decisions, and do not pull in material from other sections.
+
+`
+
 
 
 )
@@ -79,6 +132,14 @@ type generatePromptConfig struct {
 	style              string
 	providerName       string
 	patientName        string
+	// dictation selects the prompt for a provider's first-person dictation instead of a conversation.
+	dictation          bool
+	// dictatedSection is set when the transcript is the content dictated under the section's spoken heading.
+	dictatedSection    bool
+	// participants is the session roster; the transcript's speaker labels are their IDs.
+	participants       []transcriber.Participant
+	// language is the name of the language to write the section in; empty for English-only visits.
+	language           string
 }
 
 type regeneratePromptConfig struct {
@@ -110,9 +171,28 @@ func formatUpdateDetails(updates bson.D) string {
 func GenerateReportContentPrompt(cfg generatePromptConfig) string {
 
     // --- Prompt Construction ---
-    prompt := fmt.Sprintf(userGenerateReportPromptTemplate, 
+    template := userGenerateReportPromptTemplate
+    if cfg.dictation {
+        template = userDictationPromptTemplate
+    }
+    prompt := fmt.Sprintf(template, 
 	cfg.patientName, cfg.providerName, cfg.targetSection, cfg.transcript, cfg.targetSection, cfg.targetSection, cfg.targetSection)
 
+    // --- Content Routed by a Spoken Section Cue ---
+    if cfg.dictatedSection {
+        prompt += dictatedSectionInstruction
+    }
+
+    // --- Output Language ---
+    if cfg.language != "" {
+        prompt += fmt.Sprintf(sectionLanguageInstruction, cfg.language, cfg.language)
+    }
+
+    // --- Session Roster ---
+    if len(cfg.participants) > 0 {
+        prompt += fmt.Sprintf(sessionParticipantsInstruction, transcriber.FormatRoster(cfg.participants))
+    }
+
     // --- Optional Style Integration ---
     if cfg.style != "" {
         prompt += "Integrate the style with the task description:\n" + cfg.style + "\n\n"
@@ -161,7 +241,8 @@ func RegenerateReportContentPrompt(cfg regeneratePromptConfig) string {
 	prompt += "The required updates strictly involve **metadata** such as:\n" +
 		"- Patient pronouns (he/she/they)\n" +
 		"- Visit type (initial visit or follow-up)\n" +
-		"- Terminology adjustments (e.g., 'patient' vs. 'client')\n\n"
+		"- Terminology adjustments (e.g., 'patient' vs. 'client')\n" +
+		"- Language (rewrite the entire section in the given language, translating faithfully)\n\n"
 
 	prompt += "Do NOT introduce new medical facts or diagnoses. Maintain coherence and accuracy while reflecting only the provided metadata updates.\n\n"
 
@@ -0,0 +1,303 @@
+package inferenceService
+
+import (
+	"Medscribe/sanitize"
+	transcriber "Medscribe/transcription"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const patientPlaceholder = "PATIENT"
+
+// placeholderPrefixes name the placeholders of each info type, e.g. [PHONE_1].
+var placeholderPrefixes = map[string]string{
+	sanitize.PersonName:           "PERSON",
+	sanitize.PhoneNumber:          "PHONE",
+	sanitize.EmailAddress:         "EMAIL",
+	sanitize.StreetAddress:        "ADDRESS",
+	sanitize.ZipCode:              "ZIP",
+	sanitize.SocialSecurityNumber: "SSN",
+	sanitize.MedicalRecordNumber:  "MRN",
+	sanitize.CreditCardNumber:     "CARD",
+	sanitize.Date:                 "DATE",
+	sanitize.IPAddress:            "IP",
+	sanitize.URL:                  "URL",
+}
+
+// placeholderPattern matches placeholders in model output, with or without the brackets the model
+// was asked to keep.
+var placeholderPattern = regexp.MustCompile(`\[?\b([A-Z]+_\d+)\b\]?`)
+
+// nameParticles are parts of a name too common to stand for the person on their own.
+var nameParticles = map[string]bool{"del": true, "van": true, "von": true, "dos": true, "der": true, "las": true, "los": true}
+
+const pseudonymizedSystemPrompt = `
+Identifiers in the transcript and context have been replaced with placeholders such as [PATIENT_1], [PERSON_2], [PHONE_1] or [DATE_1].
+Copy placeholders exactly as written, brackets included, wherever the note refers to them. Never guess, alter or fill in the values they stand for.
+`
+
+// pseudonymizer replaces the identifiers of one request with stable placeholders before they are sent to
+// the chat model, and restores them in the model's output. The mapping lives only in memory for the request.
+type pseudonymizer struct {
+	redactor sanitize.Redactor
+	// allowed are the words of the provider's name, which the note is expected to carry.
+	allowed map[string]bool
+
+	mu sync.Mutex
+	// placeholders maps each identifier, lowercased, to its placeholder. Names are also mapped by their parts.
+	placeholders map[string]string
+	// originals maps each placeholder back to the identifier it stands for.
+	originals map[string]string
+	counts    map[string]int
+}
+
+// newPseudonymizer creates the pseudonymizer of a request, seeded with the patient's name and the
+// names on the session roster so they are replaced wherever they appear.
+func newPseudonymizer(req *ReportRequest) (*pseudonymizer, error) {
+	p := &pseudonymizer{
+		allowed:      make(map[string]bool),
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counts:       make(map[string]int),
+	}
+	for _, word := range nameParts(req.ProviderName) {
+		p.allowed[word] = true
+	}
+
+	var names []string
+	p.seed(req.PatientName, patientPlaceholder)
+	names = append(names, req.PatientName)
+	for _, participant := range req.Participants {
+		prefix := placeholderPrefixes[sanitize.PersonName]
+		if participant.Role == transcriber.RolePatient {
+			prefix = patientPlaceholder
+		}
+		p.seed(participant.Name, prefix)
+		names = append(names, participant.Name)
+	}
+	// Parts of the known names are redacted on their own too, so "Ms. Okafor" is caught as well as "Ada Okafor".
+	for _, name := range names {
+		for _, part := range nameParts(name) {
+			if !p.allowed[part] {
+				names = append(names, part)
+			}
+		}
+	}
+
+	redactor, err := sanitize.NewLocalRedactor(sanitize.LocalConfig{Names: names})
+	if err != nil {
+		return nil, fmt.Errorf("newPseudonymizer: %w", err)
+	}
+	p.redactor = redactor
+	return p, nil
+}
+
+// nameParts returns the lowercased words of a name that can identify the person on their own. Parts that
+// are everyday words, like the "Rose" of "Ada Rose Okafor", are left out since they would match all
+// over a clinical note.
+func nameParts(name string) []string {
+	var parts []string
+	for _, part := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '-'
+	}) {
+		if len([]rune(part)) >= 3 && !nameParticles[part] && !sanitize.IsEverydayWord(part) {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// seed assigns a placeholder to a known name and its parts.
+func (p *pseudonymizer) seed(name, prefix string) {
+	name = strings.TrimSpace(name)
+	key := strings.ToLower(name)
+	if name == "" || p.isAllowed(key) {
+		return
+	}
+	placeholder, ok := p.placeholders[key]
+	if !ok {
+		placeholder = p.newPlaceholder(prefix, name)
+		p.placeholders[key] = placeholder
+	}
+	for _, part := range nameParts(name) {
+		if _, ok := p.placeholders[part]; !ok && !p.allowed[part] {
+			p.placeholders[part] = placeholder
+		}
+	}
+}
+
+// isAllowed reports whether every word of an identifier belongs to the provider's name.
+func (p *pseudonymizer) isAllowed(key string) bool {
+	parts := nameParts(key)
+	if len(parts) == 0 {
+		return false
+	}
+	for _, part := range parts {
+		if !p.allowed[part] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *pseudonymizer) newPlaceholder(prefix, original string) string {
+	p.counts[prefix]++
+	placeholder := fmt.Sprintf("[%s_%d]", prefix, p.counts[prefix])
+	p.originals[placeholder] = original
+	return placeholder
+}
+
+// placeholderFor returns the placeholder of a finding, assigning a new one the first time it is seen.
+func (p *pseudonymizer) placeholderFor(finding sanitize.Finding) string {
+	key := strings.ToLower(strings.TrimSpace(finding.Text))
+	if placeholder, ok := p.placeholders[key]; ok {
+		return placeholder
+	}
+	if finding.InfoType == sanitize.PersonName && p.isAllowed(key) {
+		return finding.Text
+	}
+
+	prefix, ok := placeholderPrefixes[finding.InfoType]
+	if !ok {
+		prefix = "ID"
+	}
+	placeholder := p.newPlaceholder(prefix, finding.Text)
+	p.placeholders[key] = placeholder
+	if finding.InfoType == sanitize.PersonName {
+		for _, part := range nameParts(key) {
+			if _, ok := p.placeholders[part]; !ok && !p.allowed[part] {
+				p.placeholders[part] = placeholder
+			}
+		}
+	}
+	return placeholder
+}
+
+// pseudonymize replaces the identifiers in text with their placeholders.
+func (p *pseudonymizer) pseudonymize(ctx context.Context, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	findings, err := p.redactor.Inspect(ctx, text)
+	if err != nil {
+		return "", fmt.Errorf("pseudonymize: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return sanitize.ReplaceFindings(text, findings, p.placeholderFor), nil
+}
+
+// pseudonymizeRoster returns a copy of the roster with the participants' names replaced.
+func (p *pseudonymizer) pseudonymizeRoster(ctx context.Context, roster []transcriber.Participant) ([]transcriber.Participant, error) {
+	if len(roster) == 0 {
+		return roster, nil
+	}
+	pseudonymized := make([]transcriber.Participant, len(roster))
+	for i, participant := range roster {
+		name, err := p.pseudonymize(ctx, participant.Name)
+		if err != nil {
+			return nil, err
+		}
+		participant.Name = name
+		pseudonymized[i] = participant
+	}
+	return pseudonymized, nil
+}
+
+// pseudonymizeTurns returns a copy of the turns with the identifiers in their text replaced.
+func (p *pseudonymizer) pseudonymizeTurns(ctx context.Context, turns []transcriber.TranscriptTurn) ([]transcriber.TranscriptTurn, error) {
+	pseudonymized := make([]transcriber.TranscriptTurn, len(turns))
+	for i, turn := range turns {
+		text, err := p.pseudonymize(ctx, turn.Text)
+		if err != nil {
+			return nil, err
+		}
+		turn.Text = text
+		pseudonymized[i] = turn
+	}
+	return pseudonymized, nil
+}
+
+// reidentify restores the identifiers behind the placeholders in text. Placeholders the request did
+// not assign are left as they are.
+func (p *pseudonymizer) reidentify(text string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		inner := placeholderPattern.FindStringSubmatch(match)[1]
+		if original, ok := p.originals["["+inner+"]"]; ok {
+			return original
+		}
+		return match
+	})
+}
+
+// hasPlaceholders reports whether any identifier was replaced.
+func (p *pseudonymizer) hasPlaceholders() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.originals) > 0
+}
+
+// checkLeaks returns an error naming the placeholders whose raw identifier still appears in the
+// pseudonymized inputs of a prompt. Only the inputs are checked, since the fixed prompt text around
+// them carries no identifiers. The identifiers themselves are left out of the error so it can be logged.
+func (p *pseudonymizer) checkLeaks(inputs ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	joined := strings.Join(inputs, "\n")
+	lowered := strings.ToLower(joined)
+	leaked := make(map[string]bool)
+	for identifier, placeholder := range p.placeholders {
+		if leaked[placeholder] {
+			continue
+		}
+		text, word := lowered, identifier
+		if sanitize.IsEverydayWord(identifier) {
+			// Like the redactor, a name that is an everyday word only counts capitalized.
+			text, word = joined, strings.ToUpper(identifier[:1])+identifier[1:]
+		}
+		if containsWord(text, word) {
+			leaked[placeholder] = true
+		}
+	}
+	if len(leaked) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(leaked))
+	for placeholder := range leaked {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+	return fmt.Errorf("prompt contains raw identifiers for %s", strings.Join(placeholders, ", "))
+}
+
+// containsWord reports whether word appears in text without a letter or digit directly on either side.
+func containsWord(text, word string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,204 @@
+package inferenceService
+
+import (
+	Chat "Medscribe/inference/store"
+	"Medscribe/reports"
+	transcriber "Medscribe/transcription"
+	"Medscribe/utils"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	ctx := context.Background()
+	req := &ReportRequest{
+		PatientName:  "Ada Okafor",
+		ProviderName: "Dr. John Smith",
+		Participants: []transcriber.Participant{
+			{ID: transcriber.RolePatient, Role: transcriber.RolePatient, Name: "Ada Okafor"},
+			{ID: transcriber.RoleOther, Role: transcriber.RoleOther, Name: "Chidi Okafor"},
+		},
+	}
+	p, err := newPseudonymizer(req)
+	require.NoError(t, err)
+
+	transcript := "Provider: Hi Ms. Okafor, I'm Dr. Smith. Is 617-555-0199 still your number?\n" +
+		"Patient: Yes. My son Chidi came with me, and Maria Lopez from the pharmacy called on 3/14/2024."
+
+	pseudonymized, err := p.pseudonymize(ctx, transcript)
+	require.NoError(t, err)
+	assert.Equal(t, "Provider: Hi Ms. [PATIENT_1], I'm Dr. Smith. Is [PHONE_1] still your number?\n"+
+		"Patient: Yes. My son [PERSON_1] came with me, and [PERSON_2] from the pharmacy called on [DATE_1].", pseudonymized)
+
+	t.Run("placeholders are stable across texts", func(t *testing.T) {
+		context, err := p.pseudonymize(ctx, "ada okafor, callback 617-555-0199")
+		require.NoError(t, err)
+		assert.Equal(t, "[PATIENT_1], callback [PHONE_1]", context)
+	})
+
+	t.Run("model output is re-identified", func(t *testing.T) {
+		output := "[PATIENT_1] presents with her son [PERSON_1]. Follow up by phone at PHONE_1. Keep [ZIP_9] as is."
+		assert.Equal(t, "Ada Okafor presents with her son Chidi Okafor. Follow up by phone at 617-555-0199. Keep [ZIP_9] as is.", p.reidentify(output))
+	})
+
+	t.Run("leak check passes pseudonymized prompts", func(t *testing.T) {
+		assert.NoError(t, p.checkLeaks("Provider: Dr. John Smith\nTranscript:\n"+pseudonymized))
+	})
+
+	t.Run("leak check fails on a raw identifier", func(t *testing.T) {
+		err := p.checkLeaks("Patient: Okafor\nPhone: 617-555-0199")
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "Okafor")
+		assert.Contains(t, err.Error(), "[PATIENT_1]")
+		assert.Contains(t, err.Error(), "[PHONE_1]")
+	})
+
+	t.Run("leak check matches whole words only", func(t *testing.T) {
+		assert.NoError(t, p.checkLeaks("Adalat 30 mg daily"))
+	})
+
+	t.Run("leak check covers every input", func(t *testing.T) {
+		err := p.checkLeaks(pseudonymized, "Chidi drove her home")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "[PERSON_1]")
+	})
+}
+
+func TestPseudonymizerEverydayWordNames(t *testing.T) {
+	ctx := context.Background()
+	p, err := newPseudonymizer(&ReportRequest{
+		PatientName: "Will Grace Okafor",
+		Participants: []transcriber.Participant{
+			{ID: transcriber.RoleOther, Role: transcriber.RoleOther, Name: "Rose"},
+		},
+	})
+	require.NoError(t, err)
+
+	pseudonymized, err := p.pseudonymize(ctx, "Patient will follow up. Okafor says Rose will drive. The rash rose over the week.")
+	require.NoError(t, err)
+	assert.Equal(t, "Patient will follow up. [PATIENT_1] says [PERSON_1] will drive. The rash rose over the week.", pseudonymized)
+
+	assert.NoError(t, p.checkLeaks(pseudonymized, "Plan: will recheck in two weeks, with grace period for labs."))
+	assert.Error(t, p.checkLeaks("Rose called the clinic."))
+}
+
+func TestGenerateMemberNotesPseudonymizesPrompts(t *testing.T) {
+	ctx := context.Background()
+	chat := new(Chat.MockInferenceStore)
+	s := &inferenceService{chat: chat}
+	req := &ReportRequest{
+		ProviderName:     "Dr. John Smith",
+		TranscribedAudio: "provider: Welcome back, Ada and Chidi.\npatient_1: Thanks. Chidi helped me with my sleep log.\npatient_2: Glad to hear it, Okafor.",
+		Participants: []transcriber.Participant{
+			{ID: transcriber.RoleProvider, Role: transcriber.RoleProvider, Name: "John Smith"},
+			{ID: "patient_1", Role: transcriber.RolePatient, Name: "Ada Okafor"},
+			{ID: "patient_2", Role: transcriber.RolePatient, Name: "Chidi Eze"},
+		},
+	}
+
+	var prompts []string
+	chat.On("Query", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		prompts = append(prompts, args.String(2))
+	}).Return(Chat.InferenceResponse{Content: "[PATIENT_1] shared her sleep log with [PATIENT_2]."}, nil).Twice()
+
+	notes, err := s.generateMemberNotes(ctx, req, &utils.SafeResponseWriter{ResponseWriter: httptest.NewRecorder()}, utils.NewSafeMap[int]())
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	for _, prompt := range prompts {
+		assert.NotContains(t, prompt, "Ada")
+		assert.NotContains(t, prompt, "Okafor")
+		assert.NotContains(t, prompt, "Chidi")
+		assert.Contains(t, prompt, "[PATIENT_1]")
+	}
+	require.Len(t, notes, 2)
+	assert.Equal(t, "Ada Okafor", notes[0].Name)
+	assert.Equal(t, "Ada Okafor shared her sleep log with Chidi Eze.", notes[0].Note.Data)
+}
+
+func TestGenerateAfterVisitSummaryPseudonymizesNote(t *testing.T) {
+	ctx := context.Background()
+	chat := new(Chat.MockInferenceStore)
+	store := new(reports.MockReportsStore)
+	s := &inferenceService{chat: chat, reportsStore: store}
+	store.On("Get", ctx, "report-1").Return(reports.Report{
+		Name:            "Ada Okafor",
+		PatientLanguage: transcriber.LanguageSpanish,
+		Subjective:      reports.ReportContent{Data: "Ada Okafor reports headaches. Call 617-555-0199 with questions."},
+	}, nil).Once()
+
+	var prompt string
+	chat.On("Query", ctx, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		prompt = args.String(2)
+	}).Return(Chat.InferenceResponse{Content: `{"whatWeDiscussed": "[PATIENT_1], hablamos de sus dolores de cabeza.", "whenToSeekHelp": "Llame al [PHONE_1]."}`}, nil).Once()
+	store.On("SetAfterVisitSummary", ctx, "report-1", mock.MatchedBy(func(summary reports.AfterVisitSummary) bool {
+		return summary.WhatWeDiscussed == "Ada Okafor, hablamos de sus dolores de cabeza." && summary.WhenToSeekHelp == "Llame al 617-555-0199."
+	})).Return(nil).Once()
+
+	summary, err := s.GenerateAfterVisitSummary(ctx, "report-1", 0)
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "Okafor")
+	assert.NotContains(t, prompt, "617-555-0199")
+	assert.Equal(t, "Llame al 617-555-0199.", summary.WhenToSeekHelp)
+	store.AssertExpectations(t)
+}
+
+func TestSpeakerQueriesPseudonymizeTranscript(t *testing.T) {
+	ctx := context.Background()
+	turns := []transcriber.TranscriptTurn{
+		{Speaker: "Speaker1", Text: "Hi Ada Okafor, I'm Dr. Smith."},
+		{Speaker: "Speaker2", Text: "Hi. My son Chidi Okafor came too."},
+	}
+
+	t.Run("roles", func(t *testing.T) {
+		chat := new(Chat.MockInferenceStore)
+		s := &inferenceService{chat: chat}
+		var excerpt string
+		chat.On("Query", ctx, speakerResolutionSystemPrompt, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			excerpt = args.String(2)
+		}).Return(Chat.InferenceResponse{Content: `{"Speaker1": "provider", "Speaker2": "patient"}`}, nil).Once()
+
+		roles, err := s.querySpeakerRoles(ctx, turns, &ReportRequest{PatientName: "Ada Okafor"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Speaker1": transcriber.RoleProvider, "Speaker2": transcriber.RolePatient}, roles)
+		assert.NotContains(t, excerpt, "Okafor")
+		assert.Contains(t, excerpt, "[Speaker1]: Hi [PATIENT_1]")
+	})
+
+	t.Run("roster", func(t *testing.T) {
+		chat := new(Chat.MockInferenceStore)
+		s := &inferenceService{chat: chat}
+		roster := []transcriber.Participant{
+			{ID: transcriber.RoleProvider, Role: transcriber.RoleProvider, Name: "John Smith"},
+			{ID: transcriber.RolePatient, Role: transcriber.RolePatient, Name: "Ada Okafor"},
+			{ID: transcriber.RoleOther, Role: transcriber.RoleOther, Name: "Chidi Okafor"},
+		}
+		var systemPrompt, excerpt string
+		chat.On("Query", ctx, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			systemPrompt, excerpt = args.String(1), args.String(2)
+		}).Return(Chat.InferenceResponse{Content: `{"Speaker1": "provider", "Speaker2": "patient"}`}, nil).Once()
+
+		participants, err := s.queryRosterSpeakers(ctx, turns, &ReportRequest{PatientName: "Ada Okafor", Participants: roster})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Speaker1": transcriber.RoleProvider, "Speaker2": transcriber.RolePatient}, participants)
+		for _, text := range []string{systemPrompt, excerpt} {
+			assert.False(t, strings.Contains(text, "Okafor") || strings.Contains(text, "Chidi"), text)
+		}
+		// The roster and the transcript share placeholders, so the model can still match names to participants.
+		assert.Contains(t, systemPrompt, "- patient: [PATIENT_1] (patient)")
+		assert.Contains(t, excerpt, "[PATIENT_1]")
+	})
+}
+
+func TestContainsWord(t *testing.T) {
+	assert.True(t, containsWord("seen by okafor today", "okafor"))
+	assert.True(t, containsWord("okafor", "okafor"))
+	assert.False(t, containsWord("okafors", "okafor"))
+	assert.True(t, containsWord("call 617-555-0199.", "617-555-0199"))
+	assert.False(t, containsWord("6617-555-0199", "617-555-0199"))
+}
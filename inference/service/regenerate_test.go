@@ -0,0 +1,91 @@
+package inferenceService
+
+import (
+	Chat "Medscribe/inference/store"
+	"Medscribe/reports"
+	"Medscribe/utils"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRegenerateReportRejectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	store := new(reports.MockReportsStore)
+	s := &inferenceService{reportsStore: store}
+	store.On("Get", ctx, "report-1").Return(reports.Report{Version: 5}, nil).Once()
+	store.On("LockForRegeneration", ctx, "report-1", int64(4), mock.Anything).Return(int64(0), &reports.VersionConflictError{Current: 5}).Once()
+
+	err := s.RegenerateReport(ctx, &ReportRequest{
+		ID:      "report-1",
+		Version: 4,
+		Updates: bson.D{{Key: reports.IsFollowUp, Value: true}},
+	}, nil)
+
+	var conflict *reports.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(5), conflict.Current)
+	store.AssertExpectations(t)
+	store.AssertNotCalled(t, "ReleaseRegeneration", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegenerateReportRejectsRegeneratingReport(t *testing.T) {
+	ctx := context.Background()
+	store := new(reports.MockReportsStore)
+	s := &inferenceService{reportsStore: store}
+	store.On("Get", ctx, "report-1").Return(reports.Report{Version: 5}, nil).Once()
+	store.On("LockForRegeneration", ctx, "report-1", int64(5), mock.Anything).Return(int64(0), reports.ErrReportRegenerating).Once()
+
+	err := s.RegenerateReport(ctx, &ReportRequest{
+		ID:      "report-1",
+		Version: 5,
+		Updates: bson.D{{Key: reports.IsFollowUp, Value: true}},
+	}, nil)
+
+	assert.ErrorIs(t, err, reports.ErrReportRegenerating)
+	store.AssertExpectations(t)
+	store.AssertNotCalled(t, "CompleteRegeneration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegenerateReportReleasesLockWhenGenerationFails(t *testing.T) {
+	ctx := context.Background()
+	store := new(reports.MockReportsStore)
+	chat := new(Chat.MockInferenceStore)
+	s := &inferenceService{reportsStore: store, chat: chat}
+	store.On("Get", ctx, "report-1").Return(reports.Report{Version: 5}, nil).Once()
+	store.On("LockForRegeneration", ctx, "report-1", int64(5), mock.Anything).Return(int64(6), nil).Once()
+	chat.On("Query", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(Chat.InferenceResponse{}, errors.New("model unavailable"))
+	store.On("ReleaseRegeneration", ctx, "report-1", int64(6)).Return(nil).Once()
+
+	err := s.RegenerateReport(ctx, &ReportRequest{
+		ID:               "report-1",
+		Version:          5,
+		TranscribedAudio: "Provider: How are the headaches?",
+		Updates:          bson.D{{Key: reports.IsFollowUp, Value: true}},
+	}, &utils.SafeResponseWriter{ResponseWriter: httptest.NewRecorder()})
+
+	assert.ErrorContains(t, err, "model unavailable")
+	store.AssertExpectations(t)
+	store.AssertNotCalled(t, "CompleteRegeneration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegenerateFromTranscriptRejectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	store := new(reports.MockReportsStore)
+	s := &inferenceService{reportsStore: store}
+	store.On("Get", ctx, "report-1").Return(reports.Report{Version: 3}, nil).Once()
+
+	err := s.RegenerateFromTranscript(ctx, &ReportRequest{ID: "report-1", Version: 2}, nil)
+
+	var conflict *reports.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(3), conflict.Current)
+	store.AssertNotCalled(t, "GetTranscription", mock.Anything, mock.Anything)
+	store.AssertNotCalled(t, "LockForRegeneration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
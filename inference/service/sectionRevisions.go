@@ -0,0 +1,61 @@
+package inferenceService
+
+import (
+	contextLogger "Medscribe/logger"
+	"Medscribe/reports"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// recordSectionRevisions records a revision of every section generated in updates, attributed to the provider
+// who requested the generation. previous is the report before the updates are stored. Revisions are recorded
+// before the content is stored so no generated content is missing from the history; the returned IDs let the
+// caller discard them when the report write is rejected. When a section fails to be recorded, the revisions
+// recorded before it are discarded and the error is returned.
+func (s *inferenceService) recordSectionRevisions(ctx context.Context, reportID, providerID, source string, previous reports.Report, updates bson.D) ([]primitive.ObjectID, error) {
+	reportObjectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("recordSectionRevisions: invalid report ID: %w", err)
+	}
+
+	recorded := []primitive.ObjectID{}
+	for _, section := range reports.TranscriptDerivedSections {
+		content := getSectionValue(updates, section)
+		if content == "" {
+			continue
+		}
+		previousContent, _ := previous.Section(section)
+		revision := sectionRevisions.SectionRevision{
+			ReportID:      reportObjectID,
+			ProviderID:    providerID,
+			Section:       section,
+			Source:        source,
+			AuthorID:      providerID,
+			Content:       content,
+			PromptVersion: PromptVersion,
+		}
+		inserted, err := sectionRevisions.Record(ctx, s.sectionRevisions, revision, previousContent.Data)
+		if err != nil {
+			s.discardSectionRevisions(ctx, recorded)
+			return nil, fmt.Errorf("recordSectionRevisions: error recording %s revision: %w", section, err)
+		}
+		recorded = append(recorded, inserted.ID)
+	}
+	return recorded, nil
+}
+
+// discardSectionRevisions removes revisions recorded for content that was not stored. A revision that cannot be
+// removed is logged; it only leaves an extra entry in the section's history.
+func (s *inferenceService) discardSectionRevisions(ctx context.Context, revisionIDs []primitive.ObjectID) {
+	logger := contextLogger.FromCtx(ctx)
+	for _, id := range revisionIDs {
+		if err := s.sectionRevisions.Discard(ctx, id); err != nil {
+			logger.Error("discardSectionRevisions: error discarding section revision", zap.String("RevisionID", id.Hex()), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package inferenceService
+
+import (
+	"Medscribe/reports"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func sectionUpdate(section, content string) bson.E {
+	return bson.E{Key: section, Value: bson.D{{Key: reports.ContentData, Value: content}, {Key: reports.Loading, Value: false}}}
+}
+
+func TestRecordSectionRevisions(t *testing.T) {
+	ctx := context.Background()
+	reportID := primitive.NewObjectID()
+	revisionOf := func(source, section, content string) interface{} {
+		return mock.MatchedBy(func(r sectionRevisions.SectionRevision) bool {
+			return r.ReportID == reportID && r.ProviderID == "provider-1" && r.AuthorID == "provider-1" &&
+				r.Source == source && r.Section == section && r.Content == content && r.PromptVersion == PromptVersion
+		})
+	}
+
+	t.Run("should record every generated section", func(t *testing.T) {
+		store := new(sectionRevisions.MockSectionRevisionStore)
+		s := &inferenceService{sectionRevisions: store}
+		store.On("Insert", ctx, revisionOf(sectionRevisions.SourceGeneration, reports.Subjective, "headache")).Return(sectionRevisions.SectionRevision{}, nil).Once()
+		store.On("Insert", ctx, revisionOf(sectionRevisions.SourceGeneration, reports.Summary, "tension headache")).Return(sectionRevisions.SectionRevision{}, nil).Once()
+
+		updates := bson.D{
+			sectionUpdate(reports.Subjective, "headache"),
+			sectionUpdate(reports.Summary, "tension headache"),
+			{Key: reports.CondensedSummary, Value: "headache"},
+			{Key: reports.Status, Value: "success"},
+		}
+		recorded, err := s.recordSectionRevisions(ctx, reportID.Hex(), "provider-1", sectionRevisions.SourceGeneration, reports.Report{}, updates)
+
+		assert.NoError(t, err)
+		assert.Len(t, recorded, 2)
+		store.AssertExpectations(t)
+		store.AssertNumberOfCalls(t, "Insert", 2)
+	})
+
+	t.Run("should keep content without history as the original revision", func(t *testing.T) {
+		store := new(sectionRevisions.MockSectionRevisionStore)
+		s := &inferenceService{sectionRevisions: store}
+		store.On("InsertOriginal", ctx, mock.MatchedBy(func(r sectionRevisions.SectionRevision) bool {
+			return r.Section == reports.Objective && r.Content == "typed by the provider"
+		})).Return(true, nil).Once()
+		store.On("Insert", ctx, revisionOf(sectionRevisions.SourceRegeneration, reports.Objective, "regenerated")).Return(sectionRevisions.SectionRevision{}, nil).Once()
+
+		previous := reports.Report{Objective: reports.ReportContent{Data: "typed by the provider"}}
+		_, err := s.recordSectionRevisions(ctx, reportID.Hex(), "provider-1", sectionRevisions.SourceRegeneration, previous, bson.D{sectionUpdate(reports.Objective, "regenerated")})
+
+		assert.NoError(t, err)
+
+		store.AssertExpectations(t)
+	})
+
+	t.Run("should fail and discard recorded revisions when a section fails to be recorded", func(t *testing.T) {
+		store := new(sectionRevisions.MockSectionRevisionStore)
+		s := &inferenceService{sectionRevisions: store}
+		recordedID := primitive.NewObjectID()
+		store.On("Insert", ctx, revisionOf(sectionRevisions.SourceRewrite, reports.Subjective, "rewritten")).Return(sectionRevisions.SectionRevision{ID: recordedID}, nil).Once()
+		store.On("Insert", ctx, revisionOf(sectionRevisions.SourceRewrite, reports.Objective, "rewritten")).Return(sectionRevisions.SectionRevision{}, errors.New("connection reset")).Once()
+		store.On("Discard", ctx, recordedID).Return(nil).Once()
+
+		updates := bson.D{sectionUpdate(reports.Subjective, "rewritten"), sectionUpdate(reports.Objective, "rewritten")}
+		recorded, err := s.recordSectionRevisions(ctx, reportID.Hex(), "provider-1", sectionRevisions.SourceRewrite, reports.Report{}, updates)
+
+		assert.ErrorContains(t, err, "connection reset")
+		assert.Nil(t, recorded)
+		store.AssertExpectations(t)
+	})
+}
@@ -1,13 +1,17 @@
 package inferenceService
 
 import (
+	"Medscribe/audio"
 	Chat "Medscribe/inference/store"
+	"Medscribe/audioStore"
 	contextLogger "Medscribe/logger"
 	"Medscribe/reports"
 	reportsTokenUsage "Medscribe/reportsTokenUsageStore"
+	sectionRevisions "Medscribe/sectionRevisionStore"
 	transcriber "Medscribe/transcription"
 	"Medscribe/user"
 	"Medscribe/utils"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -26,7 +30,10 @@ import (
 type InferenceService interface {
 	GenerateReportPipeline(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error
 	RegenerateReport(ctx context.Context, report *ReportRequest,w *utils.SafeResponseWriter) error
+	RegenerateFromTranscript(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error
+	AppendRecording(ctx context.Context, report *ReportRequest, w *utils.SafeResponseWriter) error
 	LearnStyle(ctx context.Context, providerID, contentSection, previous, content string) error
+	GenerateAfterVisitSummary(ctx context.Context, reportID string, readingLevel int) (reports.AfterVisitSummary, error)
 }
 
 type inferenceService struct {
@@ -35,6 +42,8 @@ type inferenceService struct {
 	chat                  Chat.InferenceStore
 	userStore             user.UserStore
 	reportTokenUsageStore reportsTokenUsage.TokenUsageStore
+	audioStore            audioStore.AudioStore
+	sectionRevisions      sectionRevisions.SectionRevisionStore
 	diarization bool
 }
 
@@ -45,16 +54,20 @@ type inferenceService struct {
 // - transcriptionService: An instance of Transcription.Transcription to handle transcription operations.
 // - chat: An instance of Chat.InferenceStore to handle chat-related operations.
 // - userStore: An instance of user.UserStore to handle user-related operations.
+// - audioStore: An instance of audioStore.AudioStore to retain the encrypted visit recordings.
+// - sectionRevisionStore: An instance of sectionRevisions.SectionRevisionStore to record every generated section.
 //
 // Returns:
 // - An instance of InferenceService initialized with the provided dependencies.
-func NewInferenceService(reportsStore reports.Reports, transcriptionService transcriber.Transcription, chat Chat.InferenceStore, userStore user.UserStore, reportTokenUsageStore reportsTokenUsage.TokenUsageStore, diarization bool) InferenceService {
+func NewInferenceService(reportsStore reports.Reports, transcriptionService transcriber.Transcription, chat Chat.InferenceStore, userStore user.UserStore, reportTokenUsageStore reportsTokenUsage.TokenUsageStore, audioStore audioStore.AudioStore, sectionRevisionStore sectionRevisions.SectionRevisionStore, diarization bool) InferenceService {
 	return &inferenceService{
 		userStore:             userStore,
 		reportsStore:          reportsStore,
 		transcriptionService:  transcriptionService,
 		chat:                  chat,
 		reportTokenUsageStore: reportTokenUsageStore,
+		audioStore:            audioStore,
+		sectionRevisions:      sectionRevisionStore,
 		diarization:           diarization,
 	}
 }
@@ -78,11 +91,16 @@ type ReportRequest struct {
 	ID                        string
 	PatientName               string
 	AudioBytes                []byte
+	AudioContentType          string
+	UploadID                  string `json:"uploadID"`
+	// ImportedTranscript is a transcript supplied instead of audio.
+	ImportedTranscript        *transcriber.ImportedTranscript `json:"-"`
 	TranscribedAudio          string
 	ProviderID                string
 	ProviderName              string
 	Timestamp                 time.Time
 	Duration                  float64
+	TrimmedDuration           float64
 	Updates                   bson.D
 	SubjectiveContent         string
 	ObjectiveContent          string
@@ -98,32 +116,103 @@ type ReportRequest struct {
 	PatientInstructionsStyle  string `bson:"patientInstructionsStyle"`
 	LastVisitID               string
 	VisitContext              string
+	Sections                  []string `json:"sections"`
+	// Mode is reports.ModeConversation for a recorded visit or reports.ModeDictation for a provider narrating the note.
+	Mode                      string `json:"mode"`
+	// Participants is the session roster for family, interpreter-mediated or group visits.
+	Participants              []transcriber.Participant `json:"participants"`
+	// NoteLanguage is the language of the clinical sections and PatientLanguage the language of the
+	// patient instructions. Either is detected from the transcript when left empty.
+	NoteLanguage              string `json:"noteLanguage"`
+	PatientLanguage           string `json:"patientLanguage"`
+	TranscriptLanguages       []string `json:"-"`
+	// Version is the version of the report the request is based on. Regeneration only stores its content
+	// while no other write has changed the report since.
+	Version                   int64 `json:"version"`
+}
+
+// dictated reports whether the request is a provider's dictation rather than a visit conversation.
+func (r *ReportRequest) dictated() bool {
+	return r.Mode == reports.ModeDictation
+}
+
+// soapSection pairs a report section with the task description, style and existing content used to generate it.
+type soapSection struct {
+	name            string
+	taskDescription string
+	style           string
+	content         string
+}
+
+// soapSections lists every section generated for a report, in display order.
+func (r *ReportRequest) soapSections() []soapSection {
+	return []soapSection{
+		{name: reports.Subjective, taskDescription: subjectiveTaskDescription, style: r.SubjectiveStyle, content: r.SubjectiveContent},
+		{name: reports.Objective, taskDescription: objectiveTaskDescription, style: r.ObjectiveStyle, content: r.ObjectiveContent},
+		{name: reports.AssessmentAndPlan, taskDescription: assessmentAndPlanTaskDescription, style: r.AssessmentAndPlanStyle, content: r.AssessmentAndPlanContent},
+		{name: reports.PatientInstructions, taskDescription: patientInstruction, style: r.PatientInstructionsStyle, content: r.PatientInstructionContent},
+		{name: reports.Summary, taskDescription: summaryTaskDescription, style: r.SummaryStyle, content: r.SummaryContent},
+	}
+}
+
+// includesSection reports whether a section is targeted by the request. No targeted sections means all of them.
+func (r *ReportRequest) includesSection(section string) bool {
+	if len(r.Sections) == 0 {
+		return true
+	}
+	for _, s := range r.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
 }
 
 // CreateInitialReportEntry creates the initial report entry in the store.
 func (s *inferenceService) createInitialReportEntry(ctx context.Context, report *ReportRequest) (string, error) {
-	reportID, err := s.reportsStore.Put(ctx, report.PatientName, report.ProviderID, report.Timestamp, report.Duration, false, reports.THEY, report.LastVisitID, s.diarization)
+	reportID, err := s.reportsStore.Put(ctx, report.PatientName, report.ProviderID, report.Timestamp, report.Duration, false, reports.THEY, report.LastVisitID, s.usesDiarization(report))
 	if err != nil {
 		return "", fmt.Errorf("CreateInitialReportEntry: error storing report: %w", err)
 	}
 	return reportID, nil
 }
 
+// usesDiarization reports whether the report's transcript is diarized. Imported transcripts are
+// diarized when they carried timings, regardless of how audio is transcribed. A dictation has a
+// single speaker, so its audio is never diarized.
+func (s *inferenceService) usesDiarization(reportRequest *ReportRequest) bool {
+	if reportRequest.ImportedTranscript != nil {
+		return reportRequest.ImportedTranscript.Diarized
+	}
+	return s.diarization && !reportRequest.dictated()
+}
+
 func (s *inferenceService) processTranscript(ctx context.Context, reportRequest *ReportRequest) (string, error) {
-	if s.diarization {
-		return s.processWithDiarization(ctx, reportRequest)
+	if reportRequest.ImportedTranscript != nil {
+		return s.processImportedTranscript(ctx, reportRequest)
 	}
-	return s.processWithoutDiarization(ctx, reportRequest)
+	transcriptionAudio, timeMap := s.trimSilence(ctx, reportRequest)
+	if s.usesDiarization(reportRequest) {
+		return s.processWithDiarization(ctx, reportRequest, transcriptionAudio, timeMap)
+	}
+	return s.processWithoutDiarization(ctx, reportRequest, transcriptionAudio)
 }
 
-func (s *inferenceService) processWithDiarization(ctx context.Context, reportRequest *ReportRequest) (string, error) {
-	diarizedTranscript, err := s.transcriptionService.TranscribeWithDiarization(ctx, reportRequest.AudioBytes)
+func (s *inferenceService) processWithDiarization(ctx context.Context, reportRequest *ReportRequest, transcriptionAudio []byte, timeMap audio.TimeMap) (string, error) {
+	opts := s.transcriptionOptions(ctx, reportRequest.ProviderID)
+	opts.Participants = reportRequest.Participants
+	opts.Languages = reportRequest.expectedLanguages()
+	diarizedTranscript, err := s.transcriptionService.TranscribeWithDiarization(ctx, transcriptionAudio, opts)
 	if err != nil {
 		return "", fmt.Errorf("error creating diarized transcript: %w", err)
 	}
+	diarizedTranscript = restoreTimeline(diarizedTranscript, timeMap)
+	diarizedTranscript = transcriber.CorrectTurnsVocabulary(diarizedTranscript, opts.Vocabulary)
 
 	logger := contextLogger.FromCtx(ctx)
-	logger.Info("Generated Diarized transcript", zap.Any("diarizedTranscript", diarizedTranscript))
+	logger.Info("Generated Diarized transcript", zap.Int("turnCount", len(diarizedTranscript)))
+
+	diarizedTranscript = s.resolveSpeakerRoles(ctx, diarizedTranscript, reportRequest)
 
 	diarizedTranscriptString,err := transcriber.DiarizedTranscriptToString(diarizedTranscript)
 	if err != nil {
@@ -137,11 +226,35 @@ func (s *inferenceService) processWithDiarization(ctx context.Context, reportReq
 	return diarizedTranscriptString, nil
 }
 
-func (s *inferenceService) processWithoutDiarization(ctx context.Context, reportRequest *ReportRequest) (string, error) {
-	transcript, err := s.transcriptionService.Transcribe(ctx, reportRequest.AudioBytes)
+// processImportedTranscript uses a transcript supplied with the request in place of transcribing audio.
+func (s *inferenceService) processImportedTranscript(ctx context.Context, reportRequest *ReportRequest) (string, error) {
+	imported := reportRequest.ImportedTranscript
+	if !imported.Diarized {
+		reportRequest.TranscribedAudio = imported.Text
+		return imported.Text, nil
+	}
+
+	turns := s.resolveSpeakerRoles(ctx, imported.Turns, reportRequest)
+	turnsString, err := transcriber.DiarizedTranscriptToString(turns)
+	if err != nil {
+		return "", fmt.Errorf("error storing imported transcript: %w", err)
+	}
+	compressed, err := transcriber.CompressDiarizedText(turnsString)
+	if err != nil {
+		return "", fmt.Errorf("error compressing imported transcript: %w", err)
+	}
+	reportRequest.TranscribedAudio = compressed
+	return turnsString, nil
+}
+
+func (s *inferenceService) processWithoutDiarization(ctx context.Context, reportRequest *ReportRequest, transcriptionAudio []byte) (string, error) {
+	opts := s.transcriptionOptions(ctx, reportRequest.ProviderID)
+	opts.Languages = reportRequest.expectedLanguages()
+	transcript, err := s.transcriptionService.Transcribe(ctx, transcriptionAudio, opts)
 	if err != nil {
 		return "", fmt.Errorf("error creating transcript: %w", err)
 	}
+	transcript = transcriber.CorrectVocabulary(transcript, opts.Vocabulary)
 	reportRequest.TranscribedAudio = transcript
 	return transcript, nil
 }
@@ -166,15 +279,25 @@ func (s *inferenceService) recordTokenUsage(ctx context.Context, reportID string
 	return nil
 }
 
-// UpdateFinalReport updates the report in the store with the generated content and final status.
-func (s *inferenceService) updateFinalReport(ctx context.Context, reportID string, combinedUpdates bson.D) error {
+// UpdateFinalReport updates the report in the store with the generated content and final status, and returns
+// its new version. The report must not have been changed since it was created.
+func (s *inferenceService) updateFinalReport(ctx context.Context, reportID string, locked int64, combinedUpdates bson.D) (int64, error) {
 	updates := append(combinedUpdates,
 		bson.E{Key: reports.Status, Value: "success"},
 	)
-	if err := s.reportsStore.UpdateReport(ctx, reportID, updates); err != nil {
-		return fmt.Errorf("UpdateFinalReport: error updating report: %w", err)
+	version, err := s.reportsStore.CompleteRegeneration(ctx, reportID, locked, updates)
+	if err != nil {
+		return 0, fmt.Errorf("UpdateFinalReport: error updating report: %w", err)
+	}
+	return version, nil
+}
+
+// releaseRegeneration gives up the lock a failed generation holds on a report. When it cannot be released, the
+// lease expires on its own.
+func (s *inferenceService) releaseRegeneration(ctx context.Context, reportID string, locked int64) {
+	if err := s.reportsStore.ReleaseRegeneration(ctx, reportID, locked); err != nil {
+		contextLogger.FromCtx(ctx).Error("releaseRegeneration: error releasing report", zap.String("ReportID", reportID), zap.Error(err))
 	}
-	return nil
 }
 
 // sendContentToFrontend writes the payload to the SafeResponseWriter and flushes it after encoding.
@@ -198,11 +321,16 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 	logger := contextLogger.FromCtx(ctx)
 
 	var skipDefer bool
+	// locked is the version the report is held at while it is generated, so no edit is lost when its content is stored
+	locked := reports.InitialVersion
 	// this will only run on failures as a less redundant way to mark a report as failed
 	defer func() {
 		if !skipDefer {
 			logger.Error("GenerateReportPipeline: Updating Report Status to Failed", zap.String("report_id", reportRequest.ID))
 			s.reportsStore.UpdateStatus(ctx, reportRequest.ID, "failed")
+			if locked != reports.InitialVersion {
+				s.releaseRegeneration(ctx, reportRequest.ID, locked)
+			}
 		}
 	}()
 
@@ -212,9 +340,23 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 	if err != nil {
 		return err
 	}
+	reportRequest.ID = reportID
+	locked, err = s.reportsStore.LockForRegeneration(ctx, reportID, reports.InitialVersion, nil)
+	if err != nil {
+		return fmt.Errorf("GenerateReportPipeline: error locking report for generation: %w", err)
+	}
 	sendContentToFrontend(w, ContentChanPayload{"_id", reportID})
+	if len(reportRequest.Participants) > 0 {
+		if err := s.reportsStore.SetParticipants(ctx, reportID, reportRequest.Participants); err != nil {
+			return fmt.Errorf("GenerateReportPipeline: error storing participants: %w", err)
+		}
+	}
+	if len(reportRequest.AudioBytes) > 0 {
+		s.retainAudio(ctx, reportID, reportRequest, w)
+		s.checkAudioQuality(ctx, reportID, reportRequest, w)
+	}
 
-	// Stage 2: Transcribe audio
+	// Stage 2: Transcribe audio, or use the transcript supplied with the request
 	logger.Info("Starting stage 2: transcribing audio")
 	rawTranscript, err := s.processTranscript(ctx, reportRequest)
 	if err != nil {
@@ -226,16 +368,26 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 		diarizedTurns      []transcriber.TranscriptTurn
 	)
 
-	if s.diarization {
+	usedDiarization := s.usesDiarization(reportRequest)
+	if usedDiarization {
 		diarizedTurns, err = transcriber.StringToDiarizedTranscript(rawTranscript)
-		logger.Info("Diarized Turns Generated Transcript", zap.Any("diarizedTurns", diarizedTurns))
 		if err != nil {
 			return fmt.Errorf("GenerateReportPipeline: error unmarshaling diarized transcript: %w", err)
 		}
+		logger.Info("Diarized Turns Generated Transcript", zap.Int("turnCount", len(diarizedTurns)))
 	} else {
 		transcript = rawTranscript
 	}
 
+	// Detect the transcript's languages and settle the note and patient languages
+	reportRequest.detectLanguages(rawTranscript, diarizedTurns)
+	if err := s.reportsStore.SetTranscriptLanguages(ctx, reportID, reportRequest.TranscriptLanguages); err != nil {
+		logger.Error("GenerateReportPipeline: error storing transcript languages", zap.String("ReportID", reportID), zap.Error(err))
+	}
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.TranscriptLanguages, Value: reportRequest.TranscriptLanguages})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.NoteLanguage, Value: reportRequest.NoteLanguage})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.PatientLanguage, Value: reportRequest.PatientLanguage})
+
 	// Send content to frontend
 	sendContentToFrontend(w, ContentChanPayload{
 		Key: reports.Transcript,
@@ -243,13 +395,23 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 			Transcript:       transcript,
 			DiarizedTranscript: diarizedTurns,
 			ProviderID:       reportRequest.ProviderID,
-			UsedDiarization:  s.diarization,
+			UsedDiarization:  usedDiarization,
 		},
 	})
 
 	combinedUpdates := bson.D{
 		{Key: reports.Transcript, Value: rawTranscript},
-		{Key:reports.UsedDiarizationUpdateKey, Value: s.diarization},
+		{Key:reports.UsedDiarizationUpdateKey, Value: usedDiarization},
+		{Key: reports.Mode, Value: reportRequest.Mode},
+		{Key: reports.NoteLanguage, Value: reportRequest.NoteLanguage},
+		{Key: reports.PatientLanguage, Value: reportRequest.PatientLanguage},
+	}
+	// The trimmed duration is stored on its own; it is not a field UpdateReport validates.
+	if reportRequest.TrimmedDuration > 0 {
+		if err := s.reportsStore.SetTrimmedDuration(ctx, reportID, reportRequest.TrimmedDuration); err != nil {
+			logger.Error("GenerateReportPipeline: error storing trimmed duration", zap.String("ReportID", reportID), zap.Error(err))
+		}
+		sendContentToFrontend(w, ContentChanPayload{Key: reports.TrimmedDuration, Value: reportRequest.TrimmedDuration})
 	}
 
 	// Stage 3: Generate report sections (SOAP + summary + patient Instructions)
@@ -262,6 +424,11 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 
 	combinedUpdates = append(combinedUpdates, contentUpdates...)
 
+	// Group sessions also get an individual note per member
+	if err := s.storeMemberNotes(ctx, reportID, reportRequest, w, tokenUsage); err != nil {
+		return fmt.Errorf("GenerateReportPipeline: error generating member notes: %w", err)
+	}
+
 	// Stage 4: Record token usage
 	logger.Info("Starting stage 4: recording token usage")
 	if err := s.recordTokenUsage(ctx, reportID, reportRequest.ProviderID, tokenUsage); err != nil {
@@ -270,12 +437,19 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 		// For now, logging and continuing
 	}
 
-	sendContentToFrontend(w, ContentChanPayload{Key: reports.Status, Value: "success"})
 	// Stage 5: Update the report with generated content
 	logger.Info("Starting stage 5: updating report with generated content")
-	if err := s.updateFinalReport(ctx, reportID, combinedUpdates); err != nil {
+	recorded, err := s.recordSectionRevisions(ctx, reportID, reportRequest.ProviderID, sectionRevisions.SourceGeneration, reports.Report{}, contentUpdates)
+	if err != nil {
+		return fmt.Errorf("GenerateReportPipeline: error recording section revisions: %w", err)
+	}
+	version, err := s.updateFinalReport(ctx, reportID, locked, combinedUpdates)
+	if err != nil {
+		s.discardSectionRevisions(ctx, recorded)
 		return err
 	}
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.Status, Value: "success"})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.VersionKey, Value: version})
 
 	skipDefer = true
 	return nil
@@ -283,6 +457,8 @@ func (s *inferenceService) GenerateReportPipeline(ctx context.Context, reportReq
 
 // RegenerateReport regenerates the SOAP content based on key-value updates.
 // probably will not make reportContents a pointer. it doesn't seem like it will have a high access pattern
+// The pre-generation update locks the report for the regeneration: every other edit is rejected until the
+// regenerated content is stored or the regeneration fails and releases it.
 func (s *inferenceService) RegenerateReport(
 	ctx context.Context,
 	reportRequest *ReportRequest,
@@ -303,20 +479,43 @@ func (s *inferenceService) RegenerateReport(
 		reports.PatientOrClient: true,
 		reports.IsFollowUp:      true,
 		reports.LastVisitID:     true,
+		reports.NoteLanguage:    true,
+		reports.PatientLanguage: true,
 	}
-	for _, update := range reportRequest.Updates {
+	for i, update := range reportRequest.Updates {
 		if !allowedKeys[update.Key] {
 			logger.Info("Regeneration aborted: Invalid update key encountered", zap.String("Key", update.Key))
 			return fmt.Errorf("invalid update key: %s", update.Key)
 		}
+		if update.Key == reports.NoteLanguage || update.Key == reports.PatientLanguage {
+			language, ok := update.Value.(string)
+			code, supported := transcriber.NormalizeLanguage(language)
+			if !ok || !supported {
+				return fmt.Errorf("unsupported language for %s: %v", update.Key, update.Value)
+			}
+			reportRequest.Updates[i].Value = code
+		}
+	}
+
+	// The stored sections are kept as their original revision when they have no history yet
+	report, err := s.reportsStore.Get(ctx, reportRequest.ID)
+	if err != nil {
+		return fmt.Errorf("RegenerateReport: error fetching report: %w", err)
 	}
 
 	logger.Info("Regenerating report: Updating report with pre-generation state")
 	preUpdates := append(reportRequest.Updates, bson.D{{Key: reports.Status, Value: "success"}}...)
 
-	if err := s.reportsStore.UpdateReport(ctx, reportRequest.ID, preUpdates); err != nil {
+	locked, err := s.reportsStore.LockForRegeneration(ctx, reportRequest.ID, reportRequest.Version, preUpdates)
+	if err != nil {
 		return fmt.Errorf("RegenerateReport: error updating loading status before report regeneration: %w", err)
 	}
+	stored := false
+	defer func() {
+		if !stored {
+			s.releaseRegeneration(ctx, reportRequest.ID, locked)
+		}
+	}()
 
 	// Stage 3: Regenerate SOAP sections
 	logger.Info("Regenerating report: Generating report sections")
@@ -326,14 +525,154 @@ func (s *inferenceService) RegenerateReport(
 		return fmt.Errorf("RegenerateReport: error generating report sections while regenerating report: %w", err)
 	}
 
-	// Stage 4: Notify client and finalize
-	sendContentToFrontend(w, ContentChanPayload{Key: reports.Status, Value: "success"})
-
+	// Stage 4: Finalize and notify client
 	combinedUpdates = append(combinedUpdates, bson.D{{Key: reports.Status, Value: "success"}}...)
 	logger.Info("Updating report with regenerated content")
-	if err := s.reportsStore.UpdateReport(ctx, reportRequest.ID, combinedUpdates); err != nil {
+	recorded, err := s.recordSectionRevisions(ctx, reportRequest.ID, reportRequest.ProviderID, sectionRevisions.SourceRewrite, report, combinedUpdates)
+	if err != nil {
+		return fmt.Errorf("RegenerateReport: error recording section revisions: %w", err)
+	}
+	version, err := s.reportsStore.CompleteRegeneration(ctx, reportRequest.ID, locked, combinedUpdates)
+	if err != nil {
+		s.discardSectionRevisions(ctx, recorded)
 		return fmt.Errorf("RegenerateReport: error updating report after regeneration: %w", err)
 	}
+	stored = true
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.Status, Value: "success"})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.VersionKey, Value: version})
+	return nil
+}
+
+// RegenerateFromTranscript regenerates report sections from the stored transcript, e.g. after it was corrected.
+// Only the sections listed in the request are regenerated; when none are listed, the stale sections are,
+// and when no section is stale every transcript-derived section is. The report must still be at the request's
+// version; it is locked while the sections are generated, so no edit can be made in the meantime.
+func (s *inferenceService) RegenerateFromTranscript(
+	ctx context.Context,
+	reportRequest *ReportRequest,
+	w *utils.SafeResponseWriter,
+) error {
+	logger := contextLogger.FromCtx(ctx)
+
+	// Stage 1: Load the report, its transcript and the provider's styles
+	logger.Info("RegenerateFromTranscript: loading report and transcript", zap.String("report_id", reportRequest.ID))
+	report, err := s.reportsStore.Get(ctx, reportRequest.ID)
+	if err != nil {
+		return fmt.Errorf("RegenerateFromTranscript: error fetching report: %w", err)
+	}
+	if report.Version != reportRequest.Version {
+		return fmt.Errorf("RegenerateFromTranscript: %w", &reports.VersionConflictError{Current: report.Version})
+	}
+	transcripts, err := s.reportsStore.GetTranscription(ctx, reportRequest.ID)
+	if err != nil {
+		return fmt.Errorf("RegenerateFromTranscript: error fetching transcript: %w", err)
+	}
+	provider, err := s.userStore.Get(ctx, reportRequest.ProviderID)
+	if err != nil {
+		return fmt.Errorf("RegenerateFromTranscript: error fetching provider: %w", err)
+	}
+
+	// The member notes of a group session are regenerated when targeted explicitly, and otherwise
+	// alongside the stale or transcript-derived sections, since they share the transcript.
+	groupSession := transcriber.IsGroupSession(report.Participants)
+	regenerateMemberNotes := false
+	sections := make([]string, 0, len(reportRequest.Sections))
+	for _, section := range reportRequest.Sections {
+		if section == reports.MemberNotes && groupSession {
+			regenerateMemberNotes = true
+			continue
+		}
+		sections = append(sections, section)
+	}
+	if len(reportRequest.Sections) == 0 {
+		sections = report.StaleSections()
+		if len(sections) == 0 {
+			sections = reports.TranscriptDerivedSections
+		}
+		regenerateMemberNotes = groupSession
+	}
+	for _, section := range sections {
+		if _, ok := report.Section(section); !ok {
+			return fmt.Errorf("RegenerateFromTranscript: invalid section: %s", section)
+		}
+	}
+
+	transcript := transcripts.Transcript
+	if transcripts.UsedDiarization {
+		transcript, err = transcriber.CompressDiarizedText(transcripts.Transcript)
+		if err != nil {
+			return fmt.Errorf("RegenerateFromTranscript: error compressing diarized transcript: %w", err)
+		}
+	}
+
+	// Section content is left empty so every targeted section is generated fresh from the transcript.
+	regenerationRequest := &ReportRequest{
+		ID:                       reportRequest.ID,
+		ProviderID:               reportRequest.ProviderID,
+		ProviderName:             provider.Name,
+		PatientName:              report.Name,
+		TranscribedAudio:         transcript,
+		VisitContext:             reportRequest.VisitContext,
+		SubjectiveStyle:          provider.SubjectiveStyle,
+		ObjectiveStyle:           provider.ObjectiveStyle,
+		AssessmentAndPlanStyle:   provider.AssessmentAndPlanStyle,
+		PatientInstructionsStyle: provider.PatientInstructionsStyle,
+		SummaryStyle:             provider.SummaryStyle,
+		Sections:                 sections,
+		Mode:                     report.Mode,
+		Participants:             report.Participants,
+		NoteLanguage:             cmp.Or(reportRequest.NoteLanguage, report.NoteLanguage),
+		PatientLanguage:          cmp.Or(reportRequest.PatientLanguage, report.PatientLanguage),
+		TranscriptLanguages:      report.TranscriptLanguages,
+	}
+
+	locked, err := s.reportsStore.LockForRegeneration(ctx, reportRequest.ID, reportRequest.Version, nil)
+	if err != nil {
+		return fmt.Errorf("RegenerateFromTranscript: error locking report for regeneration: %w", err)
+	}
+	stored := false
+	defer func() {
+		if !stored {
+			s.releaseRegeneration(ctx, reportRequest.ID, locked)
+		}
+	}()
+
+	// Stage 2: Regenerate the targeted sections
+	logger.Info("RegenerateFromTranscript: generating report sections", zap.Strings("sections", sections), zap.Bool("memberNotes", regenerateMemberNotes))
+	tokenUsage := utils.NewSafeMap[int]()
+	combinedUpdates := bson.D{}
+	if len(sections) > 0 {
+		combinedUpdates, err = s.generateSoapSections(ctx, regenerationRequest, w, tokenUsage)
+		if err != nil {
+			return fmt.Errorf("RegenerateFromTranscript: error generating report sections: %w", err)
+		}
+	}
+	if regenerateMemberNotes {
+		if err := s.storeMemberNotes(ctx, reportRequest.ID, regenerationRequest, w, tokenUsage); err != nil {
+			return fmt.Errorf("RegenerateFromTranscript: error generating member notes: %w", err)
+		}
+	}
+
+	// Stage 3: Finalize and notify client
+	combinedUpdates = append(combinedUpdates, bson.E{Key: reports.Status, Value: "success"})
+	if regenerationRequest.NoteLanguage != "" {
+		combinedUpdates = append(combinedUpdates, bson.E{Key: reports.NoteLanguage, Value: regenerationRequest.NoteLanguage})
+	}
+	if regenerationRequest.PatientLanguage != "" {
+		combinedUpdates = append(combinedUpdates, bson.E{Key: reports.PatientLanguage, Value: regenerationRequest.PatientLanguage})
+	}
+	recorded, err := s.recordSectionRevisions(ctx, reportRequest.ID, reportRequest.ProviderID, sectionRevisions.SourceRegeneration, report, combinedUpdates)
+	if err != nil {
+		return fmt.Errorf("RegenerateFromTranscript: error recording section revisions: %w", err)
+	}
+	version, err := s.reportsStore.CompleteRegeneration(ctx, reportRequest.ID, locked, combinedUpdates)
+	if err != nil {
+		s.discardSectionRevisions(ctx, recorded)
+		return fmt.Errorf("RegenerateFromTranscript: error updating report after regeneration: %w", err)
+	}
+	stored = true
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.Status, Value: "success"})
+	sendContentToFrontend(w, ContentChanPayload{Key: reports.VersionKey, Value: version})
 	return nil
 }
 
@@ -364,38 +703,22 @@ func (s *inferenceService) LearnStyle(ctx context.Context, providerID, contentSe
 	return nil
 }
 
-func contentPromptFunc(
-	transcript string,
-	targetSection string,
-	context string,
-	style string,
-	providerName string,
-	patientName string,
-	content string,
-	updates bson.D,
-) string {
+// contentPromptFunc builds the prompt for a section: a generation prompt when the section has no
+// content yet, otherwise a prompt rewriting the existing content with the metadata updates.
+func contentPromptFunc(cfg generatePromptConfig, content string, updates bson.D) string {
 	if content == "" {
-		cfg := generatePromptConfig{
-			transcript:         transcript,
-			targetSection:      targetSection,
-			context:            context,
-			style:              style,
-			providerName:       providerName,
-			patientName:        patientName,
-		}
 		return GenerateReportContentPrompt(cfg)
 	}
 
-	cfg := regeneratePromptConfig{
-		transcript:         transcript,
-		targetSection:      targetSection,
+	return RegenerateReportContentPrompt(regeneratePromptConfig{
+		transcript:         cfg.transcript,
+		targetSection:      cfg.targetSection,
 		targetContent:      content,
-		priorVisitContext:  context,
-		providerName:       providerName,
-		patientName:        patientName,
+		priorVisitContext:  cfg.context,
+		providerName:       cfg.providerName,
+		patientName:        cfg.patientName,
 		reportUpdates:      updates,
-	}
-	return RegenerateReportContentPrompt(cfg)
+	})
 }
 
 func (s *inferenceService) generateSectionPipeline(
@@ -403,6 +726,7 @@ func (s *inferenceService) generateSectionPipeline(
 	systemPrompt,
 	queryMessage, 
 	field string,
+	pseudonyms *pseudonymizer,
 	tokenUsage *utils.SafeMap[int],
 	aggregator func(...bson.E),
 	writer *utils.SafeResponseWriter,
@@ -415,18 +739,19 @@ func (s *inferenceService) generateSectionPipeline(
 	if err != nil {
 		return fmt.Errorf("error generating report section: %w", err)
 	}
+	content := pseudonyms.reidentify(response.Content)
 
 	// Stage 2: Record token usage
 	tokenUsage.Set(field+"Tokens", response.Usage.TotalTokens)
 
 	// Stage 3: Send content to frontend
-	sendContentToFrontend(writer, ContentChanPayload{Key: field, Value: response.Content})
+	sendContentToFrontend(writer, ContentChanPayload{Key: field, Value: content})
 
 	// Stage 4: Aggregate updates
 	aggregator(bson.E{
 		Key: field,
 		Value: bson.D{
-			{Key: reports.ContentData, Value: response.Content},
+			{Key: reports.ContentData, Value: content},
 			{Key: reports.Loading, Value: false},
 		},
 	})
@@ -469,116 +794,96 @@ func (s *inferenceService) generateSoapSections(
 		m.Unlock()
 	}
 
+	// Identifiers are replaced with placeholders before any prompt is built, and restored in the output.
+	pseudonyms, err := newPseudonymizer(reportRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing report request: %w", err)
+	}
+	transcribedAudio, err := pseudonyms.pseudonymize(ctx, reportRequest.TranscribedAudio)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing transcript: %w", err)
+	}
+	visitContext, err := pseudonyms.pseudonymize(ctx, reportRequest.VisitContext)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing visit context: %w", err)
+	}
+	patientName, err := pseudonyms.pseudonymize(ctx, reportRequest.PatientName)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing patient name: %w", err)
+	}
+	participants, err := pseudonyms.pseudonymizeRoster(ctx, reportRequest.Participants)
+	if err != nil {
+		return nil, fmt.Errorf("error pseudonymizing participants: %w", err)
+	}
+
 	stitchSystemPrompt := func (subSystemPrompt string) string{
-		return fmt.Sprintf("%s\n%s\n%s\n%s", baseSystemPrompt, subSystemPrompt,defaultReturnFormatSystemPrompt, defaultWarningsSystemPrompt)
-	}
-
-	// Generate Subjective Section
-	g.Go(func() error {
-		contentPrompt := contentPromptFunc(
-			reportRequest.TranscribedAudio,
-			reports.Subjective,
-			reportRequest.VisitContext,
-			reportRequest.SubjectiveStyle,
-			reportRequest.ProviderName,
-			reportRequest.PatientName,
-			reportRequest.SubjectiveContent,
-			reportRequest.Updates,
-		)
-		err := s.generateSectionPipeline(ctx, stitchSystemPrompt(subjectiveTaskDescription), contentPrompt, reports.Subjective, tokenUsage, aggregateUpdates, w)
-		if err != nil {
-			return fmt.Errorf("error generating report section: %w", err)
+		systemPrompt := fmt.Sprintf("%s\n%s\n%s\n%s", baseSystemPrompt, subSystemPrompt,defaultReturnFormatSystemPrompt, defaultWarningsSystemPrompt)
+		if pseudonyms.hasPlaceholders() {
+			systemPrompt += pseudonymizedSystemPrompt
 		}
-		return nil
-	})
+		return systemPrompt
+	}
 
-	// Generate Objective Section
-	g.Go(func() error {
-		contentPrompt := contentPromptFunc(
-			reportRequest.TranscribedAudio,
-			reports.Objective,
-			reportRequest.VisitContext,
-			reportRequest.ObjectiveStyle,
-			reportRequest.ProviderName,
-			reportRequest.PatientName,
-			reportRequest.ObjectiveContent,
-			reportRequest.Updates,
-		)
-		err := s.generateSectionPipeline(ctx, stitchSystemPrompt(objectiveTaskDescription), contentPrompt, reports.Objective, tokenUsage, aggregateUpdates, w)
-		if err != nil {
-			return fmt.Errorf("error generating report section: %w", err)
-		}
-		return nil
-	})
+	// Spoken section cues in a dictation route the content under them straight to their section.
+	var dictation DictatedSections
+	if reportRequest.dictated() {
+		dictation = SplitDictation(transcribedAudio)
+	}
 
-	// Generate Assessment and Plan Section
-	g.Go(func() error {
-		contentPrompt := contentPromptFunc(
-			reportRequest.TranscribedAudio,
-			reports.AssessmentAndPlan,
-			reportRequest.VisitContext,
-			reportRequest.AssessmentAndPlanStyle,
-			reportRequest.ProviderName,
-			reportRequest.PatientName,
-			reportRequest.AssessmentAndPlanContent,
-			reportRequest.Updates,
-		)
-		err := s.generateSectionPipeline(ctx, stitchSystemPrompt(assessmentAndPlanTaskDescription),contentPrompt, reports.AssessmentAndPlan, tokenUsage, aggregateUpdates, w)
-		if err != nil {
-			return fmt.Errorf("error generating report section: %w", err)
+	for _, section := range reportRequest.soapSections() {
+		if !reportRequest.includesSection(section.name) {
+			continue
 		}
-		return nil
-	})
-	
-	// Generate Patient Instructions Section
-	g.Go(func() error {
-		contentPrompt := contentPromptFunc(
-			reportRequest.TranscribedAudio,
-			reports.PatientInstructions,
-			reportRequest.VisitContext,
-			reportRequest.PatientInstructionsStyle,
-			reportRequest.ProviderName,
-			reportRequest.PatientName,
-			reportRequest.PatientInstructionContent,
-			reportRequest.Updates,
-		)
-		err := s.generateSectionPipeline(ctx, stitchSystemPrompt(patientInstruction),contentPrompt, reports.PatientInstructions, tokenUsage, aggregateUpdates, w)
-		if err != nil {
-			return fmt.Errorf("error generating report section: %w", err)
+		transcript, dictatedSection := transcribedAudio, false
+		if reportRequest.dictated() {
+			transcript, dictatedSection = dictatedSectionTranscript(dictation, transcribedAudio, section.name)
 		}
-		return nil
-	})
-
-
-	// Generate Summary and Sub-Summaries
-	g.Go(func() error {
-		contentPrompt := contentPromptFunc(
-			reportRequest.TranscribedAudio,
-			reports.Summary,
-			reportRequest.VisitContext,
-			reportRequest.SummaryStyle,
-			reportRequest.ProviderName,
-			reportRequest.PatientName,
-			reportRequest.SummaryContent,
-			reportRequest.Updates,
-		)
-		err := s.generateSectionPipeline(ctx, stitchSystemPrompt(summaryTaskDescription),contentPrompt, reports.Summary, tokenUsage, aggregateUpdates, w)
+		// Existing content being rewritten carries the identifiers restored in an earlier generation.
+		content, err := pseudonyms.pseudonymize(ctx, section.content)
 		if err != nil {
-			return fmt.Errorf("error generating report section: %w", err)
+			return nil, fmt.Errorf("error pseudonymizing %s content: %w", section.name, err)
 		}
-
-		summary := getSectionValue(combinedUpdates, reports.Summary)
-		if summary == "" {
-			return fmt.Errorf("error generating report sub-summaries due to no summary generated: %w", err)
+		// Make sure no raw identifier leaves with the prompt
+		if err := pseudonyms.checkLeaks(transcript, visitContext, patientName, transcriber.FormatRoster(participants), content); err != nil {
+			return nil, fmt.Errorf("error generating report section %s: %w", section.name, err)
 		}
+		g.Go(func() error {
+			contentPrompt := contentPromptFunc(generatePromptConfig{
+				transcript:      transcript,
+				targetSection:   section.name,
+				context:         visitContext,
+				style:           section.style,
+				providerName:    reportRequest.ProviderName,
+				patientName:     patientName,
+				dictation:       reportRequest.dictated(),
+				dictatedSection: dictatedSection,
+				participants:    participants,
+				language:        reportRequest.sectionLanguage(section.name),
+			}, content, sectionUpdates(reportRequest.Updates, section.name))
+			err := s.generateSectionPipeline(ctx, stitchSystemPrompt(section.taskDescription), contentPrompt, section.name, pseudonyms, tokenUsage, aggregateUpdates, w)
+			if err != nil {
+				return fmt.Errorf("error generating report section: %w", err)
+			}
+			if section.name != reports.Summary {
+				return nil
+			}
 
-		// Generate condensed and session summaries
-		err = s.generateSummaries(ctx, summary, tokenUsage, aggregateUpdates, w)
-		if err != nil {
-			return fmt.Errorf("error generating report section: %w", err)
-		}
-		return nil
-	})
+			// Generate Sub-Summaries
+			m.Lock()
+			summary := getSectionValue(combinedUpdates, reports.Summary)
+			m.Unlock()
+			if summary == "" {
+				return fmt.Errorf("error generating report sub-summaries due to no summary generated: %w", err)
+			}
+
+			// Generate condensed and session summaries
+			err = s.generateSummaries(ctx, summary, pseudonyms, tokenUsage, aggregateUpdates, w)
+			if err != nil {
+				return fmt.Errorf("error generating report section: %w", err)
+			}
+			return nil
+		})
+	}
 
 	if err := g.Wait(); err != nil {
 		return nil, err
@@ -594,12 +899,22 @@ func (s *inferenceService) generateSoapSections(
 func (s *inferenceService) generateSummaries(
 	ctx context.Context,
 	summary string,
+	pseudonyms *pseudonymizer,
 	tokenUsage *utils.SafeMap[int],
 	aggregator func(...bson.E),
 	writer *utils.SafeResponseWriter,
 ) error {
 	logger := contextLogger.FromCtx(ctx)
 
+	// The summary was re-identified when it was generated, so it is pseudonymized again before being sent on
+	summary, err := pseudonyms.pseudonymize(ctx, summary)
+	if err != nil {
+		return fmt.Errorf("error pseudonymizing summary: %w", err)
+	}
+	if err := pseudonyms.checkLeaks(summary); err != nil {
+		return fmt.Errorf("error generating summaries: %w", err)
+	}
+
 	// Generate condensed summary
 	logger.Info("generateSummaries: generating condensed summary")
 	condensed, err := s.chat.Query(ctx, condensedSummary, summary, Chat.MaxTokens)
@@ -618,6 +933,8 @@ func (s *inferenceService) generateSummaries(
 	tokenUsage.Set(reports.CondensedSummary, condensed.Usage.TotalTokens)
 	tokenUsage.Set(reports.SessionSummary, session.Usage.TotalTokens)
 
+	condensed.Content = pseudonyms.reidentify(condensed.Content)
+	session.Content = pseudonyms.reidentify(session.Content)
 	sendContentToFrontend(writer, ContentChanPayload{Key: reports.CondensedSummary, Value: condensed.Content})
 	sendContentToFrontend(writer, ContentChanPayload{Key: reports.SessionSummary, Value: session.Content})
 
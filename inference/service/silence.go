@@ -0,0 +1,57 @@
+package inferenceService
+
+import (
+	"Medscribe/audio"
+	contextLogger "Medscribe/logger"
+	transcriber "Medscribe/transcription"
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// trimSilence removes long silences from PCM recordings before they are sent for transcription,
+// since the backends bill by audio length. It returns the audio to transcribe and the map from its
+// timeline back to the original recording, and records the trimmed duration on the request.
+// Recordings that cannot be trimmed are transcribed unchanged.
+func (s *inferenceService) trimSilence(ctx context.Context, reportRequest *ReportRequest) ([]byte, audio.TimeMap) {
+	logger := contextLogger.FromCtx(ctx)
+
+	result, err := audio.TrimSilence(reportRequest.AudioBytes, audio.DefaultTrimOptions())
+	if err != nil {
+		if !errors.Is(err, audio.ErrUnsupportedFormat) {
+			logger.Warn("trimSilence: transcribing untrimmed audio", zap.Error(err))
+		}
+		return reportRequest.AudioBytes, audio.TimeMap{}
+	}
+
+	reportRequest.TrimmedDuration = result.TrimmedDuration
+	logger.Info("trimSilence: silence trimmed",
+		zap.Float64("OriginalDuration", result.OriginalDuration),
+		zap.Float64("TrimmedDuration", result.TrimmedDuration),
+		zap.Int("Segments", len(result.TimeMap.Segments)))
+	return result.Audio, result.TimeMap
+}
+
+// restoreTimeline translates the timestamps of turns transcribed from trimmed audio back to the original recording.
+func restoreTimeline(turns []transcriber.TranscriptTurn, timeMap audio.TimeMap) []transcriber.TranscriptTurn {
+	if len(timeMap.Segments) == 0 {
+		return turns
+	}
+	restored := make([]transcriber.TranscriptTurn, len(turns))
+	for i, turn := range turns {
+		turn.StartTime = timeMap.ToOriginal(turn.StartTime)
+		turn.EndTime = timeMap.ToOriginal(turn.EndTime)
+		if len(turn.Words) > 0 {
+			words := make([]transcriber.TranscriptWord, len(turn.Words))
+			for j, word := range turn.Words {
+				word.StartTime = timeMap.ToOriginal(word.StartTime)
+				word.EndTime = timeMap.ToOriginal(word.EndTime)
+				words[j] = word
+			}
+			turn.Words = words
+		}
+		restored[i] = turn
+	}
+	return restored
+}
@@ -0,0 +1,195 @@
+package inferenceService
+
+import (
+	contextLogger "Medscribe/logger"
+	transcriber "Medscribe/transcription"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// speakerResolutionSampleTurns caps how much of the transcript is sent to the model to identify speakers.
+const speakerResolutionSampleTurns = 40
+
+const speakerResolutionSystemPrompt = `
+You are an AI medical assistant identifying who is speaking in a diarized clinical visit transcript.
+Each line is formatted as [speaker label]: text.
+Assign every speaker label exactly one of these roles: "provider", "patient", "other", "interpreter".
+Use "other" for family members, caregivers or anyone who is neither the provider, the patient nor an interpreter.
+Return only a JSON object mapping each speaker label to its role, with no markdown and no explanation.
+Example: {"Speaker1": "provider", "Speaker2": "patient"}
+`
+
+const rosterResolutionSystemPrompt = `
+You are an AI medical assistant identifying who is speaking in a diarized clinical session transcript.
+Each line is formatted as [speaker label]: text.
+The session participants are listed below as "- ID: name (role)". Assign every speaker label the ID of the participant speaking,
+using self-introductions, people being addressed by name and what each speaker says about themselves.
+Return only a JSON object mapping each speaker label to a participant ID, with no markdown and no explanation.
+Example: {"Speaker1": "provider", "Speaker2": "patient_1"}
+
+Participants:
+%s`
+
+// resolveSpeakerRoles normalizes the speaker labels of a diarized transcript to stable roles, or
+// to the participant IDs of the session roster when the request has one.
+// Heuristics are tried first; when they cannot clearly tell the speakers apart the chat model
+// is asked for a second opinion, with the identifiers of the request replaced by placeholders.
+// If that fails, the heuristic mapping is used as is.
+func (s *inferenceService) resolveSpeakerRoles(ctx context.Context, turns []transcriber.TranscriptTurn, reportRequest *ReportRequest) []transcriber.TranscriptTurn {
+	logger := contextLogger.FromCtx(ctx)
+
+	if len(reportRequest.Participants) > 0 {
+		return s.resolveRosterSpeakers(ctx, turns, reportRequest)
+	}
+
+	resolution := transcriber.ResolveSpeakerRoles(turns)
+	if !resolution.Confident {
+		logger.Info("resolveSpeakerRoles: heuristics inconclusive, querying chat model")
+		roles, err := s.querySpeakerRoles(ctx, turns, reportRequest)
+		if err != nil {
+			logger.Warn("resolveSpeakerRoles: falling back to heuristic speaker roles", zap.Error(err))
+		} else {
+			for label, role := range roles {
+				resolution.Roles[label] = role
+			}
+		}
+	}
+
+	return transcriber.ApplySpeakerRoles(turns, resolution.Roles)
+}
+
+// resolveRosterSpeakers maps the speaker labels of a diarized transcript onto the session roster.
+func (s *inferenceService) resolveRosterSpeakers(ctx context.Context, turns []transcriber.TranscriptTurn, reportRequest *ReportRequest) []transcriber.TranscriptTurn {
+	logger := contextLogger.FromCtx(ctx)
+	roster := reportRequest.Participants
+
+	resolution := transcriber.MapSpeakersToRoster(turns, roster)
+	if !resolution.Confident {
+		logger.Info("resolveRosterSpeakers: heuristics inconclusive, querying chat model", zap.Int("participants", len(roster)))
+		participants, err := s.queryRosterSpeakers(ctx, turns, reportRequest)
+		if err != nil {
+			logger.Warn("resolveRosterSpeakers: falling back to heuristic participant mapping", zap.Error(err))
+		} else {
+			for label, id := range participants {
+				resolution.Roles[label] = id
+			}
+		}
+	}
+
+	return transcriber.ApplySpeakerRoles(turns, resolution.Roles)
+}
+
+// queryRosterSpeakers asks the chat model to map the raw speaker labels onto roster participant IDs.
+// The names on the roster and in the transcript are replaced by the same placeholders, so the model can
+// still match people addressed by name to the roster.
+func (s *inferenceService) queryRosterSpeakers(ctx context.Context, turns []transcriber.TranscriptTurn, reportRequest *ReportRequest) (map[string]string, error) {
+	roster := reportRequest.Participants
+	pseudonyms, err := newPseudonymizer(reportRequest)
+	if err != nil {
+		return nil, fmt.Errorf("queryRosterSpeakers: %w", err)
+	}
+	pseudonymizedRoster, err := pseudonyms.pseudonymizeRoster(ctx, roster)
+	if err != nil {
+		return nil, fmt.Errorf("queryRosterSpeakers: error pseudonymizing participants: %w", err)
+	}
+	formattedRoster := transcriber.FormatRoster(pseudonymizedRoster)
+	excerpt, err := pseudonymizedExcerpt(ctx, pseudonyms, turns)
+	if err != nil {
+		return nil, fmt.Errorf("queryRosterSpeakers: %w", err)
+	}
+	if err := pseudonyms.checkLeaks(formattedRoster, excerpt); err != nil {
+		return nil, fmt.Errorf("queryRosterSpeakers: %w", err)
+	}
+
+	response, err := s.chat.Query(ctx, fmt.Sprintf(rosterResolutionSystemPrompt, formattedRoster), excerpt, 300)
+	if err != nil {
+		return nil, fmt.Errorf("queryRosterSpeakers: error querying chat model: %w", err)
+	}
+
+	var participants map[string]string
+	if err := json.Unmarshal([]byte(stripCodeFence(response.Content)), &participants); err != nil {
+		return nil, fmt.Errorf("queryRosterSpeakers: error parsing participants: %w", err)
+	}
+
+	valid := make(map[string]string, len(participants))
+	for label, id := range participants {
+		if _, ok := transcriber.RosterParticipant(roster, id); ok {
+			valid[label] = id
+		}
+	}
+	return valid, nil
+}
+
+// speakerExcerpt formats the opening turns of a transcript for speaker identification.
+func speakerExcerpt(turns []transcriber.TranscriptTurn) string {
+	sample := turns
+	if len(sample) > speakerResolutionSampleTurns {
+		sample = sample[:speakerResolutionSampleTurns]
+	}
+
+	var excerpt strings.Builder
+	for _, turn := range sample {
+		excerpt.WriteString(fmt.Sprintf("[%s]: %s\n", turn.Speaker, turn.Text))
+	}
+	return excerpt.String()
+}
+
+// pseudonymizedExcerpt formats the opening turns of a transcript for speaker identification, with
+// their identifiers replaced.
+func pseudonymizedExcerpt(ctx context.Context, pseudonyms *pseudonymizer, turns []transcriber.TranscriptTurn) (string, error) {
+	sample := turns
+	if len(sample) > speakerResolutionSampleTurns {
+		sample = sample[:speakerResolutionSampleTurns]
+	}
+	pseudonymized, err := pseudonyms.pseudonymizeTurns(ctx, sample)
+	if err != nil {
+		return "", fmt.Errorf("error pseudonymizing transcript: %w", err)
+	}
+	return speakerExcerpt(pseudonymized), nil
+}
+
+// querySpeakerRoles asks the chat model to map the raw speaker labels onto stable roles.
+func (s *inferenceService) querySpeakerRoles(ctx context.Context, turns []transcriber.TranscriptTurn, reportRequest *ReportRequest) (map[string]string, error) {
+	pseudonyms, err := newPseudonymizer(reportRequest)
+	if err != nil {
+		return nil, fmt.Errorf("querySpeakerRoles: %w", err)
+	}
+	excerpt, err := pseudonymizedExcerpt(ctx, pseudonyms, turns)
+	if err != nil {
+		return nil, fmt.Errorf("querySpeakerRoles: %w", err)
+	}
+	if err := pseudonyms.checkLeaks(excerpt); err != nil {
+		return nil, fmt.Errorf("querySpeakerRoles: %w", err)
+	}
+
+	response, err := s.chat.Query(ctx, speakerResolutionSystemPrompt, excerpt, 200)
+	if err != nil {
+		return nil, fmt.Errorf("querySpeakerRoles: error querying chat model: %w", err)
+	}
+
+	var roles map[string]string
+	if err := json.Unmarshal([]byte(stripCodeFence(response.Content)), &roles); err != nil {
+		return nil, fmt.Errorf("querySpeakerRoles: error parsing speaker roles: %w", err)
+	}
+
+	validRoles := make(map[string]string, len(roles))
+	for label, role := range roles {
+		if normalized, ok := transcriber.NormalizeSpeakerRole(role); ok {
+			validRoles[label] = normalized
+		}
+	}
+	return validRoles, nil
+}
+
+// stripCodeFence removes the markdown code fence models sometimes wrap JSON output in.
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,20 @@
+package inferenceService
+
+import (
+	contextLogger "Medscribe/logger"
+	transcriber "Medscribe/transcription"
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// transcriptionOptions loads the provider's custom vocabulary for the transcription backends.
+// A failed lookup is logged and transcription continues without vocabulary hints.
+func (s *inferenceService) transcriptionOptions(ctx context.Context, providerID string) transcriber.Options {
+	provider, err := s.userStore.Get(ctx, providerID)
+	if err != nil {
+		contextLogger.FromCtx(ctx).Warn("transcriptionOptions: transcribing without provider vocabulary", zap.Error(err))
+		return transcriber.Options{}
+	}
+	return transcriber.Options{Vocabulary: provider.Vocabulary}
+}
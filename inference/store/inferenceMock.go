@@ -10,7 +10,7 @@ type MockInferenceStore struct {
 	mock.Mock
 }
 
-func (m *MockInferenceStore) Query(ctx context.Context, request string, tokens int) (InferenceResponse, error) {
-	args := m.Called(ctx, request, tokens)
+func (m *MockInferenceStore) Query(ctx context.Context, systemPrompt, request string, tokens int) (InferenceResponse, error) {
+	args := m.Called(ctx, systemPrompt, request, tokens)
 	return args.Get(0).(InferenceResponse), args.Error(1)
 }
@@ -0,0 +1,165 @@
+package contextLogger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Classification says how sensitive a logged field is.
+type Classification int
+
+const (
+	// ClassSafe fields are logged as they are.
+	ClassSafe Classification = iota
+	// ClassPHI fields identify a patient or a provider, or carry clinical content. They are hashed or
+	// redacted in production and logged as they are in development.
+	ClassPHI
+	// ClassSecret fields are credentials. They are redacted in every environment.
+	ClassSecret
+)
+
+const redacted = "[REDACTED]"
+
+// fieldClassifications classifies field keys that must never be logged raw, by their normalized key.
+// Fields logged under these keys are redacted even when they were not built with PHI or Secret.
+var fieldClassifications = map[string]Classification{
+	"email":              ClassPHI,
+	"name":               ClassPHI,
+	"newname":            ClassPHI,
+	"patientname":        ClassPHI,
+	"providername":       ClassPHI,
+	"phone":              ClassPHI,
+	"address":            ClassPHI,
+	"dateofbirth":        ClassPHI,
+	"transcript":         ClassPHI,
+	"diarizedtranscript": ClassPHI,
+	"diarizedturns":      ClassPHI,
+	"turns":              ClassPHI,
+	"note":               ClassPHI,
+	"notes":              ClassPHI,
+	"content":            ClassPHI,
+	"summary":            ClassPHI,
+	"prompt":             ClassPHI,
+
+	"secret":        ClassSecret,
+	"jwtsecret":     ClassSecret,
+	"password":      ClassSecret,
+	"token":         ClassSecret,
+	"accesstoken":   ClassSecret,
+	"refreshtoken":  ClassSecret,
+	"resetlink":     ClassSecret,
+	"apikey":        ClassSecret,
+	"authorization": ClassSecret,
+	"mongouri":      ClassSecret,
+}
+
+// normalizeKey lowercases a field key and drops everything but letters and digits, so "newName",
+// "NewName" and "new_name" are classified alike.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
+
+// Classify returns the classification of a field key.
+func Classify(key string) Classification {
+	return fieldClassifications[normalizeKey(key)]
+}
+
+// classifiedValue is a field value built with PHI, HashedPHI or Secret. Its String form is the value
+// safe to log in production, so it stays masked even on a logger without the redacting core.
+type classifiedValue struct {
+	class  Classification
+	hashed bool
+	value  interface{}
+}
+
+func (v classifiedValue) String() string {
+	if v.class == ClassPHI && v.hashed {
+		return hashValue(v.value)
+	}
+	return redacted
+}
+
+// PHI logs a value that identifies a person or carries clinical content. It is redacted in production.
+func PHI(key string, value interface{}) zap.Field {
+	return zap.Stringer(key, classifiedValue{class: ClassPHI, value: value})
+}
+
+// HashedPHI logs an identifier, such as an email address, that is hashed in production so log lines
+// about the same person can still be correlated.
+func HashedPHI(key, value string) zap.Field {
+	return zap.Stringer(key, classifiedValue{class: ClassPHI, hashed: true, value: value})
+}
+
+// Secret logs a credential. It is always redacted; use it only to record whether a secret was set.
+func Secret(key, value string) zap.Field {
+	return zap.Stringer(key, classifiedValue{class: ClassSecret, value: value})
+}
+
+func hashValue(value interface{}) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(fmt.Sprint(value)))))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// redactingCore masks classified fields before they reach the wrapped core.
+type redactingCore struct {
+	zapcore.Core
+	production bool
+}
+
+func newRedactingCore(core zapcore.Core, production bool) zapcore.Core {
+	return &redactingCore{Core: core, production: production}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redact(fields)), production: c.production}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+// redact returns the fields with classified values masked for the environment.
+func (c *redactingCore) redact(fields []zapcore.Field) []zapcore.Field {
+	masked := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		masked[i] = c.redactField(field)
+	}
+	return masked
+}
+
+func (c *redactingCore) redactField(field zapcore.Field) zapcore.Field {
+	if value, ok := field.Interface.(classifiedValue); ok && field.Type == zapcore.StringerType {
+		if value.class == ClassPHI && !c.production {
+			return zap.Any(field.Key, value.value)
+		}
+		return zap.String(field.Key, value.String())
+	}
+
+	switch Classify(field.Key) {
+	case ClassSecret:
+		return zap.String(field.Key, redacted)
+	case ClassPHI:
+		if c.production {
+			return zap.String(field.Key, redacted)
+		}
+	}
+	return field
+}
@@ -0,0 +1,136 @@
+package contextLogger
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Classification
+	}{
+		{"email", ClassPHI},
+		{"Email", ClassPHI},
+		{"diarizedTurns", ClassPHI},
+		{"NewName", ClassPHI},
+		{"jwt_secret", ClassSecret},
+		{"reset_link", ClassSecret},
+		{"ReportID", ClassSafe},
+		{"turnCount", ClassSafe},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.key))
+		})
+	}
+}
+
+func TestRedactingCore(t *testing.T) {
+	tests := []struct {
+		name       string
+		production bool
+		field      zap.Field
+		want       interface{}
+	}{
+		{name: "PHI in development", field: PHI("name", "Ada Okafor"), want: "Ada Okafor"},
+		{name: "PHI in production", production: true, field: PHI("name", "Ada Okafor"), want: redacted},
+		{name: "hashed PHI in development", field: HashedPHI("email", "ada@example.com"), want: "ada@example.com"},
+		{name: "hashed PHI in production", production: true, field: HashedPHI("email", "ada@example.com"), want: hashValue("ADA@example.com ")},
+		{name: "secret in development", field: Secret("token", "123456"), want: redacted},
+		{name: "secret in production", production: true, field: Secret("token", "123456"), want: redacted},
+		{name: "raw PHI key in production", production: true, field: zap.String("transcript", "chest pain"), want: redacted},
+		{name: "raw secret key in development", field: zap.String("password", "hunter2"), want: redacted},
+		{name: "safe field", production: true, field: zap.String("ReportID", "abc"), want: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			logger := zap.New(newRedactingCore(core, tt.production))
+
+			logger.Info("message", tt.field)
+			logger.With(tt.field).Info("message")
+
+			require.Equal(t, 2, logs.Len())
+			for _, entry := range logs.All() {
+				assert.Equal(t, tt.want, entry.ContextMap()[tt.field.Key])
+			}
+		})
+	}
+}
+
+func TestClassifiedFieldsWithoutRedactingCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	zap.New(core).Info("message", PHI("name", "Ada Okafor"), Secret("token", "123456"))
+
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, redacted, fields["name"])
+	assert.Equal(t, redacted, fields["token"])
+}
+
+// TestNoRawClassifiedFields fails when code logs a PHI or secret field key with a raw zap constructor
+// instead of PHI, HashedPHI or Secret.
+func TestNoRawClassifiedFields(t *testing.T) {
+	root, err := filepath.Abs("..")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	var violations []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name == "vendor" || name == "integration_tests" || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			pkg, ok := selector.X.(*ast.Ident)
+			if !ok || pkg.Name != "zap" {
+				return true
+			}
+			key, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || key.Kind != token.STRING {
+				return true
+			}
+			name, err := strconv.Unquote(key.Value)
+			if err == nil && Classify(name) != ClassSafe {
+				violations = append(violations, fset.Position(call.Pos()).String()+": zap."+selector.Sel.Name+"("+key.Value+", ...)")
+			}
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, violations, "log classified fields with contextLogger.PHI, HashedPHI or Secret")
+}
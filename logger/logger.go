@@ -12,7 +12,8 @@ import (
 // ctxKey is used as the context key for the logger.
 type ctxKey struct{}
 
-// NewLogger creates a new zap.Logger based on the environment.
+// NewLogger creates a new zap.Logger based on the environment. Fields classified as PHI are masked in
+// production and secrets in every environment; see Classify.
 func NewLogger(env string) (*zap.Logger, error) {
 	var cfg zap.Config
 
@@ -55,7 +56,10 @@ func NewLogger(env string) (*zap.Logger, error) {
 		}
 	}
 
-	l, err := cfg.Build(zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	production := env == "production"
+	l, err := cfg.Build(zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel), zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newRedactingCore(core, production)
+	}))
 	if err != nil {
 		return nil, err
 	}
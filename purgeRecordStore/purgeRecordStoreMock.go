@@ -0,0 +1,25 @@
+package purgeRecords
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockPurgeRecordStore struct {
+	mock.Mock
+}
+
+func (m *MockPurgeRecordStore) Insert(ctx context.Context, record PurgeRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockPurgeRecordStore) GetByReportID(ctx context.Context, reportID primitive.ObjectID) ([]PurgeRecord, error) {
+	args := m.Called(ctx, reportID)
+	if records := args.Get(0); records != nil {
+		return records.([]PurgeRecord), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,85 @@
+package purgeRecords
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Purged content
+const (
+	ContentAudio      = "audio"
+	ContentTranscript = "transcript"
+	ContentNotes      = "notes"
+	ContentTokenUsage = "tokenUsage"
+)
+
+// PurgeRecord is the evidence that retention purged a piece of clinical data. It never holds the data itself.
+type PurgeRecord struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReportID   primitive.ObjectID `bson:"reportId" json:"reportID"`
+	ProviderID string             `bson:"providerId" json:"providerID"`
+	Content    string             `bson:"content" json:"content"`
+	// ResourceID identifies the purged item when a report has several, such as an audio blob.
+	ResourceID string `bson:"resourceId,omitempty" json:"resourceID,omitempty"`
+	// RetentionDays is the retention period that expired, zero when it was set when the data was stored.
+	RetentionDays int                `bson:"retentionDays,omitempty" json:"retentionDays,omitempty"`
+	PurgedAt      primitive.DateTime `bson:"purgedAt" json:"purgedAt"`
+}
+
+type PurgeRecordStore interface {
+	Insert(ctx context.Context, record PurgeRecord) error
+	GetByReportID(ctx context.Context, reportId primitive.ObjectID) ([]PurgeRecord, error)
+}
+
+type purgeRecordStore struct {
+	collection *mongo.Collection
+}
+
+func NewPurgeRecordStore(collection *mongo.Collection) PurgeRecordStore {
+	return &purgeRecordStore{collection: collection}
+}
+
+func (s *purgeRecordStore) Insert(ctx context.Context, record PurgeRecord) error {
+	if record.ReportID.IsZero() {
+		return errors.New("reportId cannot be empty")
+	}
+	if record.ProviderID == "" {
+		return errors.New("providerId cannot be empty")
+	}
+	if record.Content == "" {
+		return errors.New("content cannot be empty")
+	}
+	if record.PurgedAt.Time().IsZero() {
+		record.PurgedAt = primitive.NewDateTimeFromTime(time.Now())
+	}
+
+	if _, err := s.collection.InsertOne(ctx, record); err != nil {
+		return fmt.Errorf("failed to insert purge record: %v", err)
+	}
+	return nil
+}
+
+// GetByReportID returns the purge records of a report, oldest first.
+func (s *purgeRecordStore) GetByReportID(ctx context.Context, reportId primitive.ObjectID) ([]PurgeRecord, error) {
+	if reportId.IsZero() {
+		return nil, errors.New("invalid reportId")
+	}
+
+	opts := options.Find().SetSort(bson.M{"purgedAt": 1})
+	cursor, err := s.collection.Find(ctx, bson.M{"reportId": reportId}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve purge records: %v", err)
+	}
+	records := []PurgeRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode purge records: %v", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,73 @@
+// Package readability scores how hard English text is to read, so patient-facing documents can be
+// checked against a target reading level without calling out to a model.
+package readability
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	sentencePattern = regexp.MustCompile(`[^.!?;:\n]+`)
+	wordPattern     = regexp.MustCompile(`[A-Za-z]+(?:'[A-Za-z]+)?`)
+	vowelGroups     = regexp.MustCompile(`[aeiouy]+`)
+	silentEnding    = regexp.MustCompile(`(?:[^laeiouy]es|[^laeiouydt]ed|[^laeiouy]e)$`)
+)
+
+// Score summarizes the readability of a text.
+type Score struct {
+	Words     int `json:"words"`
+	Sentences int `json:"sentences"`
+	Syllables int `json:"syllables"`
+	// Grade is the Flesch-Kincaid grade level: roughly the US school grade needed to follow the text.
+	Grade float64 `json:"grade"`
+	// Ease is the Flesch reading ease, from 0 (very hard) to 100 and above (very easy).
+	Ease float64 `json:"ease"`
+}
+
+// Analyze scores a text. Every line counts as at least one sentence, so short list items are not
+// merged into one long sentence. Text without words scores zero.
+func Analyze(text string) Score {
+	var score Score
+	for _, sentence := range sentencePattern.FindAllString(text, -1) {
+		words := wordPattern.FindAllString(sentence, -1)
+		if len(words) == 0 {
+			continue
+		}
+		score.Sentences++
+		score.Words += len(words)
+		for _, word := range words {
+			score.Syllables += Syllables(word)
+		}
+	}
+	if score.Words == 0 {
+		return score
+	}
+
+	wordsPerSentence := float64(score.Words) / float64(score.Sentences)
+	syllablesPerWord := float64(score.Syllables) / float64(score.Words)
+	score.Grade = 0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59
+	score.Ease = 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+	return score
+}
+
+// FleschKincaidGrade returns the Flesch-Kincaid grade level of a text.
+func FleschKincaidGrade(text string) float64 {
+	return Analyze(text).Grade
+}
+
+// Syllables estimates the syllables of an English word by counting vowel groups, discounting a
+// silent final "e", "es" or "ed" (but not the spoken "ed" of "needed" or "started").
+// Every word has at least one syllable.
+func Syllables(word string) int {
+	word = strings.ToLower(word)
+	word = strings.TrimSuffix(word, "'s")
+	if len(word) <= 3 {
+		return 1
+	}
+	if !strings.HasSuffix(word, "le") {
+		word = silentEnding.ReplaceAllStringFunc(word, func(ending string) string { return ending[:1] })
+	}
+	word = strings.TrimPrefix(word, "y")
+	return max(len(vowelGroups.FindAllString(word, -1)), 1)
+}
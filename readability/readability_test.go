@@ -0,0 +1,59 @@
+package readability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyllables(t *testing.T) {
+	testCases := []struct {
+		word     string
+		expected int
+	}{
+		{word: "the", expected: 1},
+		{word: "take", expected: 1},
+		{word: "liked", expected: 1},
+		{word: "needed", expected: 2},
+		{word: "tablets", expected: 2},
+		{word: "doctor", expected: 2},
+		{word: "possible", expected: 3},
+		{word: "appointment", expected: 3},
+		{word: "medicine", expected: 3},
+		{word: "hypertension", expected: 4},
+		{word: "Patient's", expected: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.word, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Syllables(tc.word))
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Run("should count words, sentences and syllables", func(t *testing.T) {
+		score := Analyze("Take one pill each day. Call us if you feel sick.")
+		assert.Equal(t, 11, score.Words)
+		assert.Equal(t, 2, score.Sentences)
+		assert.Equal(t, 11, score.Syllables)
+		assert.InDelta(t, 0.39*5.5+11.8-15.59, score.Grade, 1e-9)
+		assert.InDelta(t, 206.835-1.015*5.5-84.6, score.Ease, 1e-9)
+	})
+
+	t.Run("should treat every line as a sentence", func(t *testing.T) {
+		score := Analyze("Stop ibuprofen\nStart lisinopril")
+		assert.Equal(t, 2, score.Sentences)
+	})
+
+	t.Run("should score clinical prose above plain language", func(t *testing.T) {
+		clinical := "Patient presents with uncontrolled essential hypertension; antihypertensive regimen intensification with lisinopril titration is recommended pending laboratory evaluation of renal function."
+		plain := "Your blood pressure is too high. We will raise your dose of lisinopril. We will check your blood first."
+		assert.Greater(t, FleschKincaidGrade(clinical), 12.0)
+		assert.Less(t, FleschKincaidGrade(plain), 6.0)
+	})
+
+	t.Run("should score text without words as zero", func(t *testing.T) {
+		assert.Equal(t, Score{}, Analyze(" 10 / 20 ... "))
+	})
+}
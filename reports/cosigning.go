@@ -0,0 +1,191 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Statuses of a note signed by a provider who is supervised. It awaits co-signature until the supervisor
+// co-signs it; when it is returned for changes it is unsigned again and back to StatusSuccess.
+const (
+	StatusPendingCoSignature = "pendingCosignature"
+	StatusCoSigned           = "cosigned"
+)
+
+// Co-signature review states
+const (
+	CoSignaturePending  = "pending"
+	CoSignatureReturned = "returned"
+	CoSignatureSigned   = "cosigned"
+)
+
+const CoSignatureKey = "cosignature"
+
+const (
+	coSignatureSupervisorKey  = CoSignatureKey + ".supervisorid"
+	coSignatureStateKey       = CoSignatureKey + ".state"
+	coSignatureRequestedAtKey = CoSignatureKey + ".requestedat"
+	coSignatureReturnedAtKey  = CoSignatureKey + ".returnedat"
+	coSignatureSignatureKey   = CoSignatureKey + ".signature"
+	coSignatureCommentsKey    = CoSignatureKey + ".comments"
+)
+
+// ErrNotPendingCoSignature is returned when reviewing a note that is not awaiting the supervisor's co-signature.
+var ErrNotPendingCoSignature = errors.New("report is not awaiting co-signature")
+
+// CoSignature tracks a supervisor's review of a signed note. Comments are kept across every round of review.
+type CoSignature struct {
+	SupervisorID string              `json:"supervisorID"`
+	State        string              `json:"state"`
+	RequestedAt  primitive.DateTime  `json:"requestedAt"`
+	ReturnedAt   *primitive.DateTime `json:"returnedAt,omitempty"`
+	// Signature is the supervisor's, set once the note is co-signed.
+	Signature *Signature      `json:"signature,omitempty"`
+	Comments  []ReviewComment `json:"comments"`
+}
+
+// ReviewComment is a supervisor's comment on a note under review.
+type ReviewComment struct {
+	ID         primitive.ObjectID `json:"id"`
+	AuthorID   string             `json:"authorID"`
+	AuthorName string             `json:"authorName"`
+	Content    string             `json:"content"`
+	CreatedAt  primitive.DateTime `json:"createdAt"`
+}
+
+// AwaitsCoSignature reports whether the note is signed and waiting for its supervisor.
+func (r *Report) AwaitsCoSignature() bool {
+	return r.Status == StatusPendingCoSignature && r.CoSignature != nil && r.CoSignature.State == CoSignaturePending
+}
+
+func reviewCommentField(commentID primitive.ObjectID) string {
+	return coSignatureCommentsKey + "." + commentID.Hex() + "." + Content
+}
+
+// pendingReviewFilter matches a report awaiting co-signature by the supervisor.
+func pendingReviewFilter(objectID primitive.ObjectID, supervisorID string) bson.M {
+	return bson.M{ID: objectID, Status: StatusPendingCoSignature, coSignatureSupervisorKey: supervisorID, coSignatureStateKey: CoSignaturePending}
+}
+
+/* PendingCoSignatures lists the notes awaiting co-signature by a supervisor, oldest signature first */
+func (r *reportsStore) PendingCoSignatures(ctx context.Context, supervisorID string) ([]Report, error) {
+	if supervisorID == "" {
+		return nil, errors.New("supervisorID cannot be empty")
+	}
+	filter := bson.M{Status: StatusPendingCoSignature, coSignatureSupervisorKey: supervisorID, coSignatureStateKey: CoSignaturePending}
+	opts := options.Find().
+		SetProjection(bson.M{Transcript: 0, DiarizedTranscript: 0}).
+		SetSort(bson.D{{Key: SignatureKey + ".signedat", Value: 1}})
+	cursor, err := r.client.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reports awaiting co-signature: %v", err)
+	}
+	var pending []Report
+	if err := cursor.All(ctx, &pending); err != nil {
+		return nil, fmt.Errorf("failed to decode reports awaiting co-signature: %v", err)
+	}
+	for i := range pending {
+		if err := r.fields.decryptReport(ctx, &pending[i]); err != nil {
+			return nil, fmt.Errorf("failed to decrypt report: %v", err)
+		}
+	}
+	return pending, nil
+}
+
+/* AddReviewComment adds the supervisor's comment to a note awaiting their co-signature and returns it as stored */
+func (r *reportsStore) AddReviewComment(ctx context.Context, reportId string, comment ReviewComment) (ReviewComment, error) {
+	objectID, stored, err := r.prepareReviewComment(ctx, reportId, &comment)
+	if err != nil {
+		return ReviewComment{}, err
+	}
+
+	update := bson.M{"$push": bson.M{coSignatureCommentsKey: stored}}
+	result, err := r.client.UpdateOne(ctx, pendingReviewFilter(objectID, comment.AuthorID), update)
+	if err != nil {
+		return ReviewComment{}, fmt.Errorf("failed to add review comment: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ReviewComment{}, ErrNotPendingCoSignature
+	}
+	return comment, nil
+}
+
+/* ReturnForChanges sends a note awaiting co-signature back to its author with the supervisor's comment. The note is unsigned so it can be edited and signed again */
+func (r *reportsStore) ReturnForChanges(ctx context.Context, reportId string, comment ReviewComment) (ReviewComment, error) {
+	objectID, stored, err := r.prepareReviewComment(ctx, reportId, &comment)
+	if err != nil {
+		return ReviewComment{}, err
+	}
+
+	update := bson.M{
+		"$set":   bson.M{Status: StatusSuccess, coSignatureStateKey: CoSignatureReturned, coSignatureReturnedAtKey: comment.CreatedAt},
+		"$unset": bson.M{SignatureKey: ""},
+		"$push":  bson.M{coSignatureCommentsKey: stored},
+	}
+	result, err := r.client.UpdateOne(ctx, pendingReviewFilter(objectID, comment.AuthorID), update)
+	if err != nil {
+		return ReviewComment{}, fmt.Errorf("failed to return report: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ReviewComment{}, ErrNotPendingCoSignature
+	}
+	return comment, nil
+}
+
+/* CoSign records the supervisor's signature on a note awaiting their co-signature */
+func (r *reportsStore) CoSign(ctx context.Context, reportId string, signature Signature) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+	if signature.SignerID == "" {
+		return errors.New("signer cannot be empty")
+	}
+	if strings.TrimSpace(signature.Attestation) == "" {
+		return errors.New("attestation cannot be empty")
+	}
+
+	update := bson.M{"$set": bson.M{Status: StatusCoSigned, coSignatureStateKey: CoSignatureSigned, coSignatureSignatureKey: signature}}
+	result, err := r.client.UpdateOne(ctx, pendingReviewFilter(objectID, signature.SignerID), update)
+	if err != nil {
+		return fmt.Errorf("failed to co-sign report: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotPendingCoSignature
+	}
+	return nil
+}
+
+// prepareReviewComment validates a comment, fills in its ID and timestamp, and returns it encrypted for storage.
+func (r *reportsStore) prepareReviewComment(ctx context.Context, reportId string, comment *ReviewComment) (primitive.ObjectID, ReviewComment, error) {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return primitive.NilObjectID, ReviewComment{}, fmt.Errorf("invalid ID format: %v", err)
+	}
+	if comment.AuthorID == "" {
+		return primitive.NilObjectID, ReviewComment{}, errors.New("author cannot be empty")
+	}
+	if strings.TrimSpace(comment.Content) == "" {
+		return primitive.NilObjectID, ReviewComment{}, errors.New("comment cannot be empty")
+	}
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	if comment.CreatedAt == 0 {
+		comment.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
+	}
+
+	stored := *comment
+	stored.Content, err = r.encryptField(ctx, objectID, reviewCommentField(comment.ID), comment.Content)
+	if err != nil {
+		return primitive.NilObjectID, ReviewComment{}, fmt.Errorf("failed to encrypt review comment: %v", err)
+	}
+	return objectID, stored, nil
+}
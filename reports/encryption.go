@@ -0,0 +1,409 @@
+package reports
+
+import (
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// encryptedValuePrefix marks a field value encrypted by the store. The full format is
+// "enc:v1:<data key version>:<base64 nonce and ciphertext>".
+const encryptedValuePrefix = "enc:v1:"
+
+// encryptedFields are the top-level string fields encrypted at rest, lowercased.
+var encryptedFields = map[string]bool{
+	strings.ToLower(Transcript):       true,
+	strings.ToLower(CondensedSummary): true,
+	strings.ToLower(SessionSummary):   true,
+}
+
+// encryptedSections are the ReportContent fields whose data is encrypted at rest, lowercased.
+var encryptedSections = map[string]bool{
+	strings.ToLower(Subjective):          true,
+	strings.ToLower(Objective):           true,
+	strings.ToLower(AssessmentAndPlan):   true,
+	strings.ToLower(Summary):             true,
+	strings.ToLower(PatientInstructions): true,
+}
+
+// fieldCipher encrypts report fields with AES-GCM under the provider's data key. Each value is bound
+// to its report and field, so ciphertexts cannot be moved between reports or fields.
+type fieldCipher struct {
+	keys dataKeyStore.DataKeyStore
+}
+
+// FieldCipher encrypts report content kept outside the report document, such as revision history,
+// under the same per-provider data keys and in the same format as the report's own fields.
+type FieldCipher interface {
+	// Encrypt seals a value of the report under the provider's current data key. Empty values are returned as they are.
+	Encrypt(ctx context.Context, providerID, reportID, field, plaintext string) (string, error)
+	// Decrypt opens a value sealed by Encrypt. Values stored before encryption was enabled are returned as they are.
+	Decrypt(ctx context.Context, providerID, reportID, field, value string) (string, error)
+	// Reencrypt re-seals a stored value under the provider's current data key. It reports false when the
+	// value is empty or already sealed under that key.
+	Reencrypt(ctx context.Context, providerID, reportID, field, value string) (string, bool, error)
+}
+
+// NewFieldCipher creates a FieldCipher over the per-provider data keys.
+func NewFieldCipher(keys dataKeyStore.DataKeyStore) FieldCipher {
+	return &fieldCipher{keys: keys}
+}
+
+func (c *fieldCipher) Encrypt(ctx context.Context, providerID, reportID, field, plaintext string) (string, error) {
+	return c.encrypt(ctx, providerID, reportID, field, plaintext)
+}
+
+func (c *fieldCipher) Decrypt(ctx context.Context, providerID, reportID, field, value string) (string, error) {
+	return c.decrypt(ctx, providerID, reportID, field, value)
+}
+
+func (c *fieldCipher) Reencrypt(ctx context.Context, providerID, reportID, field, value string) (string, bool, error) {
+	if c == nil {
+		return "", false, errors.New("report encryption is not configured")
+	}
+	current, err := c.keys.Current(ctx, providerID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return c.reencryptValue(ctx, current, reportID, field, value)
+}
+
+func fieldAdditionalData(reportID, field string) []byte {
+	return []byte(reportID + "/" + strings.ToLower(field))
+}
+
+// encryptedVersion returns the data key version an encrypted value was sealed with.
+func encryptedVersion(value string) (int, bool) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return 0, false
+	}
+	version, _, found := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if !found {
+		return 0, false
+	}
+	v, err := strconv.Atoi(version)
+	return v, err == nil
+}
+
+// encrypt seals a value under the provider's current data key. Empty values are stored as they are.
+func (c *fieldCipher) encrypt(ctx context.Context, providerID, reportID, field, plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	key, err := c.keys.Current(ctx, providerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get data key: %w", err)
+	}
+	return sealField(key, reportID, field, plaintext)
+}
+
+func sealField(key dataKeyStore.DataKey, reportID, field, plaintext string) (string, error) {
+	sealed, err := encryption.Seal(key.Key, []byte(plaintext), fieldAdditionalData(reportID, field))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", field, err)
+	}
+	return fmt.Sprintf("%s%d:%s", encryptedValuePrefix, key.Version, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decrypt opens a value sealed by encrypt. Values written before encryption was enabled are returned as they are.
+func (c *fieldCipher) decrypt(ctx context.Context, providerID, reportID, field, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	if c == nil {
+		return "", errors.New("report field is encrypted but no data keys are configured")
+	}
+	version, ok := encryptedVersion(value)
+	if !ok {
+		return "", fmt.Errorf("malformed encrypted %s", field)
+	}
+	_, payload, _ := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted %s: %v", field, err)
+	}
+
+	key, err := c.keys.Get(ctx, providerID, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to get data key: %w", err)
+	}
+	plaintext, err := encryption.Open(key.Key, sealed, fieldAdditionalData(reportID, field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+// encryptUpdates returns a copy of UpdateReport updates with the encrypted fields sealed.
+func (c *fieldCipher) encryptUpdates(ctx context.Context, providerID, reportID string, updates bson.D) (bson.D, error) {
+	encrypted := make(bson.D, 0, len(updates))
+	for _, update := range updates {
+		key := strings.ToLower(update.Key)
+		switch value := update.Value.(type) {
+		case string:
+			if encryptedFields[key] {
+				sealed, err := c.encrypt(ctx, providerID, reportID, key, value)
+				if err != nil {
+					return nil, err
+				}
+				update.Value = sealed
+			}
+		case bson.D:
+			if encryptedSections[key] {
+				section := make(bson.D, len(value))
+				copy(section, value)
+				for i, field := range section {
+					data, ok := field.Value.(string)
+					if !ok || field.Key != ContentData {
+						continue
+					}
+					sealed, err := c.encrypt(ctx, providerID, reportID, key+"."+ContentData, data)
+					if err != nil {
+						return nil, err
+					}
+					section[i].Value = sealed
+				}
+				update.Value = section
+			}
+		}
+		encrypted = append(encrypted, update)
+	}
+	return encrypted, nil
+}
+
+// needsEncryption reports whether any update writes an encrypted field.
+func needsEncryption(updates bson.D) bool {
+	for _, update := range updates {
+		key := strings.ToLower(update.Key)
+		if encryptedFields[key] || encryptedSections[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// reportSections returns the encrypted sections of a report by field name.
+func reportSections(report *Report) map[string]*ReportContent {
+	return map[string]*ReportContent{
+		Subjective:          &report.Subjective,
+		Objective:           &report.Objective,
+		AssessmentAndPlan:   &report.AssessmentAndPlan,
+		Summary:             &report.Summary,
+		PatientInstructions: &report.PatientInstructions,
+	}
+}
+
+// afterVisitSummaryFields returns the text fields of an after-visit summary by field name.
+func afterVisitSummaryFields(summary *AfterVisitSummary) map[string]*string {
+	return map[string]*string{
+		AfterVisitSummaryKey + ".whatwediscussed":   &summary.WhatWeDiscussed,
+		AfterVisitSummaryKey + ".medicationchanges": &summary.MedicationChanges,
+		AfterVisitSummaryKey + ".nextappointment":   &summary.NextAppointment,
+		AfterVisitSummaryKey + ".whentoseekhelp":    &summary.WhenToSeekHelp,
+	}
+}
+
+func memberNoteField(participantID string) string {
+	return MemberNotes + "." + participantID + "." + ContentData
+}
+
+// decryptReport decrypts the encrypted fields of a report in place.
+func (c *fieldCipher) decryptReport(ctx context.Context, report *Report) error {
+	reportID := report.ID.Hex()
+	fields := map[string]*string{
+		Transcript:       &report.Transcript,
+		CondensedSummary: &report.CondensedSummary,
+		SessionSummary:   &report.SessionSummary,
+	}
+	for name, section := range reportSections(report) {
+		fields[name+"."+ContentData] = &section.Data
+	}
+	if report.AfterVisitSummary != nil {
+		for name, value := range afterVisitSummaryFields(report.AfterVisitSummary) {
+			fields[name] = value
+		}
+	}
+	for i := range report.MemberNotes {
+		fields[memberNoteField(report.MemberNotes[i].ParticipantID)] = &report.MemberNotes[i].Note.Data
+	}
+	for i := range report.Addenda {
+		fields[addendumField(report.Addenda[i].ID)] = &report.Addenda[i].Content
+	}
+	if report.CoSignature != nil {
+		for i := range report.CoSignature.Comments {
+			fields[reviewCommentField(report.CoSignature.Comments[i].ID)] = &report.CoSignature.Comments[i].Content
+		}
+	}
+
+	for name, value := range fields {
+		plaintext, err := c.decrypt(ctx, report.ProviderID, reportID, name, *value)
+		if err != nil {
+			return err
+		}
+		*value = plaintext
+	}
+	return nil
+}
+
+// encryptMemberNotes returns a copy of the notes with their content sealed.
+func (c *fieldCipher) encryptMemberNotes(ctx context.Context, providerID, reportID string, notes []MemberNote) ([]MemberNote, error) {
+	encrypted := make([]MemberNote, len(notes))
+	for i, note := range notes {
+		sealed, err := c.encrypt(ctx, providerID, reportID, memberNoteField(note.ParticipantID), note.Note.Data)
+		if err != nil {
+			return nil, err
+		}
+		note.Note.Data = sealed
+		encrypted[i] = note
+	}
+	return encrypted, nil
+}
+
+// encryptAfterVisitSummary returns a copy of the summary with its text sealed.
+func (c *fieldCipher) encryptAfterVisitSummary(ctx context.Context, providerID, reportID string, summary AfterVisitSummary) (AfterVisitSummary, error) {
+	for name, value := range afterVisitSummaryFields(&summary) {
+		sealed, err := c.encrypt(ctx, providerID, reportID, name, *value)
+		if err != nil {
+			return AfterVisitSummary{}, err
+		}
+		*value = sealed
+	}
+	return summary, nil
+}
+
+// reencryptValue re-seals a stored value under the current data key. It reports false when the value
+// is empty or already sealed under that key.
+func (c *fieldCipher) reencryptValue(ctx context.Context, current dataKeyStore.DataKey, reportID, field, value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+	if version, ok := encryptedVersion(value); ok && version == current.Version {
+		return value, false, nil
+	}
+	plaintext, err := c.decrypt(ctx, current.ProviderID, reportID, field, value)
+	if err != nil {
+		return "", false, err
+	}
+	sealed, err := sealField(current, reportID, field, plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return sealed, true, nil
+}
+
+// reencryptDocument collects the $set and filter entries that re-seal the encrypted fields of a raw
+// report document. Keys are matched case-insensitively because older updates stored some fields
+// under their JSON names; each is rewritten under the key it was found at. The filter pins every
+// rewritten value so a concurrent write is never overwritten with stale content.
+func (c *fieldCipher) reencryptDocument(ctx context.Context, current dataKeyStore.DataKey, reportID string, document bson.M) (set bson.M, filter bson.M, err error) {
+	set, filter = bson.M{}, bson.M{}
+	reencrypt := func(path, field string, value interface{}) error {
+		stored, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		sealed, changed, err := c.reencryptValue(ctx, current, reportID, field, stored)
+		if err != nil || !changed {
+			return err
+		}
+		set[path], filter[path] = sealed, stored
+		return nil
+	}
+
+	for key, value := range document {
+		lower := strings.ToLower(key)
+		switch {
+		case encryptedFields[lower]:
+			err = reencrypt(key, lower, value)
+		case encryptedSections[lower]:
+			if section, ok := asDocument(value); ok {
+				err = reencrypt(key+"."+ContentData, lower+"."+ContentData, section[ContentData])
+			}
+		case lower == AfterVisitSummaryKey:
+			if summary, ok := asDocument(value); ok {
+				for name, text := range summary {
+					field := AfterVisitSummaryKey + "." + strings.ToLower(name)
+					if _, ok := afterVisitSummaryFields(&AfterVisitSummary{})[field]; ok {
+						if err = reencrypt(key+"."+name, field, text); err != nil {
+							break
+						}
+					}
+				}
+			}
+		case lower == MemberNotes:
+			notes, _ := value.(primitive.A)
+			for i, item := range notes {
+				note, ok := asDocument(item)
+				if !ok {
+					continue
+				}
+				participantID, _ := note["participantid"].(string)
+				content, ok := asDocument(note["note"])
+				if !ok {
+					continue
+				}
+				if err = reencrypt(fmt.Sprintf("%s.%d.note.%s", key, i, ContentData), memberNoteField(participantID), content[ContentData]); err != nil {
+					break
+				}
+			}
+		case lower == AddendaKey:
+			addenda, _ := value.(primitive.A)
+			for i, item := range addenda {
+				addendum, ok := asDocument(item)
+				if !ok {
+					continue
+				}
+				addendumID, ok := addendum["id"].(primitive.ObjectID)
+				if !ok {
+					continue
+				}
+				if err = reencrypt(fmt.Sprintf("%s.%d.%s", key, i, Content), addendumField(addendumID), addendum[Content]); err != nil {
+					break
+				}
+			}
+		case lower == CoSignatureKey:
+			review, _ := asDocument(value)
+			comments, _ := review["comments"].(primitive.A)
+			for i, item := range comments {
+				comment, ok := asDocument(item)
+				if !ok {
+					continue
+				}
+				commentID, ok := comment["id"].(primitive.ObjectID)
+				if !ok {
+					continue
+				}
+				if err = reencrypt(fmt.Sprintf("%s.comments.%d.%s", key, i, Content), reviewCommentField(commentID), comment[Content]); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return set, filter, nil
+}
+
+func asDocument(value interface{}) (bson.M, bool) {
+	switch document := value.(type) {
+	case bson.M:
+		return document, true
+	case bson.D:
+		m := bson.M{}
+		for _, e := range document {
+			m[e.Key] = e.Value
+		}
+		return m, true
+	default:
+		return nil, false
+	}
+}
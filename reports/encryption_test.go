@@ -0,0 +1,242 @@
+package reports
+
+import (
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestDataKey(t *testing.T, version int) dataKeyStore.DataKey {
+	t.Helper()
+	raw, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	return dataKeyStore.DataKey{ProviderID: "provider-1", Version: version, Key: raw}
+}
+
+func newTestCipher(t *testing.T, current dataKeyStore.DataKey, older ...dataKeyStore.DataKey) *fieldCipher {
+	t.Helper()
+	keys := new(dataKeyStore.MockDataKeyStore)
+	keys.On("Current", mock.Anything, current.ProviderID).Return(current, nil)
+	for _, key := range append(older, current) {
+		keys.On("Get", mock.Anything, key.ProviderID, key.Version).Return(key, nil)
+	}
+	return &fieldCipher{keys: keys}
+}
+
+func TestFieldCipherRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cipher := newTestCipher(t, newTestDataKey(t, 1))
+	reportID := primitive.NewObjectID()
+
+	sealed, err := cipher.encrypt(ctx, "provider-1", reportID.Hex(), Transcript, "patient reports chest pain")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, encryptedValuePrefix+"1:"))
+	assert.NotContains(t, sealed, "chest pain")
+
+	tests := []struct {
+		name     string
+		reportID string
+		field    string
+		wantErr  bool
+	}{
+		{name: "same report and field", reportID: reportID.Hex(), field: Transcript},
+		{name: "field name case does not matter", reportID: reportID.Hex(), field: strings.ToUpper(Transcript)},
+		{name: "other field", reportID: reportID.Hex(), field: SessionSummary, wantErr: true},
+		{name: "other report", reportID: primitive.NewObjectID().Hex(), field: Transcript, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := cipher.decrypt(ctx, "provider-1", tt.reportID, tt.field, sealed)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "patient reports chest pain", plaintext)
+		})
+	}
+}
+
+func TestFieldCipherPlaintextPassthrough(t *testing.T) {
+	ctx := context.Background()
+	cipher := newTestCipher(t, newTestDataKey(t, 1))
+
+	plaintext, err := cipher.decrypt(ctx, "provider-1", "report", Transcript, "stored before encryption")
+	require.NoError(t, err)
+	assert.Equal(t, "stored before encryption", plaintext)
+
+	empty, err := cipher.encrypt(ctx, "provider-1", "report", Transcript, "")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	var plaintextStore *fieldCipher
+	_, err = plaintextStore.decrypt(ctx, "provider-1", "report", Transcript, encryptedValuePrefix+"1:AAAA")
+	assert.Error(t, err, "encrypted values cannot be read without data keys")
+}
+
+func TestEncryptUpdates(t *testing.T) {
+	ctx := context.Background()
+	cipher := newTestCipher(t, newTestDataKey(t, 1))
+	reportID := primitive.NewObjectID()
+
+	updates := bson.D{
+		{Key: "condensedSummary", Value: "short summary"},
+		{Key: "assessmentAndPlan", Value: bson.D{{Key: ContentData, Value: "start lisinopril"}, {Key: "loading", Value: false}}},
+		{Key: Name, Value: "Follow-up"},
+	}
+	assert.True(t, needsEncryption(updates))
+	assert.False(t, needsEncryption(bson.D{{Key: Name, Value: "Follow-up"}}))
+
+	encrypted, err := cipher.encryptUpdates(ctx, "provider-1", reportID.Hex(), updates)
+	require.NoError(t, err)
+	require.Len(t, encrypted, 3)
+
+	assert.True(t, strings.HasPrefix(encrypted[0].Value.(string), encryptedValuePrefix))
+	section := encrypted[1].Value.(bson.D)
+	assert.True(t, strings.HasPrefix(section[0].Value.(string), encryptedValuePrefix))
+	assert.Equal(t, false, section[1].Value)
+	assert.Equal(t, "Follow-up", encrypted[2].Value)
+	assert.Equal(t, "start lisinopril", updates[1].Value.(bson.D)[0].Value, "updates are not modified in place")
+
+	report := Report{
+		ID:                reportID,
+		ProviderID:        "provider-1",
+		CondensedSummary:  encrypted[0].Value.(string),
+		AssessmentAndPlan: ReportContent{Data: section[0].Value.(string)},
+	}
+	require.NoError(t, cipher.decryptReport(ctx, &report))
+	assert.Equal(t, "short summary", report.CondensedSummary)
+	assert.Equal(t, "start lisinopril", report.AssessmentAndPlan.Data)
+}
+
+func TestDecryptReportMemberNotesAndAfterVisitSummary(t *testing.T) {
+	ctx := context.Background()
+	cipher := newTestCipher(t, newTestDataKey(t, 1))
+	reportID := primitive.NewObjectID()
+
+	notes, err := cipher.encryptMemberNotes(ctx, "provider-1", reportID.Hex(), []MemberNote{
+		{ParticipantID: "p1", Name: "Parent", Note: ReportContent{Data: "parent note"}},
+		{ParticipantID: "p2", Name: "Child", Note: ReportContent{Data: "child note"}},
+	})
+	require.NoError(t, err)
+	summary, err := cipher.encryptAfterVisitSummary(ctx, "provider-1", reportID.Hex(), AfterVisitSummary{WhatWeDiscussed: "your blood pressure"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(summary.WhatWeDiscussed, encryptedValuePrefix))
+	assert.Empty(t, summary.MedicationChanges)
+
+	// Swapping two notes must not let one participant's note decrypt as the other's.
+	swapped := []MemberNote{notes[0], notes[1]}
+	swapped[0].Note.Data, swapped[1].Note.Data = notes[1].Note.Data, notes[0].Note.Data
+	assert.Error(t, cipher.decryptReport(ctx, &Report{ID: reportID, ProviderID: "provider-1", MemberNotes: swapped}))
+
+	report := Report{ID: reportID, ProviderID: "provider-1", MemberNotes: notes, AfterVisitSummary: &summary}
+	require.NoError(t, cipher.decryptReport(ctx, &report))
+	assert.Equal(t, "parent note", report.MemberNotes[0].Note.Data)
+	assert.Equal(t, "child note", report.MemberNotes[1].Note.Data)
+	assert.Equal(t, "your blood pressure", report.AfterVisitSummary.WhatWeDiscussed)
+}
+
+func TestReencryptDocument(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newKey := newTestDataKey(t, 1), newTestDataKey(t, 2)
+	reportID := primitive.NewObjectID().Hex()
+	addendumID, commentID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	oldSummary, err := sealField(oldKey, reportID, SessionSummary, "session summary")
+	require.NoError(t, err)
+	currentTranscript, err := sealField(newKey, reportID, Transcript, "already current")
+	require.NoError(t, err)
+
+	cipher := newTestCipher(t, newKey, oldKey)
+	document := bson.M{
+		"transcript":        currentTranscript,
+		"sessionsummary":    oldSummary,
+		"condensedSummary":  "legacy plaintext",
+		"subjective":        bson.M{"data": "subjective note", "loading": false},
+		"afterVisitSummary": bson.M{"whatWeDiscussed": "discussed", "readingGrade": 5.2},
+		"membernotes": primitive.A{
+			bson.M{"participantid": "p1", "note": bson.M{"data": "member note"}},
+		},
+		"addenda": primitive.A{
+			bson.M{"id": addendumID, "authorid": "provider-1", "content": "corrected dose"},
+		},
+		"cosignature": bson.M{"state": "pending", "comments": primitive.A{
+			bson.M{"id": commentID, "authorid": "supervisor-1", "content": "check the dose"},
+		}},
+		"name": "Visit",
+	}
+
+	set, filter, err := cipher.reencryptDocument(ctx, newKey, reportID, document)
+	require.NoError(t, err)
+
+	assert.NotContains(t, set, "transcript", "values under the current key are left alone")
+	assert.NotContains(t, set, "name")
+	assert.ElementsMatch(t, []string{
+		"sessionsummary", "condensedSummary", "subjective.data", "afterVisitSummary.whatWeDiscussed", "membernotes.0.note.data", "addenda.0.content",
+		"cosignature.comments.0.content",
+	}, keys(set))
+	assert.Equal(t, "legacy plaintext", filter["condensedSummary"])
+	assert.Equal(t, oldSummary, filter["sessionsummary"])
+
+	tests := []struct {
+		path, field, want string
+	}{
+		{"sessionsummary", SessionSummary, "session summary"},
+		{"condensedSummary", CondensedSummary, "legacy plaintext"},
+		{"subjective.data", Subjective + "." + ContentData, "subjective note"},
+		{"afterVisitSummary.whatWeDiscussed", AfterVisitSummaryKey + ".whatwediscussed", "discussed"},
+		{"membernotes.0.note.data", memberNoteField("p1"), "member note"},
+		{"addenda.0.content", addendumField(addendumID), "corrected dose"},
+		{"cosignature.comments.0.content", reviewCommentField(commentID), "check the dose"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sealed := set[tt.path].(string)
+			version, ok := encryptedVersion(sealed)
+			require.True(t, ok)
+			assert.Equal(t, newKey.Version, version)
+			plaintext, err := cipher.decrypt(ctx, "provider-1", reportID, tt.field, sealed)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, plaintext)
+		})
+	}
+}
+
+func keys(m bson.M) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
+
+func TestFieldCipherReencrypt(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newKey := newTestDataKey(t, 1), newTestDataKey(t, 2)
+	cipher := NewFieldCipher(newTestCipher(t, newKey, oldKey).keys)
+	reportID := primitive.NewObjectID().Hex()
+
+	old, err := sealField(oldKey, reportID, "transcriptRevisions.1.transcript", "earlier transcript")
+	require.NoError(t, err)
+	sealed, changed, err := cipher.Reencrypt(ctx, "provider-1", reportID, "transcriptRevisions.1.transcript", old)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	version, _ := encryptedVersion(sealed)
+	assert.Equal(t, 2, version)
+
+	_, changed, err = cipher.Reencrypt(ctx, "provider-1", reportID, "transcriptRevisions.1.transcript", sealed)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	plaintext, err := cipher.Decrypt(ctx, "provider-1", reportID, "transcriptRevisions.1.transcript", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier transcript", plaintext)
+}
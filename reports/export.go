@@ -0,0 +1,76 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NoteExportContentType is the content type of a note rendered by FormatNoteText.
+const NoteExportContentType = "text/plain; charset=utf-8"
+
+// sectionTitles are the headings of the note sections in the order they are exported.
+var sectionTitles = []struct {
+	section, title string
+}{
+	{Subjective, "Subjective"},
+	{Objective, "Objective"},
+	{AssessmentAndPlan, "Assessment and Plan"},
+	{PatientInstructions, "Patient Instructions"},
+	{Summary, "Summary"},
+}
+
+// FormatNoteText renders a report as a plain-text note: its sections and member notes, followed by the
+// signature and every addendum once it is signed. Timestamps are in UTC.
+func FormatNoteText(report Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", report.Name, formatNoteTime(report.TimeStamp.Time()))
+
+	for _, heading := range sectionTitles {
+		content, _ := report.Section(heading.section)
+		if data := strings.TrimSpace(content.Data); data != "" {
+			fmt.Fprintf(&b, "\n%s\n%s\n", strings.ToUpper(heading.title), data)
+		}
+	}
+	for _, note := range report.MemberNotes {
+		if data := strings.TrimSpace(note.Note.Data); data != "" {
+			fmt.Fprintf(&b, "\nNOTE FOR %s\n%s\n", strings.ToUpper(note.Name), data)
+		}
+	}
+
+	if report.Signature == nil {
+		b.WriteString("\nUNSIGNED\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nSIGNED by %s on %s\n%s\nContent hash: %s\n",
+		displayName(report.Signature.SignerName, report.Signature.SignerID), formatNoteTime(report.Signature.SignedAt.Time()),
+		report.Signature.Attestation, report.Signature.ContentHash)
+
+	if review := report.CoSignature; review != nil {
+		switch {
+		case review.State == CoSignatureSigned && review.Signature != nil:
+			fmt.Fprintf(&b, "\nCO-SIGNED by %s on %s\n%s\n",
+				displayName(review.Signature.SignerName, review.Signature.SignerID), formatNoteTime(review.Signature.SignedAt.Time()), review.Signature.Attestation)
+		case review.State == CoSignaturePending:
+			b.WriteString("\nAWAITING CO-SIGNATURE\n")
+		}
+	}
+
+	for i, addendum := range report.Addenda {
+		fmt.Fprintf(&b, "\nADDENDUM %d by %s on %s\n%s\n",
+			i+1, displayName(addendum.AuthorName, addendum.AuthorID), formatNoteTime(addendum.CreatedAt.Time()), strings.TrimSpace(addendum.Content))
+	}
+	return b.String()
+}
+
+// displayName returns the user's name, or their ID for users without one.
+func displayName(name, userID string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return userID
+}
+
+func formatNoteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
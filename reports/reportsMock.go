@@ -1,11 +1,14 @@
 package reports
 
 import (
+	"Medscribe/audio"
+	transcriber "Medscribe/transcription"
 	"context"
 	"time"
 
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type MockReportsStore struct {
@@ -52,8 +55,23 @@ func (m *MockReportsStore) MarkUnread(ctx context.Context, reportId string) erro
 	return args.Error(0)
 }
 
-func (m *MockReportsStore) UpdateReport(ctx context.Context, reportId string, batchedUpdates bson.D) error {
-	args := m.Called(ctx, reportId, batchedUpdates)
+func (m *MockReportsStore) UpdateReport(ctx context.Context, reportId string, version int64, batchedUpdates bson.D) (int64, error) {
+	args := m.Called(ctx, reportId, version, batchedUpdates)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReportsStore) LockForRegeneration(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error) {
+	args := m.Called(ctx, reportId, version, updates)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReportsStore) CompleteRegeneration(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error) {
+	args := m.Called(ctx, reportId, version, updates)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReportsStore) ReleaseRegeneration(ctx context.Context, reportId string, version int64) error {
+	args := m.Called(ctx, reportId, version)
 	return args.Error(0)
 }
 
@@ -61,3 +79,125 @@ func (m *MockReportsStore) Validate(report *Report) error {
 	args := m.Called(report)
 	return args.Error(0)
 }
+
+func (m *MockReportsStore) UpdateTranscript(ctx context.Context, reportId string, version int64, transcript string) (int64, error) {
+	args := m.Called(ctx, reportId, version, transcript)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReportsStore) MarkSectionsStale(ctx context.Context, reportId string, sections ...string) error {
+	args := m.Called(ctx, reportId, sections)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) AppendRecording(ctx context.Context, reportId string, version int64, previousDuration, duration float64, transcript string, segments ...AudioSegment) (int64, error) {
+	args := m.Called(ctx, reportId, version, previousDuration, duration, transcript, segments)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReportsStore) SetAudioQuality(ctx context.Context, reportId string, quality audio.Quality) error {
+	args := m.Called(ctx, reportId, quality)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) SetTrimmedDuration(ctx context.Context, reportId string, trimmedDuration float64) error {
+	args := m.Called(ctx, reportId, trimmedDuration)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) SetAfterVisitSummary(ctx context.Context, reportId string, summary AfterVisitSummary) error {
+	args := m.Called(ctx, reportId, summary)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) SetTranscriptLanguages(ctx context.Context, reportId string, languages []string) error {
+	args := m.Called(ctx, reportId, languages)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) SetParticipants(ctx context.Context, reportId string, participants []transcriber.Participant) error {
+	args := m.Called(ctx, reportId, participants)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) SetMemberNotes(ctx context.Context, reportId string, notes []MemberNote) error {
+	args := m.Called(ctx, reportId, notes)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) SetAudioBlobID(ctx context.Context, reportId string, blobID string) error {
+	args := m.Called(ctx, reportId, blobID)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) ReencryptAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockReportsStore) SetLegalHold(ctx context.Context, reportId string, hold *LegalHold) error {
+	args := m.Called(ctx, reportId, hold)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) Sign(ctx context.Context, reportId string, version int64, signature Signature, supervisorID string) error {
+	args := m.Called(ctx, reportId, version, signature, supervisorID)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) AddAddendum(ctx context.Context, reportId string, addendum Addendum) (Addendum, error) {
+	args := m.Called(ctx, reportId, addendum)
+	return args.Get(0).(Addendum), args.Error(1)
+}
+
+func (m *MockReportsStore) PendingCoSignatures(ctx context.Context, supervisorID string) ([]Report, error) {
+	args := m.Called(ctx, supervisorID)
+	if pending := args.Get(0); pending != nil {
+		return pending.([]Report), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReportsStore) AddReviewComment(ctx context.Context, reportId string, comment ReviewComment) (ReviewComment, error) {
+	args := m.Called(ctx, reportId, comment)
+	return args.Get(0).(ReviewComment), args.Error(1)
+}
+
+func (m *MockReportsStore) ReturnForChanges(ctx context.Context, reportId string, comment ReviewComment) (ReviewComment, error) {
+	args := m.Called(ctx, reportId, comment)
+	return args.Get(0).(ReviewComment), args.Error(1)
+}
+
+func (m *MockReportsStore) CoSign(ctx context.Context, reportId string, signature Signature) error {
+	args := m.Called(ctx, reportId, signature)
+	return args.Error(0)
+}
+
+func (m *MockReportsStore) ProviderIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if ids := args.Get(0); ids != nil {
+		return ids.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReportsStore) HeldReportIDs(ctx context.Context, providerID string) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx, providerID)
+	if ids := args.Get(0); ids != nil {
+		return ids.([]primitive.ObjectID), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReportsStore) ExpiredReportIDs(ctx context.Context, providerID string, content PurgeContent, before time.Time, limit int64) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx, providerID, content, before, limit)
+	if ids := args.Get(0); ids != nil {
+		return ids.([]primitive.ObjectID), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReportsStore) Purge(ctx context.Context, reportID primitive.ObjectID, content PurgeContent, before, now time.Time) (bool, error) {
+	args := m.Called(ctx, reportID, content, before, now)
+	return args.Bool(0), args.Error(1)
+}
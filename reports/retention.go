@@ -0,0 +1,204 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	LegalHoldKey          = "legalhold"
+	TranscriptPurgedAtKey = "transcriptpurgedat"
+	NotesPurgedAtKey      = "notespurgedat"
+)
+
+// ErrReportOnLegalHold is returned when deleting a report that is under a legal hold.
+var ErrReportOnLegalHold = errors.New("report is under a legal hold")
+
+// PurgeContent names the part of a report a retention policy purges.
+type PurgeContent string
+
+const (
+	// PurgeTranscript clears the transcript, diarized or not.
+	PurgeTranscript PurgeContent = "transcript"
+	// PurgeNotes clears the generated sections, summaries, member notes, addenda, review comments and after-visit summary.
+	PurgeNotes PurgeContent = "notes"
+)
+
+// LegalHold keeps a report, and the recordings attached to it, from being purged until it is released.
+type LegalHold struct {
+	Reason   string             `json:"reason"`
+	PlacedAt primitive.DateTime `json:"placedAt"`
+}
+
+// purgedAtKey returns the field recording when the content was purged.
+func (c PurgeContent) purgedAtKey() (string, error) {
+	switch c {
+	case PurgeTranscript:
+		return TranscriptPurgedAtKey, nil
+	case PurgeNotes:
+		return NotesPurgedAtKey, nil
+	default:
+		return "", fmt.Errorf("unknown purge content: %s", c)
+	}
+}
+
+// purgeUpdate returns the update that clears the content in place. Notes are also cleared under the
+// JSON names older updates stored some fields with.
+func (c PurgeContent) purgeUpdate(now time.Time) (bson.M, error) {
+	purgedAtKey, err := c.purgedAtKey()
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{purgedAtKey: primitive.NewDateTimeFromTime(now)}
+	unset := bson.M{}
+
+	switch c {
+	case PurgeTranscript:
+		set[Transcript] = ""
+	case PurgeNotes:
+		for _, section := range TranscriptDerivedSections {
+			set[strings.ToLower(section)+"."+ContentData] = ""
+			if section != strings.ToLower(section) {
+				unset[section] = ""
+			}
+		}
+		for _, field := range []string{CondensedSummary, SessionSummary} {
+			set[strings.ToLower(field)] = ""
+			unset[field] = ""
+		}
+		unset[MemberNotes] = ""
+		unset["memberNotes"] = ""
+		unset[AfterVisitSummaryKey] = ""
+		unset["afterVisitSummary"] = ""
+		unset[AddendaKey] = ""
+		unset[coSignatureCommentsKey] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
+
+/* SetLegalHold places a legal hold on a report, or releases it when hold is nil */
+func (r *reportsStore) SetLegalHold(ctx context.Context, reportId string, hold *LegalHold) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	update := bson.M{"$unset": bson.M{LegalHoldKey: ""}}
+	if hold != nil {
+		if strings.TrimSpace(hold.Reason) == "" {
+			return errors.New("legal hold reason cannot be empty")
+		}
+		update = bson.M{"$set": bson.M{LegalHoldKey: hold}}
+	}
+
+	result, err := r.client.UpdateOne(ctx, bson.M{ID: objectID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to set legal hold: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+/* ProviderIDs returns the IDs of every provider with at least one report */
+func (r *reportsStore) ProviderIDs(ctx context.Context) ([]string, error) {
+	values, err := r.client.Distinct(ctx, ProviderID, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list providers: %v", err)
+	}
+	providerIDs := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok && id != "" {
+			providerIDs = append(providerIDs, id)
+		}
+	}
+	return providerIDs, nil
+}
+
+/* HeldReportIDs returns the IDs of the provider's reports under a legal hold */
+func (r *reportsStore) HeldReportIDs(ctx context.Context, providerID string) ([]primitive.ObjectID, error) {
+	filter := bson.M{ProviderID: providerID, LegalHoldKey: bson.M{"$ne": nil}}
+	return r.findIDs(ctx, filter, 0)
+}
+
+/*
+	ExpiredReportIDs returns up to limit reports of the provider created before the cutoff whose content
+
+has not been purged yet. Reports under a legal hold are left out
+*/
+func (r *reportsStore) ExpiredReportIDs(ctx context.Context, providerID string, content PurgeContent, before time.Time, limit int64) ([]primitive.ObjectID, error) {
+	purgedAtKey, err := content.purgedAtKey()
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{
+		ProviderID:   providerID,
+		TimeStamp:    bson.M{"$lt": primitive.NewDateTimeFromTime(before)},
+		LegalHoldKey: nil,
+		purgedAtKey:  nil,
+	}
+	return r.findIDs(ctx, filter, limit)
+}
+
+/*
+	Purge clears the content of a report in place if it is still expired, unpurged and not under a legal hold.
+
+It reports whether this call purged it, so when several instances race only one of them records the purge
+*/
+func (r *reportsStore) Purge(ctx context.Context, reportID primitive.ObjectID, content PurgeContent, before, now time.Time) (bool, error) {
+	purgedAtKey, err := content.purgedAtKey()
+	if err != nil {
+		return false, err
+	}
+	update, err := content.purgeUpdate(now)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{
+		ID:           reportID,
+		TimeStamp:    bson.M{"$lt": primitive.NewDateTimeFromTime(before)},
+		LegalHoldKey: nil,
+		purgedAtKey:  nil,
+	}
+	result, err := r.client.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to purge %s of report %s: %v", content, reportID.Hex(), err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *reportsStore) findIDs(ctx context.Context, filter bson.M, limit int64) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{ID: 1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := r.client.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reports: %v", err)
+	}
+	var documents []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("failed to decode reports: %v", err)
+	}
+	ids := make([]primitive.ObjectID, len(documents))
+	for i, document := range documents {
+		ids[i] = document.ID
+	}
+	return ids, nil
+}
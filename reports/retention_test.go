@@ -0,0 +1,62 @@
+package reports
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestPurgeUpdate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	transcript, err := PurgeTranscript.purgeUpdate(now)
+	require.NoError(t, err)
+	set := transcript["$set"].(bson.M)
+	assert.Equal(t, "", set[Transcript])
+	assert.Contains(t, set, TranscriptPurgedAtKey)
+	assert.NotContains(t, transcript, "$unset")
+
+	notes, err := PurgeNotes.purgeUpdate(now)
+	require.NoError(t, err)
+	set, unset := notes["$set"].(bson.M), notes["$unset"].(bson.M)
+	for _, key := range []string{"subjective.data", "assessmentandplan.data", "patientinstructions.data", "condensedsummary", "sessionsummary"} {
+		assert.Equal(t, "", set[key], key)
+	}
+	assert.Contains(t, set, NotesPurgedAtKey)
+	assert.NotContains(t, set, Transcript)
+	for _, key := range []string{"assessmentAndPlan", "condensedSummary", MemberNotes, "memberNotes", AfterVisitSummaryKey, "afterVisitSummary", AddendaKey, "cosignature.comments"} {
+		assert.Contains(t, unset, key)
+	}
+	assert.NotContains(t, unset, Subjective, "lowercase sections are cleared, not removed")
+
+	_, err = PurgeContent("audio").purgeUpdate(now)
+	assert.Error(t, err)
+}
+
+func TestGetTranscriptionAfterPurge(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("diarized transcript", func(mt *mtest.T) {
+		store := &reportsStore{client: mt.Coll}
+		reportID := primitive.NewObjectID()
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: ProviderID, Value: "provider"},
+			{Key: Transcript, Value: ""},
+			{Key: UsedDiarization, Value: true},
+			{Key: TranscriptPurgedAtKey, Value: primitive.NewDateTimeFromTime(time.Now())},
+		}))
+
+		transcript, err := store.GetTranscription(context.Background(), reportID.Hex())
+		require.NoError(t, err)
+		assert.True(t, transcript.UsedDiarization)
+		assert.Empty(t, transcript.Transcript)
+		assert.Empty(t, transcript.DiarizedTranscript)
+	})
+}
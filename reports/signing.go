@@ -0,0 +1,200 @@
+package reports
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Report statuses. Generation leaves a report pending until it succeeds or fails; a signed report is
+// locked and only accepts addenda.
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusSigned  = "signed"
+)
+
+const (
+	SignatureKey = "signature"
+	AddendaKey   = "addenda"
+)
+
+// DefaultAttestation is recorded when the signer does not provide their own attestation text.
+const DefaultAttestation = "I have reviewed this note and attest that it accurately reflects the visit."
+
+var (
+	// ErrReportSigned is returned when changing the content of a signed report.
+	ErrReportSigned = errors.New("report is signed")
+	// ErrReportNotSigned is returned when adding an addendum to a report that has not been signed.
+	ErrReportNotSigned = errors.New("report is not signed")
+	// ErrReportNotReady is returned when signing a report whose generation has not succeeded.
+	ErrReportNotReady = errors.New("report generation has not completed")
+)
+
+// Signature records who signed a report, when, what they attested to and the hash of the content they signed.
+type Signature struct {
+	SignerID    string             `json:"signerID"`
+	SignerName  string             `json:"signerName"`
+	SignedAt    primitive.DateTime `json:"signedAt"`
+	Attestation string             `json:"attestation"`
+	// ContentHash is the ContentHash of the report when it was signed.
+	ContentHash string `json:"contentHash"`
+}
+
+// Addendum is a correction appended to a signed report. Addenda are never edited or removed.
+type Addendum struct {
+	ID         primitive.ObjectID `json:"id"`
+	AuthorID   string             `json:"authorID"`
+	AuthorName string             `json:"authorName"`
+	Content    string             `json:"content"`
+	CreatedAt  primitive.DateTime `json:"createdAt"`
+}
+
+// signedContent is the note content covered by a signature, in a fixed order so its hash is stable.
+type signedContent struct {
+	ReportID            string       `json:"reportID"`
+	ProviderID          string       `json:"providerID"`
+	Subjective          string       `json:"subjective"`
+	Objective           string       `json:"objective"`
+	AssessmentAndPlan   string       `json:"assessmentAndPlan"`
+	Summary             string       `json:"summary"`
+	PatientInstructions string       `json:"patientInstructions"`
+	CondensedSummary    string       `json:"condensedSummary"`
+	SessionSummary      string       `json:"sessionSummary"`
+	MemberNotes         []signedNote `json:"memberNotes"`
+}
+
+type signedNote struct {
+	ParticipantID string `json:"participantID"`
+	Name          string `json:"name"`
+	Note          string `json:"note"`
+}
+
+// ContentHash returns the hex SHA-256 of the report's note content: its sections, summaries and member
+// notes. It is recorded when the report is signed so later changes to the stored content can be detected.
+func (r *Report) ContentHash() string {
+	content := signedContent{
+		ReportID:            r.ID.Hex(),
+		ProviderID:          r.ProviderID,
+		Subjective:          r.Subjective.Data,
+		Objective:           r.Objective.Data,
+		AssessmentAndPlan:   r.AssessmentAndPlan.Data,
+		Summary:             r.Summary.Data,
+		PatientInstructions: r.PatientInstructions.Data,
+		CondensedSummary:    r.CondensedSummary,
+		SessionSummary:      r.SessionSummary,
+		MemberNotes:         []signedNote{},
+	}
+	for _, note := range r.MemberNotes {
+		content.MemberNotes = append(content.MemberNotes, signedNote{ParticipantID: note.ParticipantID, Name: note.Name, Note: note.Note.Data})
+	}
+	// Marshalling a struct of strings cannot fail.
+	encoded, _ := json.Marshal(content)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsSigned reports whether the report has been signed and is locked.
+func (r *Report) IsSigned() bool {
+	return r.Signature != nil
+}
+
+func addendumField(addendumID primitive.ObjectID) string {
+	return AddendaKey + "." + addendumID.Hex() + "." + Content
+}
+
+// Sign locks a report whose generation succeeded under the signature; afterwards it is only corrected with
+// addenda. When the signer is supervised, supervisorID is set and the note awaits the supervisor's co-signature.
+// The report must still be at version, the one whose content was hashed into the signature.
+func (r *reportsStore) Sign(ctx context.Context, reportId string, version int64, signature Signature, supervisorID string) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+	if signature.SignerID == "" {
+		return errors.New("signer cannot be empty")
+	}
+	if strings.TrimSpace(signature.Attestation) == "" {
+		return errors.New("attestation cannot be empty")
+	}
+	if signature.ContentHash == "" {
+		return errors.New("content hash cannot be empty")
+	}
+
+	filter := bson.M{ID: objectID, SignatureKey: nil, Status: StatusSuccess, VersionKey: versionFilter(version), "$or": notRegenerating(time.Now())}
+	update := bson.M{"$set": bson.M{SignatureKey: signature, Status: StatusSigned}}
+	if supervisorID != "" {
+		// A note returned for changes keeps the comments of earlier reviews
+		update["$set"] = bson.M{
+			SignatureKey:              signature,
+			Status:                    StatusPendingCoSignature,
+			coSignatureSupervisorKey:  supervisorID,
+			coSignatureStateKey:       CoSignaturePending,
+			coSignatureRequestedAtKey: signature.SignedAt,
+		}
+		update["$unset"] = bson.M{coSignatureSignatureKey: "", coSignatureReturnedAtKey: ""}
+	}
+	result, err := r.client.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to sign report: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.staleWriteError(ctx, objectID, version, ErrReportNotReady)
+	}
+	return nil
+}
+
+/* AddAddendum appends a correction to a signed report and returns it as stored */
+func (r *reportsStore) AddAddendum(ctx context.Context, reportId string, addendum Addendum) (Addendum, error) {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return Addendum{}, fmt.Errorf("invalid ID format: %v", err)
+	}
+	if addendum.AuthorID == "" {
+		return Addendum{}, errors.New("author cannot be empty")
+	}
+	if strings.TrimSpace(addendum.Content) == "" {
+		return Addendum{}, errors.New("addendum content cannot be empty")
+	}
+	if addendum.ID.IsZero() {
+		addendum.ID = primitive.NewObjectID()
+	}
+
+	stored := addendum
+	stored.Content, err = r.encryptField(ctx, objectID, addendumField(addendum.ID), addendum.Content)
+	if err != nil {
+		return Addendum{}, fmt.Errorf("failed to encrypt addendum: %v", err)
+	}
+
+	filter := bson.M{ID: objectID, SignatureKey: bson.M{"$ne": nil}}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$push": bson.M{AddendaKey: stored}})
+	if err != nil {
+		return Addendum{}, fmt.Errorf("failed to add addendum: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return Addendum{}, r.unmatchedWriteError(ctx, objectID, ErrReportNotSigned)
+	}
+	return addendum, nil
+}
+
+// unmatchedWriteError explains why a conditional write on a report matched nothing: the report is missing,
+// it is signed, or otherwise the condition the write was guarded by no longer held, reported as fallback.
+func (r *reportsStore) unmatchedWriteError(ctx context.Context, objectID primitive.ObjectID, fallback error) error {
+	state, err := r.writeState(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if state.Signature != nil {
+		return ErrReportSigned
+	}
+	return fallback
+}
@@ -0,0 +1,170 @@
+package reports
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestContentHash(t *testing.T) {
+	report := Report{
+		ID:                primitive.NewObjectID(),
+		ProviderID:        "provider-1",
+		Subjective:        ReportContent{Data: "headache for three days"},
+		AssessmentAndPlan: ReportContent{Data: "start ibuprofen"},
+		MemberNotes:       []MemberNote{{ParticipantID: "p1", Name: "Parent", Note: ReportContent{Data: "parent note"}}},
+	}
+	hash := report.ContentHash()
+	assert.Len(t, hash, 64)
+
+	// Fields outside the note content do not change the hash.
+	unchanged := report
+	unchanged.Name = "Renamed visit"
+	unchanged.ReadStatus = true
+	unchanged.Subjective.Stale = true
+	assert.Equal(t, hash, unchanged.ContentHash())
+
+	tests := []struct {
+		name   string
+		change func(*Report)
+	}{
+		{"section", func(r *Report) { r.AssessmentAndPlan.Data = "start naproxen" }},
+		{"summary", func(r *Report) { r.CondensedSummary = "headache" }},
+		{"member note", func(r *Report) {
+			r.MemberNotes = []MemberNote{{ParticipantID: "p1", Name: "Parent", Note: ReportContent{Data: "edited"}}}
+		}},
+		{"moved between sections", func(r *Report) { r.Subjective.Data, r.Objective.Data = "", r.Subjective.Data }},
+		{"other report", func(r *Report) { r.ID = primitive.NewObjectID() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := report
+			tt.change(&changed)
+			assert.NotEqual(t, hash, changed.ContentHash())
+		})
+	}
+}
+
+func TestDecryptReportAddenda(t *testing.T) {
+	ctx := context.Background()
+	cipher := newTestCipher(t, newTestDataKey(t, 1))
+	reportID := primitive.NewObjectID()
+
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	firstContent, err := cipher.encrypt(ctx, "provider-1", reportID.Hex(), addendumField(first), "corrected dose")
+	require.NoError(t, err)
+	secondContent, err := cipher.encrypt(ctx, "provider-1", reportID.Hex(), addendumField(second), "added allergy")
+	require.NoError(t, err)
+
+	// Swapping the content of two addenda must not decrypt.
+	swapped := []Addendum{{ID: first, Content: secondContent}, {ID: second, Content: firstContent}}
+	assert.Error(t, cipher.decryptReport(ctx, &Report{ID: reportID, ProviderID: "provider-1", Addenda: swapped}))
+
+	report := Report{ID: reportID, ProviderID: "provider-1", Addenda: []Addendum{{ID: first, Content: firstContent}, {ID: second, Content: secondContent}}}
+	require.NoError(t, cipher.decryptReport(ctx, &report))
+	assert.Equal(t, "corrected dose", report.Addenda[0].Content)
+	assert.Equal(t, "added allergy", report.Addenda[1].Content)
+}
+
+func TestFormatNoteText(t *testing.T) {
+	visit := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	report := Report{
+		Name:              "Follow-up",
+		TimeStamp:         primitive.NewDateTimeFromTime(visit),
+		Subjective:        ReportContent{Data: "headache for three days"},
+		AssessmentAndPlan: ReportContent{Data: "start ibuprofen"},
+	}
+
+	unsigned := FormatNoteText(report)
+	assert.Contains(t, unsigned, "SUBJECTIVE\nheadache for three days\n")
+	assert.Contains(t, unsigned, "ASSESSMENT AND PLAN\nstart ibuprofen\n")
+	assert.NotContains(t, unsigned, "OBJECTIVE", "empty sections are left out")
+	assert.True(t, strings.HasSuffix(unsigned, "UNSIGNED\n"))
+
+	report.Signature = &Signature{SignerID: "provider-1", SignerName: "Dr. Rivera", SignedAt: primitive.NewDateTimeFromTime(visit.Add(time.Hour)), Attestation: DefaultAttestation, ContentHash: "abc123"}
+	report.Addenda = []Addendum{
+		{AuthorID: "provider-1", Content: "dose is 400mg", CreatedAt: primitive.NewDateTimeFromTime(visit.Add(2 * time.Hour))},
+		{AuthorID: "provider-2", Content: "no known allergies", CreatedAt: primitive.NewDateTimeFromTime(visit.Add(3 * time.Hour))},
+	}
+	signed := FormatNoteText(report)
+	assert.Contains(t, signed, "SIGNED by Dr. Rivera on 2026-03-01 10:30 UTC\n"+DefaultAttestation+"\nContent hash: abc123\n")
+	first := strings.Index(signed, "ADDENDUM 1 by provider-1 on 2026-03-01 11:30 UTC\ndose is 400mg\n")
+	second := strings.Index(signed, "ADDENDUM 2 by provider-2 on 2026-03-01 12:30 UTC\nno known allergies\n")
+	require.NotEqual(t, -1, first)
+	assert.Greater(t, second, first, "addenda are exported in the order they were added")
+	assert.NotContains(t, signed, "UNSIGNED")
+
+	report.CoSignature = &CoSignature{SupervisorID: "supervisor-1", State: CoSignaturePending}
+	assert.Contains(t, FormatNoteText(report), "AWAITING CO-SIGNATURE\n")
+
+	report.CoSignature.State = CoSignatureSigned
+	report.CoSignature.Signature = &Signature{SignerID: "supervisor-1", SignerName: "Dr. Chen", SignedAt: primitive.NewDateTimeFromTime(visit.Add(90 * time.Minute)), Attestation: "Reviewed and agree."}
+	cosigned := FormatNoteText(report)
+	assert.Contains(t, cosigned, "CO-SIGNED by Dr. Chen on 2026-03-01 11:00 UTC\nReviewed and agree.\n")
+	assert.NotContains(t, cosigned, "AWAITING CO-SIGNATURE")
+}
+
+func TestDecryptReviewComments(t *testing.T) {
+	ctx := context.Background()
+	cipher := newTestCipher(t, newTestDataKey(t, 1))
+	reportID, commentID := primitive.NewObjectID(), primitive.NewObjectID()
+	content, err := cipher.encrypt(ctx, "provider-1", reportID.Hex(), reviewCommentField(commentID), "clarify the dose")
+	require.NoError(t, err)
+
+	report := Report{ID: reportID, ProviderID: "provider-1", CoSignature: &CoSignature{Comments: []ReviewComment{{ID: commentID, Content: content}}}}
+	require.NoError(t, cipher.decryptReport(ctx, &report))
+	assert.Equal(t, "clarify the dose", report.CoSignature.Comments[0].Content)
+
+	// A comment cannot be decrypted as an addendum.
+	addendum := Report{ID: reportID, ProviderID: "provider-1", Addenda: []Addendum{{ID: commentID, Content: content}}}
+	assert.Error(t, cipher.decryptReport(ctx, &addendum))
+}
+
+func TestDeleteKeepsSignedReports(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("signed report", func(mt *mtest.T) {
+		store := &reportsStore{client: mt.Coll}
+		reportID := primitive.NewObjectID()
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: SignatureKey, Value: bson.D{{Key: "signerid", Value: "provider-1"}}}}),
+		)
+
+		err := store.Delete(context.Background(), reportID.Hex())
+		assert.ErrorIs(t, err, ErrReportSigned)
+
+		filter := mt.GetAllStartedEvents()[0].Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+		signature, err := filter.LookupErr(SignatureKey)
+		require.NoError(t, err)
+		assert.Equal(t, bson.TypeNull, signature.Type)
+	})
+
+	mt.Run("held report", func(mt *mtest.T) {
+		store := &reportsStore{client: mt.Coll}
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: LegalHoldKey, Value: bson.D{{Key: "reason", Value: "litigation"}}}}),
+		)
+
+		assert.ErrorIs(t, store.Delete(context.Background(), primitive.NewObjectID().Hex()), ErrReportOnLegalHold)
+	})
+
+	mt.Run("missing report", func(mt *mtest.T) {
+		store := &reportsStore{client: mt.Coll}
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}), mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+		err := store.Delete(context.Background(), primitive.NewObjectID().Hex())
+		assert.EqualError(t, err, "report not found")
+	})
+}
@@ -1,6 +1,8 @@
 package reports
 
 import (
+	"Medscribe/audio"
+	"Medscribe/dataKeyStore"
 	transcriber "Medscribe/transcription"
 	"context"
 	"encoding/json"
@@ -44,6 +46,7 @@ const (
 
 
 	Loading = "loading"
+	Stale   = "stale"
 
 	Content = "content"
 
@@ -76,15 +79,59 @@ const (
 
 	tokens = "tokens"
 
+	AudioBlobID = "audioblobid"
+
+	TrimmedDuration = "trimmedduration"
+
+	AudioQuality = "audioquality"
+
+	Duration      = "duration"
+	AudioSegments = "audiosegments"
+
 	UsedDiarization = "useddiarizedtranscript"
 	UsedDiarizationUpdateKey = "usedDiarizedTranscript"
 
+	Mode = "mode"
+
+	Participants = "participants"
+	MemberNotes  = "membernotes"
+
+	AfterVisitSummaryKey = "aftervisitsummary"
+
+	NoteLanguage        = "noteLanguage"
+	PatientLanguage     = "patientLanguage"
+	TranscriptLanguages = "transcriptLanguages"
+
 
 )
 
+// Report modes describe how the visit was captured. A conversation is a recorded visit between
+// provider and patient; a dictation is the provider narrating the note alone.
+const (
+	ModeConversation = "conversation"
+	ModeDictation    = "dictation"
+)
+
+// ParseMode validates a requested report mode, defaulting to a conversation when none is given.
+func ParseMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return ModeConversation, nil
+	case ModeConversation, ModeDictation:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("mode must be either '%s' or '%s', got: %s", ModeConversation, ModeDictation, mode)
+	}
+}
+
+// TranscriptDerivedSections lists the content sections generated from the visit transcript.
+// They are marked stale whenever the transcript changes after generation.
+var TranscriptDerivedSections = []string{Subjective, Objective, AssessmentAndPlan, PatientInstructions, Summary}
+
 type ReportContent struct {
 	Data    string `json:"data"`
 	Loading bool   `json:"loading"`
+	Stale   bool   `json:"stale"`
 }
 
 // RetrievedReportTranscripts
@@ -93,7 +140,49 @@ type RetrievedReportTranscripts struct {
 	ProviderID         string `json:"providerID"`
 	Transcript string `json:"transcript"`
 	DiarizedTranscript []transcriber.TranscriptTurn `json:"diarizedTranscript"`
+	// SpeakerMapping maps each raw diarization label to the role or participant its turns are resolved to.
+	SpeakerMapping map[string]string `json:"speakerMapping"`
 	UsedDiarization bool `json:"usedDiarization"`
+	// LowConfidenceSpans lists the passages worth double-checking against the audio.
+	LowConfidenceSpans []transcriber.LowConfidenceSpan `json:"lowConfidenceSpans"`
+	// Participants is the session roster; diarized turns carry participant IDs when it is set.
+	Participants []transcriber.Participant `json:"participants"`
+	// Signed is set once the report is signed, after which the transcript can no longer be changed.
+	Signed bool `json:"signed"`
+	// Version is the version of the report the transcript was read at.
+	Version int64 `json:"version"`
+}
+
+// AfterVisitSummary is the plain-language summary handed to the patient after the visit. It is kept
+// apart from PatientInstructions, which clinicians review in clinical language.
+type AfterVisitSummary struct {
+	WhatWeDiscussed   string `json:"whatWeDiscussed"`
+	MedicationChanges string `json:"medicationChanges"`
+	NextAppointment   string `json:"nextAppointment"`
+	WhenToSeekHelp    string `json:"whenToSeekHelp"`
+	Language          string `json:"language"`
+	// ReadingLevel is the target US school grade and Grade the Flesch-Kincaid grade the summary
+	// scored. Grade is zero for languages the score does not apply to.
+	ReadingLevel int                `json:"readingLevel"`
+	Grade        float64            `json:"grade"`
+	GeneratedAt  primitive.DateTime `json:"generatedAt"`
+}
+
+// MemberNote is the note written for one member of a group session.
+type MemberNote struct {
+	ParticipantID string        `json:"participantID"`
+	Name          string        `json:"name"`
+	Note          ReportContent `json:"note"`
+}
+
+// AudioSegment records one recording of a visit that was captured in several parts.
+// Offset is where the recording starts on the combined transcript timeline, in seconds.
+type AudioSegment struct {
+	AudioBlobID     string             `json:"audioBlobID"`
+	Offset          float64            `json:"offset"`
+	Duration        float64            `json:"duration"`
+	TrimmedDuration float64            `json:"trimmedDuration"`
+	RecordedAt      primitive.DateTime `json:"recordedAt"`
 }
 
 type Report struct {
@@ -117,29 +206,160 @@ type Report struct {
 	LastVisitID         string             `json:"lastVisitID"`
 	Status              string             `json:"status"`
 	UsedDiarizedTranscript bool `json:"usedDiarizedTranscript"`
+	AudioBlobID         string             `json:"audioBlobID"`
+	// TrimmedDuration is the length of audio sent for transcription after long silences were
+	// removed. It is zero when the recording format could not be trimmed.
+	TrimmedDuration float64 `json:"trimmedDuration"`
+	// AudioQuality is nil when the recording format could not be analyzed.
+	AudioQuality *audio.Quality `json:"audioQuality,omitempty"`
+	// AudioSegments lists the recordings merged into the report once a second one was appended.
+	AudioSegments []AudioSegment `json:"audioSegments"`
+	// Mode is how the visit was captured, ModeConversation or ModeDictation. Reports created
+	// before dictation was supported have no mode and are conversations.
+	Mode string `json:"mode"`
+	// Participants is the session roster for visits with more than a provider and a patient.
+	Participants []transcriber.Participant `json:"participants"`
+	// MemberNotes holds one note per patient of a group session.
+	MemberNotes []MemberNote `json:"memberNotes"`
+	// NoteLanguage is the language clinical sections are written in and PatientLanguage the language
+	// of the patient instructions, as ISO 639-1 codes. The bson keys match the JSON keys so both can
+	// be edited through UpdateReport.
+	NoteLanguage    string `bson:"noteLanguage" json:"noteLanguage"`
+	PatientLanguage string `bson:"patientLanguage" json:"patientLanguage"`
+	// TranscriptLanguages lists the languages detected in the transcript, most spoken first.
+	TranscriptLanguages []string `bson:"transcriptLanguages" json:"transcriptLanguages"`
+	// AfterVisitSummary is nil until one is generated for the patient.
+	AfterVisitSummary *AfterVisitSummary `json:"afterVisitSummary,omitempty"`
+	// LegalHold is set while the report must be kept regardless of retention policies.
+	LegalHold *LegalHold `json:"legalHold,omitempty"`
+	// TranscriptPurgedAt and NotesPurgedAt are set once retention purged the transcript or the note content.
+	TranscriptPurgedAt *primitive.DateTime `json:"transcriptPurgedAt,omitempty"`
+	NotesPurgedAt      *primitive.DateTime `json:"notesPurgedAt,omitempty"`
+	// Signature is set once the report is signed, after which its content is locked and corrections are
+	// appended to Addenda, oldest first.
+	Signature *Signature `json:"signature,omitempty"`
+	Addenda   []Addendum `json:"addenda"`
+	// CoSignature is set once a supervised provider signs the note and tracks the supervisor's review.
+	CoSignature *CoSignature `bson:"cosignature,omitempty" json:"coSignature,omitempty"`
+	// Version counts the changes to the report's content and transcript. Every such write is based on
+	// the version it read and is rejected with a VersionConflictError once another write got there first.
+	Version int64 `bson:"version" json:"version"`
+	// Regenerating is set while a generation holds the report, until the time its lease expires. Writes to the
+	// content and transcript are rejected until the generation stores its content or releases the report.
+	Regenerating *primitive.DateTime `bson:"regenerating,omitempty" json:"regenerating,omitempty"`
+}
+
+// Section returns the content of a transcript-derived section by name.
+func (r *Report) Section(section string) (ReportContent, bool) {
+	switch section {
+	case Subjective:
+		return r.Subjective, true
+	case Objective:
+		return r.Objective, true
+	case AssessmentAndPlan:
+		return r.AssessmentAndPlan, true
+	case Summary:
+		return r.Summary, true
+	case PatientInstructions:
+		return r.PatientInstructions, true
+	default:
+		return ReportContent{}, false
+	}
+}
+
+// StaleSections returns the sections whose transcript changed after they were generated.
+func (r *Report) StaleSections() []string {
+	stale := []string{}
+	for _, section := range TranscriptDerivedSections {
+		if content, _ := r.Section(section); content.Stale {
+			stale = append(stale, section)
+		}
+	}
+	return stale
 }
 
 type Reports interface {
 	Put(ctx context.Context, name, providerID string, timestamp time.Time, duration float64, isFollowUp bool, pronouns string, lastVisitID string, usedDiarization bool) (string, error)
 	Get(ctx context.Context, reportId string) (Report, error)
 	GetAll(ctx context.Context, userId string) ([]Report, error)
-	UpdateReport(ctx context.Context, reportId string, batchedUpdates bson.D) error
+	UpdateReport(ctx context.Context, reportId string, version int64, batchedUpdates bson.D) (int64, error)
+	LockForRegeneration(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error)
+	CompleteRegeneration(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error)
+	ReleaseRegeneration(ctx context.Context, reportId string, version int64) error
 	Validate(report *Report) error
 	Delete(ctx context.Context, reportId string) error
 	GetTranscription(ctx context.Context, reportId string) (RetrievedReportTranscripts, error)
 	MarkRead(ctx context.Context, reportId string) error
 	MarkUnread(ctx context.Context, reportId string) error
 	UpdateStatus(ctx context.Context, reportId string, status string) error
+	UpdateTranscript(ctx context.Context, reportId string, version int64, transcript string) (int64, error)
+	MarkSectionsStale(ctx context.Context, reportId string, sections ...string) error
+	SetAudioBlobID(ctx context.Context, reportId string, blobID string) error
+	SetAudioQuality(ctx context.Context, reportId string, quality audio.Quality) error
+	SetTrimmedDuration(ctx context.Context, reportId string, trimmedDuration float64) error
+	SetAfterVisitSummary(ctx context.Context, reportId string, summary AfterVisitSummary) error
+	SetTranscriptLanguages(ctx context.Context, reportId string, languages []string) error
+	SetParticipants(ctx context.Context, reportId string, participants []transcriber.Participant) error
+	SetMemberNotes(ctx context.Context, reportId string, notes []MemberNote) error
+	AppendRecording(ctx context.Context, reportId string, version int64, previousDuration, duration float64, transcript string, segments ...AudioSegment) (int64, error)
+	ReencryptAll(ctx context.Context) (int, error)
+	SetLegalHold(ctx context.Context, reportId string, hold *LegalHold) error
+	Sign(ctx context.Context, reportId string, version int64, signature Signature, supervisorID string) error
+	AddAddendum(ctx context.Context, reportId string, addendum Addendum) (Addendum, error)
+	PendingCoSignatures(ctx context.Context, supervisorID string) ([]Report, error)
+	AddReviewComment(ctx context.Context, reportId string, comment ReviewComment) (ReviewComment, error)
+	ReturnForChanges(ctx context.Context, reportId string, comment ReviewComment) (ReviewComment, error)
+	CoSign(ctx context.Context, reportId string, signature Signature) error
+	ProviderIDs(ctx context.Context) ([]string, error)
+	HeldReportIDs(ctx context.Context, providerID string) ([]primitive.ObjectID, error)
+	ExpiredReportIDs(ctx context.Context, providerID string, content PurgeContent, before time.Time, limit int64) ([]primitive.ObjectID, error)
+	Purge(ctx context.Context, reportID primitive.ObjectID, content PurgeContent, before, now time.Time) (bool, error)
 }
 
 type reportsStore struct {
 	client *mongo.Collection
+	// fields encrypts transcripts, note content and summaries at rest. It is nil for a plaintext store.
+	fields *fieldCipher
 }
 
 func NewReportsStore(collection *mongo.Collection) Reports {
 	return &reportsStore{client: collection}
 }
 
+// NewEncryptedReportsStore creates a Reports store that encrypts transcripts, note content and summaries
+// with each provider's data key from keys. Callers see plaintext; reports stored before encryption was
+// enabled are read as they are until ReencryptAll seals them.
+func NewEncryptedReportsStore(collection *mongo.Collection, keys dataKeyStore.DataKeyStore) Reports {
+	return &reportsStore{client: collection, fields: &fieldCipher{keys: keys}}
+}
+
+// providerID looks up the provider a report belongs to, whose data key encrypts its fields.
+func (r *reportsStore) providerID(ctx context.Context, objectID primitive.ObjectID) (string, error) {
+	var owner struct {
+		ProviderID string
+	}
+	opts := options.FindOne().SetProjection(bson.M{ProviderID: 1})
+	if err := r.client.FindOne(ctx, bson.M{ID: objectID}, opts).Decode(&owner); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", fmt.Errorf("report not found")
+		}
+		return "", fmt.Errorf("failed to retrieve report owner: %v", err)
+	}
+	return owner.ProviderID, nil
+}
+
+// encryptField seals one field of a report for storage. It is a no-op for a plaintext store.
+func (r *reportsStore) encryptField(ctx context.Context, objectID primitive.ObjectID, field, value string) (string, error) {
+	if r.fields == nil || value == "" {
+		return value, nil
+	}
+	providerID, err := r.providerID(ctx, objectID)
+	if err != nil {
+		return "", err
+	}
+	return r.fields.encrypt(ctx, providerID, objectID.Hex(), field, value)
+}
+
 /* Put partially filled record into reports collection */
 func (r *reportsStore) Put(ctx context.Context, name, providerID string, timestamp time.Time, duration float64, isFollowUp bool, pronouns string, lastVisitID string, usedDiarization bool) (string, error) {
 	if name == "" {
@@ -212,6 +432,9 @@ func (r *reportsStore) Get(ctx context.Context, reportId string) (Report, error)
 	if err != nil {
 		return Report{}, fmt.Errorf("failed to retrieve report: %v", err)
 	}
+	if err := r.fields.decryptReport(ctx, &retrievedReport); err != nil {
+		return Report{}, fmt.Errorf("failed to decrypt report: %v", err)
+	}
 
 	return retrievedReport, nil
 }
@@ -224,8 +447,9 @@ func (r *reportsStore) UpdateStatus(ctx context.Context, reportId string, status
 	if err != nil {
 		return fmt.Errorf("invalid ID format: %v", err)
 	}
-	filter := bson.M{ID: objectID}
-	update := bson.M{"$set": bson.M{status: status}}
+	// A signed report keeps its status
+	filter := bson.M{ID: objectID, SignatureKey: nil}
+	update := bson.M{"$set": bson.M{Status: status}}
 	_, err = r.client.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return fmt.Errorf("failed to update report status: %v", err)
@@ -242,22 +466,30 @@ func (r *reportsStore) GetTranscription(ctx context.Context, reportId string) (R
 	}
 
 	filter := bson.M{ID: objectID}
-	projection := bson.M{Transcript: 1, ProviderID: 1, UsedDiarization: 1,ID: 0} // Include only transcript and providerID fields
+	projection := bson.M{Transcript: 1, ProviderID: 1, UsedDiarization: 1, Participants: 1, SignatureKey: 1, VersionKey: 1, ID: 0} // Include only transcript and providerID fields
 	opts := options.FindOne().SetProjection(projection)
 
 	var partialReport struct {
 		ProviderID string 
 		Transcript string
 		UsedDiarizedTranscript bool
+		Participants []transcriber.Participant
+		Signature *Signature
+		Version int64
 	}
 
 	err = r.client.FindOne(ctx, filter, opts).Decode(&partialReport)
 	if err != nil {
 		return RetrievedReportTranscripts{}, fmt.Errorf("failed to retrieve transcript: %v", err)
 	}
+	partialReport.Transcript, err = r.fields.decrypt(ctx, partialReport.ProviderID, reportId, Transcript, partialReport.Transcript)
+	if err != nil {
+		return RetrievedReportTranscripts{}, fmt.Errorf("failed to decrypt transcript: %v", err)
+	}
 
+	// A purged transcript is cleared in place and left with no turns.
 	var transcriptTurns []transcriber.TranscriptTurn
-	if partialReport.UsedDiarizedTranscript{
+	if partialReport.UsedDiarizedTranscript && strings.TrimSpace(partialReport.Transcript) != "" {
 		err = json.Unmarshal([]byte(partialReport.Transcript), &transcriptTurns)
 		if err != nil {
 			return RetrievedReportTranscripts{}, fmt.Errorf("failed to unmarshal transcript: %v", err)
@@ -266,8 +498,13 @@ func (r *reportsStore) GetTranscription(ctx context.Context, reportId string) (R
 	retrievedTranscript := RetrievedReportTranscripts{
 		Transcript:         partialReport.Transcript,
 		DiarizedTranscript: transcriptTurns,
+		SpeakerMapping: transcriber.SpeakerMapping(transcriptTurns),
 		ProviderID: partialReport.ProviderID,
 		UsedDiarization: partialReport.UsedDiarizedTranscript,
+		LowConfidenceSpans: transcriber.LowConfidenceSpans(transcriptTurns, transcriber.DefaultConfidenceThreshold),
+		Participants: partialReport.Participants,
+		Signed: partialReport.Signature != nil,
+		Version: partialReport.Version,
 	}
 	return retrievedTranscript, nil
 }
@@ -296,6 +533,11 @@ func (r *reportsStore) GetAll(ctx context.Context, providerId string) ([]Report,
     if err := cursor.All(ctx, &retrievedReports); err != nil {
         return nil, fmt.Errorf("failed to decode reports: %v", err)
     }
+    for i := range retrievedReports {
+        if err := r.fields.decryptReport(ctx, &retrievedReports[i]); err != nil {
+            return nil, fmt.Errorf("failed to decrypt report %s: %v", retrievedReports[i].ID.Hex(), err)
+        }
+    }
 
     return retrievedReports, nil
 }
@@ -308,13 +550,25 @@ func (r *reportsStore) Delete(ctx context.Context, reportId string) error {
 		return fmt.Errorf("invalid ID format: %v", err)
 	}
 
-	filter := bson.M{ID: objectID}
+	// A held report is kept until the hold is released, and a signed report is a legal record that is never deleted.
+	filter := bson.M{ID: objectID, LegalHoldKey: nil, SignatureKey: nil}
 	result, err := r.client.DeleteOne(ctx, filter)
 	if err != nil {
 		return fmt.Errorf("failed to delete report: %v", err)
 	}
 
 	if result.DeletedCount == 0 {
+		var kept struct {
+			LegalHold *LegalHold
+			Signature *Signature
+		}
+		opts := options.FindOne().SetProjection(bson.M{LegalHoldKey: 1, SignatureKey: 1})
+		if err := r.client.FindOne(ctx, bson.M{ID: objectID}, opts).Decode(&kept); err == nil {
+			if kept.Signature != nil {
+				return ErrReportSigned
+			}
+			return ErrReportOnLegalHold
+		}
 		return fmt.Errorf("report not found")
 	}
 
@@ -357,6 +611,306 @@ func (r *reportsStore) MarkUnread(ctx context.Context, reportId string) error {
 	return nil
 }
 
+/* UpdateTranscript replaces the stored transcript of a report still at version and returns the report's new version */
+func (r *reportsStore) UpdateTranscript(ctx context.Context, reportId string, version int64, transcript string) (int64, error) {
+	if transcript == "" {
+		return 0, errors.New("transcript cannot be empty")
+	}
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	transcript, err = r.encryptField(ctx, objectID, Transcript, transcript)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt transcript: %v", err)
+	}
+
+	filter := bson.M{ID: objectID, SignatureKey: nil, VersionKey: versionFilter(version), "$or": notRegenerating(time.Now())}
+	update := bson.M{"$set": bson.M{Transcript: transcript}, "$inc": bson.M{VersionKey: 1}}
+	result, err := r.client.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update transcript: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return 0, r.staleWriteError(ctx, objectID, version, fmt.Errorf("report not found"))
+	}
+	return version + 1, nil
+}
+
+/* MarkSectionsStale flags content sections whose source transcript changed after they were generated */
+func (r *reportsStore) MarkSectionsStale(ctx context.Context, reportId string, sections ...string) error {
+	if len(sections) == 0 {
+		return errors.New("no sections provided")
+	}
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	staleFields := bson.M{}
+	for _, section := range sections {
+		if !isContentSection(section) {
+			return fmt.Errorf("invalid content section: %s", section)
+		}
+		staleFields[section+"."+Stale] = true
+	}
+
+	filter := bson.M{ID: objectID, SignatureKey: nil}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": staleFields})
+	if err != nil {
+		return fmt.Errorf("failed to mark sections stale: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.unmatchedWriteError(ctx, objectID, fmt.Errorf("report not found"))
+	}
+	return nil
+}
+
+/* SetAudioBlobID links the retained recording of the visit to the report */
+func (r *reportsStore) SetAudioBlobID(ctx context.Context, reportId string, blobID string) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	filter := bson.M{ID: objectID}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{AudioBlobID: blobID}})
+	if err != nil {
+		return fmt.Errorf("failed to set audio blob id: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+/* SetAudioQuality stores the quality summary of the visit recording on the report */
+func (r *reportsStore) SetAudioQuality(ctx context.Context, reportId string, quality audio.Quality) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	filter := bson.M{ID: objectID}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{AudioQuality: quality}})
+	if err != nil {
+		return fmt.Errorf("failed to set audio quality: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+/* SetTrimmedDuration stores the length of the audio sent for transcription once long silences were removed */
+func (r *reportsStore) SetTrimmedDuration(ctx context.Context, reportId string, trimmedDuration float64) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	filter := bson.M{ID: objectID}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{TrimmedDuration: trimmedDuration}})
+	if err != nil {
+		return fmt.Errorf("failed to set trimmed duration: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+/* SetAfterVisitSummary stores the patient-facing after-visit summary of a report, replacing any previous one */
+func (r *reportsStore) SetAfterVisitSummary(ctx context.Context, reportId string, summary AfterVisitSummary) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	if r.fields != nil {
+		providerID, err := r.providerID(ctx, objectID)
+		if err != nil {
+			return err
+		}
+		if summary, err = r.fields.encryptAfterVisitSummary(ctx, providerID, reportId, summary); err != nil {
+			return fmt.Errorf("failed to encrypt after-visit summary: %v", err)
+		}
+	}
+
+	filter := bson.M{ID: objectID}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{AfterVisitSummaryKey: summary}})
+	if err != nil {
+		return fmt.Errorf("failed to set after-visit summary: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+/* SetTranscriptLanguages stores the languages detected in the transcript of a report */
+func (r *reportsStore) SetTranscriptLanguages(ctx context.Context, reportId string, languages []string) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	filter := bson.M{ID: objectID}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{TranscriptLanguages: languages}})
+	if err != nil {
+		return fmt.Errorf("failed to set transcript languages: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("report not found")
+	}
+	return nil
+}
+
+/* SetParticipants stores the session roster of a report */
+func (r *reportsStore) SetParticipants(ctx context.Context, reportId string, participants []transcriber.Participant) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	filter := bson.M{ID: objectID, SignatureKey: nil}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{Participants: participants}})
+	if err != nil {
+		return fmt.Errorf("failed to set participants: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.unmatchedWriteError(ctx, objectID, fmt.Errorf("report not found"))
+	}
+	return nil
+}
+
+/* SetMemberNotes replaces the per-member notes of a group session report */
+func (r *reportsStore) SetMemberNotes(ctx context.Context, reportId string, notes []MemberNote) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	if r.fields != nil {
+		providerID, err := r.providerID(ctx, objectID)
+		if err != nil {
+			return err
+		}
+		if notes, err = r.fields.encryptMemberNotes(ctx, providerID, reportId, notes); err != nil {
+			return fmt.Errorf("failed to encrypt member notes: %v", err)
+		}
+	}
+
+	filter := bson.M{ID: objectID, SignatureKey: nil}
+	result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": bson.M{MemberNotes: notes}})
+	if err != nil {
+		return fmt.Errorf("failed to set member notes: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.unmatchedWriteError(ctx, objectID, fmt.Errorf("report not found"))
+	}
+	return nil
+}
+
+/* AppendRecording stores the transcript merged with an appended recording, sets the combined duration and records the segments.
+The update only applies while the report is still at version with previousDuration, so neither two recordings appended at once nor
+an edit made while the recording was transcribed can be overwritten. The report's new version is returned */
+func (r *reportsStore) AppendRecording(ctx context.Context, reportId string, version int64, previousDuration, duration float64, transcript string, segments ...AudioSegment) (int64, error) {
+	if transcript == "" {
+		return 0, errors.New("transcript cannot be empty")
+	}
+	if len(segments) == 0 {
+		return 0, errors.New("no audio segments provided")
+	}
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	transcript, err = r.encryptField(ctx, objectID, Transcript, transcript)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt transcript: %v", err)
+	}
+
+	filter := bson.M{ID: objectID, Duration: previousDuration, SignatureKey: nil, VersionKey: versionFilter(version), "$or": notRegenerating(time.Now())}
+	update := bson.M{
+		"$set":  bson.M{Transcript: transcript, Duration: duration},
+		"$push": bson.M{AudioSegments: bson.M{"$each": segments}},
+		"$inc":  bson.M{VersionKey: 1},
+	}
+	result, err := r.client.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to append recording: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return 0, r.staleWriteError(ctx, objectID, version, fmt.Errorf("report not found or changed while the recording was appended"))
+	}
+	return version + 1, nil
+}
+
+/* ReencryptAll seals every encrypted field still stored in plaintext or under an older data key with its provider's current data key, and returns how many reports were rewritten.
+It is safe to run while reports are being edited: a field is only rewritten if it still holds the value that was re-encrypted */
+func (r *reportsStore) ReencryptAll(ctx context.Context) (int, error) {
+	if r.fields == nil {
+		return 0, errors.New("report encryption is not configured")
+	}
+
+	cursor, err := r.client.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve reports: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	reencrypted := 0
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return reencrypted, fmt.Errorf("failed to decode report: %v", err)
+		}
+		objectID, ok := document[ID].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		providerID, _ := document[ProviderID].(string)
+		if providerID == "" {
+			continue
+		}
+
+		current, err := r.fields.keys.Current(ctx, providerID)
+		if err != nil {
+			return reencrypted, fmt.Errorf("failed to get data key: %v", err)
+		}
+		set, filter, err := r.fields.reencryptDocument(ctx, current, objectID.Hex(), document)
+		if err != nil {
+			return reencrypted, fmt.Errorf("failed to re-encrypt report %s: %v", objectID.Hex(), err)
+		}
+		if len(set) == 0 {
+			continue
+		}
+
+		filter[ID] = objectID
+		result, err := r.client.UpdateOne(ctx, filter, bson.M{"$set": set})
+		if err != nil {
+			return reencrypted, fmt.Errorf("failed to store re-encrypted report %s: %v", objectID.Hex(), err)
+		}
+		reencrypted += int(result.ModifiedCount)
+	}
+	if err := cursor.Err(); err != nil {
+		return reencrypted, fmt.Errorf("failed to iterate reports: %v", err)
+	}
+	return reencrypted, nil
+}
+
+func isContentSection(section string) bool {
+	for _, s := range TranscriptDerivedSections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
 func validateUpdateDFS(updateMap map[string]interface{}, reportMap map[string]interface{}) error {
 	// Loop through the keys in the updateMap and compare with reportMap
 	for key, updateVal := range updateMap {
@@ -441,21 +995,52 @@ func applyUpdatesToReport(updateMap map[string]interface{}, report *Report) erro
 	return nil
 }
 
-// UpdateReport handles updates for any field in the report after validation
-func (r *reportsStore) UpdateReport(ctx context.Context, reportId string, updates bson.D) error {
+// UpdateReport handles updates for any field in the report after validation. The report must still be at
+// version, and no generation may hold it; the new version is returned.
+func (r *reportsStore) UpdateReport(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error) {
 	// Validate that the reportId is not empty and there are updates
 	if reportId == "" {
-		return errors.New("reportId cannot be empty")
+		return 0, errors.New("reportId cannot be empty")
 	}
 
 	if len(updates) == 0 {
-		return errors.New("cannot apply zero updates to report")
+		return 0, errors.New("cannot apply zero updates to report")
+	}
+
+	objectId, updates, err := r.prepareUpdates(ctx, reportId, updates)
+	if err != nil {
+		return 0, err
+	}
+
+	// Perform the update in MongoDB; a signed or regenerating report is locked and a stale version is rejected
+	filter := bson.D{{Key: ID, Value: objectId}, {Key: SignatureKey, Value: nil}, {Key: VersionKey, Value: versionFilter(version)}, {Key: "$or", Value: notRegenerating(time.Now())}}
+	result, err := r.client.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updates}, {Key: "$inc", Value: bson.D{{Key: VersionKey, Value: 1}}}})
+	if err != nil {
+		return 0, fmt.Errorf("error updating the report field in MongoDB: %v", err)
+	}
+
+	// If no document was matched, return an error
+	if result.MatchedCount == 0 {
+		return 0, r.staleWriteError(ctx, objectId, version, fmt.Errorf("no document found with id %s", reportId))
+	}
+
+	return version + 1, nil
+}
+
+// prepareUpdates validates updates to a report and encrypts the fields stored encrypted, returning the
+// report's object ID and the updates to store.
+func (r *reportsStore) prepareUpdates(ctx context.Context, reportId string, updates bson.D) (primitive.ObjectID, bson.D, error) {
+	// The version and the regeneration lease only move through the guarded writes
+	for _, update := range updates {
+		if update.Key == VersionKey || update.Key == RegeneratingKey {
+			return primitive.NilObjectID, nil, fmt.Errorf("%s cannot be updated directly", update.Key)
+		}
 	}
 
 	// Convert BSON to map
 	updateMap, err := bsonDToStringMap(updates)
 	if err != nil {
-		return fmt.Errorf("error converting BSON to map: %v", err)
+		return primitive.NilObjectID, nil, fmt.Errorf("error converting BSON to map: %v", err)
 	}
 
 	// Initialize a report with default values for validation
@@ -494,48 +1079,48 @@ func (r *reportsStore) UpdateReport(ctx context.Context, reportId string, update
 
 	reportJSON, err := json.Marshal(&report)
 	if err != nil {
-		return fmt.Errorf("error marshalling report %v", err)
+		return primitive.NilObjectID, nil, fmt.Errorf("error marshalling report %v", err)
 	}
 
 	var reportMap map[string]interface{}
 	if err := json.Unmarshal(reportJSON, &reportMap); err != nil {
-		return fmt.Errorf("error marshalling report %v", err)
+		return primitive.NilObjectID, nil, fmt.Errorf("error marshalling report %v", err)
 	}
 
 	// Validate the fields of the updateMap
 	if err := validateUpdateDFS(updateMap, reportMap); err != nil {
-		return err
+		return primitive.NilObjectID, nil, err
 	}
 
 	// Apply updates to the report
 	if err := applyUpdatesToReport(updateMap, &report); err != nil {
-		return err
+		return primitive.NilObjectID, nil, err
 	}
 
 	// Validate the updated report
 	err = r.Validate(&report)
 	if err != nil {
-		return fmt.Errorf("error validating report: %v", err)
+		return primitive.NilObjectID, nil, fmt.Errorf("error validating report: %v", err)
 	}
 
 	// Convert reportId to ObjectId
 	objectId, err := primitive.ObjectIDFromHex(reportId)
 	if err != nil {
-		return fmt.Errorf("invalid ID format: %v", err)
-	}
-
-	// Perform the update in MongoDB
-	result, err := r.client.UpdateOne(ctx, bson.D{{Key: ID, Value: objectId}}, bson.D{{Key: "$set", Value: updates}})
-	if err != nil {
-		return fmt.Errorf("error updating the report field in MongoDB: %v", err)
+		return primitive.NilObjectID, nil, fmt.Errorf("invalid ID format: %v", err)
 	}
 
-	// If no document was matched, return an error
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("no document found with id %s", reportId)
+	// Encrypt the transcript, note content and summaries before they are stored
+	if r.fields != nil && needsEncryption(updates) {
+		providerID, err := r.providerID(ctx, objectId)
+		if err != nil {
+			return primitive.NilObjectID, nil, err
+		}
+		if updates, err = r.fields.encryptUpdates(ctx, providerID, reportId, updates); err != nil {
+			return primitive.NilObjectID, nil, fmt.Errorf("error encrypting report fields: %v", err)
+		}
 	}
 
-	return nil
+	return objectId, updates, nil
 }
 
 func (r *reportsStore) Validate(report *Report) error {
@@ -567,6 +1152,18 @@ func (r *reportsStore) Validate(report *Report) error {
 		return errors.New("condensedSummary cannot be empty")
 	}
 
+	if report.Mode != "" {
+		if _, err := ParseMode(report.Mode); err != nil {
+			return err
+		}
+	}
+
+	for _, language := range []string{report.NoteLanguage, report.PatientLanguage} {
+		if code, ok := transcriber.NormalizeLanguage(language); language != "" && (!ok || code != language) {
+			return fmt.Errorf("unsupported language: %s", language)
+		}
+	}
+
 	if report.LastVisitID != "" {
 		_, err := primitive.ObjectIDFromHex(report.LastVisitID)
 		if err != nil {
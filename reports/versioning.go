@@ -0,0 +1,176 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// VersionKey is the bson key of the report version.
+const VersionKey = "version"
+
+// InitialVersion is the version of a report when it is created, before its generated content is stored.
+const InitialVersion int64 = 0
+
+// RegeneratingKey is the bson key of the regeneration lease.
+const RegeneratingKey = "regenerating"
+
+// RegenerationLease is how long a generation holds a report. The lease of a generation that crashed expires
+// after it, so the report does not stay locked.
+const RegenerationLease = 15 * time.Minute
+
+// ErrReportRegenerating is returned when writing to a report while its content is being generated.
+var ErrReportRegenerating = errors.New("report is being regenerated")
+
+// ErrVersionConflict is returned when a write is based on a version of the report that is no longer current.
+var ErrVersionConflict = errors.New("report was changed by another write")
+
+// VersionConflictError is a rejected write based on a stale version of the report. Current is the version
+// the client has to reload before writing again.
+type VersionConflictError struct {
+	Current int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%v: current version is %d", ErrVersionConflict, e.Current)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// versionFilter matches a report at version. Reports stored before versioning have no version and are at
+// the initial one.
+func versionFilter(version int64) interface{} {
+	if version == InitialVersion {
+		return bson.M{"$in": bson.A{InitialVersion, nil}}
+	}
+	return version
+}
+
+// notRegenerating matches a report no generation holds, because it never took the lease, released it or let it expire.
+func notRegenerating(now time.Time) bson.A {
+	return bson.A{
+		bson.M{RegeneratingKey: nil},
+		bson.M{RegeneratingKey: bson.M{"$lte": primitive.NewDateTimeFromTime(now)}},
+	}
+}
+
+// IsRegenerating reports whether a generation holds the report at now.
+func (r *Report) IsRegenerating(now time.Time) bool {
+	return r.Regenerating != nil && r.Regenerating.Time().After(now)
+}
+
+// writeState is what decides whether a conditional write on a report can apply.
+type writeState struct {
+	Signature    *Signature
+	Version      int64
+	Regenerating *primitive.DateTime
+}
+
+func (r *reportsStore) writeState(ctx context.Context, objectID primitive.ObjectID) (writeState, error) {
+	var state writeState
+	opts := options.FindOne().SetProjection(bson.M{SignatureKey: 1, VersionKey: 1, RegeneratingKey: 1})
+	if err := r.client.FindOne(ctx, bson.M{ID: objectID}, opts).Decode(&state); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return writeState{}, fmt.Errorf("report not found")
+		}
+		return writeState{}, fmt.Errorf("failed to retrieve report: %v", err)
+	}
+	return state, nil
+}
+
+// staleWriteError explains why a write guarded by the report's version matched nothing: the report is missing,
+// it is signed, a generation holds it, it moved past version, or otherwise the remaining condition of the write
+// no longer held, reported as fallback.
+func (r *reportsStore) staleWriteError(ctx context.Context, objectID primitive.ObjectID, version int64, fallback error) error {
+	state, err := r.writeState(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if state.Signature != nil {
+		return ErrReportSigned
+	}
+	if state.Regenerating != nil && state.Regenerating.Time().After(time.Now()) {
+		return ErrReportRegenerating
+	}
+	if state.Version != version {
+		return &VersionConflictError{Current: state.Version}
+	}
+	return fallback
+}
+
+// LockForRegeneration stores updates on a report still at version and takes the regeneration lease on it. Until
+// CompleteRegeneration or ReleaseRegeneration is called at the returned version, or the lease expires, every
+// other write to the report's content or transcript is rejected with ErrReportRegenerating. updates may be empty.
+func (r *reportsStore) LockForRegeneration(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error) {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID format: %v", err)
+	}
+	if len(updates) > 0 {
+		if objectID, updates, err = r.prepareUpdates(ctx, reportId, updates); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now()
+	lease := bson.E{Key: RegeneratingKey, Value: primitive.NewDateTimeFromTime(now.Add(RegenerationLease))}
+	filter := bson.D{{Key: ID, Value: objectID}, {Key: SignatureKey, Value: nil}, {Key: VersionKey, Value: versionFilter(version)}, {Key: "$or", Value: notRegenerating(now)}}
+	update := bson.D{{Key: "$set", Value: append(updates, lease)}, {Key: "$inc", Value: bson.D{{Key: VersionKey, Value: 1}}}}
+	result, err := r.client.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock report for regeneration: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return 0, r.staleWriteError(ctx, objectID, version, fmt.Errorf("no document found with id %s", reportId))
+	}
+	return version + 1, nil
+}
+
+// CompleteRegeneration stores the generated updates on a report locked at version and releases the lease. It
+// only fails when the lease expired and another write changed the report in the meantime.
+func (r *reportsStore) CompleteRegeneration(ctx context.Context, reportId string, version int64, updates bson.D) (int64, error) {
+	if len(updates) == 0 {
+		return 0, errors.New("cannot apply zero updates to report")
+	}
+	objectID, updates, err := r.prepareUpdates(ctx, reportId, updates)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.D{{Key: ID, Value: objectID}, {Key: SignatureKey, Value: nil}, {Key: VersionKey, Value: version}}
+	update := bson.D{
+		{Key: "$set", Value: updates},
+		{Key: "$unset", Value: bson.D{{Key: RegeneratingKey, Value: ""}}},
+		{Key: "$inc", Value: bson.D{{Key: VersionKey, Value: 1}}},
+	}
+	result, err := r.client.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store regenerated report: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return 0, r.staleWriteError(ctx, objectID, version, fmt.Errorf("no document found with id %s", reportId))
+	}
+	return version + 1, nil
+}
+
+// ReleaseRegeneration gives up the lease on a report locked at version without storing anything, after its
+// generation failed. A report that moved on since, once the lease expired, is left as it is.
+func (r *reportsStore) ReleaseRegeneration(ctx context.Context, reportId string, version int64) error {
+	objectID, err := primitive.ObjectIDFromHex(reportId)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+	filter := bson.M{ID: objectID, VersionKey: version}
+	if _, err := r.client.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{RegeneratingKey: ""}}); err != nil {
+		return fmt.Errorf("failed to release report regeneration: %v", err)
+	}
+	return nil
+}
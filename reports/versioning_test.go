@@ -0,0 +1,48 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestVersionFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int64
+		expected interface{}
+	}{
+		{name: "initial version matches reports stored before versioning", version: InitialVersion, expected: bson.M{"$in": bson.A{InitialVersion, nil}}},
+		{name: "later version matches exactly", version: 3, expected: int64(3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, versionFilter(tt.version))
+		})
+	}
+}
+
+func TestVersionConflictError(t *testing.T) {
+	err := fmt.Errorf("RegenerateReport: error updating report: %w", &VersionConflictError{Current: 4})
+
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	var conflict *VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(4), conflict.Current)
+	assert.Contains(t, err.Error(), "current version is 4")
+	assert.False(t, errors.Is(err, ErrReportSigned))
+}
+
+func TestUpdateReportRejectsVersionUpdates(t *testing.T) {
+	store := &reportsStore{}
+	_, err := store.UpdateReport(context.Background(), "507f1f77bcf86cd799439011", 2, bson.D{
+		{Key: Name, Value: "Follow-up"},
+		{Key: VersionKey, Value: int64(10)},
+	})
+	assert.EqualError(t, err, "version cannot be updated directly")
+}
@@ -2,6 +2,7 @@ package reportsTokenUsage
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -25,3 +26,11 @@ func (m *MockTokenUsageStore) GetByReportID(ctx context.Context, reportID primit
 	args := m.Called(ctx, reportID)
 	return args.Get(0).(TokenUsageEntry), args.Error(1)
 }
+
+func (m *MockTokenUsageStore) DeleteExpired(ctx context.Context, providerID string, before time.Time, held []primitive.ObjectID, limit int64) ([]TokenUsageEntry, error) {
+	args := m.Called(ctx, providerID, before, held, limit)
+	if entries := args.Get(0); entries != nil {
+		return entries.([]TokenUsageEntry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
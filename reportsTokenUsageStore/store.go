@@ -25,6 +25,7 @@ type TokenUsageStore interface {
 	Insert(ctx context.Context, entry TokenUsageEntry) error
 	UpdateSectionTokens(ctx context.Context, reportId primitive.ObjectID, section string, tokens int) error
 	GetByReportID(ctx context.Context, reportId primitive.ObjectID) (TokenUsageEntry, error)
+	DeleteExpired(ctx context.Context, providerID string, before time.Time, held []primitive.ObjectID, limit int64) ([]TokenUsageEntry, error)
 }
 
 type tokenUsageStore struct {
@@ -93,3 +94,40 @@ func (s *tokenUsageStore) GetByReportID(ctx context.Context, reportId primitive.
 
 	return result, nil
 }
+
+// DeleteExpired deletes up to limit of the provider's entries recorded before the cutoff, except those of
+// held reports, and returns the entries this call deleted. An entry another instance deleted first is not returned.
+func (s *tokenUsageStore) DeleteExpired(ctx context.Context, providerID string, before time.Time, held []primitive.ObjectID, limit int64) ([]TokenUsageEntry, error) {
+	if providerID == "" {
+		return nil, errors.New("providerId cannot be empty")
+	}
+
+	filter := bson.M{"providerId": providerID, "timestamp": bson.M{"$lt": primitive.NewDateTimeFromTime(before)}}
+	if len(held) > 0 {
+		filter["reportId"] = bson.M{"$nin": held}
+	}
+	opts := options.Find()
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired token usage: %v", err)
+	}
+	var entries []TokenUsageEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode expired token usage: %v", err)
+	}
+
+	var deleted []TokenUsageEntry
+	for _, entry := range entries {
+		result, err := s.collection.DeleteOne(ctx, bson.M{"reportId": entry.ReportID, "providerId": providerID, "timestamp": entry.Timestamp})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete token usage of report %s: %v", entry.ReportID.Hex(), err)
+		}
+		if result.DeletedCount > 0 {
+			deleted = append(deleted, entry)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,219 @@
+package retention
+
+import (
+	"Medscribe/audioStore"
+	purgeRecords "Medscribe/purgeRecordStore"
+	"Medscribe/reports"
+	reportsTokenUsage "Medscribe/reportsTokenUsageStore"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	transcriptRevisions "Medscribe/transcriptRevisionStore"
+	"Medscribe/user"
+	"Medscribe/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// purgeBatchSize caps how many reports or token usage entries are looked up per query.
+const purgeBatchSize = 100
+
+type purger struct {
+	reports    reports.Reports
+	revisions  transcriptRevisions.TranscriptRevisionStore
+	sections   sectionRevisions.SectionRevisionStore
+	tokenUsage reportsTokenUsage.TokenUsageStore
+	audio      audioStore.AudioStore
+	users      user.UserStore
+	records    purgeRecords.PurgeRecordStore
+	defaults   user.RetentionPolicy
+}
+
+// NewPurger enforces retention policies: expired recordings and token usage entries are deleted, and expired
+// transcripts and notes are cleared in place so the report itself remains. Each provider's own policy
+// overrides defaults period by period. Reports under a legal hold are skipped, and every deletion is
+// recorded in records. Every purge is conditional, so several instances can run the purger at once.
+func NewPurger(
+	reportsStore reports.Reports,
+	revisions transcriptRevisions.TranscriptRevisionStore,
+	sections sectionRevisions.SectionRevisionStore,
+	tokenUsage reportsTokenUsage.TokenUsageStore,
+	audio audioStore.AudioStore,
+	users user.UserStore,
+	records purgeRecords.PurgeRecordStore,
+	defaults user.RetentionPolicy,
+) utils.Purger {
+	return &purger{
+		reports:    reportsStore,
+		revisions:  revisions,
+		sections:   sections,
+		tokenUsage: tokenUsage,
+		audio:      audio,
+		users:      users,
+		records:    records,
+		defaults:   defaults,
+	}
+}
+
+// PurgeExpired purges everything whose retention period ended before now and returns how many items were
+// purged. A failure for one provider does not stop the others from being purged.
+func (p *purger) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	purged, err := p.purgeAudio(ctx, now)
+	if err != nil {
+		return purged, err
+	}
+
+	policies, err := p.users.ListRetentionPolicies(ctx)
+	if err != nil {
+		return purged, fmt.Errorf("PurgeExpired: %w", err)
+	}
+	providerIDs, err := p.reports.ProviderIDs(ctx)
+	if err != nil {
+		return purged, fmt.Errorf("PurgeExpired: %w", err)
+	}
+
+	var errs []error
+	for _, providerID := range providerIDs {
+		count, err := p.purgeProvider(ctx, providerID, policies[providerID].WithDefaults(p.defaults), now)
+		purged += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("PurgeExpired: provider %s: %w", providerID, err))
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+// purgeAudio records the recordings whose retention period, fixed when they were saved, has ended.
+func (p *purger) purgeAudio(ctx context.Context, now time.Time) (int, error) {
+	blobs, purgeErr := p.audio.PurgeExpiredRecordings(ctx, now)
+	for i, blob := range blobs {
+		err := p.records.Insert(ctx, purgeRecords.PurgeRecord{
+			ReportID:   blob.ReportID,
+			ProviderID: blob.ProviderID,
+			Content:    purgeRecords.ContentAudio,
+			ResourceID: blob.ID.Hex(),
+			PurgedAt:   primitive.NewDateTimeFromTime(now),
+		})
+		if err != nil {
+			return i, fmt.Errorf("purgeAudio: %w", err)
+		}
+	}
+	if purgeErr != nil {
+		return len(blobs), fmt.Errorf("purgeAudio: %w", purgeErr)
+	}
+	return len(blobs), nil
+}
+
+func (p *purger) purgeProvider(ctx context.Context, providerID string, policy user.RetentionPolicy, now time.Time) (int, error) {
+	transcripts, err := p.purgeReports(ctx, providerID, reports.PurgeTranscript, policy.TranscriptDays, now)
+	if err != nil {
+		return transcripts, err
+	}
+	notes, err := p.purgeReports(ctx, providerID, reports.PurgeNotes, policy.NoteDays, now)
+	if err != nil {
+		return transcripts + notes, err
+	}
+	tokenUsage, err := p.purgeTokenUsage(ctx, providerID, policy.TokenUsageDays, now)
+	return transcripts + notes + tokenUsage, err
+}
+
+// purgeReports clears the content of the provider's reports older than days. The revision history of a
+// transcript or of the note sections is deleted only once this call purged the content itself, so a report
+// put under a legal hold since it was listed keeps its history.
+func (p *purger) purgeReports(ctx context.Context, providerID string, content reports.PurgeContent, days int, now time.Time) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	before := now.AddDate(0, 0, -days)
+	recordContent := purgeRecords.ContentNotes
+	if content == reports.PurgeTranscript {
+		recordContent = purgeRecords.ContentTranscript
+	}
+
+	purged := 0
+	for {
+		reportIDs, err := p.reports.ExpiredReportIDs(ctx, providerID, content, before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		batch := 0
+		for _, reportID := range reportIDs {
+			ok, err := p.reports.Purge(ctx, reportID, content, before, now)
+			if err != nil {
+				return purged, err
+			}
+			if !ok {
+				// Another instance purged it first, or it was put under a legal hold since it was listed.
+				continue
+			}
+			err = p.records.Insert(ctx, purgeRecords.PurgeRecord{
+				ReportID:      reportID,
+				ProviderID:    providerID,
+				Content:       recordContent,
+				RetentionDays: days,
+				PurgedAt:      primitive.NewDateTimeFromTime(now),
+			})
+			if err != nil {
+				return purged, err
+			}
+			purged++
+			batch++
+			if err := p.purgeRevisions(ctx, reportID, content); err != nil {
+				return purged, err
+			}
+		}
+		if len(reportIDs) < purgeBatchSize || batch == 0 {
+			return purged, nil
+		}
+	}
+}
+
+// purgeRevisions deletes the revision history of the purged content of a report.
+func (p *purger) purgeRevisions(ctx context.Context, reportID primitive.ObjectID, content reports.PurgeContent) error {
+	switch content {
+	case reports.PurgeTranscript:
+		return p.revisions.DeleteByReportID(ctx, reportID)
+	case reports.PurgeNotes:
+		return p.sections.DeleteByReportID(ctx, reportID)
+	}
+	return nil
+}
+
+// purgeTokenUsage deletes the provider's token usage entries older than days, except those of held reports.
+func (p *purger) purgeTokenUsage(ctx context.Context, providerID string, days int, now time.Time) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	before := now.AddDate(0, 0, -days)
+	held, err := p.reports.HeldReportIDs(ctx, providerID)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for {
+		entries, deleteErr := p.tokenUsage.DeleteExpired(ctx, providerID, before, held, purgeBatchSize)
+		for _, entry := range entries {
+			err := p.records.Insert(ctx, purgeRecords.PurgeRecord{
+				ReportID:      entry.ReportID,
+				ProviderID:    providerID,
+				Content:       purgeRecords.ContentTokenUsage,
+				RetentionDays: days,
+				PurgedAt:      primitive.NewDateTimeFromTime(now),
+			})
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if deleteErr != nil {
+			return purged, deleteErr
+		}
+		if len(entries) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package retention
+
+import (
+	"Medscribe/audioStore"
+	purgeRecords "Medscribe/purgeRecordStore"
+	"Medscribe/reports"
+	reportsTokenUsage "Medscribe/reportsTokenUsageStore"
+	sectionRevisions "Medscribe/sectionRevisionStore"
+	transcriptRevisions "Medscribe/transcriptRevisionStore"
+	"Medscribe/user"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type purgerMocks struct {
+	reports    *reports.MockReportsStore
+	revisions  *transcriptRevisions.MockTranscriptRevisionStore
+	sections   *sectionRevisions.MockSectionRevisionStore
+	tokenUsage *reportsTokenUsage.MockTokenUsageStore
+	audio      *audioStore.MockAudioStore
+	users      *user.MockUserStore
+	records    *purgeRecords.MockPurgeRecordStore
+}
+
+func newTestPurger(defaults user.RetentionPolicy) (*purger, purgerMocks) {
+	m := purgerMocks{
+		reports:    new(reports.MockReportsStore),
+		revisions:  new(transcriptRevisions.MockTranscriptRevisionStore),
+		sections:   new(sectionRevisions.MockSectionRevisionStore),
+		tokenUsage: new(reportsTokenUsage.MockTokenUsageStore),
+		audio:      new(audioStore.MockAudioStore),
+		users:      new(user.MockUserStore),
+		records:    new(purgeRecords.MockPurgeRecordStore),
+	}
+	p := NewPurger(m.reports, m.revisions, m.sections, m.tokenUsage, m.audio, m.users, m.records, defaults).(*purger)
+	return p, m
+}
+
+func recordOf(content string, reportID primitive.ObjectID) interface{} {
+	return mock.MatchedBy(func(record purgeRecords.PurgeRecord) bool {
+		return record.Content == content && record.ReportID == reportID
+	})
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	p, m := newTestPurger(user.RetentionPolicy{TranscriptDays: 30, TokenUsageDays: 365})
+
+	blob := audioStore.AudioBlob{ID: primitive.NewObjectID(), ReportID: primitive.NewObjectID(), ProviderID: "provider-a"}
+	m.audio.On("PurgeExpiredRecordings", ctx, now).Return([]audioStore.AudioBlob{blob}, nil)
+	m.records.On("Insert", ctx, mock.MatchedBy(func(record purgeRecords.PurgeRecord) bool {
+		return record.Content == purgeRecords.ContentAudio && record.ResourceID == blob.ID.Hex()
+	})).Return(nil).Once()
+
+	// provider-b keeps transcripts for a year and notes for ten days instead of the defaults.
+	m.users.On("ListRetentionPolicies", ctx).Return(map[string]user.RetentionPolicy{
+		"provider-b": {TranscriptDays: 365, NoteDays: 10},
+	}, nil)
+	m.reports.On("ProviderIDs", ctx).Return([]string{"provider-a", "provider-b"}, nil)
+
+	// provider-a: two expired transcripts, one of which another instance purges first or is put under a hold.
+	purgedID, racedID := primitive.NewObjectID(), primitive.NewObjectID()
+	transcriptCutoffA := now.AddDate(0, 0, -30)
+	m.reports.On("ExpiredReportIDs", ctx, "provider-a", reports.PurgeTranscript, transcriptCutoffA, int64(purgeBatchSize)).
+		Return([]primitive.ObjectID{purgedID, racedID}, nil)
+	m.revisions.On("DeleteByReportID", ctx, purgedID).Return(nil).Once()
+	m.reports.On("Purge", ctx, purgedID, reports.PurgeTranscript, transcriptCutoffA, now).Return(true, nil)
+	m.reports.On("Purge", ctx, racedID, reports.PurgeTranscript, transcriptCutoffA, now).Return(false, nil)
+	m.records.On("Insert", ctx, recordOf(purgeRecords.ContentTranscript, purgedID)).Return(nil).Once()
+
+	// provider-a: token usage of held reports is kept.
+	held := []primitive.ObjectID{primitive.NewObjectID()}
+	usageID := primitive.NewObjectID()
+	m.reports.On("HeldReportIDs", ctx, "provider-a").Return(held, nil)
+	m.tokenUsage.On("DeleteExpired", ctx, "provider-a", now.AddDate(0, 0, -365), held, int64(purgeBatchSize)).
+		Return([]reportsTokenUsage.TokenUsageEntry{{ReportID: usageID, ProviderID: "provider-a"}}, nil)
+	m.records.On("Insert", ctx, recordOf(purgeRecords.ContentTokenUsage, usageID)).Return(nil).Once()
+
+	// provider-b: nothing expired under its own transcript period, one expired note.
+	noteID := primitive.NewObjectID()
+	m.reports.On("ExpiredReportIDs", ctx, "provider-b", reports.PurgeTranscript, now.AddDate(0, 0, -365), int64(purgeBatchSize)).
+		Return([]primitive.ObjectID{}, nil)
+	m.reports.On("ExpiredReportIDs", ctx, "provider-b", reports.PurgeNotes, now.AddDate(0, 0, -10), int64(purgeBatchSize)).
+		Return([]primitive.ObjectID{noteID}, nil)
+	m.sections.On("DeleteByReportID", ctx, noteID).Return(nil).Once()
+	m.reports.On("Purge", ctx, noteID, reports.PurgeNotes, now.AddDate(0, 0, -10), now).Return(true, nil)
+	m.records.On("Insert", ctx, recordOf(purgeRecords.ContentNotes, noteID)).Return(nil).Once()
+	m.reports.On("HeldReportIDs", ctx, "provider-b").Return([]primitive.ObjectID{}, nil)
+	m.tokenUsage.On("DeleteExpired", ctx, "provider-b", now.AddDate(0, 0, -365), []primitive.ObjectID{}, int64(purgeBatchSize)).
+		Return([]reportsTokenUsage.TokenUsageEntry{}, nil)
+
+	purged, err := p.PurgeExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 4, purged)
+
+	m.reports.AssertNotCalled(t, "ExpiredReportIDs", ctx, "provider-a", reports.PurgeNotes, mock.Anything, mock.Anything)
+	m.revisions.AssertNotCalled(t, "DeleteByReportID", ctx, noteID)
+	m.revisions.AssertNotCalled(t, "DeleteByReportID", ctx, racedID)
+	m.revisions.AssertExpectations(t)
+	m.sections.AssertNotCalled(t, "DeleteByReportID", ctx, purgedID)
+	m.sections.AssertExpectations(t)
+	m.records.AssertExpectations(t)
+	m.reports.AssertExpectations(t)
+	m.tokenUsage.AssertExpectations(t)
+}
+
+func TestPurgeExpiredContinuesAfterProviderFailure(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	p, m := newTestPurger(user.RetentionPolicy{NoteDays: 30})
+
+	m.audio.On("PurgeExpiredRecordings", ctx, now).Return([]audioStore.AudioBlob{}, nil)
+	m.users.On("ListRetentionPolicies", ctx).Return(map[string]user.RetentionPolicy{}, nil)
+	m.reports.On("ProviderIDs", ctx).Return([]string{"provider-a", "provider-b"}, nil)
+
+	cutoff := now.AddDate(0, 0, -30)
+	m.reports.On("ExpiredReportIDs", ctx, "provider-a", reports.PurgeNotes, cutoff, int64(purgeBatchSize)).
+		Return(nil, errors.New("connection reset"))
+	noteID := primitive.NewObjectID()
+	m.reports.On("ExpiredReportIDs", ctx, "provider-b", reports.PurgeNotes, cutoff, int64(purgeBatchSize)).
+		Return([]primitive.ObjectID{noteID}, nil)
+	m.sections.On("DeleteByReportID", ctx, noteID).Return(nil).Once()
+	m.reports.On("Purge", ctx, noteID, reports.PurgeNotes, cutoff, now).Return(true, nil)
+	m.records.On("Insert", ctx, recordOf(purgeRecords.ContentNotes, noteID)).Return(nil).Once()
+
+	purged, err := p.PurgeExpired(ctx, now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "provider-a")
+	assert.Equal(t, 1, purged)
+	m.records.AssertExpectations(t)
+}
+
+func TestRetentionPolicyWithDefaults(t *testing.T) {
+	audioDays, noAudio := 30, 0
+	defaults := user.RetentionPolicy{AudioDays: &audioDays, TranscriptDays: 365, NoteDays: 0, TokenUsageDays: 90}
+
+	tests := []struct {
+		name   string
+		policy user.RetentionPolicy
+		want   user.RetentionPolicy
+	}{
+		{name: "no overrides", want: defaults},
+		{
+			name:   "overrides replace defaults period by period",
+			policy: user.RetentionPolicy{TranscriptDays: 30, NoteDays: 3650},
+			want:   user.RetentionPolicy{AudioDays: &audioDays, TranscriptDays: 30, NoteDays: 3650, TokenUsageDays: 90},
+		},
+		{
+			name:   "zero audio retention is kept",
+			policy: user.RetentionPolicy{AudioDays: &noAudio},
+			want:   user.RetentionPolicy{AudioDays: &noAudio, TranscriptDays: 365, NoteDays: 0, TokenUsageDays: 90},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.WithDefaults(defaults))
+		})
+	}
+
+	assert.NoError(t, user.RetentionPolicy{TranscriptDays: 30}.Validate())
+	assert.Error(t, user.RetentionPolicy{NoteDays: -1}.Validate())
+	tooLong := user.MaxAudioRetentionDays + 1
+	assert.Error(t, user.RetentionPolicy{AudioDays: &tooLong}.Validate())
+	assert.NoError(t, user.RetentionPolicy{AudioDays: &noAudio}.Validate())
+}
@@ -0,0 +1,241 @@
+package revisions
+
+import (
+	"Medscribe/reports"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxInsertAttempts bounds the retries when a concurrent change takes the same revision number first.
+const maxInsertAttempts = 10
+
+// Kind describes a kind of revision history and the field of its revisions that is encrypted.
+type Kind struct {
+	// Name names the revisions in errors, e.g. "transcript revision".
+	Name string
+	// Namespace and Field bind the encrypted Field of a revision to the revision itself, so its ciphertext
+	// does not decrypt when it is moved to another revision.
+	Namespace string
+	Field     string
+	// Scope lists the fields besides reportId that separate the histories of a report, e.g. its section.
+	Scope []string
+}
+
+// Store keeps the revision histories of one kind in a collection. Revisions are numbered from 1 within
+// their history, and their encrypted field is sealed with cipher like the report's own content, or stored
+// as it is when cipher is nil.
+type Store struct {
+	collection *mongo.Collection
+	cipher     reports.FieldCipher
+	kind       Kind
+}
+
+// New creates a store for the revisions of kind kept in collection.
+func New(collection *mongo.Collection, cipher reports.FieldCipher, kind Kind) *Store {
+	return &Store{collection: collection, cipher: cipher, kind: kind}
+}
+
+// EnsureIndex creates the unique index that keeps revision numbers distinct when a history is changed
+// concurrently.
+func (s *Store) EnsureIndex(ctx context.Context) error {
+	keys := bson.D{{Key: "reportId", Value: 1}}
+	for _, key := range s.kind.Scope {
+		keys = append(keys, bson.E{Key: key, Value: 1})
+	}
+	keys = append(keys, bson.E{Key: "revision", Value: 1})
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: options.Index().SetUnique(true)})
+	if err != nil {
+		return fmt.Errorf("failed to create %s index: %v", s.kind.Name, err)
+	}
+	return nil
+}
+
+// boundField binds the encrypted field of a revision to the revision itself.
+func (s *Store) boundField(id primitive.ObjectID) string {
+	return s.kind.Namespace + "." + id.Hex() + "." + s.kind.Field
+}
+
+// Seal encrypts the value of the encrypted field of the revision with the given ID.
+func (s *Store) Seal(ctx context.Context, id, reportID primitive.ObjectID, providerID, value string) (string, error) {
+	if s.cipher == nil {
+		return value, nil
+	}
+	sealed, err := s.cipher.Encrypt(ctx, providerID, reportID.Hex(), s.boundField(id), value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %v", s.kind.Name, err)
+	}
+	return sealed, nil
+}
+
+// Open decrypts the stored value of the encrypted field of the revision with the given ID.
+func (s *Store) Open(ctx context.Context, id, reportID primitive.ObjectID, providerID, value string) (string, error) {
+	if s.cipher == nil {
+		return value, nil
+	}
+	opened, err := s.cipher.Decrypt(ctx, providerID, reportID.Hex(), s.boundField(id), value)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %v", s.kind.Name, err)
+	}
+	return opened, nil
+}
+
+// Insert stores a revision as the next one of the history selected by history, numbering it after the
+// latest revision. document returns the sealed revision numbered revision; when a concurrent change takes
+// the number first, the revision is numbered again. It returns the number the revision was stored with.
+func (s *Store) Insert(ctx context.Context, history bson.M, document func(revision int) interface{}) (int, error) {
+	for attempt := 0; attempt < maxInsertAttempts; attempt++ {
+		latest, err := s.latestRevision(ctx, history)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = s.collection.InsertOne(ctx, document(latest+1))
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert %s: %v", s.kind.Name, err)
+		}
+		return latest + 1, nil
+	}
+	return 0, fmt.Errorf("failed to insert %s: revision number still taken after %d attempts", s.kind.Name, maxInsertAttempts)
+}
+
+// InsertFirst stores a revision as the first one of the history selected by history, unless the history
+// already has revisions. document returns the sealed revision numbered revision. The unique index decides
+// between concurrent callers, so only one of them stores its revision. It reports whether the revision was
+// stored.
+func (s *Store) InsertFirst(ctx context.Context, history bson.M, document func(revision int) interface{}) (bool, error) {
+	latest, err := s.latestRevision(ctx, history)
+	if err != nil {
+		return false, err
+	}
+	if latest > 0 {
+		return false, nil
+	}
+
+	_, err = s.collection.InsertOne(ctx, document(1))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to insert %s: %v", s.kind.Name, err)
+	}
+	return true, nil
+}
+
+// latestRevision returns the highest revision number of a history, or 0 when it is empty.
+func (s *Store) latestRevision(ctx context.Context, history bson.M) (int, error) {
+	opts := options.FindOne().SetSort(bson.M{"revision": -1}).SetProjection(bson.M{"revision": 1})
+	var latest struct {
+		Revision int `bson:"revision"`
+	}
+	err := s.collection.FindOne(ctx, history, opts).Decode(&latest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read latest %s: %v", s.kind.Name, err)
+	}
+	return latest.Revision, nil
+}
+
+// FindOne decodes the revision matching filter into result. It returns mongo.ErrNoDocuments when there
+// is none.
+func (s *Store) FindOne(ctx context.Context, filter bson.M, result interface{}) error {
+	err := s.collection.FindOne(ctx, filter).Decode(result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s: %v", s.kind.Name, err)
+	}
+	return nil
+}
+
+// Find decodes the revisions matching filter into results, a pointer to a slice, oldest first.
+func (s *Store) Find(ctx context.Context, filter bson.M, results interface{}) error {
+	opts := options.Find().SetSort(bson.M{"revision": 1})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %ss: %v", s.kind.Name, err)
+	}
+	if err := cursor.All(ctx, results); err != nil {
+		return fmt.Errorf("failed to decode %ss: %v", s.kind.Name, err)
+	}
+	return nil
+}
+
+// Discard removes a revision whose change was rejected, so a history only holds changes that were
+// actually stored.
+func (s *Store) Discard(ctx context.Context, id primitive.ObjectID) error {
+	if id.IsZero() {
+		return errors.New("invalid revision id")
+	}
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to discard %s: %v", s.kind.Name, err)
+	}
+	return nil
+}
+
+// DeleteByReportID removes every revision history of a report.
+func (s *Store) DeleteByReportID(ctx context.Context, reportId primitive.ObjectID) error {
+	if reportId.IsZero() {
+		return errors.New("invalid reportId")
+	}
+	if _, err := s.collection.DeleteMany(ctx, bson.M{"reportId": reportId}); err != nil {
+		return fmt.Errorf("failed to delete %ss: %v", s.kind.Name, err)
+	}
+	return nil
+}
+
+// ReencryptAll rewrites the encrypted field of every revision not yet sealed under its provider's current
+// data key, and returns how many were rewritten. A revision is only rewritten if it still holds the value
+// that was re-encrypted.
+func (s *Store) ReencryptAll(ctx context.Context) (int, error) {
+	if s.cipher == nil {
+		return 0, errors.New("report encryption is not configured")
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve %ss: %v", s.kind.Name, err)
+	}
+	defer cursor.Close(ctx)
+
+	reencrypted := 0
+	for cursor.Next(ctx) {
+		var revision struct {
+			ID         primitive.ObjectID `bson:"_id"`
+			ReportID   primitive.ObjectID `bson:"reportId"`
+			ProviderID string             `bson:"providerId"`
+		}
+		if err := cursor.Decode(&revision); err != nil {
+			return reencrypted, fmt.Errorf("failed to decode %s: %v", s.kind.Name, err)
+		}
+		value, _ := cursor.Current.Lookup(s.kind.Field).StringValueOK()
+		sealed, changed, err := s.cipher.Reencrypt(ctx, revision.ProviderID, revision.ReportID.Hex(), s.boundField(revision.ID), value)
+		if err != nil {
+			return reencrypted, fmt.Errorf("failed to re-encrypt %s %s: %v", s.kind.Name, revision.ID.Hex(), err)
+		}
+		if !changed {
+			continue
+		}
+		filter := bson.M{"_id": revision.ID, s.kind.Field: value}
+		result, err := s.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{s.kind.Field: sealed}})
+		if err != nil {
+			return reencrypted, fmt.Errorf("failed to store re-encrypted %s %s: %v", s.kind.Name, revision.ID.Hex(), err)
+		}
+		reencrypted += int(result.ModifiedCount)
+	}
+	if err := cursor.Err(); err != nil {
+		return reencrypted, fmt.Errorf("failed to iterate %ss: %v", s.kind.Name, err)
+	}
+	return reencrypted, nil
+}
@@ -0,0 +1,242 @@
+package revisions
+
+import (
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
+	"Medscribe/reports"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+var testKind = Kind{Name: "note revision", Namespace: "noteRevisions", Field: "text", Scope: []string{"note"}}
+
+type noteRevision struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	ReportID   primitive.ObjectID `bson:"reportId"`
+	ProviderID string             `bson:"providerId"`
+	Note       string             `bson:"note"`
+	Revision   int                `bson:"revision"`
+	Text       string             `bson:"text"`
+}
+
+func latestResponse(mt *mtest.T, reportID primitive.ObjectID, revision int) bson.D {
+	ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+	if revision == 0 {
+		return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch)
+	}
+	return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "reportId", Value: reportID},
+		{Key: "note", Value: "plan"},
+		{Key: "revision", Value: revision},
+	})
+}
+
+func duplicateKeyResponse() bson.D {
+	return mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"})
+}
+
+func insertNote(store *Store, reportID primitive.ObjectID) (int, error) {
+	revision := noteRevision{ID: primitive.NewObjectID(), ReportID: reportID, ProviderID: "provider", Note: "plan", Text: "rest"}
+	return store.Insert(context.Background(), bson.M{"reportId": reportID, "note": "plan"}, func(number int) interface{} {
+		revision.Revision = number
+		return revision
+	})
+}
+
+func TestInsertNumbersAfterLatestRevision(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("first revision", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateSuccessResponse())
+
+		number, err := insertNote(store, reportID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, number)
+	})
+
+	mt.Run("after existing history", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(latestResponse(mt, reportID, 4), mtest.CreateSuccessResponse())
+
+		number, err := insertNote(store, reportID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, number)
+
+		filter := mt.GetAllStartedEvents()[0].Command.Lookup("filter").Document()
+		assert.Equal(t, "plan", filter.Lookup("note").StringValue())
+		inserted := mt.GetAllStartedEvents()[1].Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, int32(5), inserted.Lookup("revision").Int32())
+	})
+}
+
+func TestInsertRetriesWhenRevisionIsTaken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("renumbers after a concurrent insert", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(
+			latestResponse(mt, reportID, 2), duplicateKeyResponse(),
+			latestResponse(mt, reportID, 3), mtest.CreateSuccessResponse(),
+		)
+
+		number, err := insertNote(store, reportID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, number)
+	})
+
+	mt.Run("gives up after bounded attempts", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		for attempt := 0; attempt < maxInsertAttempts; attempt++ {
+			mt.AddMockResponses(latestResponse(mt, reportID, 1), duplicateKeyResponse())
+		}
+
+		_, err := insertNote(store, reportID)
+		assert.ErrorContains(t, err, "still taken")
+	})
+
+	mt.Run("does not retry other errors", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(latestResponse(mt, reportID, 1), mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}))
+
+		_, err := insertNote(store, reportID)
+		require.Error(t, err)
+		assert.False(t, mongo.IsDuplicateKeyError(err))
+		assert.ErrorContains(t, err, "failed to insert note revision")
+	})
+}
+
+func TestInsertFirstStoresOnlyOneFirstRevision(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+	insertFirst := func(store *Store) (bool, error) {
+		revision := noteRevision{ID: primitive.NewObjectID(), ReportID: reportID, ProviderID: "provider", Note: "plan", Text: "rest"}
+		return store.InsertFirst(context.Background(), bson.M{"reportId": reportID, "note": "plan"}, func(number int) interface{} {
+			revision.Revision = number
+			return revision
+		})
+	}
+
+	mt.Run("stores revision 1 of an empty history", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateSuccessResponse())
+
+		stored, err := insertFirst(store)
+		require.NoError(t, err)
+		assert.True(t, stored)
+		inserted := mt.GetAllStartedEvents()[1].Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, int32(1), inserted.Lookup("revision").Int32())
+	})
+
+	mt.Run("skips a history that already has revisions", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(latestResponse(mt, reportID, 3))
+
+		stored, err := insertFirst(store)
+		require.NoError(t, err)
+		assert.False(t, stored)
+		assert.Len(t, mt.GetAllStartedEvents(), 1)
+	})
+
+	mt.Run("skips when a concurrent caller stored revision 1 first", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(
+			latestResponse(mt, reportID, 0), mtest.CreateSuccessResponse(),
+			latestResponse(mt, reportID, 0), duplicateKeyResponse(),
+		)
+
+		first, err := insertFirst(store)
+		require.NoError(t, err)
+		second, err := insertFirst(store)
+		require.NoError(t, err)
+		assert.True(t, first)
+		assert.False(t, second)
+	})
+
+	mt.Run("fails on other errors", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}))
+
+		_, err := insertFirst(store)
+		assert.ErrorContains(t, err, "failed to insert note revision")
+	})
+}
+
+func TestDiscardRejectsEmptyID(t *testing.T) {
+	store := New(nil, nil, testKind)
+	assert.Error(t, store.Discard(context.Background(), primitive.NilObjectID))
+}
+
+func newTestCipher(t *testing.T) reports.FieldCipher {
+	raw, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	key := dataKeyStore.DataKey{ProviderID: "provider", Version: 1, Key: raw}
+	keys := new(dataKeyStore.MockDataKeyStore)
+	keys.On("Current", mock.Anything, "provider").Return(key, nil)
+	keys.On("Get", mock.Anything, "provider", 1).Return(key, nil)
+	return reports.NewFieldCipher(keys)
+}
+
+func TestSealIsBoundToRevision(t *testing.T) {
+	store := New(nil, newTestCipher(t), testKind)
+	ctx := context.Background()
+	id, reportID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	sealed, err := store.Seal(ctx, id, reportID, "provider", "rest")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "enc:v1:"))
+
+	opened, err := store.Open(ctx, id, reportID, "provider", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "rest", opened)
+
+	_, err = store.Open(ctx, primitive.NewObjectID(), reportID, "provider", sealed)
+	assert.ErrorContains(t, err, "failed to decrypt note revision")
+}
+
+func TestReencryptAll(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("re-encrypts plaintext revisions", func(mt *mtest.T) {
+		store := New(mt.Coll, newTestCipher(t), testKind)
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "reportId", Value: reportID},
+				{Key: "providerId", Value: "provider"},
+				{Key: "note", Value: "plan"},
+				{Key: "revision", Value: 1},
+				{Key: "text", Value: "stored before encryption"},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		reencrypted, err := store.ReencryptAll(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, reencrypted)
+
+		update := mt.GetAllStartedEvents()[1].Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "stored before encryption", update.Lookup("q", "text").StringValue())
+		assert.True(t, strings.HasPrefix(update.Lookup("u", "$set", "text").StringValue(), "enc:v1:"))
+	})
+
+	mt.Run("requires a cipher", func(mt *mtest.T) {
+		store := New(mt.Coll, nil, testKind)
+		_, err := store.ReencryptAll(context.Background())
+		assert.ErrorContains(t, err, "not configured")
+	})
+}
@@ -0,0 +1,63 @@
+package sanitize
+
+import (
+	"context"
+	"fmt"
+
+	dlp "cloud.google.com/go/dlp/apiv2"
+	"cloud.google.com/go/dlp/apiv2/dlppb"
+)
+
+type dlpRedactor struct {
+	projectID string
+	infoTypes []string
+}
+
+// NewDLPRedactor creates a Redactor backed by Google Cloud DLP. It needs DLP enabled for the project
+// and credentials for a service account allowed to use it. Empty infoTypes means infoTypesToRedact.
+func NewDLPRedactor(projectID string, infoTypes []string) Redactor {
+	if len(infoTypes) == 0 {
+		infoTypes = infoTypesToRedact
+	}
+	return &dlpRedactor{projectID: projectID, infoTypes: infoTypes}
+}
+
+func (r *dlpRedactor) Inspect(ctx context.Context, text string) ([]Finding, error) {
+	client, err := dlp.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("DLPRedactor: dlp.NewClient: %w", err)
+	}
+	defer client.Close()
+
+	var inspectInfoTypes []*dlppb.InfoType
+	for _, it := range r.infoTypes {
+		inspectInfoTypes = append(inspectInfoTypes, &dlppb.InfoType{Name: it})
+	}
+	resp, err := client.InspectContent(ctx, &dlppb.InspectContentRequest{
+		Parent:        fmt.Sprintf("projects/%s/locations/global", r.projectID),
+		InspectConfig: &dlppb.InspectConfig{InfoTypes: inspectInfoTypes},
+		Item:          &dlppb.ContentItem{DataItem: &dlppb.ContentItem_Value{Value: text}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DLPRedactor: error InspectContent: %w", err)
+	}
+
+	var findings []Finding
+	for _, f := range resp.GetResult().GetFindings() {
+		byteRange := f.GetLocation().GetByteRange()
+		start, end := int(byteRange.GetStart()), int(byteRange.GetEnd())
+		if start < 0 || end > len(text) || start >= end {
+			continue
+		}
+		findings = append(findings, Finding{InfoType: f.GetInfoType().GetName(), Start: start, End: end, Text: text[start:end]})
+	}
+	return resolveOverlaps(findings), nil
+}
+
+func (r *dlpRedactor) Redact(ctx context.Context, text string) (string, error) {
+	findings, err := r.Inspect(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return ReplaceFindings(text, findings, Placeholder), nil
+}
@@ -0,0 +1,281 @@
+package sanitize
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultInfoTypes are the info types the local redactor detects when none are configured.
+var DefaultInfoTypes = []string{
+	SocialSecurityNumber,
+	PhoneNumber,
+	EmailAddress,
+	MedicalRecordNumber,
+	CreditCardNumber,
+	Date,
+	ZipCode,
+	StreetAddress,
+	PersonName,
+	IPAddress,
+	URL,
+}
+
+// LocalConfig configures the local redactor.
+type LocalConfig struct {
+	// InfoTypes limits detection to these info types. Empty means DefaultInfoTypes.
+	InfoTypes []string
+	// Names are known names to redact wherever they appear, such as the patient's name from the chart,
+	// in addition to the names found by the built-in dictionary.
+	Names []string
+}
+
+// detector finds the identifiers of one info type in a text.
+type detector func(text string) []Finding
+
+// detectorOrder lists the detectors by precedence: when two find the same span, the earlier one wins.
+var detectorOrder = []string{
+	EmailAddress,
+	URL,
+	SocialSecurityNumber,
+	MedicalRecordNumber,
+	CreditCardNumber,
+	PhoneNumber,
+	IPAddress,
+	Date,
+	StreetAddress,
+	ZipCode,
+	PersonName,
+}
+
+var detectors = map[string]detector{
+	SocialSecurityNumber: detectSSNs,
+	PhoneNumber:          detectPhoneNumbers,
+	EmailAddress:         patternDetector(EmailAddress, emailPattern),
+	MedicalRecordNumber:  patternDetector(MedicalRecordNumber, mrnPattern),
+	CreditCardNumber:     detectCreditCardNumbers,
+	Date:                 detectDates,
+	ZipCode:              patternDetector(ZipCode, zipPatterns...),
+	StreetAddress:        patternDetector(StreetAddress, streetAddressPattern),
+	PersonName:           detectNames,
+	IPAddress:            detectIPAddresses,
+	URL:                  detectURLs,
+}
+
+type localRedactor struct {
+	infoTypes map[string]bool
+	names     *regexp.Regexp
+}
+
+// NewLocalRedactor creates a Redactor that runs entirely in process, using pattern and checksum
+// detectors for numbers and addresses and a name dictionary for people. It returns an error for
+// info types it cannot detect.
+func NewLocalRedactor(config LocalConfig) (Redactor, error) {
+	infoTypes := config.InfoTypes
+	if len(infoTypes) == 0 {
+		infoTypes = DefaultInfoTypes
+	}
+
+	r := &localRedactor{infoTypes: make(map[string]bool, len(infoTypes))}
+	for _, infoType := range infoTypes {
+		if _, ok := detectors[infoType]; !ok {
+			return nil, fmt.Errorf("NewLocalRedactor: unsupported info type %q", infoType)
+		}
+		r.infoTypes[infoType] = true
+	}
+	r.names = namesPattern(config.Names)
+	return r, nil
+}
+
+func (r *localRedactor) Inspect(ctx context.Context, text string) ([]Finding, error) {
+	var findings []Finding
+	for _, infoType := range detectorOrder {
+		if !r.infoTypes[infoType] {
+			continue
+		}
+		if infoType == PersonName && r.names != nil {
+			findings = append(findings, matchFindings(PersonName, r.names, text)...)
+		}
+		findings = append(findings, detectors[infoType](text)...)
+	}
+	return resolveOverlaps(findings), nil
+}
+
+func (r *localRedactor) Redact(ctx context.Context, text string) (string, error) {
+	findings, err := r.Inspect(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return ReplaceFindings(text, findings, Placeholder), nil
+}
+
+var (
+	emailPattern = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)
+	urlPattern   = regexp.MustCompile(`\b(?:https?://|www\.)[^\s<>"]+`)
+	ssnPatterns  = []*regexp.Regexp{
+		regexp.MustCompile(`\b(\d{3}-\d{2}-\d{4})\b`),
+		regexp.MustCompile(`(?i)\b(?:ssn|social security(?: number| no\.?)?)\s*(?:is|:|#)?\s*(\d{9}|\d{3} \d{2} \d{4})\b`),
+	}
+	mrnPattern   = regexp.MustCompile(`(?i)\b(?:mrn|medical record(?: number| no\.?)?|chart(?: number| no\.?)|patient id)\s*(?:is|:|#)?\s*#?\s*([a-z]{0,3}-?\d{5,12})\b`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	phonePattern = regexp.MustCompile(`(?:\+?1[-.\s]?)?(?:\(\d{3}\)\s?|\b\d{3}[-.\s]?)\d{3}[-.\s]?\d{4}\b`)
+	ipPattern    = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+	numericDatePattern = regexp.MustCompile(`\b(\d{1,2})[/-](\d{1,2})[/-](\d{4}|\d{2})\b`)
+	isoDatePattern     = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	writtenDatePattern = regexp.MustCompile(`\b(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|June?|July?|Aug(?:ust)?|Sep(?:t(?:ember)?)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\.?(?:\s+\d{1,2}(?:st|nd|rd|th)?(?:,?\s+\d{4})?|,?\s+\d{4})\b`)
+
+	zipPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:A[KLRZ]|C[AOT]|D[CE]|FL|GA|HI|I[ADLN]|K[SY]|LA|M[ADEINOST]|N[CDEHJMVY]|O[HKR]|PA|RI|S[CD]|T[NX]|UT|V[AT]|W[AIVY]),?\s+(\d{5}(?:-\d{4})?)\b`),
+		regexp.MustCompile(`(?i)\bzip(?: code)?\s*(?:is|:)?\s*(\d{5}(?:-\d{4})?)\b`),
+		regexp.MustCompile(`\b(\d{5}-\d{4})\b`),
+	}
+	streetAddressPattern = regexp.MustCompile(`\b\d{1,6}\s+(?:[A-Z][A-Za-z]*\.?\s+){1,4}(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Court|Ct|Way|Place|Pl|Terrace|Ter|Circle|Cir|Parkway|Pkwy|Highway|Hwy)\b\.?(?:,?\s+(?:Apt|Apartment|Suite|Ste|Unit|#)\.?\s*#?[A-Za-z0-9-]+)?`)
+)
+
+// patternDetector finds the matches of the patterns. Patterns with a capture group report only the
+// group, so context words like "MRN" stay in the text.
+func patternDetector(infoType string, patterns ...*regexp.Regexp) detector {
+	return func(text string) []Finding {
+		var findings []Finding
+		for _, pattern := range patterns {
+			findings = append(findings, matchFindings(infoType, pattern, text)...)
+		}
+		return findings
+	}
+}
+
+func matchFindings(infoType string, pattern *regexp.Regexp, text string) []Finding {
+	var findings []Finding
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[0], match[1]
+		if len(match) > 2 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+		findings = append(findings, Finding{InfoType: infoType, Start: start, End: end, Text: text[start:end]})
+	}
+	return findings
+}
+
+// filterFindings keeps the findings whose text passes valid.
+func filterFindings(findings []Finding, valid func(string) bool) []Finding {
+	kept := findings[:0]
+	for _, finding := range findings {
+		if valid(finding.Text) {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func detectSSNs(text string) []Finding {
+	return filterFindings(patternDetector(SocialSecurityNumber, ssnPatterns...)(text), func(s string) bool {
+		return validSSN(digits(s))
+	})
+}
+
+// validSSN rejects numbers the Social Security Administration never issues: area 000, 666 or 900-999,
+// group 00 and serial 0000.
+func validSSN(ssn string) bool {
+	if len(ssn) != 9 {
+		return false
+	}
+	area, group, serial := ssn[:3], ssn[3:5], ssn[5:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func detectPhoneNumbers(text string) []Finding {
+	return filterFindings(matchFindings(PhoneNumber, phonePattern, text), func(s string) bool {
+		return validPhoneNumber(digits(s))
+	})
+}
+
+// validPhoneNumber checks a North American number: ten digits, optionally after the country code 1,
+// with an area code and exchange that do not start with 0 or 1.
+func validPhoneNumber(number string) bool {
+	if len(number) == 11 && number[0] == '1' {
+		number = number[1:]
+	}
+	return len(number) == 10 && number[0] >= '2' && number[3] >= '2'
+}
+
+func detectCreditCardNumbers(text string) []Finding {
+	return filterFindings(matchFindings(CreditCardNumber, cardPattern, text), func(s string) bool {
+		return luhnValid(digits(s))
+	})
+}
+
+// luhnValid checks the Luhn checksum card numbers carry in their last digit.
+func luhnValid(number string) bool {
+	if len(number) < 13 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func detectIPAddresses(text string) []Finding {
+	return filterFindings(matchFindings(IPAddress, ipPattern, text), func(s string) bool {
+		for _, octet := range strings.Split(s, ".") {
+			if n, err := strconv.Atoi(octet); err != nil || n > 255 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func detectURLs(text string) []Finding {
+	findings := matchFindings(URL, urlPattern, text)
+	for i := range findings {
+		// Sentence punctuation after a link is not part of it.
+		trimmed := strings.TrimRight(findings[i].Text, ".,;:!?)")
+		findings[i].End = findings[i].Start + len(trimmed)
+		findings[i].Text = trimmed
+	}
+	return findings
+}
+
+func detectDates(text string) []Finding {
+	findings := matchFindings(Date, writtenDatePattern, text)
+	for _, match := range numericDatePattern.FindAllStringSubmatchIndex(text, -1) {
+		if validDate(text[match[2]:match[3]], text[match[4]:match[5]]) {
+			findings = append(findings, Finding{InfoType: Date, Start: match[0], End: match[1], Text: text[match[0]:match[1]]})
+		}
+	}
+	for _, match := range isoDatePattern.FindAllStringSubmatchIndex(text, -1) {
+		if validDate(text[match[4]:match[5]], text[match[6]:match[7]]) {
+			findings = append(findings, Finding{InfoType: Date, Start: match[0], End: match[1], Text: text[match[0]:match[1]]})
+		}
+	}
+	return findings
+}
+
+func validDate(month, day string) bool {
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	return m >= 1 && m <= 12 && d >= 1 && d <= 31
+}
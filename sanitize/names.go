@@ -0,0 +1,144 @@
+package sanitize
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// titledNamePattern finds names after an honorific. Only the name is reported.
+	titledNamePattern = regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Mx|Dr)\.?\s+([A-Z][a-z]+(?:['-][A-Z]?[a-z]+)?(?:\s+[A-Z][a-z]+(?:['-][A-Z]?[a-z]+)?)?)`)
+	// introducedNamePattern finds names people give for themselves or others.
+	introducedNamePattern  = regexp.MustCompile(`(?i:my name is|name is|call me|goes by)\s+([A-Z][a-z]+(?:['-][A-Z]?[a-z]+)?(?:\s+[A-Z][a-z]+(?:['-][A-Z]?[a-z]+)?)?)`)
+	capitalizedWordPattern = regexp.MustCompile(`\b[A-Z][a-z]+(?:['-][A-Z]?[a-z]+)?\b`)
+)
+
+// firstNames and surnames are common US given and family names. Names that are also everyday words
+// (Will, May, Hope, Grace, White, Brown, Young...) are left out, since they would be redacted at the
+// start of every sentence that uses them.
+var firstNames = wordSet(`
+	James John Robert Michael William David Richard Joseph Thomas Charles Christopher Daniel Matthew
+	Anthony Donald Steven Paul Andrew Joshua Kenneth Kevin Brian George Timothy Ronald Edward Jason
+	Jeffrey Ryan Jacob Gary Nicholas Eric Jonathan Stephen Larry Justin Scott Brandon Benjamin Samuel
+	Gregory Alexander Patrick Raymond Dennis Tyler Aaron Jose Adam Nathan Henry Douglas Zachary Peter
+	Kyle Ethan Walter Noah Jeremy Keith Roger Terry Sean Gerald Carl Harold Dylan
+	Arthur Lawrence Jesse Bryan Billy Bruce Gabriel Joe Logan Alan Juan Albert Willie Elijah
+	Wayne Randy Vincent Mason Roy Ralph Bobby Russell Bradley Philip Eugene Carlos Luis Jorge Miguel
+	Mary Patricia Jennifer Linda Elizabeth Barbara Susan Jessica Sarah Karen Lisa Nancy Betty Sandra
+	Margaret Ashley Kimberly Emily Donna Michelle Carol Amanda Melissa Deborah Stephanie Dorothy
+	Rebecca Sharon Laura Cynthia Amy Kathleen Angela Shirley Brenda Anna Pamela Nicole Samantha
+	Katherine Emma Helen Debra Rachel Carolyn Janet Maria Catherine Heather Diane Olivia Julie Joyce
+	Victoria Ruth Virginia Lauren Kelly Christina Joan Evelyn Judith Andrea Hannah Megan Cheryl
+	Jacqueline Martha Madison Teresa Gloria Sara Janice Ann Kathryn Abigail Sophia Frances Jean Alice
+	Judy Isabella Julia Beverly Denise Marilyn Amber Danielle Brittany Diana Natalie Lori Alexis
+	Tiffany Kayla Carmen Rosa Ana Lucia Sofia Guadalupe Esperanza Marisol Yolanda Jean-Pierre Marie
+	Nadia Fatima Aisha Mohammed Ahmed Wei Mei Priya Raj
+`)
+
+var surnames = wordSet(`
+	Smith Johnson Williams Jones Garcia Miller Davis Rodriguez Martinez Hernandez Lopez Gonzalez
+	Wilson Anderson Thomas Taylor Moore Jackson Martin Lee Perez Thompson Harris Sanchez Clark
+	Ramirez Lewis Robinson Walker Allen Wright Scott Torres Nguyen Flores Adams Nelson Rivera
+	Campbell Mitchell Carter Roberts Gomez Phillips Evans Turner Diaz Parker Cruz Edwards Collins
+	Reyes Stewart Morris Morales Murphy Rogers Gutierrez Ortiz Morgan Cooper Peterson Ramos
+	Kim Cox Richardson Watson Brooks Chavez Bennett Mendoza Ruiz Hughes Alvarez
+	Castillo Sanders Patel Myers Ross Foster Jimenez Pierre Joseph Jean-Baptiste
+`)
+
+// nameStopWords are capitalized words that never continue a name, such as words that start the next
+// clause after a name ("Maria Said").
+var nameStopWords = wordSet(`
+	I The And But Or So He She They We You It Is Was Has Had Said Says Told Reports Reported Denies
+	Denied States Stated Presents Presented Notes Today Yesterday Tomorrow Patient Doctor Nurse
+	Monday Tuesday Wednesday Thursday Friday Saturday Sunday
+	January February March April May June July August September October November December
+`)
+
+// everydayWords are everyday English words that are also given names or surnames. A known name that is
+// one of them is only matched capitalized, so a patient called Will does not redact every "will".
+var everydayWords = wordSet(`
+	will may june april august hope grace faith joy rose lily daisy iris ivy violet amber ruby pearl
+	crystal dawn summer autumn winter sky rain river storm frost snow bill bob mark jack frank grant
+	hunter chase guy ray rich art buck sue pat sandy rusty patience prudence honor heather holly page
+	bishop price king young white brown black green gray grey rice wood woods hill stone lane fox bell
+	bush hall long little best mills cook baker mason porter fisher weaver shepherd golden sharp strong
+	short love moody english french north west cross church park banks case wells fields waters march
+	early gale bloom field brook dean drew gene wade rob nick penny sunny star angel prince
+	major rector bright free hardy noble wise sage clay cliff glen dale heath forest
+`)
+
+// IsEverydayWord reports whether a name is also an everyday English word, ignoring case.
+func IsEverydayWord(name string) bool {
+	return everydayWords[strings.ToLower(strings.TrimSpace(name))]
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// detectNames finds person names after honorifics, in introductions, and from the name dictionary.
+// A dictionary name takes the capitalized word after it along as a surname.
+func detectNames(text string) []Finding {
+	findings := matchFindings(PersonName, titledNamePattern, text)
+	findings = append(findings, matchFindings(PersonName, introducedNamePattern, text)...)
+
+	words := capitalizedWordPattern.FindAllStringIndex(text, -1)
+	for i := 0; i < len(words); i++ {
+		word := text[words[i][0]:words[i][1]]
+		if !firstNames[word] && !surnames[word] {
+			continue
+		}
+		start, end := words[i][0], words[i][1]
+		for i+1 < len(words) && strings.TrimSpace(text[end:words[i+1][0]]) == "" {
+			next := text[words[i+1][0]:words[i+1][1]]
+			if nameStopWords[next] || (!firstNames[word] && !firstNames[next] && !surnames[next]) {
+				break
+			}
+			i++
+			word, end = next, words[i][1]
+		}
+		findings = append(findings, Finding{InfoType: PersonName, Start: start, End: end, Text: text[start:end]})
+	}
+	return findings
+}
+
+// namesPattern matches any of the known names as whole words, ignoring case except for names that are
+// also everyday words, which only match capitalized as given. It returns nil when no names are given.
+func namesPattern(names []string) *regexp.Regexp {
+	var quoted, asWritten []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if IsEverydayWord(name) {
+			asWritten = append(asWritten, regexp.QuoteMeta(capitalize(name)))
+		} else {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+	}
+	if len(quoted) == 0 && len(asWritten) == 0 {
+		return nil
+	}
+	// Longer names first, so "Maria Lopez" is matched whole rather than as "Maria".
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+
+	var alternatives []string
+	if len(quoted) > 0 {
+		alternatives = append(alternatives, `(?i:`+strings.Join(quoted, "|")+`)`)
+	}
+	alternatives = append(alternatives, asWritten...)
+	return regexp.MustCompile(`\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+}
+
+// capitalize upper-cases the first letter of a name, the way a transcript writes it.
+func capitalize(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,81 @@
+package sanitize
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Info types the redactors detect. The names match Cloud DLP's built-in info types so a configuration
+// can move between the local redactor and the DLP adapter unchanged.
+const (
+	SocialSecurityNumber = "SOCIAL_SECURITY_NUMBER"
+	PhoneNumber          = "PHONE_NUMBER"
+	EmailAddress         = "EMAIL_ADDRESS"
+	MedicalRecordNumber  = "MEDICAL_RECORD_NUMBER"
+	CreditCardNumber     = "CREDIT_CARD_NUMBER"
+	Date                 = "DATE"
+	ZipCode              = "ZIP_CODE"
+	StreetAddress        = "STREET_ADDRESS"
+	PersonName           = "PERSON_NAME"
+	IPAddress            = "IP_ADDRESS"
+	URL                  = "URL"
+)
+
+// Finding is one identifier found in a text. Start and End are byte offsets, so Text is text[Start:End].
+type Finding struct {
+	InfoType string `json:"infoType"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Text     string `json:"text"`
+}
+
+// Redactor finds protected health information in free text and removes it.
+type Redactor interface {
+	// Inspect returns the identifiers found in text, ordered by position and without overlaps.
+	Inspect(ctx context.Context, text string) ([]Finding, error)
+	// Redact replaces every identifier found in text with its info type, e.g. "[PHONE_NUMBER]".
+	Redact(ctx context.Context, text string) (string, error)
+}
+
+// ReplaceFindings replaces each finding in text with the string returned by replace. Findings must
+// come from the same text, ordered by position and without overlaps, as Inspect returns them.
+func ReplaceFindings(text string, findings []Finding, replace func(Finding) string) string {
+	var result strings.Builder
+	last := 0
+	for _, finding := range findings {
+		result.WriteString(text[last:finding.Start])
+		result.WriteString(replace(finding))
+		last = finding.End
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// Placeholder is the replacement Redact uses for a finding.
+func Placeholder(finding Finding) string {
+	return "[" + finding.InfoType + "]"
+}
+
+// resolveOverlaps orders findings by position and drops those overlapping an earlier, longer one.
+// Findings covering the same span keep the first one given, so detectors are listed by precedence.
+func resolveOverlaps(findings []Finding) []Finding {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Start != findings[j].Start {
+			return findings[i].Start < findings[j].Start
+		}
+		return findings[i].End-findings[i].Start > findings[j].End-findings[j].Start
+	})
+
+	resolved := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		if n := len(resolved); n > 0 && finding.Start < resolved[n-1].End {
+			if finding.End-finding.Start <= resolved[n-1].End-resolved[n-1].Start {
+				continue
+			}
+			resolved = resolved[:n-1]
+		}
+		resolved = append(resolved, finding)
+	}
+	return resolved
+}
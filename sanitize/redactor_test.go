@@ -0,0 +1,127 @@
+package sanitize
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRedactorDetectors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		infoType string
+		text     string
+		expected []string
+	}{
+		{name: "ssn with dashes", infoType: SocialSecurityNumber, text: "Her SSN is 123-45-6789.", expected: []string{"123-45-6789"}},
+		{name: "ssn after keyword", infoType: SocialSecurityNumber, text: "social security number: 123456789", expected: []string{"123456789"}},
+		{name: "ssn never issued", infoType: SocialSecurityNumber, text: "Reference 000-12-3456 and 666-12-3456 and 912-34-5678.", expected: nil},
+		{name: "phone number formats", infoType: PhoneNumber, text: "Call (617) 555-0199 or 617.555.0142 or +1 617-555-0100.", expected: []string{"(617) 555-0199", "617.555.0142", "+1 617-555-0100"}},
+		{name: "phone with invalid area code", infoType: PhoneNumber, text: "Lot 123-456-7890 expired.", expected: nil},
+		{name: "email", infoType: EmailAddress, text: "Send results to maria.lopez@example.com please.", expected: []string{"maria.lopez@example.com"}},
+		{name: "medical record number", infoType: MedicalRecordNumber, text: "MRN: 00482913, chart number A-5531207.", expected: []string{"00482913", "A-5531207"}},
+		{name: "card number passing luhn", infoType: CreditCardNumber, text: "Paid with 4111 1111 1111 1111 today.", expected: []string{"4111 1111 1111 1111"}},
+		{name: "card number failing luhn", infoType: CreditCardNumber, text: "Paid with 4111 1111 1111 1112 today.", expected: nil},
+		{name: "dates", infoType: Date, text: "Seen 3/14/2024, again on 2024-04-02 and March 5th, 2024.", expected: []string{"3/14/2024", "2024-04-02", "March 5th, 2024"}},
+		{name: "not a date", infoType: Date, text: "BP 120/80, dose 13/45/2024 units, may 5 mg.", expected: nil},
+		{name: "zip code", infoType: ZipCode, text: "Lives in Boston, MA 02118 and mail goes to 02139-4307.", expected: []string{"02118", "02139-4307"}},
+		{name: "bare number is not a zip code", infoType: ZipCode, text: "Platelets 15000 and 25000.", expected: nil},
+		{name: "street address", infoType: StreetAddress, text: "She lives at 42 N Main St., Apt 3B near the clinic.", expected: []string{"42 N Main St., Apt 3B"}},
+		{name: "ip address", infoType: IPAddress, text: "Logged in from 192.168.1.20, not 300.1.1.1.", expected: []string{"192.168.1.20"}},
+		{name: "url", infoType: URL, text: "See https://portal.example.com/visit?id=7.", expected: []string{"https://portal.example.com/visit?id=7"}},
+		{name: "dictionary names", infoType: PersonName, text: "Maria Lopez came with her son Carlos. Smith was not there.", expected: []string{"Maria Lopez", "Carlos", "Smith"}},
+		{name: "titled and introduced names", infoType: PersonName, text: "Dr. Okafor referred her. My name is Tenzin Dorje.", expected: []string{"Okafor", "Tenzin Dorje"}},
+		{name: "name followed by a verb", infoType: PersonName, text: "Maria Said she was tired.", expected: []string{"Maria"}},
+		{name: "everyday words are not names", infoType: PersonName, text: "White blood cells are normal. May return in June.", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			redactor, err := NewLocalRedactor(LocalConfig{InfoTypes: []string{tc.infoType}})
+			require.NoError(t, err)
+
+			findings, err := redactor.Inspect(context.Background(), tc.text)
+			require.NoError(t, err)
+
+			var found []string
+			for _, finding := range findings {
+				assert.Equal(t, tc.infoType, finding.InfoType)
+				assert.Equal(t, tc.text[finding.Start:finding.End], finding.Text)
+				found = append(found, finding.Text)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func TestLocalRedactorRedact(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   LocalConfig
+		text     string
+		expected string
+	}{
+		{
+			name:     "all default info types",
+			text:     "Maria Lopez (MRN 00482913) can be reached at 617-555-0199 or maria@example.com.",
+			expected: "[PERSON_NAME] (MRN [MEDICAL_RECORD_NUMBER]) can be reached at [PHONE_NUMBER] or [EMAIL_ADDRESS].",
+		},
+		{
+			name:     "configured names",
+			config:   LocalConfig{Names: []string{"Tenzin Dorje"}},
+			text:     "tenzin dorje says the pain started Monday.",
+			expected: "[PERSON_NAME] says the pain started Monday.",
+		},
+		{
+			name:     "configured names that are everyday words match capitalized only",
+			config:   LocalConfig{Names: []string{"Will", "rose", "Tenzin"}},
+			text:     "Will says the rash rose overnight and tenzin will call Rose.",
+			expected: "[PERSON_NAME] says the rash rose overnight and [PERSON_NAME] will call [PERSON_NAME].",
+		},
+		{
+			name:     "only configured info types",
+			config:   LocalConfig{InfoTypes: []string{PhoneNumber}},
+			text:     "Maria's number is 617-555-0199.",
+			expected: "Maria's number is [PHONE_NUMBER].",
+		},
+		{
+			name:     "longer finding wins an overlap",
+			text:     "Visit on 4/2/2024 at 12 Oak Dr, Springfield, IL 62704.",
+			expected: "Visit on [DATE] at [STREET_ADDRESS], Springfield, IL [ZIP_CODE].",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			redactor, err := NewLocalRedactor(tc.config)
+			require.NoError(t, err)
+
+			redacted, err := redactor.Redact(context.Background(), tc.text)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, redacted)
+		})
+	}
+}
+
+func TestNewLocalRedactorUnsupportedInfoType(t *testing.T) {
+	_, err := NewLocalRedactor(LocalConfig{InfoTypes: []string{"BIOMETRIC_ID"}})
+	assert.Error(t, err)
+}
+
+func TestResolveOverlaps(t *testing.T) {
+	findings := []Finding{
+		{InfoType: MedicalRecordNumber, Start: 4, End: 14},
+		{InfoType: PhoneNumber, Start: 4, End: 14},
+		{InfoType: PersonName, Start: 0, End: 3},
+		{InfoType: ZipCode, Start: 25, End: 30},
+		{InfoType: StreetAddress, Start: 16, End: 30},
+	}
+
+	assert.Equal(t, []Finding{
+		{InfoType: PersonName, Start: 0, End: 3},
+		{InfoType: MedicalRecordNumber, Start: 4, End: 14},
+		{InfoType: StreetAddress, Start: 16, End: 30},
+	}, resolveOverlaps(findings))
+}
@@ -39,6 +39,9 @@ var infoTypesToRedact = []string{
 	// And any custom InfoTypes you create for specific medical terms, codes, etc.
 }
 
+// SanitizeTranscript redacts a transcript with Cloud DLP.
+//
+// Deprecated: use NewDLPRedactor, or NewLocalRedactor to redact without Cloud DLP.
 func SanitizeTranscript(ctx context.Context, projectID, inputString string) (string, error) {
 	// Instantiate a client.
 	client, err := dlp.NewClient(ctx)
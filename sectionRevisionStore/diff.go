@@ -0,0 +1,101 @@
+package sectionRevisions
+
+import "strings"
+
+// Diff operations
+const (
+	DiffEqual  = "equal"
+	DiffDelete = "delete"
+	DiffInsert = "insert"
+)
+
+// maxDiffCells bounds the table of the word alignment. Beyond it the differing middle of the two texts
+// is reported as one deletion followed by one insertion instead of being aligned word by word.
+const maxDiffCells = 4_000_000
+
+// DiffChunk is a run of consecutive words that are kept, deleted or inserted.
+type DiffChunk struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// WordDiff returns the word-level changes that turn from into to. Words are separated by any whitespace,
+// and the words of a chunk are joined by single spaces. Deletions come before insertions where a run of
+// words was replaced.
+func WordDiff(from, to string) []DiffChunk {
+	a, b := strings.Fields(from), strings.Fields(to)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	d := &differ{}
+	d.add(DiffEqual, a[:prefix]...)
+	d.align(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	d.add(DiffEqual, a[len(a)-suffix:]...)
+	return d.chunks
+}
+
+type differ struct {
+	chunks []DiffChunk
+}
+
+// align diffs two runs of words along their longest common subsequence.
+func (d *differ) align(a, b []string) {
+	if len(a) == 0 || len(b) == 0 || (len(a)+1)*(len(b)+1) > maxDiffCells {
+		d.add(DiffDelete, a...)
+		d.add(DiffInsert, b...)
+		return
+	}
+
+	// common[i*width+j] is the length of the longest common subsequence of a[i:] and b[j:].
+	width := len(b) + 1
+	common := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				common[i*width+j] = common[(i+1)*width+j+1] + 1
+			case common[(i+1)*width+j] >= common[i*width+j+1]:
+				common[i*width+j] = common[(i+1)*width+j]
+			default:
+				common[i*width+j] = common[i*width+j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			d.add(DiffEqual, a[i])
+			i, j = i+1, j+1
+		case common[(i+1)*width+j] >= common[i*width+j+1]:
+			d.add(DiffDelete, a[i])
+			i++
+		default:
+			d.add(DiffInsert, b[j])
+			j++
+		}
+	}
+	d.add(DiffDelete, a[i:]...)
+	d.add(DiffInsert, b[j:]...)
+}
+
+// add appends words to the diff, extending the last chunk when it has the same operation.
+func (d *differ) add(op string, words ...string) {
+	if len(words) == 0 {
+		return
+	}
+	text := strings.Join(words, " ")
+	if last := len(d.chunks) - 1; last >= 0 && d.chunks[last].Op == op {
+		d.chunks[last].Text += " " + text
+		return
+	}
+	d.chunks = append(d.chunks, DiffChunk{Op: op, Text: text})
+}
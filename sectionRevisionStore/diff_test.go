@@ -0,0 +1,97 @@
+package sectionRevisions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected []DiffChunk
+	}{
+		{
+			name:     "identical",
+			from:     "start ibuprofen 400mg",
+			to:       "start  ibuprofen\n400mg",
+			expected: []DiffChunk{{DiffEqual, "start ibuprofen 400mg"}},
+		},
+		{
+			name: "replaced word",
+			from: "start ibuprofen 400mg twice daily",
+			to:   "start naproxen 400mg twice daily",
+			expected: []DiffChunk{
+				{DiffEqual, "start"},
+				{DiffDelete, "ibuprofen"},
+				{DiffInsert, "naproxen"},
+				{DiffEqual, "400mg twice daily"},
+			},
+		},
+		{
+			name: "inserted and deleted runs",
+			from: "headache for three days no fever",
+			to:   "severe headache for three days",
+			expected: []DiffChunk{
+				{DiffInsert, "severe"},
+				{DiffEqual, "headache for three days"},
+				{DiffDelete, "no fever"},
+			},
+		},
+		{
+			name:     "from empty",
+			from:     "",
+			to:       "follow up in two weeks",
+			expected: []DiffChunk{{DiffInsert, "follow up in two weeks"}},
+		},
+		{
+			name:     "to empty",
+			from:     "follow up in two weeks",
+			to:       " ",
+			expected: []DiffChunk{{DiffDelete, "follow up in two weeks"}},
+		},
+		{
+			name:     "both empty",
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WordDiff(tt.from, tt.to))
+		})
+	}
+}
+
+func TestWordDiffReconstructs(t *testing.T) {
+	from := "patient reports intermittent headache worse in the morning relieved by rest"
+	to := "patient reports constant headache worse at night not relieved by rest or ibuprofen"
+	chunks := WordDiff(from, to)
+
+	var before, after []string
+	for _, chunk := range chunks {
+		if chunk.Op != DiffInsert {
+			before = append(before, chunk.Text)
+		}
+		if chunk.Op != DiffDelete {
+			after = append(after, chunk.Text)
+		}
+	}
+	assert.Equal(t, from, strings.Join(before, " "))
+	assert.Equal(t, to, strings.Join(after, " "))
+	for i := 1; i < len(chunks); i++ {
+		assert.NotEqual(t, chunks[i-1].Op, chunks[i].Op, "adjacent chunks are merged")
+	}
+}
+
+func TestWordDiffBeyondAlignmentLimit(t *testing.T) {
+	from := strings.Repeat("a ", 2500) + "end"
+	to := strings.Repeat("b ", 2500) + "end"
+	chunks := WordDiff(from, to)
+
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, DiffDelete, chunks[0].Op)
+	assert.Equal(t, DiffInsert, chunks[1].Op)
+	assert.Equal(t, DiffChunk{DiffEqual, "end"}, chunks[2])
+}
@@ -0,0 +1,47 @@
+package sectionRevisions
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockSectionRevisionStore struct {
+	mock.Mock
+}
+
+func (m *MockSectionRevisionStore) Insert(ctx context.Context, revision SectionRevision) (SectionRevision, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(SectionRevision), args.Error(1)
+}
+
+func (m *MockSectionRevisionStore) InsertOriginal(ctx context.Context, revision SectionRevision) (bool, error) {
+	args := m.Called(ctx, revision)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSectionRevisionStore) Get(ctx context.Context, reportID primitive.ObjectID, section string, revision int) (SectionRevision, error) {
+	args := m.Called(ctx, reportID, section, revision)
+	return args.Get(0).(SectionRevision), args.Error(1)
+}
+
+func (m *MockSectionRevisionStore) GetBySection(ctx context.Context, reportID primitive.ObjectID, section string) ([]SectionRevision, error) {
+	args := m.Called(ctx, reportID, section)
+	return args.Get(0).([]SectionRevision), args.Error(1)
+}
+
+func (m *MockSectionRevisionStore) Discard(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSectionRevisionStore) DeleteByReportID(ctx context.Context, reportID primitive.ObjectID) error {
+	args := m.Called(ctx, reportID)
+	return args.Error(0)
+}
+
+func (m *MockSectionRevisionStore) ReencryptAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
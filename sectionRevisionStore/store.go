@@ -0,0 +1,237 @@
+package sectionRevisions
+
+import (
+	"Medscribe/reports"
+	revisions "Medscribe/revisionStore"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Revision sources
+const (
+	// SourceOriginal is content that predates the revision history, snapshotted before its first change.
+	SourceOriginal = "original"
+	// SourceGeneration is content generated with the report.
+	SourceGeneration = "generation"
+	// SourceRegeneration is content generated again from the transcript, e.g. after it was corrected or extended.
+	SourceRegeneration = "regeneration"
+	// SourceRewrite is existing content rewritten in the provider's style with updated visit details.
+	SourceRewrite = "rewrite"
+	SourceEdit    = "edit"
+	SourceRestore = "restore"
+)
+
+// kind binds the encrypted content of a revision to the revision itself; each section has its own history.
+var kind = revisions.Kind{Name: "section revision", Namespace: "sectionRevisions", Field: "content", Scope: []string{"section"}}
+
+// ErrRevisionNotFound is returned when a section has no revision with the requested number.
+var ErrRevisionNotFound = errors.New("section revision not found")
+
+// SectionRevision is an immutable snapshot of a report section after a change to its content.
+type SectionRevision struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReportID   primitive.ObjectID `bson:"reportId" json:"reportID"`
+	ProviderID string             `bson:"providerId" json:"providerID"`
+	Section    string             `bson:"section" json:"section"`
+	// Revision numbers the section's revisions from 1, oldest first.
+	Revision int    `bson:"revision" json:"revision"`
+	Source   string `bson:"source" json:"source"`
+	AuthorID string `bson:"authorId" json:"authorID"`
+	Content  string `bson:"content" json:"content"`
+	// PromptVersion identifies the prompts that generated the content; it is empty for manual changes.
+	PromptVersion string `bson:"promptVersion,omitempty" json:"promptVersion,omitempty"`
+	// RestoredFrom is the revision a SourceRestore revision brought back.
+	RestoredFrom int                `bson:"restoredFrom,omitempty" json:"restoredFrom,omitempty"`
+	Timestamp    primitive.DateTime `bson:"timestamp" json:"timestamp"`
+}
+
+type SectionRevisionStore interface {
+	Insert(ctx context.Context, revision SectionRevision) (SectionRevision, error)
+	InsertOriginal(ctx context.Context, revision SectionRevision) (bool, error)
+	Get(ctx context.Context, reportId primitive.ObjectID, section string, revision int) (SectionRevision, error)
+	GetBySection(ctx context.Context, reportId primitive.ObjectID, section string) ([]SectionRevision, error)
+	Discard(ctx context.Context, id primitive.ObjectID) error
+	DeleteByReportID(ctx context.Context, reportId primitive.ObjectID) error
+	// ReencryptAll seals every revision still stored in plaintext or under an older data key with its
+	// provider's current data key, and returns how many were rewritten.
+	ReencryptAll(ctx context.Context) (int, error)
+}
+
+type sectionRevisionStore struct {
+	revisions *revisions.Store
+}
+
+// NewSectionRevisionStore creates the store and the unique index that keeps revision numbers distinct
+// when a section is changed concurrently. Revision content is encrypted with cipher like the report's
+// own sections, and stored as it is when cipher is nil.
+func NewSectionRevisionStore(ctx context.Context, collection *mongo.Collection, cipher reports.FieldCipher) (SectionRevisionStore, error) {
+	if collection == nil {
+		return nil, errors.New("mongodb collection cannot be nil")
+	}
+	store := revisions.New(collection, cipher, kind)
+	if err := store.EnsureIndex(ctx); err != nil {
+		return nil, err
+	}
+	return &sectionRevisionStore{revisions: store}, nil
+}
+
+// open decrypts the content of a stored revision in place.
+func (s *sectionRevisionStore) open(ctx context.Context, revision *SectionRevision) error {
+	content, err := s.revisions.Open(ctx, revision.ID, revision.ReportID, revision.ProviderID, revision.Content)
+	if err != nil {
+		return err
+	}
+	revision.Content = content
+	return nil
+}
+
+// prepare validates a revision and returns it with its defaults set, together with the copy to store
+// with its content encrypted.
+func (s *sectionRevisionStore) prepare(ctx context.Context, revision SectionRevision) (SectionRevision, SectionRevision, error) {
+	if revision.ReportID.IsZero() {
+		return SectionRevision{}, SectionRevision{}, errors.New("reportId cannot be empty")
+	}
+	if revision.ProviderID == "" {
+		return SectionRevision{}, SectionRevision{}, errors.New("providerId cannot be empty")
+	}
+	if revision.Section == "" {
+		return SectionRevision{}, SectionRevision{}, errors.New("section cannot be empty")
+	}
+	if revision.Source == "" {
+		return SectionRevision{}, SectionRevision{}, errors.New("source cannot be empty")
+	}
+	if revision.Timestamp.Time().IsZero() {
+		revision.Timestamp = primitive.NewDateTimeFromTime(time.Now())
+	}
+	// The ID is assigned up front because the encrypted content is bound to it.
+	if revision.ID.IsZero() {
+		revision.ID = primitive.NewObjectID()
+	}
+	stored := revision
+	sealed, err := s.revisions.Seal(ctx, revision.ID, revision.ReportID, revision.ProviderID, revision.Content)
+	if err != nil {
+		return SectionRevision{}, SectionRevision{}, err
+	}
+	stored.Content = sealed
+	return revision, stored, nil
+}
+
+// Insert appends a revision to the history of a report section, numbering it after the latest one. When a
+// concurrent change takes the same number first, the revision is numbered again.
+func (s *sectionRevisionStore) Insert(ctx context.Context, revision SectionRevision) (SectionRevision, error) {
+	revision, stored, err := s.prepare(ctx, revision)
+	if err != nil {
+		return SectionRevision{}, err
+	}
+
+	history := bson.M{"reportId": revision.ReportID, "section": revision.Section}
+	number, err := s.revisions.Insert(ctx, history, func(number int) interface{} {
+		stored.Revision = number
+		return stored
+	})
+	if err != nil {
+		return SectionRevision{}, err
+	}
+	revision.Revision = number
+	return revision, nil
+}
+
+// InsertOriginal snapshots the content a section had before its first change as revision 1. It stores
+// nothing when the section already has a history, including when a concurrent change snapshotted it first,
+// and reports whether the snapshot was stored.
+func (s *sectionRevisionStore) InsertOriginal(ctx context.Context, revision SectionRevision) (bool, error) {
+	revision.Source = SourceOriginal
+	_, stored, err := s.prepare(ctx, revision)
+	if err != nil {
+		return false, err
+	}
+
+	history := bson.M{"reportId": revision.ReportID, "section": revision.Section}
+	return s.revisions.InsertFirst(ctx, history, func(number int) interface{} {
+		stored.Revision = number
+		return stored
+	})
+}
+
+// Get returns one revision of a report section.
+func (s *sectionRevisionStore) Get(ctx context.Context, reportId primitive.ObjectID, section string, revision int) (SectionRevision, error) {
+	if reportId.IsZero() {
+		return SectionRevision{}, errors.New("invalid reportId")
+	}
+
+	var found SectionRevision
+	filter := bson.M{"reportId": reportId, "section": section, "revision": revision}
+	if err := s.revisions.FindOne(ctx, filter, &found); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return SectionRevision{}, ErrRevisionNotFound
+		}
+		return SectionRevision{}, err
+	}
+	if err := s.open(ctx, &found); err != nil {
+		return SectionRevision{}, err
+	}
+	return found, nil
+}
+
+// GetBySection returns the revision history of a report section, oldest first.
+func (s *sectionRevisionStore) GetBySection(ctx context.Context, reportId primitive.ObjectID, section string) ([]SectionRevision, error) {
+	if reportId.IsZero() {
+		return nil, errors.New("invalid reportId")
+	}
+
+	history := []SectionRevision{}
+	if err := s.revisions.Find(ctx, bson.M{"reportId": reportId, "section": section}, &history); err != nil {
+		return nil, err
+	}
+	for i := range history {
+		if err := s.open(ctx, &history[i]); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// Discard removes a revision whose content change was rejected, so the history only holds content that
+// was actually stored.
+func (s *sectionRevisionStore) Discard(ctx context.Context, id primitive.ObjectID) error {
+	return s.revisions.Discard(ctx, id)
+}
+
+// DeleteByReportID removes the revision history of every section of a report.
+func (s *sectionRevisionStore) DeleteByReportID(ctx context.Context, reportId primitive.ObjectID) error {
+	return s.revisions.DeleteByReportID(ctx, reportId)
+}
+
+// ReencryptAll rewrites the content of every revision not yet sealed under its provider's current data
+// key. A revision is only rewritten if it still holds the content that was re-encrypted.
+func (s *sectionRevisionStore) ReencryptAll(ctx context.Context) (int, error) {
+	return s.revisions.ReencryptAll(ctx)
+}
+
+// Record appends a revision to the history of its section. The first revision of a section that already had
+// content also snapshots that previous content as the original, so it is never lost.
+func Record(ctx context.Context, store SectionRevisionStore, revision SectionRevision, previous string) (SectionRevision, error) {
+	if previous != "" {
+		original := SectionRevision{
+			ReportID:   revision.ReportID,
+			ProviderID: revision.ProviderID,
+			Section:    revision.Section,
+			Content:    previous,
+		}
+		if _, err := store.InsertOriginal(ctx, original); err != nil {
+			return SectionRevision{}, fmt.Errorf("error recording original section content: %w", err)
+		}
+	}
+
+	inserted, err := store.Insert(ctx, revision)
+	if err != nil {
+		return SectionRevision{}, fmt.Errorf("error recording section revision: %w", err)
+	}
+	return inserted, nil
+}
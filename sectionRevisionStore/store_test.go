@@ -0,0 +1,126 @@
+package sectionRevisions
+
+import (
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
+	"Medscribe/reports"
+	revisions "Medscribe/revisionStore"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func latestResponse(mt *mtest.T, reportID primitive.ObjectID, section string, revision int) bson.D {
+	ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+	if revision == 0 {
+		return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch)
+	}
+	return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "reportId", Value: reportID},
+		{Key: "section", Value: section},
+		{Key: "revision", Value: revision},
+	})
+}
+
+func newTestStore(collection *mongo.Collection, cipher reports.FieldCipher) *sectionRevisionStore {
+	return &sectionRevisionStore{revisions: revisions.New(collection, cipher, kind)}
+}
+
+func testRevision(reportID primitive.ObjectID) SectionRevision {
+	return SectionRevision{ReportID: reportID, ProviderID: "provider", Section: "subjective", Source: SourceEdit, Content: "headache"}
+}
+
+func TestInsertNumbersAfterLatestRevision(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("first revision", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, nil)
+		mt.AddMockResponses(latestResponse(mt, reportID, "subjective", 0), mtest.CreateSuccessResponse())
+
+		revision, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.Equal(t, 1, revision.Revision)
+		assert.False(t, revision.ID.IsZero())
+	})
+
+	mt.Run("after existing history", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, nil)
+		mt.AddMockResponses(latestResponse(mt, reportID, "subjective", 7), mtest.CreateSuccessResponse())
+
+		revision, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.Equal(t, 8, revision.Revision)
+	})
+}
+
+func newTestCipher(t *testing.T) reports.FieldCipher {
+	raw, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	key := dataKeyStore.DataKey{ProviderID: "provider", Version: 1, Key: raw}
+	keys := new(dataKeyStore.MockDataKeyStore)
+	keys.On("Current", mock.Anything, "provider").Return(key, nil)
+	keys.On("Get", mock.Anything, "provider", 1).Return(key, nil)
+	return reports.NewFieldCipher(keys)
+}
+
+func TestContentIsEncryptedAtRest(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("insert and read back", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, newTestCipher(t))
+		mt.AddMockResponses(latestResponse(mt, reportID, "subjective", 0), mtest.CreateSuccessResponse())
+
+		revision, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.Equal(t, "headache", revision.Content)
+
+		inserted := mt.GetAllStartedEvents()[1].Command.Lookup("documents").Array().Index(0).Value().Document()
+		stored := inserted.Lookup("content").StringValue()
+		assert.True(t, strings.HasPrefix(stored, "enc:v1:"))
+		assert.NotContains(t, stored, "headache")
+
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: revision.ID},
+			{Key: "reportId", Value: reportID},
+			{Key: "providerId", Value: "provider"},
+			{Key: "section", Value: "subjective"},
+			{Key: "revision", Value: 1},
+			{Key: "content", Value: stored},
+		}))
+		found, err := store.Get(context.Background(), reportID, "subjective", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "headache", found.Content)
+	})
+
+	mt.Run("content moved to another revision does not decrypt", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, newTestCipher(t))
+		mt.AddMockResponses(latestResponse(mt, reportID, "subjective", 0), mtest.CreateSuccessResponse())
+		_, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		stored := mt.GetAllStartedEvents()[1].Command.Lookup("documents").Array().Index(0).Value().Document().Lookup("content").StringValue()
+
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "reportId", Value: reportID},
+			{Key: "providerId", Value: "provider"},
+			{Key: "section", Value: "subjective"},
+			{Key: "revision", Value: 2},
+			{Key: "content", Value: stored},
+		}))
+		_, err = store.Get(context.Background(), reportID, "subjective", 2)
+		assert.ErrorContains(t, err, "failed to decrypt")
+	})
+}
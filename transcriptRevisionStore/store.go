@@ -0,0 +1,165 @@
+package transcriptRevisions
+
+import (
+	"Medscribe/reports"
+	revisions "Medscribe/revisionStore"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Revision sources
+const (
+	SourceOriginal = "original"
+	SourceEdit     = "edit"
+	SourceRelabel  = "relabel"
+	SourceAppend   = "append"
+)
+
+// kind binds the encrypted transcript of a revision to the revision itself.
+var kind = revisions.Kind{Name: "transcript revision", Namespace: "transcriptRevisions", Field: "transcript"}
+
+// TranscriptRevision is an immutable snapshot of a report transcript after a change.
+type TranscriptRevision struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReportID        primitive.ObjectID `bson:"reportId" json:"reportID"`
+	ProviderID      string             `bson:"providerId" json:"providerID"`
+	Revision        int                `bson:"revision" json:"revision"`
+	Source          string             `bson:"source" json:"source"`
+	Transcript      string             `bson:"transcript" json:"transcript"`
+	UsedDiarization bool               `bson:"usedDiarization" json:"usedDiarization"`
+	Timestamp       primitive.DateTime `bson:"timestamp" json:"timestamp"`
+}
+
+type TranscriptRevisionStore interface {
+	Insert(ctx context.Context, revision TranscriptRevision) (TranscriptRevision, error)
+	InsertOriginal(ctx context.Context, revision TranscriptRevision) (bool, error)
+	Discard(ctx context.Context, id primitive.ObjectID) error
+	GetByReportID(ctx context.Context, reportId primitive.ObjectID) ([]TranscriptRevision, error)
+	DeleteByReportID(ctx context.Context, reportId primitive.ObjectID) error
+	// ReencryptAll seals every revision still stored in plaintext or under an older data key with its
+	// provider's current data key, and returns how many were rewritten.
+	ReencryptAll(ctx context.Context) (int, error)
+}
+
+type transcriptRevisionStore struct {
+	revisions *revisions.Store
+}
+
+// NewTranscriptRevisionStore creates the store and the unique index that keeps revision numbers distinct
+// when a report is changed concurrently. Revision transcripts are encrypted with cipher like the report's
+// own transcript, and stored as they are when cipher is nil.
+func NewTranscriptRevisionStore(ctx context.Context, collection *mongo.Collection, cipher reports.FieldCipher) (TranscriptRevisionStore, error) {
+	if collection == nil {
+		return nil, errors.New("mongodb collection cannot be nil")
+	}
+	store := revisions.New(collection, cipher, kind)
+	if err := store.EnsureIndex(ctx); err != nil {
+		return nil, err
+	}
+	return &transcriptRevisionStore{revisions: store}, nil
+}
+
+// prepare validates a revision and returns it with its defaults set, together with the copy to store
+// with its transcript encrypted.
+func (s *transcriptRevisionStore) prepare(ctx context.Context, revision TranscriptRevision) (TranscriptRevision, TranscriptRevision, error) {
+	if revision.ReportID.IsZero() {
+		return TranscriptRevision{}, TranscriptRevision{}, errors.New("reportId cannot be empty")
+	}
+	if revision.ProviderID == "" {
+		return TranscriptRevision{}, TranscriptRevision{}, errors.New("providerId cannot be empty")
+	}
+	if revision.Transcript == "" {
+		return TranscriptRevision{}, TranscriptRevision{}, errors.New("transcript cannot be empty")
+	}
+	if revision.Timestamp.Time().IsZero() {
+		revision.Timestamp = primitive.NewDateTimeFromTime(time.Now())
+	}
+	// The ID is assigned up front because the encrypted transcript is bound to it.
+	if revision.ID.IsZero() {
+		revision.ID = primitive.NewObjectID()
+	}
+	stored := revision
+	sealed, err := s.revisions.Seal(ctx, revision.ID, revision.ReportID, revision.ProviderID, revision.Transcript)
+	if err != nil {
+		return TranscriptRevision{}, TranscriptRevision{}, err
+	}
+	stored.Transcript = sealed
+	return revision, stored, nil
+}
+
+// Insert appends a revision to the history of a report, numbering it after the latest one. When a
+// concurrent change takes the same number first, the revision is numbered again.
+func (s *transcriptRevisionStore) Insert(ctx context.Context, revision TranscriptRevision) (TranscriptRevision, error) {
+	revision, stored, err := s.prepare(ctx, revision)
+	if err != nil {
+		return TranscriptRevision{}, err
+	}
+
+	number, err := s.revisions.Insert(ctx, bson.M{"reportId": revision.ReportID}, func(number int) interface{} {
+		stored.Revision = number
+		return stored
+	})
+	if err != nil {
+		return TranscriptRevision{}, err
+	}
+	revision.Revision = number
+	return revision, nil
+}
+
+// InsertOriginal snapshots the transcript a report had before its first change as revision 1. It stores
+// nothing when the report already has a history, including when a concurrent change snapshotted it first,
+// and reports whether the snapshot was stored.
+func (s *transcriptRevisionStore) InsertOriginal(ctx context.Context, revision TranscriptRevision) (bool, error) {
+	revision.Source = SourceOriginal
+	_, stored, err := s.prepare(ctx, revision)
+	if err != nil {
+		return false, err
+	}
+
+	return s.revisions.InsertFirst(ctx, bson.M{"reportId": revision.ReportID}, func(number int) interface{} {
+		stored.Revision = number
+		return stored
+	})
+}
+
+// Discard removes a revision whose transcript change was rejected, so the history only holds
+// transcripts that were actually stored.
+func (s *transcriptRevisionStore) Discard(ctx context.Context, id primitive.ObjectID) error {
+	return s.revisions.Discard(ctx, id)
+}
+
+// GetByReportID returns the revision history of a report, oldest first.
+func (s *transcriptRevisionStore) GetByReportID(ctx context.Context, reportId primitive.ObjectID) ([]TranscriptRevision, error) {
+	if reportId.IsZero() {
+		return nil, errors.New("invalid reportId")
+	}
+
+	history := []TranscriptRevision{}
+	if err := s.revisions.Find(ctx, bson.M{"reportId": reportId}, &history); err != nil {
+		return nil, err
+	}
+	for i := range history {
+		transcript, err := s.revisions.Open(ctx, history[i].ID, history[i].ReportID, history[i].ProviderID, history[i].Transcript)
+		if err != nil {
+			return nil, err
+		}
+		history[i].Transcript = transcript
+	}
+	return history, nil
+}
+
+// DeleteByReportID removes the revision history of a report.
+func (s *transcriptRevisionStore) DeleteByReportID(ctx context.Context, reportId primitive.ObjectID) error {
+	return s.revisions.DeleteByReportID(ctx, reportId)
+}
+
+// ReencryptAll rewrites the transcript of every revision not yet sealed under its provider's current data
+// key. A revision is only rewritten if it still holds the transcript that was re-encrypted.
+func (s *transcriptRevisionStore) ReencryptAll(ctx context.Context) (int, error) {
+	return s.revisions.ReencryptAll(ctx)
+}
@@ -0,0 +1,177 @@
+package transcriptRevisions
+
+import (
+	"Medscribe/dataKeyStore"
+	"Medscribe/encryption"
+	"Medscribe/reports"
+	revisions "Medscribe/revisionStore"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func latestResponse(mt *mtest.T, reportID primitive.ObjectID, revision int) bson.D {
+	ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+	if revision == 0 {
+		return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch)
+	}
+	return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "reportId", Value: reportID},
+		{Key: "revision", Value: revision},
+	})
+}
+
+func newTestStore(collection *mongo.Collection, cipher reports.FieldCipher) *transcriptRevisionStore {
+	return &transcriptRevisionStore{revisions: revisions.New(collection, cipher, kind)}
+}
+
+func testRevision(reportID primitive.ObjectID) TranscriptRevision {
+	return TranscriptRevision{ReportID: reportID, ProviderID: "provider", Source: SourceEdit, Transcript: "Doctor: hello"}
+}
+
+func TestInsertNumbersAfterLatestRevision(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("first revision", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, nil)
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateSuccessResponse())
+
+		revision, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.Equal(t, 1, revision.Revision)
+		assert.False(t, revision.ID.IsZero())
+		assert.False(t, revision.Timestamp.Time().IsZero())
+	})
+
+	mt.Run("after existing history", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, nil)
+		mt.AddMockResponses(latestResponse(mt, reportID, 4), mtest.CreateSuccessResponse())
+
+		revision, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.Equal(t, 5, revision.Revision)
+	})
+}
+
+func TestInsertOriginal(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("snapshots the original transcript as revision 1", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, nil)
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateSuccessResponse())
+
+		stored, err := store.InsertOriginal(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.True(t, stored)
+
+		inserted := mt.GetAllStartedEvents()[1].Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, SourceOriginal, inserted.Lookup("source").StringValue())
+		assert.Equal(t, int32(1), inserted.Lookup("revision").Int32())
+	})
+
+	mt.Run("keeps the snapshot a concurrent edit stored first", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, nil)
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}))
+
+		stored, err := store.InsertOriginal(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.False(t, stored)
+	})
+}
+
+func TestInsertValidatesRevision(t *testing.T) {
+	store := &transcriptRevisionStore{}
+	reportID := primitive.NewObjectID()
+
+	tests := []struct {
+		name     string
+		revision TranscriptRevision
+		want     string
+	}{
+		{"missing report", TranscriptRevision{ProviderID: "provider", Transcript: "text"}, "reportId"},
+		{"missing provider", TranscriptRevision{ReportID: reportID, Transcript: "text"}, "providerId"},
+		{"missing transcript", TranscriptRevision{ReportID: reportID, ProviderID: "provider"}, "transcript"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Insert(context.Background(), tt.revision)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func newTestCipher(t *testing.T) reports.FieldCipher {
+	raw, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	key := dataKeyStore.DataKey{ProviderID: "provider", Version: 1, Key: raw}
+	keys := new(dataKeyStore.MockDataKeyStore)
+	keys.On("Current", mock.Anything, "provider").Return(key, nil)
+	keys.On("Get", mock.Anything, "provider", 1).Return(key, nil)
+	return reports.NewFieldCipher(keys)
+}
+
+func TestTranscriptsAreEncryptedAtRest(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	reportID := primitive.NewObjectID()
+
+	mt.Run("insert and read back", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, newTestCipher(t))
+		mt.AddMockResponses(latestResponse(mt, reportID, 0), mtest.CreateSuccessResponse())
+
+		revision, err := store.Insert(context.Background(), testRevision(reportID))
+		require.NoError(t, err)
+		assert.Equal(t, "Doctor: hello", revision.Transcript)
+
+		inserted := mt.GetAllStartedEvents()[1].Command.Lookup("documents").Array().Index(0).Value().Document()
+		stored := inserted.Lookup("transcript").StringValue()
+		assert.True(t, strings.HasPrefix(stored, "enc:v1:"))
+		assert.NotContains(t, stored, "hello")
+
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: revision.ID},
+			{Key: "reportId", Value: reportID},
+			{Key: "providerId", Value: "provider"},
+			{Key: "revision", Value: 1},
+			{Key: "transcript", Value: stored},
+		}))
+		history, err := store.GetByReportID(context.Background(), reportID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, "Doctor: hello", history[0].Transcript)
+	})
+
+	mt.Run("re-encrypts plaintext revisions", func(mt *mtest.T) {
+		store := newTestStore(mt.Coll, newTestCipher(t))
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "reportId", Value: reportID},
+				{Key: "providerId", Value: "provider"},
+				{Key: "revision", Value: 1},
+				{Key: "transcript", Value: "Doctor: stored before encryption"},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		reencrypted, err := store.ReencryptAll(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, reencrypted)
+
+		update := mt.GetAllStartedEvents()[1].Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "Doctor: stored before encryption", update.Lookup("q", "transcript").StringValue())
+		assert.True(t, strings.HasPrefix(update.Lookup("u", "$set", "transcript").StringValue(), "enc:v1:"))
+	})
+}
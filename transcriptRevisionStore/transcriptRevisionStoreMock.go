@@ -0,0 +1,42 @@
+package transcriptRevisions
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockTranscriptRevisionStore struct {
+	mock.Mock
+}
+
+func (m *MockTranscriptRevisionStore) Insert(ctx context.Context, revision TranscriptRevision) (TranscriptRevision, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(TranscriptRevision), args.Error(1)
+}
+
+func (m *MockTranscriptRevisionStore) InsertOriginal(ctx context.Context, revision TranscriptRevision) (bool, error) {
+	args := m.Called(ctx, revision)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTranscriptRevisionStore) Discard(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTranscriptRevisionStore) GetByReportID(ctx context.Context, reportID primitive.ObjectID) ([]TranscriptRevision, error) {
+	args := m.Called(ctx, reportID)
+	return args.Get(0).([]TranscriptRevision), args.Error(1)
+}
+
+func (m *MockTranscriptRevisionStore) DeleteByReportID(ctx context.Context, reportID primitive.ObjectID) error {
+	args := m.Called(ctx, reportID)
+	return args.Error(0)
+}
+
+func (m *MockTranscriptRevisionStore) ReencryptAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
@@ -4,11 +4,14 @@ import (
 	transcriber "Medscribe/transcription"
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupEnv(t *testing.T) {
@@ -92,7 +95,7 @@ func TestTranscribeWithDiarization(t *testing.T) {
 	txn := NewAzureTranscriber(apiURL, apiDiarizationURL, apiKey)
 	audioData := loadAudioFile(t, "../../testdata/sample1.wav")
 
-	transcript, err := txn.TranscribeWithDiarization(ctx, audioData)
+	transcript, err := txn.TranscribeWithDiarization(ctx, audioData, transcriber.Options{})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, transcript, "Transcript should not be empty")
 
@@ -127,4 +130,29 @@ func TestTranscribeWithDiarization(t *testing.T) {
 	fmt.Println("------------------------------------------------")
 	fmt.Println(compressedDiarizedText)
 	fmt.Println("------------------------------------------------")
-}
\ No newline at end of file
+}
+func TestTranscribeWithDiarizationKeepsWordsOfUncertainPhrases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"phrases": [
+			{"speaker": 1, "offsetMilliseconds": 0, "durationMilliseconds": 1000, "text": "Any side effects?", "confidence": 0.95,
+			 "words": [{"text": "Any", "offsetMilliseconds": 0, "durationMilliseconds": 200}]},
+			{"speaker": 2, "offsetMilliseconds": 1000, "durationMilliseconds": 1500, "text": "Some nausea.", "confidence": 0.5,
+			 "words": [{"text": "Some", "offsetMilliseconds": 1000, "durationMilliseconds": 500}, {"text": "nausea.", "offsetMilliseconds": 1500, "durationMilliseconds": 1000}]}
+		]}`)
+	}))
+	defer server.Close()
+
+	txn := NewAzureTranscriber(server.URL, server.URL, "key")
+	turns, err := txn.TranscribeWithDiarization(context.Background(), []byte("audio"), transcriber.Options{})
+	require.NoError(t, err)
+	require.Len(t, turns, 2)
+
+	assert.Equal(t, 0.95, turns[0].Confidence)
+	assert.Empty(t, turns[0].Words, "confident phrases do not keep their words")
+	assert.Equal(t, 0.5, turns[1].Confidence)
+	assert.Equal(t, []transcriber.TranscriptWord{
+		{Text: "Some", StartTime: 1, EndTime: 1.5},
+		{Text: "nausea.", StartTime: 1.5, EndTime: 2.5},
+	}, turns[1].Words)
+}
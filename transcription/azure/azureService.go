@@ -101,12 +101,22 @@ func (t *azureTranscriber) doAzureRequest(ctx context.Context, apiURL string, au
 	return resp.Body, nil
 }
 
-func (t *azureTranscriber) Transcribe(ctx context.Context, audio []byte) (string, error) {
-	definition := map[string]interface{}{
-		"locales":             []string{"en-US"},
+// withPhraseList adds the provider vocabulary to the request definition as an Azure phrase list.
+func withPhraseList(definition map[string]interface{}, opts transcriber.Options) map[string]interface{} {
+	if len(opts.Vocabulary) > 0 {
+		definition["phraseList"] = map[string]interface{}{
+			"phrases": opts.Vocabulary,
+		}
+	}
+	return definition
+}
+
+func (t *azureTranscriber) Transcribe(ctx context.Context, audio []byte, opts transcriber.Options) (string, error) {
+	definition := withPhraseList(map[string]interface{}{
+		"locales":             opts.Locales(),
 		"profanityFilterMode": "Masked",
 		"channels":            []int{0, 1},
-	}
+	}, opts)
 
 	respBody, err := t.doAzureRequest(ctx, t.apiUrl, audio, definition)
 	if err != nil {
@@ -130,16 +140,16 @@ func (t *azureTranscriber) Transcribe(ctx context.Context, audio []byte) (string
 	return "", fmt.Errorf("transcript not found in the response")
 }
 
-func (t *azureTranscriber) TranscribeWithDiarization(ctx context.Context, audio []byte) ([]transcriber.TranscriptTurn, error) {
-	definition := map[string]interface{}{
-		"locales":             []string{"en-US"},
+func (t *azureTranscriber) TranscribeWithDiarization(ctx context.Context, audio []byte, opts transcriber.Options) ([]transcriber.TranscriptTurn, error) {
+	definition := withPhraseList(map[string]interface{}{
+		"locales":             opts.Locales(),
 		"profanityFilterMode": "Masked",
 		"diarization": map[string]interface{}{
 			"enabled":     true,
-			"maxSpeakers": 2,
+			"maxSpeakers": max(opts.SpeakerCount(), 2),
 			"minSpeakers": 2,
 		},
-	}
+	}, opts)
 
 	respBody, err := t.doAzureRequest(ctx, t.diarizationURL, audio, definition)
 	if err != nil {
@@ -158,10 +168,24 @@ func (t *azureTranscriber) TranscribeWithDiarization(ctx context.Context, audio
 	transcriptTurns := make([]transcriber.TranscriptTurn, 0)
 	for _, phrase := range response.Phrases {
 		turn := transcriber.TranscriptTurn{
-			Speaker:   "Speaker" + strconv.Itoa(phrase.Speaker),
-			StartTime: float64(phrase.OffsetMilliseconds) / 1000.0,
-			EndTime:   float64(phrase.OffsetMilliseconds+phrase.DurationMilliseconds) / 1000.0,
-			Text:      phrase.Text,
+			Speaker:    "Speaker" + strconv.Itoa(phrase.Speaker),
+			StartTime:  float64(phrase.OffsetMilliseconds) / 1000.0,
+			EndTime:    float64(phrase.OffsetMilliseconds+phrase.DurationMilliseconds) / 1000.0,
+			Text:       phrase.Text,
+			Confidence: phrase.Confidence,
+		}
+		// Azure reports confidence per phrase only, so word timings are worth keeping just where the
+		// provider may need to find an uncertain passage in the audio.
+		if !transcriber.IsLowConfidence(phrase.Confidence, transcriber.DefaultConfidenceThreshold) {
+			transcriptTurns = append(transcriptTurns, turn)
+			continue
+		}
+		for _, word := range phrase.Words {
+			turn.Words = append(turn.Words, transcriber.TranscriptWord{
+				Text:      word.Text,
+				StartTime: float64(word.OffsetMilliseconds) / 1000.0,
+				EndTime:   float64(word.OffsetMilliseconds+word.DurationMilliseconds) / 1000.0,
+			})
 		}
 		transcriptTurns = append(transcriptTurns, turn)
 	}
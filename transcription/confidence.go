@@ -0,0 +1,71 @@
+package transcriber
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultConfidenceThreshold is the confidence below which a passage is worth checking against the audio.
+const DefaultConfidenceThreshold = 0.7
+
+// LowConfidenceSpan is a passage of a diarized transcript the backend was unsure about.
+type LowConfidenceSpan struct {
+	TurnIndex  int     `json:"turnIndex"`
+	Speaker    string  `json:"speaker"`
+	StartTime  float64 `json:"startTime"`
+	EndTime    float64 `json:"endTime"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+}
+
+// LowConfidenceSpans lists the turns recognized with a confidence below the threshold. Turns
+// without any confidence information are skipped.
+func LowConfidenceSpans(turns []TranscriptTurn, threshold float64) []LowConfidenceSpan {
+	spans := []LowConfidenceSpan{}
+	for i, turn := range turns {
+		if !IsLowConfidence(turn.Confidence, threshold) {
+			continue
+		}
+		spans = append(spans, LowConfidenceSpan{
+			TurnIndex:  i,
+			Speaker:    turn.Speaker,
+			StartTime:  turn.StartTime,
+			EndTime:    turn.EndTime,
+			Text:       turn.Text,
+			Confidence: turn.Confidence,
+		})
+	}
+	return spans
+}
+
+// IsLowConfidence reports whether a reported confidence is below the threshold. Zero means no
+// confidence was reported and is never low.
+func IsLowConfidence(confidence, threshold float64) bool {
+	return confidence > 0 && confidence < threshold
+}
+
+// CarryConfidence copies confidence and word timings from the previous version of a transcript onto
+// turns a provider left unchanged while editing. Edited turns lose them: the provider has reviewed
+// that passage and the old word timings no longer match its text.
+func CarryConfidence(previous, updated []TranscriptTurn) []TranscriptTurn {
+	unchanged := make(map[string]TranscriptTurn, len(previous))
+	for _, turn := range previous {
+		unchanged[turnKey(turn)] = turn
+	}
+
+	carried := make([]TranscriptTurn, len(updated))
+	for i, turn := range updated {
+		turn.Confidence = 0
+		turn.Words = nil
+		if original, ok := unchanged[turnKey(turn)]; ok {
+			turn.Confidence = original.Confidence
+			turn.Words = original.Words
+		}
+		carried[i] = turn
+	}
+	return carried
+}
+
+func turnKey(turn TranscriptTurn) string {
+	return fmt.Sprintf("%.3f|%s", turn.StartTime, strings.Join(strings.Fields(turn.Text), " "))
+}
@@ -0,0 +1,70 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowConfidenceSpans(t *testing.T) {
+	testCases := []struct {
+		name  string
+		turns []TranscriptTurn
+		want  []LowConfidenceSpan
+	}{
+		{
+			name: "flags whole turns by phrase confidence",
+			turns: []TranscriptTurn{
+				{Speaker: RoleProvider, StartTime: 0, EndTime: 2, Text: "Any side effects?", Confidence: 0.94},
+				{Speaker: RolePatient, StartTime: 2, EndTime: 5, Text: "Some nausea on the sertraline.", Confidence: 0.52},
+			},
+			want: []LowConfidenceSpan{
+				{TurnIndex: 1, Speaker: RolePatient, StartTime: 2, EndTime: 5, Text: "Some nausea on the sertraline.", Confidence: 0.52},
+			},
+		},
+		{
+			name: "keeps the whole turn even when it carries word timings",
+			turns: []TranscriptTurn{
+				{Speaker: RolePatient, StartTime: 0, EndTime: 3, Text: "I take fifty milligrams daily", Confidence: 0.65, Words: []TranscriptWord{
+					{Text: "I", StartTime: 0, EndTime: 0.2},
+					{Text: "take", StartTime: 0.2, EndTime: 0.5},
+					{Text: "fifty", StartTime: 0.5, EndTime: 1},
+					{Text: "milligrams", StartTime: 1, EndTime: 1.8},
+					{Text: "daily", StartTime: 1.8, EndTime: 3},
+				}},
+			},
+			want: []LowConfidenceSpan{
+				{TurnIndex: 0, Speaker: RolePatient, StartTime: 0, EndTime: 3, Text: "I take fifty milligrams daily", Confidence: 0.65},
+			},
+		},
+		{
+			name:  "skips turns without confidence",
+			turns: []TranscriptTurn{{Speaker: RoleProvider, EndTime: 1, Text: "Hello."}},
+			want:  []LowConfidenceSpan{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, LowConfidenceSpans(tc.turns, DefaultConfidenceThreshold))
+		})
+	}
+}
+
+func TestCarryConfidence(t *testing.T) {
+	words := []TranscriptWord{{Text: "Hello.", EndTime: 1}}
+	previous := []TranscriptTurn{
+		{Speaker: "Speaker1", EndTime: 1, Text: "Hello.", Confidence: 0.9, Words: words},
+		{Speaker: "Speaker2", StartTime: 1, EndTime: 2, Text: "Hi there.", Confidence: 0.4},
+	}
+	updated := []TranscriptTurn{
+		{Speaker: RoleProvider, EndTime: 1, Text: "Hello."},
+		{Speaker: RolePatient, StartTime: 1, EndTime: 2, Text: "Hi, thanks.", Confidence: 0.4},
+	}
+
+	carried := CarryConfidence(previous, updated)
+	assert.Equal(t, 0.9, carried[0].Confidence)
+	assert.Equal(t, words, carried[0].Words)
+	assert.Equal(t, RoleProvider, carried[0].Speaker)
+	assert.Zero(t, carried[1].Confidence, "edited turns have been reviewed")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	transcriber "Medscribe/transcription"
 )
@@ -30,12 +31,53 @@ func NewDeepgramTranscriber(apiUrl, apiKey string) transcriber.Transcription {
 	return &deepGramTranscriber{
 		apiKey: apiKey, apiUrl: apiUrl}
 }
-func (t *deepGramTranscriber) Transcribe(ctx context.Context, audio []byte) (string, error) {
+
+// requestURL adds the provider vocabulary to the configured API url as Deepgram keywords, and the
+// expected language when it is not English; several expected languages use multilingual transcription.
+func (t *deepGramTranscriber) requestURL(opts transcriber.Options) (string, error) {
+	language := deepgramLanguage(opts.Languages)
+	if len(opts.Vocabulary) == 0 && language == "" {
+		return t.apiUrl, nil
+	}
+	u, err := url.Parse(t.apiUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse api url: %w", err)
+	}
+	query := u.Query()
+	for _, term := range opts.Vocabulary {
+		query.Add("keywords", term)
+	}
+	if language != "" {
+		query.Set("language", language)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// deepgramLanguage returns the language parameter for the expected languages, or an empty string
+// to keep the configured default for English visits.
+func deepgramLanguage(languages []string) string {
+	switch {
+	case len(languages) > 1:
+		return "multi"
+	case len(languages) == 1 && languages[0] != transcriber.LanguageEnglish:
+		return languages[0]
+	default:
+		return ""
+	}
+}
+
+func (t *deepGramTranscriber) Transcribe(ctx context.Context, audio []byte, opts transcriber.Options) (string, error) {
 	if len(audio) == 0 {
 		return "", nil // No need to exhaust API usage
 	}
 
-	req, err := http.NewRequest("POST", t.apiUrl, bytes.NewReader(audio))
+	apiURL, err := t.requestURL(opts)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", apiURL, bytes.NewReader(audio))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -68,6 +110,6 @@ func (t *deepGramTranscriber) Transcribe(ctx context.Context, audio []byte) (str
 	return "", fmt.Errorf("transcript not found in the response")
 }
 
-func (t *deepGramTranscriber) TranscribeWithDiarization(ctx context.Context, audio []byte) ([]transcriber.TranscriptTurn, error) {
+func (t *deepGramTranscriber) TranscribeWithDiarization(ctx context.Context, audio []byte, opts transcriber.Options) ([]transcriber.TranscriptTurn, error) {
 	return nil, nil
 }
@@ -1,6 +1,7 @@
 package deepgram
 
 import (
+	transcriber "Medscribe/transcription"
 	"context"
 	"os"
 	"testing"
@@ -68,7 +69,7 @@ func TestTranscribe(t *testing.T) {
 			ctx := context.Background()
 			txn := NewDeepgramTranscriber(apiUrl, apiKey)
 
-			transcript, err := txn.Transcribe(ctx, tc.audioData)
+			transcript, err := txn.Transcribe(ctx, tc.audioData, transcriber.Options{})
 			assert.Equal(t, err, tc.expectErr)
 			assert.Equal(t, tc.expectEmpty, len(transcript) == 0)
 		})
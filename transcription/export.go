@@ -0,0 +1,180 @@
+package transcriber
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Transcript export formats.
+const (
+	ExportFormatVTT  = "vtt"
+	ExportFormatSRT  = "srt"
+	ExportFormatText = "txt"
+	ExportFormatJSON = "json"
+)
+
+// ExportFormats lists the formats a transcript can be exported as.
+var ExportFormats = []string{ExportFormatVTT, ExportFormatSRT, ExportFormatText, ExportFormatJSON}
+
+var exportContentTypes = map[string]string{
+	ExportFormatVTT:  "text/vtt; charset=utf-8",
+	ExportFormatSRT:  "application/x-subrip; charset=utf-8",
+	ExportFormatText: "text/plain; charset=utf-8",
+	ExportFormatJSON: "application/json",
+}
+
+// ExportedTranscript is a rendered transcript ready to be served as a download.
+type ExportedTranscript struct {
+	Content     []byte
+	ContentType string
+	Extension   string
+}
+
+// NormalizedTranscript is the JSON export shape, independent of how the transcript is stored.
+type NormalizedTranscript struct {
+	Diarized bool             `json:"diarized"`
+	Speakers []string         `json:"speakers"`
+	Turns    []TranscriptTurn `json:"turns"`
+}
+
+// ExportTranscript renders transcript turns in the requested format. A transcript made without
+// diarization is passed as a single turn without a speaker.
+func ExportTranscript(turns []TranscriptTurn, diarized bool, format string) (ExportedTranscript, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return ExportedTranscript{}, fmt.Errorf("unsupported transcript export format %q, expected one of: %s", format, strings.Join(ExportFormats, ", "))
+	}
+
+	var content []byte
+	switch format {
+	case ExportFormatVTT:
+		content = []byte(FormatWebVTT(turns))
+	case ExportFormatSRT:
+		content = []byte(FormatSRT(turns))
+	case ExportFormatText:
+		content = []byte(FormatSpeakerText(turns))
+	case ExportFormatJSON:
+		normalized := NormalizeTranscript(turns, diarized)
+		data, err := json.MarshalIndent(normalized, "", "  ")
+		if err != nil {
+			return ExportedTranscript{}, fmt.Errorf("failed to marshal transcript: %w", err)
+		}
+		content = data
+	}
+	return ExportedTranscript{Content: content, ContentType: contentType, Extension: format}, nil
+}
+
+// NormalizeTranscript trims the turns, drops empty ones and lists the speakers in order of appearance.
+func NormalizeTranscript(turns []TranscriptTurn, diarized bool) NormalizedTranscript {
+	normalized := NormalizedTranscript{Diarized: diarized, Speakers: []string{}, Turns: []TranscriptTurn{}}
+	seen := map[string]bool{}
+	for _, turn := range turns {
+		text := cueText(turn.Text)
+		if text == "" {
+			continue
+		}
+		speaker := strings.TrimSpace(turn.Speaker)
+		start, end := cueTimes(turn)
+		normalized.Turns = append(normalized.Turns, TranscriptTurn{Speaker: speaker, StartTime: start, EndTime: end, Text: text})
+		if speaker != "" && !seen[speaker] {
+			seen[speaker] = true
+			normalized.Speakers = append(normalized.Speakers, speaker)
+		}
+	}
+	return normalized
+}
+
+// FormatWebVTT renders the turns as WebVTT cues, using voice spans for the speakers.
+func FormatWebVTT(turns []TranscriptTurn) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	cue := 0
+	for _, turn := range turns {
+		text := cueText(turn.Text)
+		if text == "" {
+			continue
+		}
+		cue++
+		start, end := cueTimes(turn)
+		fmt.Fprintf(&b, "\n%d\n%s --> %s\n", cue, formatTimestamp(start, "."), formatTimestamp(end, "."))
+		if speaker := strings.TrimSpace(turn.Speaker); speaker != "" {
+			fmt.Fprintf(&b, "<v %s>%s\n", escapeVTT(speaker), escapeVTT(text))
+		} else {
+			b.WriteString(escapeVTT(text) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// FormatSRT renders the turns as SubRip cues with the speaker prefixed to the text.
+func FormatSRT(turns []TranscriptTurn) string {
+	var b strings.Builder
+	cue := 0
+	for _, turn := range turns {
+		text := cueText(turn.Text)
+		if text == "" {
+			continue
+		}
+		cue++
+		if cue > 1 {
+			b.WriteString("\n")
+		}
+		start, end := cueTimes(turn)
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n", cue, formatTimestamp(start, ","), formatTimestamp(end, ","), speakerPrefixed(turn.Speaker, text))
+	}
+	return b.String()
+}
+
+// FormatSpeakerText renders the turns as readable text, one speaker-prefixed line per turn
+// in the same style as CompressDiarizedText.
+func FormatSpeakerText(turns []TranscriptTurn) string {
+	var b strings.Builder
+	for _, turn := range turns {
+		text := cueText(turn.Text)
+		if text == "" {
+			continue
+		}
+		b.WriteString(speakerPrefixed(turn.Speaker, text) + "\n")
+	}
+	return b.String()
+}
+
+func speakerPrefixed(speaker, text string) string {
+	if speaker = strings.TrimSpace(speaker); speaker == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s]: %s", speaker, text)
+}
+
+// cueText collapses whitespace so a blank line inside a turn cannot terminate a cue early.
+func cueText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// cueTimes clamps the turn timings so every cue has a valid, non-negative range.
+func cueTimes(turn TranscriptTurn) (float64, float64) {
+	start := math.Max(turn.StartTime, 0)
+	end := math.Max(turn.EndTime, start)
+	return start, end
+}
+
+// formatTimestamp renders seconds as HH:MM:SS followed by the separator and milliseconds.
+func formatTimestamp(seconds float64, separator string) string {
+	millis := int64(math.Round(seconds * 1000))
+	hours := millis / 3_600_000
+	millis -= hours * 3_600_000
+	minutes := millis / 60_000
+	millis -= minutes * 60_000
+	secs := millis / 1000
+	millis -= secs * 1000
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, secs, separator, millis)
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func escapeVTT(text string) string {
+	return vttEscaper.Replace(text)
+}
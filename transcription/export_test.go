@@ -0,0 +1,78 @@
+package transcriber
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exportTurns = []TranscriptTurn{
+	{Speaker: RoleProvider, StartTime: 0.5, EndTime: 3.25, Text: "How have you been sleeping?"},
+	{Speaker: RolePatient, StartTime: 3.5, EndTime: 3725.1, Text: "Better,\n\nbut <still> tired & anxious."},
+	{Speaker: RolePatient, StartTime: 3726, EndTime: 3727, Text: "   "},
+}
+
+func TestExportTranscript(t *testing.T) {
+	testCases := []struct {
+		name        string
+		format      string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "webvtt",
+			format:      ExportFormatVTT,
+			contentType: "text/vtt; charset=utf-8",
+			want: "WEBVTT\n\n" +
+				"1\n00:00:00.500 --> 00:00:03.250\n<v provider>How have you been sleeping?\n\n" +
+				"2\n00:00:03.500 --> 01:02:05.100\n<v patient>Better, but &lt;still&gt; tired &amp; anxious.\n",
+		},
+		{
+			name:        "srt",
+			format:      ExportFormatSRT,
+			contentType: "application/x-subrip; charset=utf-8",
+			want: "1\n00:00:00,500 --> 00:00:03,250\n[provider]: How have you been sleeping?\n\n" +
+				"2\n00:00:03,500 --> 01:02:05,100\n[patient]: Better, but <still> tired & anxious.\n",
+		},
+		{
+			name:        "plain text",
+			format:      "TXT",
+			contentType: "text/plain; charset=utf-8",
+			want:        "[provider]: How have you been sleeping?\n[patient]: Better, but <still> tired & anxious.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exported, err := ExportTranscript(exportTurns, true, tc.format)
+			require.NoError(t, err)
+			assert.Equal(t, tc.contentType, exported.ContentType)
+			assert.Equal(t, tc.want, string(exported.Content))
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		exported, err := ExportTranscript(exportTurns, true, ExportFormatJSON)
+		require.NoError(t, err)
+
+		var normalized NormalizedTranscript
+		require.NoError(t, json.Unmarshal(exported.Content, &normalized))
+		assert.True(t, normalized.Diarized)
+		assert.Equal(t, []string{RoleProvider, RolePatient}, normalized.Speakers)
+		require.Len(t, normalized.Turns, 2)
+		assert.Equal(t, "Better, but <still> tired & anxious.", normalized.Turns[1].Text)
+	})
+
+	t.Run("undiarized transcript has no speaker prefix", func(t *testing.T) {
+		exported, err := ExportTranscript([]TranscriptTurn{{EndTime: 12, Text: "Patient reports improved mood."}}, false, ExportFormatVTT)
+		require.NoError(t, err)
+		assert.Equal(t, "WEBVTT\n\n1\n00:00:00.000 --> 00:00:12.000\nPatient reports improved mood.\n", string(exported.Content))
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := ExportTranscript(exportTurns, true, "docx")
+		assert.Error(t, err)
+	})
+}
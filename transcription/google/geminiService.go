@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"google.golang.org/genai"
 )
@@ -31,6 +32,50 @@ const prompt = `Please provide a detailed diarized transcript of the following t
 **Audio:** (The audio data will be provided inline)
 `
 
+const vocabularyPrompt = `
+**Provider Vocabulary:** The provider regularly uses the terms below (medication names, colleagues, facilities). When you hear a word or phrase that sounds like one of them, spell it exactly as written here:
+%s
+`
+
+const rosterPrompt = `
+**Session Participants:** The people taking part in this session are listed below. The list overrides the two-way context and the speaker values above: label each turn's "speaker" with the ID of the participant speaking, from this list:
+%s`
+
+const languagePrompt = `
+**Languages:** This visit may be conducted in %s, and speakers may switch languages mid-visit. This overrides the primary language stated above. Transcribe every utterance in the language it was spoken; never translate.
+`
+
+// buildPrompt appends the provider vocabulary, the session roster and the expected languages, if any,
+// to the transcription prompt.
+func buildPrompt(opts transcriber.Options) string {
+	built := prompt
+	if languages := languageNames(opts.Languages); languages != "" {
+		built += fmt.Sprintf(languagePrompt, languages)
+	}
+	if len(opts.Vocabulary) > 0 {
+		built += fmt.Sprintf(vocabularyPrompt, strings.Join(opts.Vocabulary, ", "))
+	}
+	if len(opts.Participants) > 0 {
+		built += fmt.Sprintf(rosterPrompt, transcriber.FormatRoster(opts.Participants))
+	}
+	return built
+}
+
+// languageNames lists the expected languages by name, or returns an empty string when only
+// English is expected.
+func languageNames(languages []string) string {
+	names := make([]string, 0, len(languages))
+	englishOnly := true
+	for _, language := range languages {
+		names = append(names, transcriber.LanguageName(language))
+		englishOnly = englishOnly && language == transcriber.LanguageEnglish
+	}
+	if englishOnly {
+		return ""
+	}
+	return strings.Join(names, " and ")
+}
+
 type geminiTranscriberStore struct {
 	client *genai.Client
 }
@@ -39,13 +84,13 @@ func NewGeminiTranscriberStore(client *genai.Client) transcriber.Transcription {
 	return &geminiTranscriberStore{client: client}
 }
 
-func (i *geminiTranscriberStore) Transcribe(ctx context.Context, audioData []byte) (string, error) {
+func (i *geminiTranscriberStore) Transcribe(ctx context.Context, audioData []byte, opts transcriber.Options) (string, error) {
 	if i.client == nil {
 		return "", fmt.Errorf("gemini transcriber: gemini client is not initialized")
 	}
 
 	parts := []*genai.Part{
-		{Text: prompt},
+		{Text: buildPrompt(opts)},
 		{InlineData: &genai.Blob{Data: audioData, MIMEType: "audio/wav"}},
 	}
 
@@ -59,13 +104,13 @@ func (i *geminiTranscriberStore) Transcribe(ctx context.Context, audioData []byt
 
 
 
-func (i *geminiTranscriberStore) TranscribeWithDiarization(ctx context.Context, audioData []byte) ([]transcriber.TranscriptTurn, error) {
+func (i *geminiTranscriberStore) TranscribeWithDiarization(ctx context.Context, audioData []byte, opts transcriber.Options) ([]transcriber.TranscriptTurn, error) {
 	if i.client == nil {
 		return nil, fmt.Errorf("gemini transcriber: gemini client is not initialized")
 	}
 
 	parts := []*genai.Part{
-		{Text: prompt},
+		{Text: buildPrompt(opts)},
 		{InlineData: &genai.Blob{Data: audioData, MIMEType: "audio/wav"}},
 	}
 	contents := []*genai.Content{{Role: genai.RoleUser, Parts: parts}}
@@ -1,6 +1,7 @@
 package geminiTranscriber
 
 import (
+	transcriber "Medscribe/transcription"
 	"context"
 	"fmt"
 	"log"
@@ -123,7 +124,7 @@ func TestTranscribeWithDiarization(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := context.Background()
-			transcript, err := geminiTranscriber.TranscribeWithDiarization(ctx, tc.audioData)
+			transcript, err := geminiTranscriber.TranscribeWithDiarization(ctx, tc.audioData, transcriber.Options{})
 			fmt.Println("transcript",transcript)
 			assert.Equal(t, err, tc.expectErr)
 			assert.Equal(t, tc.expectEmpty, len(transcript) == 0)
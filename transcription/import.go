@@ -0,0 +1,203 @@
+package transcriber
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ImportFormatAuto detects the format of an imported transcript from its content.
+const ImportFormatAuto = "auto"
+
+// importedSpeaker labels cues that carry timings but no speaker, so speaker resolution can assign them a role.
+const importedSpeaker = "Speaker"
+
+// wordsPerSecond estimates the length of a conversation from a transcript without timings.
+const wordsPerSecond = 2.5
+
+// ImportedTranscript is a transcript supplied instead of audio. Turns is set when the input carried
+// timings (WebVTT, SRT or diarized JSON); plain text is kept as Text.
+type ImportedTranscript struct {
+	Diarized bool
+	Turns    []TranscriptTurn
+	Text     string
+	// Duration is the length of the conversation in seconds, from the timings or estimated from the word count.
+	Duration float64
+}
+
+var (
+	cueTimingPattern = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s*-->\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+	voiceSpanPattern = regexp.MustCompile(`^<v(?:\.[^\s>]*)?\s+([^>]+)>`)
+	cueTagPattern    = regexp.MustCompile(`</?[^>]+>`)
+	bracketedSpeaker = regexp.MustCompile(`^\[([^\]]{1,40})\]:\s*`)
+	namedSpeaker     = regexp.MustCompile(`^([\p{L}][\p{L}\p{N}.' -]{0,39}):\s+`)
+)
+
+// maxSpeakerNameWords keeps a sentence such as "The plan is simple: rest" from being read as a speaker name.
+const maxSpeakerNameWords = 3
+
+// ParseTranscript parses a transcript supplied as plain text, WebVTT, SRT or diarized JSON.
+// Diarized input is validated like an edited transcript.
+func ParseTranscript(content, format string) (ImportedTranscript, error) {
+	content = strings.TrimPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "\ufeff")
+	if strings.TrimSpace(content) == "" {
+		return ImportedTranscript{}, fmt.Errorf("transcript is empty")
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == ImportFormatAuto {
+		format = detectTranscriptFormat(content)
+	}
+
+	var turns []TranscriptTurn
+	var err error
+	switch format {
+	case ExportFormatText:
+		text := strings.TrimSpace(content)
+		return ImportedTranscript{Text: text, Duration: estimateDuration(text)}, nil
+	case ExportFormatVTT, ExportFormatSRT:
+		turns, err = parseCues(content)
+	case ExportFormatJSON:
+		turns, err = parseTranscriptJSON(content)
+	default:
+		return ImportedTranscript{}, fmt.Errorf("unsupported transcript format %q, expected one of: %s", format, strings.Join(ExportFormats, ", "))
+	}
+	if err != nil {
+		return ImportedTranscript{}, err
+	}
+	if err := ValidateTranscriptTurns(turns); err != nil {
+		return ImportedTranscript{}, err
+	}
+
+	duration := 0.0
+	for _, turn := range turns {
+		duration = max(duration, turn.EndTime)
+	}
+	if duration == 0 {
+		var text strings.Builder
+		for _, turn := range turns {
+			text.WriteString(turn.Text + " ")
+		}
+		duration = estimateDuration(text.String())
+	}
+	return ImportedTranscript{Diarized: true, Turns: turns, Duration: duration}, nil
+}
+
+func detectTranscriptFormat(content string) string {
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case strings.HasPrefix(trimmed, "WEBVTT"):
+		return ExportFormatVTT
+	case strings.HasPrefix(trimmed, "[") && json.Valid([]byte(trimmed)), strings.HasPrefix(trimmed, "{"):
+		return ExportFormatJSON
+	}
+	scanner := bufio.NewScanner(strings.NewReader(trimmed))
+	for i := 0; i < 3 && scanner.Scan(); i++ {
+		if cueTimingPattern.MatchString(scanner.Text()) {
+			return ExportFormatSRT
+		}
+	}
+	return ExportFormatText
+}
+
+// parseCues reads WebVTT or SRT cues. Speakers come from WebVTT voice spans or a "[speaker]:" or
+// "Name:" prefix, and consecutive cues from the same speaker are merged into one turn.
+func parseCues(content string) ([]TranscriptTurn, error) {
+	var turns []TranscriptTurn
+	blocks := strings.Split(content, "\n\n")
+	for _, block := range blocks {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		timingLine := -1
+		for i, line := range lines {
+			if cueTimingPattern.MatchString(line) {
+				timingLine = i
+				break
+			}
+		}
+		// Headers, NOTE and STYLE blocks carry no timing line.
+		if timingLine < 0 {
+			continue
+		}
+
+		match := cueTimingPattern.FindStringSubmatch(lines[timingLine])
+		start, err := parseCueTimestamp(match[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseCueTimestamp(match[2])
+		if err != nil {
+			return nil, err
+		}
+
+		speaker, text := cueSpeaker(strings.Join(lines[timingLine+1:], " "))
+		if text == "" {
+			continue
+		}
+		if n := len(turns); n > 0 && turns[n-1].Speaker == speaker {
+			turns[n-1].Text += " " + text
+			turns[n-1].EndTime = max(turns[n-1].EndTime, end)
+			continue
+		}
+		turns = append(turns, TranscriptTurn{Speaker: speaker, StartTime: start, EndTime: max(end, start), Text: text})
+	}
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("transcript contains no cues")
+	}
+	return turns, nil
+}
+
+func cueSpeaker(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	speaker := importedSpeaker
+	if match := voiceSpanPattern.FindStringSubmatch(line); match != nil {
+		speaker = strings.TrimSpace(match[1])
+		line = line[len(match[0]):]
+	}
+	line = strings.TrimSpace(html.UnescapeString(cueTagPattern.ReplaceAllString(line, "")))
+
+	if speaker == importedSpeaker {
+		if match := bracketedSpeaker.FindStringSubmatch(line); match != nil {
+			speaker = strings.TrimSpace(match[1])
+			line = line[len(match[0]):]
+		} else if match := namedSpeaker.FindStringSubmatch(line); match != nil && len(strings.Fields(match[1])) <= maxSpeakerNameWords {
+			speaker = strings.TrimSpace(match[1])
+			line = line[len(match[0]):]
+		}
+	}
+	return speaker, cueText(line)
+}
+
+// parseCueTimestamp reads HH:MM:SS.mmm (WebVTT, hours optional) or HH:MM:SS,mmm (SRT) as seconds.
+func parseCueTimestamp(timestamp string) (float64, error) {
+	parts := strings.Split(strings.Replace(timestamp, ",", ".", 1), ":")
+	seconds := 0.0
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cue timestamp %q: %w", timestamp, err)
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds, nil
+}
+
+// parseTranscriptJSON accepts a list of turns, as stored on a report, or the normalized export shape.
+func parseTranscriptJSON(content string) ([]TranscriptTurn, error) {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") {
+		var normalized NormalizedTranscript
+		if err := json.Unmarshal([]byte(trimmed), &normalized); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transcript: %w", err)
+		}
+		return normalized.Turns, nil
+	}
+	return UnmarshalTranscript([]byte(trimmed))
+}
+
+func estimateDuration(text string) float64 {
+	return max(float64(len(strings.Fields(text)))/wordsPerSecond, 1)
+}
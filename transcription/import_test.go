@@ -0,0 +1,103 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTranscript(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		format   string
+		diarized bool
+		turns    []TranscriptTurn
+		text     string
+		duration float64
+	}{
+		{
+			name:     "plain text",
+			content:  "Patient reports improved sleep and fewer panic attacks.\n",
+			format:   ExportFormatText,
+			text:     "Patient reports improved sleep and fewer panic attacks.",
+			duration: 8 / wordsPerSecond,
+		},
+		{
+			name: "webvtt with voice spans merges consecutive cues",
+			content: "WEBVTT\n\nNOTE exported from telehealth\n\n1\n00:00:01.000 --> 00:00:03.500\n<v Dr. Smith>How are you &amp; the family?\n\n" +
+				"2\n00:00:03.600 --> 00:00:05.000\n<v Dr. Smith>Any changes?\n\n00:06.000 --> 00:09.250 align:start\n<v.loud Client><b>Much</b> better.\n",
+			format:   ImportFormatAuto,
+			diarized: true,
+			turns: []TranscriptTurn{
+				{Speaker: "Dr. Smith", StartTime: 1, EndTime: 5, Text: "How are you & the family? Any changes?"},
+				{Speaker: "Client", StartTime: 6, EndTime: 9.25, Text: "Much better."},
+			},
+			duration: 9.25,
+		},
+		{
+			name:     "srt with bracketed and named speakers",
+			content:  "1\r\n00:00:00,500 --> 00:00:02,000\r\n[provider]: Hello.\r\n\r\n2\r\n01:00:02,000 --> 01:00:04,000\r\nJane Doe: Hi,\r\nthanks.\r\n",
+			format:   ImportFormatAuto,
+			diarized: true,
+			turns: []TranscriptTurn{
+				{Speaker: RoleProvider, StartTime: 0.5, EndTime: 2, Text: "Hello."},
+				{Speaker: "Jane Doe", StartTime: 3602, EndTime: 3604, Text: "Hi, thanks."},
+			},
+			duration: 3604,
+		},
+		{
+			name:     "cues without speakers",
+			content:  "1\n00:00:00,000 --> 00:00:02,000\nThe plan is simple: rest and fluids.\n",
+			format:   ExportFormatSRT,
+			diarized: true,
+			turns:    []TranscriptTurn{{Speaker: importedSpeaker, EndTime: 2, Text: "The plan is simple: rest and fluids."}},
+			duration: 2,
+		},
+		{
+			name:     "diarized json",
+			content:  `[{"speaker":"Speaker1","startTime":0,"endTime":2.5,"text":"Hello."},{"speaker":"Speaker2","startTime":2.5,"endTime":4,"text":"Hi."}]`,
+			format:   ImportFormatAuto,
+			diarized: true,
+			turns: []TranscriptTurn{
+				{Speaker: "Speaker1", EndTime: 2.5, Text: "Hello."},
+				{Speaker: "Speaker2", StartTime: 2.5, EndTime: 4, Text: "Hi."},
+			},
+			duration: 4,
+		},
+		{
+			name:     "normalized json export",
+			content:  `{"diarized":true,"speakers":["provider"],"turns":[{"speaker":"provider","startTime":0,"endTime":0,"text":"One two three four five."}]}`,
+			format:   ExportFormatJSON,
+			diarized: true,
+			turns:    []TranscriptTurn{{Speaker: RoleProvider, Text: "One two three four five."}},
+			duration: 5 / wordsPerSecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			imported, err := ParseTranscript(tc.content, tc.format)
+			require.NoError(t, err)
+			assert.Equal(t, tc.diarized, imported.Diarized)
+			assert.Equal(t, tc.turns, imported.Turns)
+			assert.Equal(t, tc.text, imported.Text)
+			assert.InDelta(t, tc.duration, imported.Duration, 1e-9)
+		})
+	}
+
+	errorCases := map[string][2]string{
+		"empty":              {"  \n", ImportFormatAuto},
+		"unsupported format": {"hello", "docx"},
+		"invalid json":       {`[{"speaker":`, ExportFormatJSON},
+		"turn without text":  {`[{"speaker":"provider","startTime":0,"endTime":1,"text":""}]`, ExportFormatJSON},
+		"vtt without cues":   {"WEBVTT\n\nNOTE nothing here\n", ExportFormatVTT},
+	}
+	for name, input := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTranscript(input[0], input[1])
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package transcriber
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Supported languages, as ISO 639-1 codes.
+const (
+	LanguageEnglish       = "en"
+	LanguageSpanish       = "es"
+	LanguageHaitianCreole = "ht"
+	LanguageFrench        = "fr"
+	LanguagePortuguese    = "pt"
+)
+
+// languageNames maps every supported language to its English name.
+var languageNames = map[string]string{
+	LanguageEnglish:       "English",
+	LanguageSpanish:       "Spanish",
+	LanguageHaitianCreole: "Haitian Creole",
+	LanguageFrench:        "French",
+	LanguagePortuguese:    "Portuguese",
+}
+
+// languageLocales maps languages to the locale sent to backends that need one.
+var languageLocales = map[string]string{
+	LanguageEnglish:    "en-US",
+	LanguageSpanish:    "es-US",
+	LanguageFrench:     "fr-FR",
+	LanguagePortuguese: "pt-BR",
+}
+
+// languageMarkers are frequent function words of each language. Words shared between languages
+// count for all of them; the distinctive ones decide.
+var languageMarkers = map[string][]string{
+	LanguageEnglish: {
+		"the", "and", "is", "you", "i", "to", "of", "it", "that", "have", "with", "what", "how", "are",
+		"for", "this", "my", "your", "was", "be", "do", "can", "we", "he", "she", "they", "yes", "been",
+		"any", "about", "take", "feel", "feeling", "today", "okay", "just", "don't", "i'm", "it's",
+	},
+	LanguageSpanish: {
+		"el", "la", "los", "las", "que", "y", "es", "usted", "yo", "tengo", "de", "en", "no", "por",
+		"para", "con", "una", "un", "muy", "pero", "cómo", "como", "está", "estoy", "mi", "su", "sí",
+		"bien", "tiene", "hace", "qué", "del", "al", "lo", "me", "se", "cuando", "también", "dolor",
+	},
+	LanguageHaitianCreole: {
+		"mwen", "ou", "li", "nou", "yo", "pa", "se", "ak", "nan", "gen", "pou", "sa", "konsa", "kijan",
+		"kòman", "bon", "wi", "non", "fè", "tankou", "paske", "anpil", "doktè", "malad", "kounye",
+		"genyen", "pran", "m", "w", "te", "ap", "kò", "tèt", "jodi", "byen",
+	},
+	LanguageFrench: {
+		"le", "la", "les", "et", "est", "je", "vous", "pas", "de", "des", "une", "un", "que", "qui",
+		"avec", "pour", "mais", "très", "oui", "il", "elle", "nous", "c'est", "j'ai", "au", "du",
+		"bien", "comment", "ça", "mon", "votre", "suis", "avez",
+	},
+	LanguagePortuguese: {
+		"o", "os", "as", "e", "é", "você", "eu", "não", "de", "do", "da", "que", "com", "para", "uma",
+		"um", "muito", "mas", "sim", "tenho", "está", "estou", "bem", "dor", "meu", "minha", "isso",
+		"também", "obrigado", "obrigada",
+	},
+}
+
+// MinLanguageShare is the share of a transcript's words a language needs to count as spoken in it.
+const MinLanguageShare = 0.15
+
+// minDetectionMarkers is how many marker words a segment needs before its language is trusted.
+const minDetectionMarkers = 2
+
+var (
+	languageWordPattern = regexp.MustCompile(`[\p{L}']+`)
+	sentencePattern     = regexp.MustCompile(`[^.!?\n]+`)
+)
+
+// LanguageShare is the share of a transcript's words spoken in a language.
+type LanguageShare struct {
+	Language string  `json:"language"`
+	Share    float64 `json:"share"`
+}
+
+// LanguageDetection describes the languages spoken in a transcript. Primary is empty when no
+// language could be detected.
+type LanguageDetection struct {
+	Primary   string          `json:"primary"`
+	Languages []LanguageShare `json:"languages"`
+}
+
+// Mixed reports whether more than one language was spoken, e.g. a provider switching languages.
+func (d LanguageDetection) Mixed() bool {
+	return len(d.Languages) > 1
+}
+
+// Codes returns the detected languages, most spoken first.
+func (d LanguageDetection) Codes() []string {
+	codes := make([]string, len(d.Languages))
+	for i, language := range d.Languages {
+		codes[i] = language.Language
+	}
+	return codes
+}
+
+// NormalizeLanguage maps a language code, locale or English name (e.g. "es", "es-MX", "Spanish",
+// "Kreyòl") onto a supported language code.
+func NormalizeLanguage(language string) (string, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, _, found := strings.Cut(language, "-"); found && len(code) == 2 {
+		language = code
+	}
+	if _, ok := languageNames[language]; ok {
+		return language, true
+	}
+	for code, name := range languageNames {
+		if language == strings.ToLower(name) {
+			return code, true
+		}
+	}
+	switch language {
+	case "creole", "kreyol", "kreyòl", "haitian":
+		return LanguageHaitianCreole, true
+	case "español", "espanol":
+		return LanguageSpanish, true
+	case "français", "francais":
+		return LanguageFrench, true
+	case "português", "portugues":
+		return LanguagePortuguese, true
+	}
+	return "", false
+}
+
+// LanguageName returns the English name of a supported language code, or the code itself.
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// LanguageLocale returns the backend locale of a language, if the backends support one.
+func LanguageLocale(code string) (string, bool) {
+	locale, ok := languageLocales[code]
+	return locale, ok
+}
+
+// DetectLanguage detects the languages of a plain transcript, sentence by sentence so that
+// switching languages mid-visit is noticed.
+func DetectLanguage(text string) LanguageDetection {
+	return detectSegments(sentencePattern.FindAllString(text, -1))
+}
+
+// DetectTurnsLanguage detects the languages of a diarized transcript, turn by turn.
+func DetectTurnsLanguage(turns []TranscriptTurn) LanguageDetection {
+	segments := make([]string, len(turns))
+	for i, turn := range turns {
+		segments[i] = turn.Text
+	}
+	return detectSegments(segments)
+}
+
+// SpeakerLanguages returns the primary language of each speaker of a diarized transcript.
+// Speakers whose language could not be detected are left out.
+func SpeakerLanguages(turns []TranscriptTurn) map[string]string {
+	bySpeaker := make(map[string][]TranscriptTurn)
+	for _, turn := range turns {
+		bySpeaker[turn.Speaker] = append(bySpeaker[turn.Speaker], turn)
+	}
+	languages := make(map[string]string, len(bySpeaker))
+	for speaker, spoken := range bySpeaker {
+		if detection := DetectTurnsLanguage(spoken); detection.Primary != "" {
+			languages[speaker] = detection.Primary
+		}
+	}
+	return languages
+}
+
+// detectSegments attributes every segment's words to the segment's language and reports the
+// languages holding at least MinLanguageShare of the attributed words.
+func detectSegments(segments []string) LanguageDetection {
+	words := make(map[string]int)
+	total := 0
+	for _, segment := range segments {
+		language, count := segmentLanguage(segment)
+		if language == "" {
+			continue
+		}
+		words[language] += count
+		total += count
+	}
+	if total == 0 {
+		return LanguageDetection{Languages: []LanguageShare{}}
+	}
+
+	shares := make([]LanguageShare, 0, len(words))
+	for language, count := range words {
+		share := float64(count) / float64(total)
+		if share >= MinLanguageShare {
+			shares = append(shares, LanguageShare{Language: language, Share: share})
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Share != shares[j].Share {
+			return shares[i].Share > shares[j].Share
+		}
+		return shares[i].Language < shares[j].Language
+	})
+	return LanguageDetection{Primary: shares[0].Language, Languages: shares}
+}
+
+// segmentLanguage returns the language with the most marker words in a segment and the number of
+// words in the segment. Segments too short to tell, or tied between languages, are not attributed.
+func segmentLanguage(segment string) (string, int) {
+	words := languageWordPattern.FindAllString(strings.ToLower(segment), -1)
+	scores := make(map[string]int)
+	for _, word := range words {
+		for language, markers := range languageMarkers {
+			for _, marker := range markers {
+				if word == marker {
+					scores[language]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < minDetectionMarkers || tied {
+		return "", len(words)
+	}
+	return best, len(words)
+}
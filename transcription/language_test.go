@@ -0,0 +1,102 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	testCases := []struct {
+		language string
+		expected string
+		ok       bool
+	}{
+		{language: "es", expected: LanguageSpanish, ok: true},
+		{language: "es-MX", expected: LanguageSpanish, ok: true},
+		{language: " Spanish ", expected: LanguageSpanish, ok: true},
+		{language: "Haitian Creole", expected: LanguageHaitianCreole, ok: true},
+		{language: "kreyòl", expected: LanguageHaitianCreole, ok: true},
+		{language: "pt-BR", expected: LanguagePortuguese, ok: true},
+		{language: "de", ok: false},
+		{language: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.language, func(t *testing.T) {
+			code, ok := NormalizeLanguage(tc.language)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, code)
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		text      string
+		primary   string
+		languages []string
+	}{
+		{
+			name:      "english",
+			text:      "How have you been feeling since the last visit? I have been taking the medication every day.",
+			primary:   LanguageEnglish,
+			languages: []string{LanguageEnglish},
+		},
+		{
+			name:      "spanish",
+			text:      "Tengo mucho dolor de cabeza por las noches. No puedo dormir bien y estoy cansada.",
+			primary:   LanguageSpanish,
+			languages: []string{LanguageSpanish},
+		},
+		{
+			name:      "haitian creole",
+			text:      "Mwen gen anpil doulè nan tèt mwen. Mwen pa ka dòmi byen depi yon semèn.",
+			primary:   LanguageHaitianCreole,
+			languages: []string{LanguageHaitianCreole},
+		},
+		{
+			name: "switching languages mid-visit",
+			text: "How are you feeling with the new medication today? " +
+				"Me siento un poco mejor, pero tengo dolor en el estómago por las mañanas. " +
+				"Okay, is the pain worse after you take it?",
+			primary:   LanguageEnglish,
+			languages: []string{LanguageEnglish, LanguageSpanish},
+		},
+		{
+			name:      "nothing to detect",
+			text:      "Hmm. Okay.",
+			primary:   "",
+			languages: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			detection := DetectLanguage(tc.text)
+			assert.Equal(t, tc.primary, detection.Primary)
+			assert.Equal(t, tc.languages, detection.Codes())
+			assert.Equal(t, len(tc.languages) > 1, detection.Mixed())
+		})
+	}
+}
+
+func TestSpeakerLanguages(t *testing.T) {
+	turns := []TranscriptTurn{
+		{Speaker: RoleProvider, Text: "What brings you in today? Have you had any fever?"},
+		{Speaker: RolePatient, Text: "Mwen gen lafyèv depi twa jou epi tèt mwen fè m mal."},
+		{Speaker: RoleInterpreter, Text: "Ok."},
+	}
+
+	assert.Equal(t, map[string]string{
+		RoleProvider: LanguageEnglish,
+		RolePatient:  LanguageHaitianCreole,
+	}, SpeakerLanguages(turns))
+}
+
+func TestOptionsLocales(t *testing.T) {
+	assert.Equal(t, []string{"en-US"}, Options{}.Locales())
+	assert.Equal(t, []string{"en-US", "es-US"}, Options{Languages: []string{LanguageEnglish, LanguageSpanish}}.Locales())
+	assert.Equal(t, []string{"en-US"}, Options{Languages: []string{LanguageHaitianCreole}}.Locales())
+}
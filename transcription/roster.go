@@ -0,0 +1,230 @@
+package transcriber
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxParticipants caps the size of a session roster.
+const MaxParticipants = 16
+
+// Participant is one person on a session roster. Once diarization is mapped to the roster,
+// the turns they spoke carry their ID as the speaker label.
+type Participant struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+	Name string `json:"name,omitempty"`
+}
+
+// DisplayName returns the participant's name, or their ID when no name was given.
+func (p Participant) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// NormalizeRoster validates a roster and assigns every participant a stable ID. A role held by
+// a single participant is used as the ID itself, so one-to-one visits keep the familiar
+// "provider"/"patient" labels; roles shared by several participants are numbered, e.g. "patient_2".
+func NormalizeRoster(roster []Participant) ([]Participant, error) {
+	if len(roster) > MaxParticipants {
+		return nil, fmt.Errorf("roster cannot have more than %d participants", MaxParticipants)
+	}
+
+	normalized := make([]Participant, len(roster))
+	counts := make(map[string]int)
+	for i, participant := range roster {
+		role, ok := NormalizeSpeakerRole(participant.Role)
+		if !ok {
+			return nil, fmt.Errorf("invalid participant role: %s", participant.Role)
+		}
+		normalized[i] = Participant{Role: role, Name: strings.Join(strings.Fields(participant.Name), " ")}
+		counts[role]++
+	}
+
+	seen := make(map[string]int)
+	for i := range normalized {
+		role := normalized[i].Role
+		seen[role]++
+		normalized[i].ID = role
+		if counts[role] > 1 {
+			normalized[i].ID = fmt.Sprintf("%s_%d", role, seen[role])
+		}
+	}
+	return normalized, nil
+}
+
+// IsGroupSession reports whether the roster has several patients, as in group therapy.
+func IsGroupSession(roster []Participant) bool {
+	return len(RosterPatients(roster)) > 1
+}
+
+// RosterPatients returns the participants with the patient role, in roster order.
+func RosterPatients(roster []Participant) []Participant {
+	patients := make([]Participant, 0)
+	for _, participant := range roster {
+		if participant.Role == RolePatient {
+			patients = append(patients, participant)
+		}
+	}
+	return patients
+}
+
+// RosterParticipant looks up a participant by ID.
+func RosterParticipant(roster []Participant, id string) (Participant, bool) {
+	for _, participant := range roster {
+		if participant.ID == id {
+			return participant, true
+		}
+	}
+	return Participant{}, false
+}
+
+// MapSpeakersToRoster maps the raw speaker labels of a diarized transcript onto roster IDs.
+// Labels that already name a participant (by ID or name) are mapped directly. Otherwise a speaker
+// who introduces themselves by name, or answers right after being addressed by name, is matched
+// to that participant, and the rest fall back to the role ResolveSpeakerRoles gives them.
+// Confident is false when a speaker had to be matched among several participants of the same
+// role without any evidence, or could not be matched at all.
+func MapSpeakersToRoster(turns []TranscriptTurn, roster []Participant) SpeakerResolution {
+	labels := SpeakerLabels(turns)
+	if direct, ok := directRosterMapping(labels, roster); ok {
+		return SpeakerResolution{Roles: direct, Confident: true}
+	}
+
+	resolution := ResolveSpeakerRoles(turns)
+	confident := resolution.Confident
+	mapping := make(map[string]string, len(labels))
+	used := make(map[string]bool, len(roster))
+
+	// Name evidence first, strongest pairs winning.
+	type candidate struct {
+		label string
+		id    string
+		score int
+	}
+	candidates := make([]candidate, 0)
+	for label, scores := range nameEvidence(turns, roster) {
+		for id, score := range scores {
+			candidates = append(candidates, candidate{label: label, id: id, score: score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].label != candidates[j].label {
+			return candidates[i].label < candidates[j].label
+		}
+		return candidates[i].id < candidates[j].id
+	})
+	for _, c := range candidates {
+		if _, mapped := mapping[c.label]; mapped || used[c.id] {
+			continue
+		}
+		mapping[c.label] = c.id
+		used[c.id] = true
+	}
+
+	// Remaining speakers take the next free participant with their heuristic role. Patients and
+	// other attendees are interchangeable here, since the heuristics only ever pick one patient.
+	for _, label := range labels {
+		if _, mapped := mapping[label]; mapped {
+			continue
+		}
+		role := resolution.Roles[label]
+		free := freeParticipants(roster, used, role)
+		if len(free) == 0 && (role == RolePatient || role == RoleOther) {
+			free = append(freeParticipants(roster, used, RolePatient), freeParticipants(roster, used, RoleOther)...)
+		}
+		if len(free) == 0 {
+			confident = false
+			continue
+		}
+		if len(free) > 1 {
+			confident = false
+		}
+		mapping[label] = free[0].ID
+		used[free[0].ID] = true
+	}
+
+	return SpeakerResolution{Roles: mapping, Confident: confident}
+}
+
+// directRosterMapping maps labels that are already participant IDs or names.
+func directRosterMapping(labels []string, roster []Participant) (map[string]string, bool) {
+	mapping := make(map[string]string, len(labels))
+	for _, label := range labels {
+		matched := false
+		for _, participant := range roster {
+			if label == participant.ID || (participant.Name != "" && strings.EqualFold(label, participant.Name)) {
+				mapping[label] = participant.ID
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+	return mapping, len(labels) > 0
+}
+
+// nameEvidence scores, per speaker label, how strongly each named participant is tied to it.
+// Introducing oneself by first name counts double; being addressed by first name in the
+// previous turn by someone else counts once.
+func nameEvidence(turns []TranscriptTurn, roster []Participant) map[string]map[string]int {
+	evidence := make(map[string]map[string]int)
+	add := func(label, id string, score int) {
+		if evidence[label] == nil {
+			evidence[label] = make(map[string]int)
+		}
+		evidence[label][id] += score
+	}
+
+	for _, participant := range roster {
+		if participant.Name == "" {
+			continue
+		}
+		first := regexp.QuoteMeta(strings.ToLower(strings.Fields(participant.Name)[0]))
+		introduction := regexp.MustCompile(`\b(?:i'm|i am|my name is|this is|it's)\s+` + first + `\b`)
+		mention := regexp.MustCompile(`\b` + first + `\b`)
+		for i, turn := range turns {
+			text := strings.ToLower(turn.Text)
+			if introduction.MatchString(text) {
+				add(turn.Speaker, participant.ID, 2)
+			}
+			if i > 0 && turns[i-1].Speaker != turn.Speaker && mention.MatchString(strings.ToLower(turns[i-1].Text)) {
+				add(turn.Speaker, participant.ID, 1)
+			}
+		}
+	}
+	return evidence
+}
+
+// freeParticipants returns the participants with a role that are not mapped to a speaker yet.
+func freeParticipants(roster []Participant, used map[string]bool, role string) []Participant {
+	free := make([]Participant, 0)
+	for _, participant := range roster {
+		if participant.Role == role && !used[participant.ID] {
+			free = append(free, participant)
+		}
+	}
+	return free
+}
+
+// FormatRoster describes the roster for prompts, one participant per line.
+func FormatRoster(roster []Participant) string {
+	var formatted strings.Builder
+	for _, participant := range roster {
+		if participant.Name != "" {
+			formatted.WriteString(fmt.Sprintf("- %s: %s (%s)\n", participant.ID, participant.Name, participant.Role))
+		} else {
+			formatted.WriteString(fmt.Sprintf("- %s (%s)\n", participant.ID, participant.Role))
+		}
+	}
+	return formatted.String()
+}
@@ -0,0 +1,153 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeRoster(t *testing.T) {
+	t.Run("should keep role IDs for unique roles and number shared ones", func(t *testing.T) {
+		roster, err := NormalizeRoster([]Participant{
+			{Role: "Therapist", Name: " Dr.  Lee "},
+			{Role: "client", Name: "Maria"},
+			{Role: "patient", Name: "Sam"},
+			{Role: "interpreter"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []Participant{
+			{ID: "provider", Role: RoleProvider, Name: "Dr. Lee"},
+			{ID: "patient_1", Role: RolePatient, Name: "Maria"},
+			{ID: "patient_2", Role: RolePatient, Name: "Sam"},
+			{ID: "interpreter", Role: RoleInterpreter},
+		}, roster)
+		assert.True(t, IsGroupSession(roster))
+	})
+
+	t.Run("should reject unknown roles", func(t *testing.T) {
+		_, err := NormalizeRoster([]Participant{{Role: "neighbor"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject oversized rosters", func(t *testing.T) {
+		_, err := NormalizeRoster(make([]Participant, MaxParticipants+1))
+		assert.Error(t, err)
+	})
+
+	t.Run("should treat a single patient as an individual session", func(t *testing.T) {
+		roster, err := NormalizeRoster([]Participant{{Role: "provider"}, {Role: "patient"}, {Role: "parent", Name: "Ana"}})
+		require.NoError(t, err)
+		assert.False(t, IsGroupSession(roster))
+		assert.Equal(t, "Ana", roster[2].DisplayName())
+		assert.Equal(t, "patient", roster[1].DisplayName())
+	})
+}
+
+func TestMapSpeakersToRoster(t *testing.T) {
+	group := []Participant{
+		{ID: "provider", Role: RoleProvider, Name: "Dr. Lee"},
+		{ID: "patient_1", Role: RolePatient, Name: "Maria Lopez"},
+		{ID: "patient_2", Role: RolePatient, Name: "Sam"},
+	}
+
+	testCases := []struct {
+		name      string
+		turns     []TranscriptTurn
+		roster    []Participant
+		expected  map[string]string
+		confident bool
+	}{
+		{
+			name: "labels that are already participant IDs or names",
+			turns: []TranscriptTurn{
+				{Speaker: "provider", Text: "Welcome back."},
+				{Speaker: "maria lopez", Text: "Thanks."},
+			},
+			roster:    group,
+			expected:  map[string]string{"provider": "provider", "maria lopez": "patient_1"},
+			confident: true,
+		},
+		{
+			name: "introductions and being addressed by name",
+			turns: []TranscriptTurn{
+				{Speaker: "Speaker1", Text: "Let's start. How have you been sleeping, any changes with the medication?"},
+				{Speaker: "Speaker2", Text: "Hi everyone, I'm Sam. Mostly okay this week."},
+				{Speaker: "Speaker1", Text: "Maria, how was your week?"},
+				{Speaker: "Speaker3", Text: "Hard. My anxiety was up."},
+			},
+			roster:    group,
+			expected:  map[string]string{"Speaker1": "provider", "Speaker2": "patient_2", "Speaker3": "patient_1"},
+			confident: true,
+		},
+		{
+			name: "several members without any name evidence",
+			turns: []TranscriptTurn{
+				{Speaker: "Speaker1", Text: "How are you doing with the medication? Any side effects?"},
+				{Speaker: "Speaker2", Text: "Fine."},
+				{Speaker: "Speaker3", Text: "Not great."},
+			},
+			roster:    group,
+			expected:  map[string]string{"Speaker1": "provider", "Speaker2": "patient_1", "Speaker3": "patient_2"},
+			confident: false,
+		},
+		{
+			name: "family visit maps by role",
+			turns: []TranscriptTurn{
+				{Speaker: "A", Text: "How has he been sleeping? Any side effects from the medication?"},
+				{Speaker: "B", Text: "My son has been up every night."},
+				{Speaker: "C", Text: "I just can't fall asleep and I feel tired all day long at school."},
+			},
+			roster: []Participant{
+				{ID: "provider", Role: RoleProvider},
+				{ID: "patient", Role: RolePatient},
+				{ID: "other", Role: RoleOther},
+			},
+			expected:  map[string]string{"A": "provider", "B": "other", "C": "patient"},
+			confident: true,
+		},
+		{
+			name: "more speakers than participants",
+			turns: []TranscriptTurn{
+				{Speaker: "A", Text: "How are you feeling on the medication?"},
+				{Speaker: "B", Text: "Better."},
+				{Speaker: "C", Text: "Hello?"},
+			},
+			roster: []Participant{
+				{ID: "provider", Role: RoleProvider},
+				{ID: "patient", Role: RolePatient},
+			},
+			expected:  map[string]string{"A": "provider", "B": "patient"},
+			confident: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolution := MapSpeakersToRoster(tc.turns, tc.roster)
+			assert.Equal(t, tc.expected, resolution.Roles)
+			assert.Equal(t, tc.confident, resolution.Confident)
+		})
+	}
+}
+
+func TestRelabelSpeakerToParticipant(t *testing.T) {
+	turns := []TranscriptTurn{{Speaker: "patient_1", Text: "Hi."}}
+	roster := []Participant{{ID: "patient_1", Role: RolePatient}, {ID: "patient_2", Role: RolePatient}}
+
+	relabeled, changed, err := RelabelSpeaker(turns, "patient_1", "patient_2", roster...)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, "patient_2", relabeled[0].Speaker)
+
+	_, _, err = RelabelSpeaker(turns, "patient_1", "patient_3", roster...)
+	assert.Error(t, err)
+}
+
+func TestFormatRoster(t *testing.T) {
+	formatted := FormatRoster([]Participant{
+		{ID: "provider", Role: RoleProvider, Name: "Dr. Lee"},
+		{ID: "interpreter", Role: RoleInterpreter},
+	})
+	assert.Equal(t, "- provider: Dr. Lee (provider)\n- interpreter (interpreter)\n", formatted)
+}
@@ -0,0 +1,293 @@
+package transcriber
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stable speaker roles every transcription backend is normalized to.
+const (
+	RoleProvider    = "provider"
+	RolePatient     = "patient"
+	RoleOther       = "other"
+	RoleInterpreter = "interpreter"
+)
+
+// SpeakerRoles lists the roles a diarized turn can be labeled with.
+var SpeakerRoles = []string{RoleProvider, RolePatient, RoleOther, RoleInterpreter}
+
+// roleAliases maps labels that backends (or people) commonly use onto a stable role.
+var roleAliases = map[string]string{
+	"provider":     RoleProvider,
+	"doctor":       RoleProvider,
+	"clinician":    RoleProvider,
+	"physician":    RoleProvider,
+	"nurse":        RoleProvider,
+	"therapist":    RoleProvider,
+	"psychiatrist": RoleProvider,
+	"patient":      RolePatient,
+	"client":       RolePatient,
+	"other":        RoleOther,
+	"family":       RoleOther,
+	"caregiver":    RoleOther,
+	"guardian":     RoleOther,
+	"parent":       RoleOther,
+	"interpreter":  RoleInterpreter,
+	"translator":   RoleInterpreter,
+}
+
+var (
+	providerCues = []string{
+		"how are you", "how have you", "any ", "medication", "milligram", " mg", "prescri", "dose",
+		"take it", "take one", "follow-up", "follow up", "appointment", "let me", "i'm going to",
+		"i'm gonna", "we're going to", "i'll send", "diagnos", "side effect", "symptom",
+	}
+	interpreterCues = []string{
+		"he says", "she says", "he said", "she said", "they say", "he is saying", "she is saying",
+		"translate", "interpret", "in spanish", "in creole", "the patient says", "wants to know",
+	}
+	familyCues = []string{
+		"my son", "my daughter", "my mother", "my father", "my husband", "my wife", "my mom",
+		"my dad", "his medication", "her medication", "he has been", "she has been", "he doesn't", "she doesn't",
+	}
+)
+
+// minProviderMargin is the minimum lead, in cues per turn, the most provider-like speaker needs
+// over the runner up before the heuristic trusts its own answer.
+const minProviderMargin = 0.15
+
+// SpeakerResolution is the outcome of mapping raw diarization labels onto stable roles.
+// Confident is false when the heuristics could not clearly tell the speakers apart and a
+// second opinion (e.g. an LLM pass) is worthwhile.
+type SpeakerResolution struct {
+	Roles     map[string]string
+	Confident bool
+}
+
+// NormalizeSpeakerRole maps a label such as "Provider", "doctor" or "Client" onto a stable role.
+func NormalizeSpeakerRole(label string) (string, bool) {
+	role, ok := roleAliases[strings.ToLower(strings.TrimSpace(label))]
+	return role, ok
+}
+
+// IsSpeakerRole reports whether label is already one of the stable roles.
+func IsSpeakerRole(label string) bool {
+	for _, role := range SpeakerRoles {
+		if label == role {
+			return true
+		}
+	}
+	return false
+}
+
+// SpeakerLabels returns the distinct speaker labels in order of first appearance.
+func SpeakerLabels(turns []TranscriptTurn) []string {
+	seen := make(map[string]bool)
+	labels := make([]string, 0)
+	for _, turn := range turns {
+		if !seen[turn.Speaker] {
+			seen[turn.Speaker] = true
+			labels = append(labels, turn.Speaker)
+		}
+	}
+	return labels
+}
+
+type speakerStats struct {
+	label       string
+	turns       int
+	words       int
+	provider    float64
+	interpreter float64
+	family      float64
+}
+
+func countCues(text string, cues []string) int {
+	count := 0
+	for _, cue := range cues {
+		count += strings.Count(text, cue)
+	}
+	return count
+}
+
+// ResolveSpeakerRoles maps the raw speaker labels of a diarized transcript onto stable roles.
+// Labels that are already role names (as Gemini returns them) are normalized directly; opaque
+// labels (e.g. Azure's "Speaker1") are scored by how much they sound like a provider asking
+// questions and managing medication, an interpreter relaying speech, or a family member.
+func ResolveSpeakerRoles(turns []TranscriptTurn) SpeakerResolution {
+	labels := SpeakerLabels(turns)
+	roles := make(map[string]string, len(labels))
+
+	allKnown := true
+	for _, label := range labels {
+		role, ok := NormalizeSpeakerRole(label)
+		if !ok {
+			allKnown = false
+			break
+		}
+		roles[label] = role
+	}
+	if allKnown {
+		return SpeakerResolution{Roles: roles, Confident: true}
+	}
+
+	roles = make(map[string]string, len(labels))
+	if len(labels) == 0 {
+		return SpeakerResolution{Roles: roles, Confident: true}
+	}
+	if len(labels) == 1 {
+		roles[labels[0]] = RoleProvider
+		return SpeakerResolution{Roles: roles, Confident: false}
+	}
+
+	statsByLabel := make(map[string]*speakerStats, len(labels))
+	for _, label := range labels {
+		statsByLabel[label] = &speakerStats{label: label}
+	}
+	for _, turn := range turns {
+		stats := statsByLabel[turn.Speaker]
+		text := " " + strings.ToLower(turn.Text)
+		stats.turns++
+		stats.words += len(strings.Fields(turn.Text))
+		stats.provider += float64(countCues(text, providerCues) + strings.Count(text, "?"))
+		stats.interpreter += float64(countCues(text, interpreterCues))
+		stats.family += float64(countCues(text, familyCues))
+	}
+
+	ranked := make([]*speakerStats, 0, len(labels))
+	for _, label := range labels {
+		stats := statsByLabel[label]
+		stats.provider /= float64(stats.turns)
+		stats.interpreter /= float64(stats.turns)
+		stats.family /= float64(stats.turns)
+		ranked = append(ranked, stats)
+	}
+
+	// Stable sort keeps the first speaker ahead on ties; providers usually open the visit.
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].provider > ranked[j].provider })
+	provider := ranked[0]
+	roles[provider.label] = RoleProvider
+	confident := provider.provider-ranked[1].provider >= minProviderMargin
+
+	remaining := ranked[1:]
+	var interpreter *speakerStats
+	for _, stats := range remaining {
+		if stats.interpreter > 0 && (interpreter == nil || stats.interpreter > interpreter.interpreter) {
+			interpreter = stats
+		}
+	}
+	if interpreter != nil && len(remaining) > 1 {
+		roles[interpreter.label] = RoleInterpreter
+	}
+
+	var patient *speakerStats
+	for _, stats := range remaining {
+		if _, assigned := roles[stats.label]; assigned {
+			continue
+		}
+		if patient == nil || stats.family < patient.family || (stats.family == patient.family && stats.words > patient.words) {
+			patient = stats
+		}
+	}
+	if patient != nil {
+		roles[patient.label] = RolePatient
+	}
+	for _, stats := range remaining {
+		if _, assigned := roles[stats.label]; !assigned {
+			roles[stats.label] = RoleOther
+		}
+	}
+
+	return SpeakerResolution{Roles: roles, Confident: confident}
+}
+
+// ApplySpeakerRoles returns a copy of turns with every speaker resolved to its role. The raw
+// diarization label is kept on each turn so the role can be reassigned later; labels without a
+// mapping are kept as the speaker.
+func ApplySpeakerRoles(turns []TranscriptTurn, roles map[string]string) []TranscriptTurn {
+	resolved := make([]TranscriptTurn, len(turns))
+	for i, turn := range turns {
+		turn.Label = SpeakerLabel(turn)
+		if role, ok := roles[turn.Label]; ok {
+			turn.Speaker = role
+		}
+		resolved[i] = turn
+	}
+	return resolved
+}
+
+// SpeakerLabel returns the raw diarization label of a turn, or its speaker for turns stored before
+// labels were kept.
+func SpeakerLabel(turn TranscriptTurn) string {
+	if turn.Label != "" {
+		return turn.Label
+	}
+	return turn.Speaker
+}
+
+// SpeakerMapping returns the role or participant each raw diarization label is resolved to, as of
+// the label's first turn.
+func SpeakerMapping(turns []TranscriptTurn) map[string]string {
+	mapping := make(map[string]string)
+	for _, turn := range turns {
+		label := SpeakerLabel(turn)
+		if _, ok := mapping[label]; !ok {
+			mapping[label] = turn.Speaker
+		}
+	}
+	return mapping
+}
+
+// PrefixSpeakerLabels returns a copy of turns whose raw labels are qualified with prefix, so the
+// speakers of a separately diarized recording are not mistaken for those of another one.
+func PrefixSpeakerLabels(turns []TranscriptTurn, prefix string) []TranscriptTurn {
+	prefixed := make([]TranscriptTurn, len(turns))
+	for i, turn := range turns {
+		turn.Label = prefix + SpeakerLabel(turn)
+		prefixed[i] = turn
+	}
+	return prefixed
+}
+
+// CarrySpeakerLabels returns a copy of edited turns in which turns sent back without their raw
+// label take it from the previous turn starting at the same time.
+func CarrySpeakerLabels(previous, updated []TranscriptTurn) []TranscriptTurn {
+	labels := make(map[float64]string, len(previous))
+	for _, turn := range previous {
+		if turn.Label != "" {
+			labels[turn.StartTime] = turn.Label
+		}
+	}
+	carried := make([]TranscriptTurn, len(updated))
+	for i, turn := range updated {
+		if turn.Label == "" {
+			turn.Label = labels[turn.StartTime]
+		}
+		carried[i] = turn
+	}
+	return carried
+}
+
+// RelabelSpeaker reassigns every turn transcribed with the raw label from to the role to across the
+// whole transcript. Only the role changes; the raw label is kept, so speakers merged into one role
+// can be split again and two roles can be swapped. When the session has a roster, to may also be a
+// participant ID. It returns the updated turns and the number of turns that changed.
+func RelabelSpeaker(turns []TranscriptTurn, from, to string, roster ...Participant) ([]TranscriptTurn, int, error) {
+	if _, onRoster := RosterParticipant(roster, to); !IsSpeakerRole(to) && !onRoster {
+		return nil, 0, fmt.Errorf("invalid speaker role: %s", to)
+	}
+	relabeled := make([]TranscriptTurn, len(turns))
+	changed := 0
+	for i, turn := range turns {
+		if SpeakerLabel(turn) == from {
+			turn.Label = from
+			if turn.Speaker != to {
+				turn.Speaker = to
+				changed++
+			}
+		}
+		relabeled[i] = turn
+	}
+	return relabeled, changed, nil
+}
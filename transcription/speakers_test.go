@@ -0,0 +1,164 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSpeakerRole(t *testing.T) {
+	testCases := []struct {
+		label    string
+		expected string
+		ok       bool
+	}{
+		{label: "provider", expected: RoleProvider, ok: true},
+		{label: " Doctor ", expected: RoleProvider, ok: true},
+		{label: "Client", expected: RolePatient, ok: true},
+		{label: "translator", expected: RoleInterpreter, ok: true},
+		{label: "caregiver", expected: RoleOther, ok: true},
+		{label: "Speaker1", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			role, ok := NormalizeSpeakerRole(tc.label)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, role)
+		})
+	}
+}
+
+func TestResolveSpeakerRoles(t *testing.T) {
+	t.Run("should normalize labels that are already roles", func(t *testing.T) {
+		turns := []TranscriptTurn{
+			{Speaker: "Provider", Text: "How are you?"},
+			{Speaker: "Patient", Text: "Fine."},
+		}
+		resolution := ResolveSpeakerRoles(turns)
+		assert.True(t, resolution.Confident)
+		assert.Equal(t, map[string]string{"Provider": RoleProvider, "Patient": RolePatient}, resolution.Roles)
+	})
+
+	t.Run("should pick the speaker asking clinical questions as provider", func(t *testing.T) {
+		turns := []TranscriptTurn{
+			{Speaker: "Speaker1", Text: "I have not been sleeping and my anxiety is high."},
+			{Speaker: "Speaker2", Text: "How long has this been going on? Are you taking the medication?"},
+			{Speaker: "Speaker1", Text: "About two weeks. I take it every night."},
+			{Speaker: "Speaker2", Text: "Let me lower the dose to 15 mg. Any side effects?"},
+		}
+		resolution := ResolveSpeakerRoles(turns)
+		assert.True(t, resolution.Confident)
+		assert.Equal(t, RoleProvider, resolution.Roles["Speaker2"])
+		assert.Equal(t, RolePatient, resolution.Roles["Speaker1"])
+	})
+
+	t.Run("should detect an interpreter relaying speech", func(t *testing.T) {
+		turns := []TranscriptTurn{
+			{Speaker: "Speaker0", Text: "How are you feeling on the medication?"},
+			{Speaker: "Speaker1", Text: "She says she feels tired in the morning."},
+			{Speaker: "Speaker2", Text: "Estoy cansada por la mañana."},
+			{Speaker: "Speaker1", Text: "She says the pills make her sleepy."},
+		}
+		resolution := ResolveSpeakerRoles(turns)
+		assert.Equal(t, RoleProvider, resolution.Roles["Speaker0"])
+		assert.Equal(t, RoleInterpreter, resolution.Roles["Speaker1"])
+		assert.Equal(t, RolePatient, resolution.Roles["Speaker2"])
+	})
+
+	t.Run("should not be confident when speakers are indistinguishable", func(t *testing.T) {
+		turns := []TranscriptTurn{
+			{Speaker: "Speaker1", Text: "Okay."},
+			{Speaker: "Speaker2", Text: "Okay."},
+		}
+		resolution := ResolveSpeakerRoles(turns)
+		assert.False(t, resolution.Confident)
+		assert.Len(t, resolution.Roles, 2)
+	})
+}
+
+func TestApplySpeakerRoles(t *testing.T) {
+	turns := []TranscriptTurn{
+		{Speaker: "Speaker1", Text: "How are you?"},
+		{Speaker: "Speaker2", Text: "Fine."},
+		{Speaker: "Speaker3", Text: "Hi."},
+	}
+	resolved := ApplySpeakerRoles(turns, map[string]string{"Speaker1": RoleProvider, "Speaker2": RolePatient})
+
+	assert.Equal(t, TranscriptTurn{Speaker: RoleProvider, Label: "Speaker1", Text: "How are you?"}, resolved[0])
+	assert.Equal(t, TranscriptTurn{Speaker: RolePatient, Label: "Speaker2", Text: "Fine."}, resolved[1])
+	assert.Equal(t, TranscriptTurn{Speaker: "Speaker3", Label: "Speaker3", Text: "Hi."}, resolved[2])
+	assert.Equal(t, map[string]string{"Speaker1": RoleProvider, "Speaker2": RolePatient, "Speaker3": "Speaker3"}, SpeakerMapping(resolved))
+}
+
+func TestRelabelSpeaker(t *testing.T) {
+	turns := []TranscriptTurn{
+		{Speaker: RoleProvider, Label: "Speaker1", Text: "Hello."},
+		{Speaker: RolePatient, Label: "Speaker2", Text: "Hi."},
+		{Speaker: RolePatient, Label: "Speaker3", Text: "I am his daughter."},
+		{Speaker: RolePatient, Label: "Speaker2", Text: "My knee hurts."},
+	}
+
+	t.Run("should relabel only the turns of the raw label", func(t *testing.T) {
+		relabeled, changed, err := RelabelSpeaker(turns, "Speaker3", RoleOther)
+		require.NoError(t, err)
+		assert.Equal(t, 1, changed)
+		assert.Equal(t, RolePatient, relabeled[1].Speaker)
+		assert.Equal(t, RoleOther, relabeled[2].Speaker)
+		assert.Equal(t, "Speaker3", relabeled[2].Label)
+		assert.Equal(t, RolePatient, turns[2].Speaker, "input must not be mutated")
+	})
+
+	t.Run("should split speakers merged into one role", func(t *testing.T) {
+		merged, _, err := RelabelSpeaker(turns, "Speaker3", RolePatient)
+		require.NoError(t, err)
+		split, changed, err := RelabelSpeaker(merged, "Speaker3", RoleOther)
+		require.NoError(t, err)
+		assert.Equal(t, 1, changed)
+		assert.Equal(t, []string{RoleProvider, RolePatient, RoleOther, RolePatient}, speakers(split))
+	})
+
+	t.Run("should swap two roles", func(t *testing.T) {
+		swapped, _, err := RelabelSpeaker(turns, "Speaker1", RolePatient)
+		require.NoError(t, err)
+		swapped, _, err = RelabelSpeaker(swapped, "Speaker2", RoleProvider)
+		require.NoError(t, err)
+		assert.Equal(t, []string{RolePatient, RoleProvider, RolePatient, RoleProvider}, speakers(swapped))
+	})
+
+	t.Run("should relabel turns stored without raw labels by speaker", func(t *testing.T) {
+		legacy := []TranscriptTurn{{Speaker: RoleProvider, Text: "Hello."}, {Speaker: RolePatient, Text: "Hi."}}
+		relabeled, changed, err := RelabelSpeaker(legacy, RolePatient, RoleOther)
+		require.NoError(t, err)
+		assert.Equal(t, 1, changed)
+		assert.Equal(t, TranscriptTurn{Speaker: RoleOther, Label: RolePatient, Text: "Hi."}, relabeled[1])
+	})
+
+	t.Run("should reject unknown roles", func(t *testing.T) {
+		_, _, err := RelabelSpeaker(turns, "Speaker2", "Speaker3")
+		assert.Error(t, err)
+	})
+}
+
+func TestCarrySpeakerLabels(t *testing.T) {
+	previous := []TranscriptTurn{
+		{Speaker: RoleProvider, Label: "Speaker1", StartTime: 0, Text: "Hello."},
+		{Speaker: RolePatient, Label: "Speaker2", StartTime: 2, Text: "Hi."},
+	}
+	edited := []TranscriptTurn{
+		{Speaker: RoleProvider, StartTime: 0, Text: "Hello there."},
+		{Speaker: RolePatient, Label: "Speaker2", StartTime: 2, Text: "Hi."},
+		{Speaker: RolePatient, StartTime: 4, Text: "Added turn."},
+	}
+	carried := CarrySpeakerLabels(previous, edited)
+	assert.Equal(t, []string{"Speaker1", "Speaker2", ""}, []string{carried[0].Label, carried[1].Label, carried[2].Label})
+}
+
+func speakers(turns []TranscriptTurn) []string {
+	result := make([]string, len(turns))
+	for i, turn := range turns {
+		result[i] = turn.Speaker
+	}
+	return result
+}
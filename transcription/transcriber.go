@@ -8,15 +8,64 @@ import (
 )
 
 type TranscriptTurn struct {
+	// Speaker is the role or roster participant the turn was resolved to, and Label the raw
+	// diarization label it was transcribed with, e.g. "Speaker1". Turns stored before labels
+	// were kept have no Label and are identified by their Speaker.
 	Speaker   string  `json:"speaker"`
+	Label     string  `json:"label,omitempty"`
 	StartTime float64 `json:"startTime"`
 	EndTime   float64 `json:"endTime"`
 	Text      string  `json:"text"`
+	// Confidence is the backend's recognition confidence for the turn between 0 and 1.
+	// Zero means the backend did not report one.
+	Confidence float64 `json:"confidence,omitempty"`
+	// Words are kept only on turns recognized with low confidence, where their timings help find
+	// the uncertain passage in the audio.
+	Words []TranscriptWord `json:"words,omitempty"`
+}
+
+// TranscriptWord carries word level timings.
+type TranscriptWord struct {
+	Text      string  `json:"text"`
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime"`
+}
+
+// Options carries per-request hints passed to the transcription backends.
+type Options struct {
+	// Vocabulary lists provider specific terms (medication names, colleagues, facilities)
+	// the backend should favour when it is unsure what was said.
+	Vocabulary []string
+	// Participants is the session roster, if one was given. Backends use it to size
+	// diarization and, where they can, to label turns with participant IDs.
+	Participants []Participant
+	// Languages lists the languages expected in the visit, as ISO 639-1 codes. Empty means English.
+	Languages []string
+}
+
+// Locales returns the backend locales of the expected languages, defaulting to US English.
+// Languages without a backend locale are left out.
+func (o Options) Locales() []string {
+	locales := make([]string, 0, len(o.Languages))
+	for _, language := range o.Languages {
+		if locale, ok := LanguageLocale(language); ok {
+			locales = append(locales, locale)
+		}
+	}
+	if len(locales) == 0 {
+		return []string{"en-US"}
+	}
+	return locales
+}
+
+// SpeakerCount returns how many speakers to expect, or zero when there is no roster.
+func (o Options) SpeakerCount() int {
+	return len(o.Participants)
 }
 
 type Transcription interface {
-	Transcribe(ctx context.Context, audio []byte) (string, error)
-	TranscribeWithDiarization(ctx context.Context, audio []byte) ([]TranscriptTurn, error)
+	Transcribe(ctx context.Context, audio []byte, opts Options) (string, error)
+	TranscribeWithDiarization(ctx context.Context, audio []byte, opts Options) ([]TranscriptTurn, error)
 }
 
 func DiarizedTranscriptToString(transcript []TranscriptTurn) (string, error) {
@@ -63,3 +112,43 @@ func UnmarshalTranscript(jsonData []byte) ([]TranscriptTurn, error) {
 	return transcript, nil
 }
 
+
+// ValidateTranscriptTurns checks that an edited diarized transcript is well formed.
+func ValidateTranscriptTurns(turns []TranscriptTurn) error {
+	if len(turns) == 0 {
+		return fmt.Errorf("transcript must contain at least one turn")
+	}
+	for i, turn := range turns {
+		if strings.TrimSpace(turn.Speaker) == "" {
+			return fmt.Errorf("turn %d is missing a speaker", i)
+		}
+		if strings.TrimSpace(turn.Text) == "" {
+			return fmt.Errorf("turn %d has no text", i)
+		}
+		if turn.StartTime < 0 || turn.EndTime < turn.StartTime {
+			return fmt.Errorf("turn %d has invalid timings: %.2f-%.2f", i, turn.StartTime, turn.EndTime)
+		}
+	}
+	return nil
+}
+
+// OffsetTurns shifts the timings of turns by offset seconds, e.g. to place a second recording
+// of a visit after the first one on the combined timeline.
+func OffsetTurns(turns []TranscriptTurn, offset float64) []TranscriptTurn {
+	shifted := make([]TranscriptTurn, len(turns))
+	for i, turn := range turns {
+		turn.StartTime += offset
+		turn.EndTime += offset
+		if len(turn.Words) > 0 {
+			words := make([]TranscriptWord, len(turn.Words))
+			for j, word := range turn.Words {
+				word.StartTime += offset
+				word.EndTime += offset
+				words[j] = word
+			}
+			turn.Words = words
+		}
+		shifted[i] = turn
+	}
+	return shifted
+}
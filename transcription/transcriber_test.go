@@ -0,0 +1,47 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTranscriptTurns(t *testing.T) {
+	testCases := []struct {
+		name    string
+		turns   []TranscriptTurn
+		wantErr bool
+	}{
+		{name: "valid", turns: []TranscriptTurn{{Speaker: RoleProvider, StartTime: 0, EndTime: 1.5, Text: "Hello."}}},
+		{name: "empty", turns: nil, wantErr: true},
+		{name: "missing speaker", turns: []TranscriptTurn{{Text: "Hello.", EndTime: 1}}, wantErr: true},
+		{name: "missing text", turns: []TranscriptTurn{{Speaker: RolePatient, EndTime: 1}}, wantErr: true},
+		{name: "end before start", turns: []TranscriptTurn{{Speaker: RolePatient, StartTime: 2, EndTime: 1, Text: "Hi."}}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTranscriptTurns(tc.turns)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOffsetTurns(t *testing.T) {
+	turns := []TranscriptTurn{
+		{Speaker: RoleProvider, StartTime: 0, EndTime: 1.5, Text: "Welcome back.", Words: []TranscriptWord{{Text: "Welcome", EndTime: 0.8}, {Text: "back.", StartTime: 0.8, EndTime: 1.5}}},
+		{Speaker: RolePatient, StartTime: 2, EndTime: 3, Text: "Thanks."},
+	}
+
+	shifted := OffsetTurns(turns, 600)
+
+	assert.Equal(t, 600.0, shifted[0].StartTime)
+	assert.Equal(t, 601.5, shifted[0].EndTime)
+	assert.Equal(t, 600.8, shifted[0].Words[1].StartTime)
+	assert.Equal(t, 603.0, shifted[1].EndTime)
+	assert.Equal(t, 0.8, turns[0].Words[1].StartTime, "the input must not be modified")
+}
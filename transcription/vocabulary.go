@@ -0,0 +1,161 @@
+package transcriber
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/texttheater/golang-levenshtein/levenshtein"
+)
+
+const (
+	// minFuzzyTermLength is the shortest term (in runes) eligible for fuzzy correction.
+	// Shorter terms are only case-corrected on an exact match to avoid rewriting common words.
+	minFuzzyTermLength = 6
+	// minVocabularySimilarity is the normalized Levenshtein similarity required to replace a word with a term.
+	minVocabularySimilarity = 0.85
+)
+
+var vocabularyWordPattern = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}'’-]*`)
+
+type vocabularyTerm struct {
+	term  string
+	key   []rune
+	words int
+}
+
+// CorrectVocabulary replaces words in a transcript that closely resemble one of the provider's
+// vocabulary terms with the term as the provider spelled it, e.g. "duloxitine" becomes "duloxetine".
+// Multi-word terms are matched against runs of consecutive words. Punctuation and spacing
+// around the replaced words are preserved.
+func CorrectVocabulary(text string, vocabulary []string) string {
+	terms := prepareVocabulary(vocabulary)
+	if len(terms) == 0 || text == "" {
+		return text
+	}
+
+	words := vocabularyWordPattern.FindAllStringIndex(text, -1)
+	var corrected strings.Builder
+	last := 0
+	for i := 0; i < len(words); {
+		term, span := matchVocabularyTerm(text, words[i:], terms)
+		if span == 0 {
+			i++
+			continue
+		}
+		start, end := words[i][0], words[i+span-1][1]
+		corrected.WriteString(text[last:start])
+		corrected.WriteString(term)
+		last = end
+		i += span
+	}
+	corrected.WriteString(text[last:])
+	return corrected.String()
+}
+
+// CorrectTurnsVocabulary applies CorrectVocabulary to the text of every turn, returning a new slice.
+func CorrectTurnsVocabulary(turns []TranscriptTurn, vocabulary []string) []TranscriptTurn {
+	corrected := make([]TranscriptTurn, len(turns))
+	for i, turn := range turns {
+		turn.Text = CorrectVocabulary(turn.Text, vocabulary)
+		corrected[i] = turn
+	}
+	return corrected
+}
+
+// prepareVocabulary normalizes the vocabulary into match keys, longest phrases first so that
+// "Brookline Hospital" wins over "Brookline".
+func prepareVocabulary(vocabulary []string) []vocabularyTerm {
+	terms := make([]vocabularyTerm, 0, len(vocabulary))
+	for _, term := range vocabulary {
+		words := vocabularyWordPattern.FindAllString(term, -1)
+		if len(words) == 0 {
+			continue
+		}
+		terms = append(terms, vocabularyTerm{
+			term:  strings.Join(strings.Fields(term), " "),
+			key:   []rune(strings.ToLower(strings.Join(words, " "))),
+			words: len(words),
+		})
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return terms[i].words > terms[j].words })
+	return terms
+}
+
+// matchVocabularyTerm finds the best vocabulary term starting at the first word and returns it
+// together with the number of words it replaces. A span of zero means no term matched.
+func matchVocabularyTerm(text string, words [][]int, terms []vocabularyTerm) (string, int) {
+	var (
+		bestTerm       string
+		bestSpan       int
+		bestSimilarity float64
+	)
+	for _, term := range terms {
+		candidate, ok := wordWindow(text, words, term.words)
+		if !ok {
+			continue
+		}
+		key := []rune(strings.ToLower(candidate))
+		if string(key) == string(term.key) {
+			return term.term, term.words
+		}
+		if len(term.key) < minFuzzyTermLength || key[0] != term.key[0] {
+			continue
+		}
+		similarity := vocabularySimilarity(key, term.key)
+		if similarity >= minVocabularySimilarity && similarity > bestSimilarity {
+			bestTerm, bestSpan, bestSimilarity = term.term, term.words, similarity
+		}
+	}
+	return bestTerm, bestSpan
+}
+
+// wordWindow joins the next n words with single spaces, provided they are only separated by
+// whitespace or the period of an abbreviation such as "Dr.".
+func wordWindow(text string, words [][]int, n int) (string, bool) {
+	if n > len(words) {
+		return "", false
+	}
+	parts := make([]string, n)
+	for i := 0; i < n; i++ {
+		if i > 0 && strings.Trim(text[words[i-1][1]:words[i][0]], " .") != "" {
+			return "", false
+		}
+		parts[i] = text[words[i][0]:words[i][1]]
+	}
+	return strings.Join(parts, " "), true
+}
+
+// vocabularySimilarity returns 1 minus the edit distance normalized by the longer string.
+func vocabularySimilarity(a, b []rune) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	distance := levenshtein.DistanceForStrings(a, b, levenshtein.DefaultOptionsWithSub)
+	return 1 - float64(distance)/float64(longest)
+}
+
+// NormalizeVocabulary trims the terms, collapses inner whitespace and drops blanks and
+// case-insensitive duplicates while keeping the first spelling given.
+func NormalizeVocabulary(vocabulary []string) []string {
+	seen := make(map[string]bool, len(vocabulary))
+	normalized := make([]string, 0, len(vocabulary))
+	for _, term := range vocabulary {
+		term = strings.Join(strings.Fields(term), " ")
+		if term == "" || !utf8.ValidString(term) {
+			continue
+		}
+		key := strings.ToLower(term)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, term)
+	}
+	return normalized
+}
@@ -0,0 +1,79 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectVocabulary(t *testing.T) {
+	vocabulary := []string{"Remeron", "duloxetine", "trazodone", "Brookline Hospital", "Dr. Okafor", "PHQ"}
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "should fix a near miss medication name",
+			text:     "We will start duloxitine 30 mg daily.",
+			expected: "We will start duloxetine 30 mg daily.",
+		},
+		{
+			name:     "should restore the provider's casing on an exact match",
+			text:     "She stopped remeron last week",
+			expected: "She stopped Remeron last week",
+		},
+		{
+			name:     "should keep punctuation attached to a corrected word",
+			text:     "Trazadone, at night.",
+			expected: "trazodone, at night.",
+		},
+		{
+			name:     "should match multi word terms",
+			text:     "He was admitted to brooklyn hospital in May.",
+			expected: "He was admitted to Brookline Hospital in May.",
+		},
+		{
+			name:     "should not fuzzy match short terms",
+			text:     "The PHD student filled out the form.",
+			expected: "The PHD student filled out the form.",
+		},
+		{
+			name:     "should leave unrelated words untouched",
+			text:     "Sleep has improved and appetite is stable.",
+			expected: "Sleep has improved and appetite is stable.",
+		},
+		{
+			name:     "should match terms containing abbreviations",
+			text:     "Follow up with dr okafer next month.",
+			expected: "Follow up with Dr. Okafor next month.",
+		},
+		{
+			name:     "should not match words separated by punctuation",
+			text:     "brooklyn, hospital",
+			expected: "brooklyn, hospital",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CorrectVocabulary(tc.text, vocabulary))
+		})
+	}
+}
+
+func TestCorrectTurnsVocabulary(t *testing.T) {
+	turns := []TranscriptTurn{{Speaker: RoleProvider, Text: "Any issues with the trazadone?", StartTime: 1, EndTime: 2}}
+
+	corrected := CorrectTurnsVocabulary(turns, []string{"trazodone"})
+
+	assert.Equal(t, "Any issues with the trazodone?", corrected[0].Text)
+	assert.Equal(t, 1.0, corrected[0].StartTime)
+	assert.Equal(t, "Any issues with the trazadone?", turns[0].Text, "input must not be mutated")
+}
+
+func TestNormalizeVocabulary(t *testing.T) {
+	normalized := NormalizeVocabulary([]string{" Remeron ", "remeron", "", "Brookline   Hospital", "  "})
+	assert.Equal(t, []string{"Remeron", "Brookline Hospital"}, normalized)
+}
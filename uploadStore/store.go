@@ -0,0 +1,367 @@
+package uploads
+
+import (
+	"Medscribe/blobStore"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Upload statuses
+const (
+	StatusPending   = "pending"
+	StatusFinalized = "finalized"
+)
+
+// uploadTTL is how long an upload may stay unfinished or unused before it is purged.
+const uploadTTL = 24 * time.Hour
+
+// purgeBatchSize caps how many expired uploads are removed per purge pass.
+const purgeBatchSize = 100
+
+var (
+	ErrUploadNotFound     = errors.New("upload not found")
+	ErrOffsetMismatch     = errors.New("upload offset does not match")
+	ErrUploadTooLarge     = errors.New("upload exceeds its declared size")
+	ErrUploadIncomplete   = errors.New("upload is incomplete")
+	ErrUploadFinalized    = errors.New("upload is already finalized")
+	ErrUploadNotFinalized = errors.New("upload is not finalized")
+	ErrChecksumMismatch   = errors.New("upload checksum does not match")
+)
+
+// Upload tracks a resumable upload. The received bytes are spooled to the blob store as numbered
+// chunks, so an interrupted upload can be resumed from Offset on any instance.
+type Upload struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProviderID  string             `bson:"providerId" json:"providerID"`
+	ContentType string             `bson:"contentType" json:"contentType"`
+	Size        int64              `bson:"size" json:"size"`
+	Offset      int64              `bson:"offset" json:"offset"`
+	Chunks      []string           `bson:"chunks" json:"-"`
+	Checksum    string             `bson:"checksum" json:"checksum"`
+	Status      string             `bson:"status" json:"status"`
+	CreatedAt   primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	ExpiresAt   primitive.DateTime `bson:"expiresAt" json:"expiresAt"`
+}
+
+type UploadStore interface {
+	Create(ctx context.Context, providerID, contentType string, size int64, checksum string) (Upload, error)
+	Get(ctx context.Context, uploadID string) (Upload, error)
+	Append(ctx context.Context, uploadID string, offset int64, chunk io.Reader) (Upload, error)
+	Finalize(ctx context.Context, uploadID string, checksum string) (Upload, error)
+	Open(ctx context.Context, uploadID string) (io.ReadCloser, error)
+	Delete(ctx context.Context, uploadID string) error
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+type uploadStore struct {
+	collection *mongo.Collection
+	blobs      blobStore.BlobStore
+	maxSize    int64
+}
+
+// NewUploadStore tracks uploads in collection and spools their bytes to blobs. Uploads larger than maxSize are rejected.
+func NewUploadStore(collection *mongo.Collection, blobs blobStore.BlobStore, maxSize int64) UploadStore {
+	return &uploadStore{collection: collection, blobs: blobs, maxSize: maxSize}
+}
+
+// Create registers a new upload of size bytes. The SHA-256 checksum may be given now or when finalizing.
+func (s *uploadStore) Create(ctx context.Context, providerID, contentType string, size int64, checksum string) (Upload, error) {
+	if providerID == "" {
+		return Upload{}, errors.New("providerId cannot be empty")
+	}
+	if size <= 0 {
+		return Upload{}, errors.New("size must be greater than 0")
+	}
+	if size > s.maxSize {
+		return Upload{}, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrUploadTooLarge, size, s.maxSize)
+	}
+	checksum, err := normalizeChecksum(checksum)
+	if err != nil {
+		return Upload{}, err
+	}
+
+	now := time.Now()
+	upload := Upload{
+		ID:          primitive.NewObjectID(),
+		ProviderID:  providerID,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+		Status:      StatusPending,
+		CreatedAt:   primitive.NewDateTimeFromTime(now),
+		ExpiresAt:   primitive.NewDateTimeFromTime(now.Add(uploadTTL)),
+	}
+	if _, err := s.collection.InsertOne(ctx, upload); err != nil {
+		return Upload{}, fmt.Errorf("failed to insert upload: %v", err)
+	}
+	return upload, nil
+}
+
+func (s *uploadStore) Get(ctx context.Context, uploadID string) (Upload, error) {
+	objectID, err := primitive.ObjectIDFromHex(uploadID)
+	if err != nil {
+		return Upload{}, fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	var upload Upload
+	err = s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&upload)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Upload{}, ErrUploadNotFound
+		}
+		return Upload{}, fmt.Errorf("failed to retrieve upload: %v", err)
+	}
+	return upload, nil
+}
+
+// Append stores the next chunk of the upload. offset must equal the number of bytes already
+// received; a mismatch means the client must ask for the current offset and resume from there.
+func (s *uploadStore) Append(ctx context.Context, uploadID string, offset int64, chunk io.Reader) (Upload, error) {
+	upload, err := s.Get(ctx, uploadID)
+	if err != nil {
+		return Upload{}, err
+	}
+	if upload.Status != StatusPending {
+		return Upload{}, ErrUploadFinalized
+	}
+	if offset != upload.Offset {
+		return Upload{}, ErrOffsetMismatch
+	}
+
+	remaining := upload.Size - upload.Offset
+	key := chunkKey(upload.ID, upload.Offset)
+	counter := &countingReader{r: io.LimitReader(chunk, remaining+1)}
+	if err := s.blobs.Put(ctx, key, counter); err != nil {
+		return Upload{}, fmt.Errorf("failed to store upload chunk: %w", err)
+	}
+	if counter.n > remaining {
+		return Upload{}, s.discardChunk(ctx, key, ErrUploadTooLarge)
+	}
+	if counter.n == 0 {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			return Upload{}, fmt.Errorf("failed to delete empty upload chunk: %v", err)
+		}
+		return upload, nil
+	}
+
+	// Filtering on the offset makes concurrent appends at the same offset lose cleanly; each attempt
+	// wrote its own chunk key, so the loser only removes its own chunk.
+	filter := bson.M{"_id": upload.ID, "status": StatusPending, "offset": upload.Offset}
+	update := bson.M{
+		"$inc":  bson.M{"offset": counter.n},
+		"$push": bson.M{"chunks": key},
+		"$set":  bson.M{"expiresAt": primitive.NewDateTimeFromTime(time.Now().Add(uploadTTL))},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated Upload
+	if err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Upload{}, s.discardChunk(ctx, key, ErrOffsetMismatch)
+		}
+		return Upload{}, s.discardChunk(ctx, key, fmt.Errorf("failed to update upload offset: %v", err))
+	}
+	return updated, nil
+}
+
+// discardChunk removes a chunk that was stored but never recorded on its upload. A chunk that cannot be
+// removed is orphaned, since no upload references it for purging, so the failure is reported alongside err.
+func (s *uploadStore) discardChunk(ctx context.Context, key string, err error) error {
+	if deleteErr := s.blobs.Delete(ctx, key); deleteErr != nil {
+		return fmt.Errorf("%w (failed to delete upload chunk %s: %v)", err, key, deleteErr)
+	}
+	return err
+}
+
+// Finalize verifies that every byte arrived and that the content matches the SHA-256 checksum.
+func (s *uploadStore) Finalize(ctx context.Context, uploadID string, checksum string) (Upload, error) {
+	upload, err := s.Get(ctx, uploadID)
+	if err != nil {
+		return Upload{}, err
+	}
+	if upload.Status == StatusFinalized {
+		return upload, nil
+	}
+	if upload.Offset != upload.Size {
+		return Upload{}, fmt.Errorf("%w: received %d of %d bytes", ErrUploadIncomplete, upload.Offset, upload.Size)
+	}
+
+	checksum, err = normalizeChecksum(checksum)
+	if err != nil {
+		return Upload{}, err
+	}
+	if checksum == "" {
+		checksum = upload.Checksum
+	}
+	if checksum == "" {
+		return Upload{}, errors.New("a sha256 checksum is required to finalize an upload")
+	}
+
+	hash := sha256.New()
+	reader := &chunkReader{ctx: ctx, blobs: s.blobs, upload: upload}
+	defer reader.Close()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return Upload{}, fmt.Errorf("failed to read upload: %v", err)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return Upload{}, ErrChecksumMismatch
+	}
+
+	filter := bson.M{"_id": upload.ID, "status": StatusPending}
+	update := bson.M{"$set": bson.M{
+		"status":    StatusFinalized,
+		"checksum":  checksum,
+		"expiresAt": primitive.NewDateTimeFromTime(time.Now().Add(uploadTTL)),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var finalized Upload
+	if err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&finalized); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return s.Get(ctx, uploadID)
+		}
+		return Upload{}, fmt.Errorf("failed to finalize upload: %v", err)
+	}
+	return finalized, nil
+}
+
+// Open returns the content of a finalized upload, read chunk by chunk. The caller must close it.
+func (s *uploadStore) Open(ctx context.Context, uploadID string) (io.ReadCloser, error) {
+	upload, err := s.Get(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != StatusFinalized {
+		return nil, ErrUploadNotFinalized
+	}
+	return &chunkReader{ctx: ctx, blobs: s.blobs, upload: upload}, nil
+}
+
+// Delete removes an upload and its spooled chunks.
+func (s *uploadStore) Delete(ctx context.Context, uploadID string) error {
+	upload, err := s.Get(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+	return s.delete(ctx, upload)
+}
+
+// PurgeExpired removes uploads that were abandoned or never used and returns how many were removed.
+func (s *uploadStore) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	filter := bson.M{"expiresAt": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}}
+	opts := options.Find().SetLimit(purgeBatchSize)
+
+	var purged int
+	for {
+		cursor, err := s.collection.Find(ctx, filter, opts)
+		if err != nil {
+			return purged, fmt.Errorf("failed to find expired uploads: %v", err)
+		}
+		var expired []Upload
+		if err := cursor.All(ctx, &expired); err != nil {
+			return purged, fmt.Errorf("failed to decode expired uploads: %v", err)
+		}
+		for _, upload := range expired {
+			if err := s.delete(ctx, upload); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(expired) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// delete removes the chunks before the record, so a failure never leaves chunks without a record to purge them later.
+func (s *uploadStore) delete(ctx context.Context, upload Upload) error {
+	for _, key := range upload.Chunks {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete upload chunk: %v", err)
+		}
+	}
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": upload.ID}); err != nil {
+		return fmt.Errorf("failed to delete upload: %v", err)
+	}
+	return nil
+}
+
+// chunkKey names a chunk after its upload and offset plus a unique suffix for the append attempt.
+func chunkKey(uploadID primitive.ObjectID, offset int64) string {
+	return fmt.Sprintf("upload-%s-%d-%s", uploadID.Hex(), offset, primitive.NewObjectID().Hex())
+}
+
+func normalizeChecksum(checksum string) (string, error) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum == "" {
+		return "", nil
+	}
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		return "", errors.New("checksum must be a hex encoded sha256 digest")
+	}
+	return checksum, nil
+}
+
+// chunkReader reads the chunks of an upload in order, opening each one only when it is needed.
+type chunkReader struct {
+	ctx     context.Context
+	blobs   blobStore.BlobStore
+	upload  Upload
+	next    int
+	current io.ReadCloser
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if c.next >= len(c.upload.Chunks) {
+				return 0, io.EOF
+			}
+			rc, err := c.blobs.Get(c.ctx, c.upload.Chunks[c.next])
+			if err != nil {
+				return 0, fmt.Errorf("failed to open upload chunk %d: %w", c.next, err)
+			}
+			c.current = rc
+			c.next++
+		}
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.current.Close()
+			c.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	err := c.current.Close()
+	c.current = nil
+	return err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,248 @@
+package uploads
+
+import (
+	"Medscribe/blobStore"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const testMaxSize = 64
+
+// failingDeleteBlobs is a blob store whose deletes always fail.
+type failingDeleteBlobs struct {
+	blobStore.BlobStore
+}
+
+func (f failingDeleteBlobs) Delete(ctx context.Context, key string) error {
+	return errors.New("bucket unavailable")
+}
+
+func newTestBlobs(t *testing.T) (blobStore.BlobStore, string) {
+	root := t.TempDir()
+	blobs, err := blobStore.NewLocalBlobStore(root)
+	require.NoError(t, err)
+	return blobs, root
+}
+
+// storedChunks lists the chunk files left in the blob directory.
+func storedChunks(t *testing.T, root string) []string {
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	var chunks []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "upload-") {
+			chunks = append(chunks, entry.Name())
+		}
+	}
+	return chunks
+}
+
+func uploadDocument(upload Upload) bson.D {
+	chunks := bson.A{}
+	for _, chunk := range upload.Chunks {
+		chunks = append(chunks, chunk)
+	}
+	return bson.D{
+		{Key: "_id", Value: upload.ID},
+		{Key: "providerId", Value: "provider"},
+		{Key: "size", Value: upload.Size},
+		{Key: "offset", Value: upload.Offset},
+		{Key: "chunks", Value: chunks},
+		{Key: "checksum", Value: upload.Checksum},
+		{Key: "status", Value: upload.Status},
+	}
+}
+
+func findResponse(mt *mtest.T, upload Upload) bson.D {
+	ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+	return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, uploadDocument(upload))
+}
+
+func updatedResponse(upload Upload) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: uploadDocument(upload)})
+}
+
+func notUpdatedResponse() bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil})
+}
+
+func checksumOf(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreateRejectsUploadsOverTheLimit(t *testing.T) {
+	store := &uploadStore{maxSize: testMaxSize}
+	_, err := store.Create(context.Background(), "provider", "audio/mpeg", testMaxSize+1, "")
+	assert.True(t, errors.Is(err, ErrUploadTooLarge))
+}
+
+func TestAppend(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("stores the chunk and advances the offset", func(mt *mtest.T) {
+		blobs, root := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 10, Status: StatusPending}
+		mt.AddMockResponses(findResponse(mt, upload), updatedResponse(Upload{ID: upload.ID, Size: 10, Offset: 5, Status: StatusPending}))
+
+		updated, err := store.Append(context.Background(), upload.ID.Hex(), 0, strings.NewReader("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), updated.Offset)
+		assert.Len(t, storedChunks(t, root), 1)
+	})
+
+	mt.Run("rejects a chunk at the wrong offset without storing it", func(mt *mtest.T) {
+		blobs, root := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 10, Offset: 5, Status: StatusPending}
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Append(context.Background(), upload.ID.Hex(), 0, strings.NewReader("hello"))
+		assert.True(t, errors.Is(err, ErrOffsetMismatch))
+		assert.Empty(t, storedChunks(t, root))
+	})
+
+	mt.Run("removes its chunk after losing a concurrent append", func(mt *mtest.T) {
+		blobs, root := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 10, Status: StatusPending}
+		mt.AddMockResponses(findResponse(mt, upload), notUpdatedResponse())
+
+		_, err := store.Append(context.Background(), upload.ID.Hex(), 0, strings.NewReader("hello"))
+		assert.True(t, errors.Is(err, ErrOffsetMismatch))
+		assert.Empty(t, storedChunks(t, root))
+	})
+
+	mt.Run("rejects bytes beyond the declared size", func(mt *mtest.T) {
+		blobs, root := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 4, Status: StatusPending}
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Append(context.Background(), upload.ID.Hex(), 0, strings.NewReader("hello"))
+		assert.True(t, errors.Is(err, ErrUploadTooLarge))
+		assert.Empty(t, storedChunks(t, root))
+	})
+
+	mt.Run("reports a chunk it could not remove", func(mt *mtest.T) {
+		blobs, root := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: failingDeleteBlobs{blobs}, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 4, Status: StatusPending}
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Append(context.Background(), upload.ID.Hex(), 0, strings.NewReader("hello"))
+		assert.True(t, errors.Is(err, ErrUploadTooLarge))
+		assert.ErrorContains(t, err, "bucket unavailable")
+		assert.Len(t, storedChunks(t, root), 1)
+	})
+
+	mt.Run("rejects appends to a finalized upload", func(mt *mtest.T) {
+		blobs, _ := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 5, Offset: 5, Status: StatusFinalized}
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Append(context.Background(), upload.ID.Hex(), 5, strings.NewReader("more"))
+		assert.True(t, errors.Is(err, ErrUploadFinalized))
+	})
+}
+
+func TestFinalize(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	// spooled stores content as two chunks of a complete upload.
+	spooled := func(t *testing.T, blobs blobStore.BlobStore, content string) Upload {
+		upload := Upload{ID: primitive.NewObjectID(), Size: int64(len(content)), Offset: int64(len(content)), Status: StatusPending}
+		for _, part := range []string{content[:3], content[3:]} {
+			key := chunkKey(upload.ID, 0)
+			require.NoError(t, blobs.Put(context.Background(), key, strings.NewReader(part)))
+			upload.Chunks = append(upload.Chunks, key)
+		}
+		return upload
+	}
+
+	mt.Run("marks a complete upload with a matching checksum finalized", func(mt *mtest.T) {
+		blobs, _ := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := spooled(t, blobs, "recording")
+		finalized := upload
+		finalized.Status = StatusFinalized
+		mt.AddMockResponses(findResponse(mt, upload), updatedResponse(finalized))
+
+		result, err := store.Finalize(context.Background(), upload.ID.Hex(), checksumOf("recording"))
+		require.NoError(t, err)
+		assert.Equal(t, StatusFinalized, result.Status)
+
+		update := mt.GetAllStartedEvents()[1].Command.Lookup("update", "$set").Document()
+		assert.Equal(t, checksumOf("recording"), update.Lookup("checksum").StringValue())
+	})
+
+	mt.Run("rejects content that does not match the checksum", func(mt *mtest.T) {
+		blobs, _ := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := spooled(t, blobs, "recording")
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Finalize(context.Background(), upload.ID.Hex(), checksumOf("tampered"))
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+		assert.Len(t, mt.GetAllStartedEvents(), 1)
+	})
+
+	mt.Run("uses the checksum given when the upload was created", func(mt *mtest.T) {
+		blobs, _ := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := spooled(t, blobs, "recording")
+		upload.Checksum = checksumOf("other")
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Finalize(context.Background(), upload.ID.Hex(), "")
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+	})
+
+	mt.Run("rejects an incomplete upload", func(mt *mtest.T) {
+		blobs, _ := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 10, Offset: 4, Status: StatusPending}
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		_, err := store.Finalize(context.Background(), upload.ID.Hex(), checksumOf("recording"))
+		assert.True(t, errors.Is(err, ErrUploadIncomplete))
+	})
+}
+
+func TestOpenReadsChunksInOrder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("finalized upload", func(mt *mtest.T) {
+		blobs, _ := newTestBlobs(t)
+		store := &uploadStore{collection: mt.Coll, blobs: blobs, maxSize: testMaxSize}
+		upload := Upload{ID: primitive.NewObjectID(), Size: 9, Offset: 9, Status: StatusFinalized}
+		for _, part := range []string{"rec", "ord", "ing"} {
+			key := chunkKey(upload.ID, 0)
+			require.NoError(t, blobs.Put(context.Background(), key, bytes.NewReader([]byte(part))))
+			upload.Chunks = append(upload.Chunks, key)
+		}
+		mt.AddMockResponses(findResponse(mt, upload))
+
+		audio, err := store.Open(context.Background(), upload.ID.Hex())
+		require.NoError(t, err)
+		defer audio.Close()
+		content, err := io.ReadAll(audio)
+		require.NoError(t, err)
+		assert.Equal(t, "recording", string(content))
+	})
+}
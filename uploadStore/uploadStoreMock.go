@@ -0,0 +1,51 @@
+package uploads
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUploadStore struct {
+	mock.Mock
+}
+
+func (m *MockUploadStore) Create(ctx context.Context, providerID, contentType string, size int64, checksum string) (Upload, error) {
+	args := m.Called(ctx, providerID, contentType, size, checksum)
+	return args.Get(0).(Upload), args.Error(1)
+}
+
+func (m *MockUploadStore) Get(ctx context.Context, uploadID string) (Upload, error) {
+	args := m.Called(ctx, uploadID)
+	return args.Get(0).(Upload), args.Error(1)
+}
+
+func (m *MockUploadStore) Append(ctx context.Context, uploadID string, offset int64, chunk io.Reader) (Upload, error) {
+	args := m.Called(ctx, uploadID, offset, chunk)
+	return args.Get(0).(Upload), args.Error(1)
+}
+
+func (m *MockUploadStore) Finalize(ctx context.Context, uploadID string, checksum string) (Upload, error) {
+	args := m.Called(ctx, uploadID, checksum)
+	return args.Get(0).(Upload), args.Error(1)
+}
+
+func (m *MockUploadStore) Open(ctx context.Context, uploadID string) (io.ReadCloser, error) {
+	args := m.Called(ctx, uploadID)
+	if rc := args.Get(0); rc != nil {
+		return rc.(io.ReadCloser), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUploadStore) Delete(ctx context.Context, uploadID string) error {
+	args := m.Called(ctx, uploadID)
+	return args.Error(0)
+}
+
+func (m *MockUploadStore) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
@@ -26,6 +26,81 @@ var EmailAlreadyExistsError = errors.New("email already exists")
 
 const EmailField = "email"
 
+const VocabularyField = "vocabulary"
+
+const AudioRetentionDaysField = "audioRetentionDays"
+
+// SupervisorIDField holds the ID of the physician who co-signs the user's notes.
+const SupervisorIDField = "supervisorID"
+
+// ErrSupervisorNotFound is returned when assigning a supervisor who is not a user.
+var ErrSupervisorNotFound = errors.New("supervisor not found")
+
+// MaxAudioRetentionDays caps how long a provider can keep visit recordings.
+const MaxAudioRetentionDays = 3650
+
+const (
+	TranscriptRetentionDaysField = "transcriptRetentionDays"
+	NoteRetentionDaysField       = "noteRetentionDays"
+	TokenUsageRetentionDaysField = "tokenUsageRetentionDays"
+)
+
+// MaxRetentionDays caps how long a provider can keep transcripts, notes and token usage.
+const MaxRetentionDays = 36500
+
+// RetentionPolicy says how many days each kind of clinical data is kept before it is purged.
+// Zero means the global default applies; a zero default means the data is kept indefinitely. Recordings
+// are the exception: an unset AudioDays means the default applies and zero means they are not kept.
+type RetentionPolicy struct {
+	AudioDays      *int `json:"audioDays"`
+	TranscriptDays int  `json:"transcriptDays"`
+	NoteDays       int  `json:"noteDays"`
+	TokenUsageDays int  `json:"tokenUsageDays"`
+}
+
+// WithDefaults fills the periods the provider has not set from the global policy.
+func (p RetentionPolicy) WithDefaults(defaults RetentionPolicy) RetentionPolicy {
+	if p.AudioDays == nil {
+		p.AudioDays = defaults.AudioDays
+	}
+	if p.TranscriptDays == 0 {
+		p.TranscriptDays = defaults.TranscriptDays
+	}
+	if p.NoteDays == 0 {
+		p.NoteDays = defaults.NoteDays
+	}
+	if p.TokenUsageDays == 0 {
+		p.TokenUsageDays = defaults.TokenUsageDays
+	}
+	return p
+}
+
+// Validate checks every period is within the allowed range.
+func (p RetentionPolicy) Validate() error {
+	if err := ValidateAudioRetention(p.AudioDays); err != nil {
+		return err
+	}
+	for name, days := range map[string]int{"transcript": p.TranscriptDays, "note": p.NoteDays, "token usage": p.TokenUsageDays} {
+		if days < 0 || days > MaxRetentionDays {
+			return fmt.Errorf("%s retention must be between 0 and %d days", name, MaxRetentionDays)
+		}
+	}
+	return nil
+}
+
+// ValidateAudioRetention checks an audio retention period is unset or within the allowed range.
+func ValidateAudioRetention(days *int) error {
+	if days != nil && (*days < 0 || *days > MaxAudioRetentionDays) {
+		return fmt.Errorf("audio retention must be between 0 and %d days", MaxAudioRetentionDays)
+	}
+	return nil
+}
+
+const (
+	MaxVocabularyTerms      = 500
+	MaxVocabularyTermLength = 100
+)
+
 type User struct {
 	ID                       primitive.ObjectID `bson:"_id,omitempty"`
 	Name                     string             `bson:"name"`
@@ -36,6 +111,23 @@ type User struct {
 	AssessmentAndPlanStyle   string             `bson:"assessmentStyle"`
 	SummaryStyle             string             `bson:"summaryStyle"`
 	PatientInstructionsStyle string             `bson:"patientInstructionsStyle"`
+	Vocabulary               []string           `bson:"vocabulary"`
+	AudioRetentionDays       *int               `bson:"audioRetentionDays,omitempty"`
+	TranscriptRetentionDays  int                `bson:"transcriptRetentionDays"`
+	NoteRetentionDays        int                `bson:"noteRetentionDays"`
+	TokenUsageRetentionDays  int                `bson:"tokenUsageRetentionDays"`
+	// SupervisorID is set for residents and nurse practitioners whose notes must be co-signed.
+	SupervisorID string `bson:"supervisorID,omitempty"`
+}
+
+// RetentionPolicy returns the retention periods the provider has set.
+func (u User) RetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		AudioDays:      u.AudioRetentionDays,
+		TranscriptDays: u.TranscriptRetentionDays,
+		NoteDays:       u.NoteRetentionDays,
+		TokenUsageDays: u.TokenUsageRetentionDays,
+	}
 }
 
 type UserStore interface {
@@ -46,6 +138,11 @@ type UserStore interface {
 	UpdateStyle(ctx context.Context, providerID, contentType, newStyle string) error
 	UpdateProfileSettings(ctx context.Context, userID string, name string, currentPassword string, newPassword string) error 
 	CheckEmailExistence(ctx context.Context, email string) (bool, error)
+	UpdateVocabulary(ctx context.Context, userID string, vocabulary []string) error
+	UpdateAudioRetention(ctx context.Context, userID string, days *int) error
+	UpdateRetentionPolicy(ctx context.Context, userID string, policy RetentionPolicy) error
+	ListRetentionPolicies(ctx context.Context) (map[string]RetentionPolicy, error)
+	SetSupervisor(ctx context.Context, userID, supervisorID string) error
 
 }
 
@@ -287,3 +384,175 @@ func (s *store) UpdateProfileSettings(ctx context.Context, userID string, name s
 	return nil
 }
 
+// ValidateVocabulary checks that a vocabulary list stays within the size limits for a profile.
+func ValidateVocabulary(vocabulary []string) error {
+	if len(vocabulary) > MaxVocabularyTerms {
+		return fmt.Errorf("vocabulary cannot contain more than %d terms", MaxVocabularyTerms)
+	}
+	for _, term := range vocabulary {
+		if len(term) > MaxVocabularyTermLength {
+			return fmt.Errorf("vocabulary term %q exceeds %d characters", term, MaxVocabularyTermLength)
+		}
+	}
+	return nil
+}
+
+// UpdateVocabulary replaces the provider's custom transcription vocabulary.
+func (s *store) UpdateVocabulary(ctx context.Context, userID string, vocabulary []string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	if err := ValidateVocabulary(vocabulary); err != nil {
+		return err
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: VocabularyField, Value: vocabulary}}}}
+	result, err := s.client.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectID}}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update vocabulary: %v", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with id %s", userID)
+	}
+	return nil
+}
+
+// UpdateAudioRetention sets how many days the provider's visit recordings are kept. Nil restores the default
+// and zero stops recordings being kept.
+func (s *store) UpdateAudioRetention(ctx context.Context, userID string, days *int) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	if err := ValidateAudioRetention(days); err != nil {
+		return err
+	}
+
+	update := audioRetentionUpdate(days, bson.D{})
+	result, err := s.client.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectID}}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update audio retention: %v", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with id %s", userID)
+	}
+	return nil
+}
+
+// UpdateRetentionPolicy sets how many days each kind of the provider's clinical data is kept. Zero restores
+// the default, except for recordings, whose default is restored by an unset AudioDays.
+func (s *store) UpdateRetentionPolicy(ctx context.Context, userID string, policy RetentionPolicy) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	update := audioRetentionUpdate(policy.AudioDays, bson.D{
+		{Key: TranscriptRetentionDaysField, Value: policy.TranscriptDays},
+		{Key: NoteRetentionDaysField, Value: policy.NoteDays},
+		{Key: TokenUsageRetentionDaysField, Value: policy.TokenUsageDays},
+	})
+	result, err := s.client.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectID}}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update retention policy: %v", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with id %s", userID)
+	}
+	return nil
+}
+
+// audioRetentionUpdate builds an update that applies set and sets the audio retention period, or unsets it
+// when days is nil so the default applies again.
+func audioRetentionUpdate(days *int, set bson.D) bson.D {
+	if days == nil {
+		update := bson.D{{Key: "$unset", Value: bson.D{{Key: AudioRetentionDaysField, Value: ""}}}}
+		if len(set) > 0 {
+			update = append(update, bson.E{Key: "$set", Value: set})
+		}
+		return update
+	}
+	return bson.D{{Key: "$set", Value: append(bson.D{{Key: AudioRetentionDaysField, Value: *days}}, set...)}}
+}
+
+// ListRetentionPolicies returns the policies of providers who set their own transcript, note or token
+// usage retention, by provider ID. Audio retention is applied when a recording is saved, so providers
+// who only set that are left out.
+func (s *store) ListRetentionPolicies(ctx context.Context) (map[string]RetentionPolicy, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{TranscriptRetentionDaysField: bson.M{"$gt": 0}},
+		bson.M{NoteRetentionDaysField: bson.M{"$gt": 0}},
+		bson.M{TokenUsageRetentionDaysField: bson.M{"$gt": 0}},
+	}}
+	opts := options.Find().SetProjection(bson.M{
+		AudioRetentionDaysField:      1,
+		TranscriptRetentionDaysField: 1,
+		NoteRetentionDaysField:       1,
+		TokenUsageRetentionDaysField: 1,
+	})
+	cursor, err := s.client.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find retention policies: %v", err)
+	}
+	var users []User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode retention policies: %v", err)
+	}
+
+	policies := make(map[string]RetentionPolicy, len(users))
+	for _, u := range users {
+		policies[u.ID.Hex()] = u.RetentionPolicy()
+	}
+	return policies, nil
+}
+
+// SetSupervisor assigns the physician who co-signs the user's notes. An empty supervisorID removes the supervisor.
+// Users cannot supervise themselves or their own supervisor.
+func (s *store) SetSupervisor(ctx context.Context, userID, supervisorID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %v", err)
+	}
+
+	update := bson.M{"$unset": bson.M{SupervisorIDField: ""}}
+	if supervisorID != "" {
+		if supervisorID == userID {
+			return errors.New("users cannot supervise themselves")
+		}
+		supervisorObjectID, err := primitive.ObjectIDFromHex(supervisorID)
+		if err != nil {
+			return fmt.Errorf("invalid supervisor ID format: %v", err)
+		}
+		var supervisor User
+		err = s.client.FindOne(ctx, bson.M{"_id": supervisorObjectID}, options.FindOne().SetProjection(bson.M{SupervisorIDField: 1})).Decode(&supervisor)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return ErrSupervisorNotFound
+			}
+			return fmt.Errorf("failed to retrieve supervisor: %v", err)
+		}
+		if supervisor.SupervisorID == userID {
+			return errors.New("users cannot supervise their own supervisor")
+		}
+		update = bson.M{"$set": bson.M{SupervisorIDField: supervisorID}}
+	}
+
+	result, err := s.client.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update supervisor: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with id %s", userID)
+	}
+	return nil
+}
@@ -46,3 +46,41 @@ func (s *MockUserStore) UpdateProfileSettings(ctx context.Context, userID string
 	return args.Error(0)
 }
 
+// CheckEmailExistence mocks the CheckEmailExistence method.
+func (m *MockUserStore) CheckEmailExistence(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+// UpdateVocabulary mocks the UpdateVocabulary method.
+func (m *MockUserStore) UpdateVocabulary(ctx context.Context, userID string, vocabulary []string) error {
+	args := m.Called(ctx, userID, vocabulary)
+	return args.Error(0)
+}
+
+// UpdateAudioRetention mocks the UpdateAudioRetention method.
+func (m *MockUserStore) UpdateAudioRetention(ctx context.Context, userID string, days *int) error {
+	args := m.Called(ctx, userID, days)
+	return args.Error(0)
+}
+
+// UpdateRetentionPolicy mocks the UpdateRetentionPolicy method.
+func (m *MockUserStore) UpdateRetentionPolicy(ctx context.Context, userID string, policy RetentionPolicy) error {
+	args := m.Called(ctx, userID, policy)
+	return args.Error(0)
+}
+
+// ListRetentionPolicies mocks the ListRetentionPolicies method.
+func (m *MockUserStore) ListRetentionPolicies(ctx context.Context) (map[string]RetentionPolicy, error) {
+	args := m.Called(ctx)
+	if policies := args.Get(0); policies != nil {
+		return policies.(map[string]RetentionPolicy), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// SetSupervisor mocks the SetSupervisor method.
+func (m *MockUserStore) SetSupervisor(ctx context.Context, userID, supervisorID string) error {
+	args := m.Called(ctx, userID, supervisorID)
+	return args.Error(0)
+}
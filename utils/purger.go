@@ -0,0 +1,37 @@
+package utils
+
+import (
+	contextLogger "Medscribe/logger"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Purger removes records whose retention period ended before now and reports how many were removed.
+type Purger interface {
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// StartPurger runs purger every interval in the background until ctx is cancelled.
+func StartPurger(ctx context.Context, name string, purger Purger, interval time.Duration) {
+	logger := contextLogger.FromCtx(ctx).With(zap.String("purger", name))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purged, err := purger.PurgeExpired(ctx, time.Now())
+			if err != nil {
+				logger.Error("StartPurger: failed to purge expired records", zap.Int("purged", purged), zap.Error(err))
+			} else if purged > 0 {
+				logger.Info("StartPurger: purged expired records", zap.Int("purged", purged))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
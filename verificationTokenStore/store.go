@@ -179,7 +179,7 @@ func (s *verificationStore) GetBufferedUserDocument(ctx context.Context, token s
 		return BufferedUserDocument{}, ErrVerificationDocumentNotFound
 	}
 	if err != nil {
-        return BufferedUserDocument{}, fmt.Errorf("failed to get verification document by token '%s': %w", token, err)
+        return BufferedUserDocument{}, fmt.Errorf("failed to get verification document by token: %w", err)
     }
 	return doc, nil
 }
@@ -191,16 +191,15 @@ func (s *verificationStore) GetResetPasswordDetails(ctx context.Context, token s
 	err := s.coll.FindOne(ctx, filter).Decode(&doc)
 
 	if errors.Is(err, mongo.ErrNoDocuments) {
-		return ResetPasswordDetails{}, fmt.Errorf("verification token '%s' not found: %w", token, err)
+		return ResetPasswordDetails{}, fmt.Errorf("verification token not found: %w", err)
 	}
 	if err != nil {
-        return ResetPasswordDetails{}, fmt.Errorf("failed to get verification document by token '%s': %w", token, err)
+        return ResetPasswordDetails{}, fmt.Errorf("failed to get verification document by token: %w", err)
     }
 	return doc, nil
 }
 
 func (s *verificationStore) PutBufferedUserDocument(ctx context.Context, token, name, email, password string) error {
-	fmt.Println("Inserting verification document with token:", token)
 	doc := BufferedUserDocument{
 		Token:     token,
 		Email:     email,
@@ -241,7 +240,7 @@ func (s *verificationStore) Delete(ctx context.Context, token string) error {
 	filter := bson.M{"token": token}
 	result, err := s.coll.DeleteOne(ctx, filter)
 	if err != nil {
-		return fmt.Errorf("failed to delete verification token '%s': %w", token, err)
+		return fmt.Errorf("failed to delete verification token: %w", err)
 	}
 	if result.DeletedCount == 0 {
 		return errors.New("no document found to delete")
@@ -263,6 +262,5 @@ func GenerateRandomToken(size int) (string, error) {
 		}
 		result += num.String()
 	}
-	fmt.Println("Generated token:", result)
 	return result, nil
 }